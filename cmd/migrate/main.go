@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	_ "github.com/lakshay35/finlit-backend/services/environment"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	"github.com/lakshay35/finlit-backend/utils/migrations"
+	_ "github.com/lib/pq"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up              apply all pending migrations
+  down            revert the most recently applied migration
+  status          list migrations and whether they are applied
+  to <version>    migrate up or down to the given version`
+
+func main() {
+	if len(os.Args) < 2 {
+		exit(usage)
+	}
+
+	database.InitializeDatabase()
+	db := database.GetDatabase()
+
+	var err error
+
+	switch os.Args[1] {
+	case "up":
+		err = migrations.Up(db)
+	case "down":
+		err = migrations.Down(db)
+	case "status":
+		err = printStatus()
+	case "to":
+		if len(os.Args) < 3 {
+			exit(usage)
+		}
+
+		version, parseErr := strconv.Atoi(os.Args[2])
+
+		if parseErr != nil {
+			exit("version must be an integer")
+		}
+
+		err = migrations.To(db, version)
+	default:
+		exit(usage)
+	}
+
+	if err != nil {
+		exit(err.Error())
+	}
+
+	if os.Args[1] != "status" {
+		version, versionErr := migrations.CurrentVersion(db)
+
+		if versionErr != nil {
+			exit(versionErr.Error())
+		}
+
+		fmt.Printf("database is at version %d (latest %d)\n", version, migrations.LatestVersion())
+	}
+}
+
+func printStatus() error {
+	statuses, err := migrations.Status(database.GetDatabase())
+
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+
+		if status.Applied {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Printf("%4d  %-20s  %s\n", status.Version, appliedAt, status.Description)
+	}
+
+	return nil
+}
+
+func exit(message string) {
+	fmt.Fprintln(os.Stderr, message)
+	os.Exit(1)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"time"
 
 	"github.com/lakshay35/finlit-backend/docs"
@@ -11,7 +12,17 @@ import (
 	_ "github.com/lakshay35/finlit-backend/docs"
 	"github.com/lakshay35/finlit-backend/middlewares"
 	"github.com/lakshay35/finlit-backend/routes"
+	accountService "github.com/lakshay35/finlit-backend/services/account"
+	budgetService "github.com/lakshay35/finlit-backend/services/budget"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	fitnessService "github.com/lakshay35/finlit-backend/services/fitness_tracker_history"
+	goalService "github.com/lakshay35/finlit-backend/services/goal"
+	notifierService "github.com/lakshay35/finlit-backend/services/notifier"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	userService "github.com/lakshay35/finlit-backend/services/user"
+	webhookService "github.com/lakshay35/finlit-backend/services/webhook"
 	"github.com/lakshay35/finlit-backend/utils/database"
+	"github.com/lakshay35/finlit-backend/utils/migrations"
 	_ "github.com/lib/pq"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
@@ -32,6 +43,20 @@ func setupSwaggerMetadata() {
 	docs.SwaggerInfo.Schemes = []string{"http", "https"}
 }
 
+// setupStores ...
+// Injects the postgres backed stores into each service
+func setupStores() {
+	accountService.SetStore(accountService.NewPostgresAccountStore())
+	budgetService.SetStore(budgetService.NewPostgresBudgetStore())
+	expenseService.SetStore(expenseService.NewPostgresExpenseStore())
+	fitnessService.SetStore(fitnessService.NewPostgresFitnessStore())
+	goalService.SetStore(goalService.NewPostgresGoalStore())
+	notifierService.SetStore(notifierService.NewPostgresNotificationStore())
+	roleService.SetStore(roleService.NewPostgresRoleStore())
+	userService.SetStore(userService.NewPostgresUserStore())
+	webhookService.SetStore(webhookService.NewPostgresWebhookStore())
+}
+
 // @contact.name Lakshay Sharma
 // @contact.url sharmalakshay.com
 // @contact.email lakshay35@gmail.com
@@ -48,6 +73,19 @@ func main() {
 
 	database.InitializeDatabase()
 
+	// Refuse to serve requests against a schema that
+	// doesn't match what the code expects
+	if err := migrations.EnsureCurrent(database.GetDatabase()); err != nil {
+		panic(err)
+	}
+
+	setupStores()
+
+	// Budget alerts are evaluated in the background
+	// whenever a sync brings in new transactions
+	accountService.OnTransactionsSynced(budgetService.QueueAlertEvaluation)
+	budgetService.StartAlertEvaluator(context.Background())
+
 	r := gin.Default()
 
 	r.Use(cors.New(cors.Config{
@@ -62,9 +100,17 @@ func main() {
 		MaxAge: 12 * time.Hour,
 	}))
 
+	// Plaid authenticates webhooks with a signed
+	// header rather than a Google access token
+	webhooks := r.Group("/webhooks")
+	{
+		webhooks.POST("/plaid", routes.PlaidWebhook)
+	}
+
 	api := r.Group("/api")
 	{
 		api.Use(middlewares.TokenAuthMiddleware())
+		api.Use(middlewares.UnitOfWorkMiddleware())
 		budget := api.Group("/budget")
 		{
 			budget.GET("/get", routes.GetBudgets)
@@ -74,15 +120,49 @@ func main() {
 			budget.POST("/create-transaction-source", routes.CreateBudgetTransactionSource)
 			budget.DELETE("/delete-transaction-source/:budget-transaction-source-id", routes.DeleteBudgetTransactionSource)
 			budget.GET("/get-expense-summary", routes.GetBudgetExpenseSummary)
+			budget.GET("/expense-trends", routes.GetBudgetExpenseTrends)
 			budget.GET("/transaction-categories", routes.GetTransactionCategories)
 			budget.DELETE("/transaction-categories/delete/:budget-transaction-category-id", routes.DeleteBudgetTransactionCategory)
 			budget.POST("/transaction-categories/create", routes.CreateBudgetTransactionCategory)
+			budget.PUT("/transaction-categories/move", routes.MoveBudgetTransactionCategory)
+			budget.PUT("/transaction-categories/rename", routes.RenameBudgetTransactionCategory)
+			budget.POST("/transaction-categories/merge", routes.MergeBudgetTransactionCategories)
+			budget.GET("/categorization-rules", routes.GetCategorizationRules)
+			budget.POST("/categorization-rules/create", routes.CreateCategorizationRule)
+			budget.PUT("/categorization-rules/update", routes.UpdateCategorizationRule)
+			budget.DELETE("/categorization-rules/delete/:rule-id", routes.DeleteCategorizationRule)
+			budget.POST("/categorization-rules/test", routes.TestCategorizationRules)
+			budget.GET("/categorization-suggestions", routes.GetCategorySuggestions)
+			budget.POST("/categorization-suggestions/apply", routes.ApplyCategorySuggestions)
+			budget.GET("/inbox", routes.GetUncategorizedInbox)
+			budget.POST("/inbox/categorize", routes.BulkCategorize)
+			budget.GET("/plaid-category-mappings", routes.GetPlaidCategoryMappings)
+			budget.POST("/plaid-category-mappings/create", routes.CreatePlaidCategoryMapping)
+			budget.DELETE("/plaid-category-mappings/delete/:mapping-id", routes.DeletePlaidCategoryMapping)
+			budget.POST("/plaid-category-mappings/defaults", routes.ApplyDefaultPlaidCategoryMappings)
+			budget.GET("/merchant-aliases", routes.GetMerchantAliases)
+			budget.POST("/merchant-aliases/create", routes.CreateMerchantAlias)
+			budget.DELETE("/merchant-aliases/delete/:alias-id", routes.DeleteMerchantAlias)
+			budget.GET("/alert-rules", routes.GetAlertRules)
+			budget.POST("/alert-rules/create", routes.CreateAlertRule)
+			budget.DELETE("/alert-rules/delete/:alert-rule-id", routes.DeleteAlertRule)
+			budget.GET("/recurring-series", routes.GetRecurringSeries)
+			budget.POST("/recurring-series/convert", routes.ConvertRecurringSeries)
+			budget.GET("/income-sources", routes.GetIncomeSources)
+			budget.POST("/income-sources/create", routes.CreateIncomeSource)
+			budget.DELETE("/income-sources/delete/:income-source-id", routes.DeleteIncomeSource)
+			budget.POST("/zero-based", routes.SetBudgetZeroBased)
 		}
 		user := api.Group("/user")
 		{
 			user.POST("/register", routes.RegisterUser)
 			user.GET("/get", routes.GetUserProfile)
 		}
+		notification := api.Group("/notification")
+		{
+			notification.GET("/get", routes.GetNotifications)
+			notification.POST("/read/:notification-id", routes.MarkNotificationRead)
+		}
 		role := api.Group("/role")
 		{
 			role.POST("/add-user-role-to-budget", routes.AddUserRoleToBudget)
@@ -90,14 +170,17 @@ func main() {
 		account := api.Group("/account")
 		{
 			account.GET("/get", routes.GetAllAccounts)
+			account.GET("/items", routes.GetItems)
 			account.POST("/get-account-details", routes.GetAccountInformation)
 			account.GET("/create-link-token", routes.CreateLinkToken)
 			account.POST("/transactions", routes.GetTransactions)
+			account.POST("/sync-transactions", routes.SyncTransactions)
 			account.POST("/live-balances", routes.GetCurrentBalances)
 			account.POST("/register-token", routes.RegisterAccessToken)
 			account.GET("/get-account/:external-account-id", routes.GetAccountByID)
 			account.DELETE("/delete/:external-account-id", routes.DeleteAccount)
 			account.POST("/renew-access-token", routes.RenewAccessToken)
+			account.POST("/renew-access-token/complete", routes.CompleteAccessTokenRenewal)
 		}
 		expense := api.Group("/expense")
 		{
@@ -107,6 +190,14 @@ func main() {
 			expense.DELETE("/delete/:id", routes.DeleteExpense)
 			expense.PUT("/update", routes.UpdateExpense)
 		}
+		goal := api.Group("/goal")
+		{
+			goal.GET("/get", routes.GetBudgetGoals)
+			goal.POST("/create", routes.CreateGoal)
+			goal.DELETE("/delete/:goal-id", routes.DeleteGoal)
+			goal.GET("/contributions/:goal-id", routes.GetGoalContributions)
+			goal.POST("/contributions/create", routes.AddGoalContribution)
+		}
 		transaction := api.Group("/transaction")
 		{
 			transaction.POST("/categorize", routes.CategorizeExpense)
@@ -48,7 +48,7 @@ func TokenAuthMiddleware() gin.HandlerFunc {
 
 		// Bypasses user enrichment when a user is being registered
 		if !strings.HasSuffix(c.Request.RequestURI, "/user/register") && !strings.HasSuffix(c.Request.RequestURI, "/user/profile") {
-			user, err := userService.GetUser(tokenInfo.UserId)
+			user, err := userService.GetUser(c.Request.Context(), tokenInfo.UserId)
 
 			if err != nil {
 				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
@@ -0,0 +1,112 @@
+package middlewares
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+	"github.com/lakshay35/finlit-backend/utils/requests"
+)
+
+// UnitOfWorkMiddleware ...
+// Runs each request's writes in a single database
+// transaction carried by the request context. The
+// transaction begins with the first statement and
+// commits when the handler succeeds and rolls back
+// when it responds with an error status or panics.
+// Services that call Plaid run outside it so it is
+// never held open during those calls
+func UnitOfWorkMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, uow := database.BeginUnitOfWork(c.Request.Context())
+
+		// Hold the response back until the transaction
+		// is settled so clients never see a success that
+		// didn't commit
+		writer := &bufferedResponseWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Request = c.Request.WithContext(ctx)
+
+		// Runs on panics too, letting the recovery
+		// middleware write straight to the client.
+		// Rollback is a no-op once committed
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			uow.Rollback()
+		}()
+
+		c.Next()
+
+		c.Writer = writer.ResponseWriter
+
+		if writer.status < http.StatusBadRequest && len(c.Errors) == 0 {
+			if commitErr := uow.Commit(); commitErr != nil {
+				logging.ErrorLogger.Println(commitErr)
+				requests.ThrowError(c, http.StatusInternalServerError, "Unable to save changes")
+				return
+			}
+		} else {
+			uow.Rollback()
+		}
+
+		writer.flush()
+	}
+}
+
+// bufferedResponseWriter ...
+// Collects the status and body written by
+// handlers until flush is called
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+
+	return w.body.Len()
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.written
+}
+
+func (w *bufferedResponseWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	} else {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
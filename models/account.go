@@ -7,9 +7,14 @@ import "github.com/google/uuid"
 type Account struct {
 	ExternalAccountID uuid.UUID `json:"external_account_id"`
 	AccountName       string    `json:"account_name,omitempty"`
+	OfficialName      string    `json:"official_name,omitempty"`
+	Mask              string    `json:"mask,omitempty"`
 	UserID            uuid.UUID `json:"user_id"`
-	AccessToken       string    `json:"access_token,omitempty"`
 	InstitutionalID   string    `json:"institutional_id,omitempty"`
+	PlaidItemID       uuid.UUID `json:"plaid_item_id"`
+	ItemID            string    `json:"item_id,omitempty"`
+	ItemStatus        string    `json:"item_status,omitempty"`
+	NeedsAttention    bool      `json:"needs_attention"`
 }
 
 type AccountIdPayload struct {
@@ -0,0 +1,16 @@
+package models
+
+import "github.com/google/uuid"
+
+// AccountDisconnection ...
+// What disconnecting an external account removed. Income
+// sources that only counted deposits into the account
+// count deposits into any of their budget's accounts.
+// Categorization rules limited to the account are deleted
+type AccountDisconnection struct {
+	ExternalAccountID     uuid.UUID            `json:"external_account_id"`
+	PlaidItemRemoved      bool                 `json:"plaid_item_removed"`
+	AffectedBudgets       []Budget             `json:"affected_budgets"`
+	UnlinkedIncomeSources []IncomeSource       `json:"unlinked_income_sources"`
+	DeletedRules          []CategorizationRule `json:"deleted_categorization_rules"`
+}
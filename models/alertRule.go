@@ -0,0 +1,35 @@
+package models
+
+import "github.com/google/uuid"
+
+// Kinds of alert rules
+const (
+	AlertKindThreshold       = "threshold"
+	AlertKindTransactionOver = "transaction_over"
+)
+
+// Channels alerts are delivered through
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelWebhook = "webhook"
+	NotificationChannelInApp   = "in_app"
+)
+
+// AlertRule ...
+// Alerts a user about spending in a budget. Threshold rules fire
+// when what an expense spent in its current period reaches each of
+// Thresholds, percentages of its ExpenseValue, and transaction_over
+// rules fire for every transaction over Amount. Rules without an
+// expense watch every expense, or every transaction, of the budget
+type AlertRule struct {
+	AlertRuleID uuid.UUID  `json:"alert_rule_id"`
+	BudgetID    uuid.UUID  `json:"budget_id"`
+	ExpenseID   *uuid.UUID `json:"expense_id,omitempty"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Kind        string     `json:"kind"`
+	Thresholds  []int      `json:"thresholds,omitempty"`
+	Amount      float64    `json:"amount,omitempty"`
+	Channel     string     `json:"channel"`
+	WebhookURL  string     `json:"webhook_url,omitempty"`
+	CreatedAt   string     `json:"created_at,omitempty"`
+}
@@ -3,10 +3,12 @@ package models
 import "github.com/google/uuid"
 
 // Budget ...
+// Zero-based budgets plan every dollar of their income
 type Budget struct {
 	BudgetName string    `json:"budget_name,omitempty"`
 	BudgetID   uuid.UUID `json:"budget_id,omitempty"`
 	OwnerID    uuid.UUID `json:"owner_id,omitempty"`
+	ZeroBased  bool      `json:"zero_based"`
 }
 
 // CreateBudgetPayload ...
@@ -0,0 +1,41 @@
+package models
+
+import "github.com/google/uuid"
+
+// BudgetSourceIssue ...
+// A budget transaction source whose transactions are
+// missing or going stale in an expense summary
+type BudgetSourceIssue struct {
+	BudgetTransactionSourceID uuid.UUID `json:"budget_transaction_source_id"`
+	ExternalAccountID         uuid.UUID `json:"external_account_id"`
+	AccountName               string    `json:"account_name,omitempty"`
+	ItemStatus                string    `json:"item_status,omitempty"`
+	Message                   string    `json:"message"`
+	Skipped                   bool      `json:"skipped"`
+}
+
+// SummaryPeriod ...
+// The window a budget expense summary covers. Either StartDate
+// and EndDate (YYYY-MM-DD, both inclusive), or every expense's
+// charge cycle period PeriodsAgo periods before the current one
+type SummaryPeriod struct {
+	StartDate  string `json:"start_date,omitempty"`
+	EndDate    string `json:"end_date,omitempty"`
+	PeriodsAgo int    `json:"periods_ago,omitempty"`
+}
+
+// BudgetExpenseSummary ...
+// Expense summaries of a budget, its spending rolled up the
+// category hierarchy and its income between PeriodStart and
+// PeriodEnd and the transaction sources that couldn't be fully
+// used. ZeroBased is only given for zero-based budgets
+type BudgetExpenseSummary struct {
+	PeriodStart        string              `json:"period_start"`
+	PeriodEnd          string              `json:"period_end"`
+	Expenses           []ExpenseSummary    `json:"expenses"`
+	Categories         []CategoryRollup    `json:"categories"`
+	UncategorizedTotal float64             `json:"uncategorized_total"`
+	Income             IncomeSummary       `json:"income"`
+	ZeroBased          *ZeroBasedSummary   `json:"zero_based,omitempty"`
+	SourceIssues       []BudgetSourceIssue `json:"source_issues"`
+}
@@ -3,14 +3,73 @@ package models
 import "github.com/google/uuid"
 
 // BudgetTransactionCategory ...
+// Categories without a parent are at the top of the budget's hierarchy
 type BudgetTransactionCategory struct {
-	BudgetTransactionCategoryID uuid.UUID `json:"budget_transaction_category_id"`
-	BudgetID                    uuid.UUID `json:"budget_id"`
-	CategoryName                string    `json:"category_name"`
+	BudgetTransactionCategoryID uuid.UUID  `json:"budget_transaction_category_id"`
+	BudgetID                    uuid.UUID  `json:"budget_id"`
+	CategoryName                string     `json:"category_name"`
+	ParentCategoryID            *uuid.UUID `json:"parent_category_id,omitempty"`
 }
 
 // BudgetTransactionCategoryCreationPayload ...
 type BudgetTransactionCategoryCreationPayload struct {
-	BudgetID     uuid.UUID `json:"budget_id"`
-	CategoryName string    `json:"category_name"`
+	BudgetID         uuid.UUID  `json:"budget_id"`
+	CategoryName     string     `json:"category_name"`
+	ParentCategoryID *uuid.UUID `json:"parent_category_id,omitempty"`
+}
+
+// BudgetTransactionCategoryMovePayload ...
+// Moves a category under another one of the same budget,
+// or to the top of the hierarchy without a parent
+type BudgetTransactionCategoryMovePayload struct {
+	BudgetTransactionCategoryID uuid.UUID  `json:"budget_transaction_category_id"`
+	ParentCategoryID            *uuid.UUID `json:"parent_category_id"`
+}
+
+// CategoryRollup ...
+// Spending in a category of a budget's hierarchy. Total adds
+// up the category's own spending and that of its descendants
+type CategoryRollup struct {
+	BudgetTransactionCategoryID uuid.UUID        `json:"budget_transaction_category_id"`
+	CategoryName                string           `json:"category_name"`
+	OwnTotal                    float64          `json:"own_total"`
+	Total                       float64          `json:"total"`
+	TransactionCount            int              `json:"transaction_count"`
+	Children                    []CategoryRollup `json:"children"`
+}
+
+// BudgetTransactionCategoryRenamePayload ...
+type BudgetTransactionCategoryRenamePayload struct {
+	BudgetTransactionCategoryID uuid.UUID `json:"budget_transaction_category_id"`
+	CategoryName                string    `json:"category_name"`
+	DryRun                      bool      `json:"dry_run"`
+}
+
+// BudgetTransactionCategoryMergePayload ...
+// Merges the source category into the target
+// category of the same budget
+type BudgetTransactionCategoryMergePayload struct {
+	SourceCategoryID uuid.UUID `json:"source_category_id"`
+	TargetCategoryID uuid.UUID `json:"target_category_id"`
+	DryRun           bool      `json:"dry_run"`
+}
+
+// TransactionCategoryChange ...
+// What renaming, merging or deleting a category touches. The
+// counts are of rows referring to the category, which follow
+// it to its new name or to the target category, or are
+// removed when it's deleted without one. Nothing is changed
+// when DryRun is set
+type TransactionCategoryChange struct {
+	BudgetTransactionCategoryID uuid.UUID  `json:"budget_transaction_category_id"`
+	CategoryName                string     `json:"category_name"`
+	NewCategoryName             string     `json:"new_category_name,omitempty"`
+	TargetCategoryID            *uuid.UUID `json:"target_category_id,omitempty"`
+	TargetCategoryName          string     `json:"target_category_name,omitempty"`
+	DryRun                      bool       `json:"dry_run"`
+	TransactionMappings         int        `json:"transaction_mappings"`
+	CategorizationRules         int        `json:"categorization_rules"`
+	PlaidCategoryMappings       int        `json:"plaid_category_mappings"`
+	ExpenseLinks                int        `json:"expense_links"`
+	Subcategories               int        `json:"subcategories"`
 }
@@ -4,12 +4,15 @@ import "github.com/google/uuid"
 
 // BudgetTransactionCategoryTransaction ...
 type BudgetTransactionCategoryTransaction struct {
-	TransactionName string `json:"transaction_name"`
-	CategoryName    string `json:"category_name"`
+	BudgetTransactionCategoryID uuid.UUID `json:"budget_transaction_category_id"`
+	TransactionName             string    `json:"transaction_name"`
+	CategoryName                string    `json:"category_name"`
+	PlaidCategory               []string  `json:"plaid_category,omitempty"`
 }
 
 //BudgetTransactionCategoryTransactionCreationPayload ...
 type BudgetTransactionCategoryTransactionCreationPayload struct {
+	TransactionID   string    `json:"transaction_id,omitempty"`
 	TransactionName string    `json:"transaction_name"`
 	CategoryName    string    `json:"category_name"`
 	BudgetID        uuid.UUID `json:"budget_id"`
@@ -0,0 +1,60 @@
+package models
+
+import "github.com/google/uuid"
+
+// Sources of a transaction's category
+const (
+	CategorizationSourceAssignment = "assignment"
+	CategorizationSourceRule       = "rule"
+	CategorizationSourcePlaid      = "plaid_category"
+)
+
+// CategorizationRule ...
+// Puts every transaction of a budget matching all of its
+// conditions in a transaction category. Empty conditions
+// match anything and lower priorities are tried first
+type CategorizationRule struct {
+	RuleID                      uuid.UUID  `json:"rule_id"`
+	BudgetID                    uuid.UUID  `json:"budget_id"`
+	BudgetTransactionCategoryID uuid.UUID  `json:"budget_transaction_category_id"`
+	CategoryName                string     `json:"category_name,omitempty"`
+	Priority                    int        `json:"priority"`
+	NameContains                string     `json:"name_contains,omitempty"`
+	NamePrefix                  string     `json:"name_prefix,omitempty"`
+	NameRegex                   string     `json:"name_regex,omitempty"`
+	Merchant                    string     `json:"merchant,omitempty"`
+	MinAmount                   *float64   `json:"min_amount,omitempty"`
+	MaxAmount                   *float64   `json:"max_amount,omitempty"`
+	ExternalAccountID           *uuid.UUID `json:"external_account_id,omitempty"`
+	PlaidCategory               string     `json:"plaid_category,omitempty"`
+}
+
+// CategorizationRuleTestPayload ...
+// Transaction to run through a budget's categorization.
+// Either the id of a synced transaction or its fields
+type CategorizationRuleTestPayload struct {
+	BudgetID          uuid.UUID  `json:"budget_id"`
+	TransactionID     string     `json:"transaction_id,omitempty"`
+	Name              string     `json:"name,omitempty"`
+	Merchant          string     `json:"merchant,omitempty"`
+	Amount            float64    `json:"amount,omitempty"`
+	ExternalAccountID *uuid.UUID `json:"external_account_id,omitempty"`
+	Category          []string   `json:"category,omitempty"`
+}
+
+// TransactionCategorization ...
+// The category a transaction lands in and what put it there.
+// MatchingRules lists every rule matching it in the order
+// they are tried, the first one decides unless the
+// transaction's name was assigned a category directly.
+// Transactions no rule matches fall back to the budget's
+// Plaid category mappings
+type TransactionCategorization struct {
+	TransactionName string                `json:"transaction_name"`
+	Merchant        string                `json:"merchant,omitempty"`
+	CategoryName    string                `json:"category_name"`
+	Source          string                `json:"source,omitempty"`
+	MatchedRule     *CategorizationRule   `json:"matched_rule,omitempty"`
+	MatchedMapping  *PlaidCategoryMapping `json:"matched_mapping,omitempty"`
+	MatchingRules   []CategorizationRule  `json:"matching_rules"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "github.com/google/uuid"
+
+// CategorySuggestion ...
+// A category suggested for a transaction along with
+// how confident the suggestion is, from 0 to 1
+type CategorySuggestion struct {
+	BudgetTransactionCategoryID uuid.UUID `json:"budget_transaction_category_id"`
+	CategoryName                string    `json:"category_name"`
+	Confidence                  float64   `json:"confidence"`
+}
+
+// TransactionCategorySuggestions ...
+// Ranked category suggestions for an uncategorized transaction.
+// Applied is set when the top suggestion was applied to it
+type TransactionCategorySuggestions struct {
+	TransactionID     string               `json:"transaction_id"`
+	TransactionName   string               `json:"transaction_name"`
+	Merchant          string               `json:"merchant,omitempty"`
+	Amount            float64              `json:"amount"`
+	Date              string               `json:"date"`
+	ExternalAccountID uuid.UUID            `json:"external_account_id"`
+	Suggestions       []CategorySuggestion `json:"suggestions"`
+	Applied           *CategorySuggestion  `json:"applied,omitempty"`
+}
+
+// CategorySuggestionApplyPayload ...
+// Applies every top suggestion of a budget whose
+// confidence is at least the threshold
+type CategorySuggestionApplyPayload struct {
+	BudgetID  uuid.UUID `json:"budget_id"`
+	Threshold float64   `json:"threshold"`
+}
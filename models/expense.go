@@ -3,7 +3,11 @@ package models
 import "github.com/google/uuid"
 
 // Expense ...
-// Represents an expense entity
+// Represents an expense entity. Expenses that roll over carry what
+// is left of a period into the next, or take what was spent over
+// it out of the next. RolloverCap limits the carried amount either
+// way, 0 meaning no limit. RolloverStart is the day rollover was
+// turned on and is set by the service
 type Expense struct {
 	ExpenseID                    uuid.UUID          `json:"expense_id,omitempty"`
 	BudgetID                     uuid.UUID          `json:"budget_id"`
@@ -12,6 +16,9 @@ type Expense struct {
 	ExpenseDescription           string             `json:"expense_description,omitempty"`
 	ExpenseChargeCycle           ExpenseChargeCycle `json:"expense_charge_cycle"`
 	ExpenseTransactionCategories []string           `json:"expense_transaction_categories"`
+	Rollover                     bool               `json:"rollover"`
+	RolloverCap                  float32            `json:"rollover_cap,omitempty"`
+	RolloverStart                string             `json:"rollover_start,omitempty"`
 }
 
 // AddExpensePayload payload for incoming expense addition requests
@@ -23,6 +30,9 @@ type AddExpensePayload struct {
 	ExpenseChargeCycle           ExpenseChargeCycle `json:"expense_charge_cycle"`
 	BudgetTransactionCategoryID  uuid.UUID          `json:"budget_transaction_category_id"`
 	ExpenseTransactionCategories []string           `json:"expense_transaction_categories"`
+	Rollover                     bool               `json:"rollover"`
+	RolloverCap                  float32            `json:"rollover_cap,omitempty"`
+	RolloverStart                string             `json:"-"`
 }
 
 // ExpenseBudgetTransactionCategory ...
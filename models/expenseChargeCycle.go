@@ -1,8 +1,15 @@
 package models
 
 // ExpenseChargeCycle ...
+// How often an expense is charged. Periods follow the calendar,
+// starting from AnchorDate (YYYY-MM-DD) where the unit needs one.
+// IntervalWeeks is only used by "every-n-weeks" and DayOfMonth by
+// "day-of-month" cycles. Days is the approximate length of a period
 type ExpenseChargeCycle struct {
 	ExpenseChargeCycleID int    `json:"expense_charge_cycle_id"`
 	Unit                 string `json:"unit"`
 	Days                 int    `json:"days"`
+	AnchorDate           string `json:"anchor_date,omitempty"`
+	IntervalWeeks        int    `json:"interval_weeks,omitempty"`
+	DayOfMonth           int    `json:"day_of_month,omitempty"`
 }
@@ -0,0 +1,18 @@
+package models
+
+// ExpenseForecast ...
+// Where an expense's spending is headed by the end of its current
+// period. ProjectedTotal combines the pace of the period so far with
+// the spending of the periods before it, plus RecurringDue, what
+// charges seen in most earlier periods but not yet in this one
+// usually come to. Low and High bound the projection with about 80%
+// confidence and ProjectedOverUnder is how far ProjectedTotal is
+// over what's available, negative when it stays under
+type ExpenseForecast struct {
+	ProjectedTotal     float64 `json:"projected_total"`
+	Low                float64 `json:"low"`
+	High               float64 `json:"high"`
+	RecurringDue       float64 `json:"recurring_due"`
+	ProjectedOverUnder float64 `json:"projected_over_under"`
+	HistoryPeriods     int     `json:"history_periods"`
+}
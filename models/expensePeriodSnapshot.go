@@ -0,0 +1,19 @@
+package models
+
+import "github.com/google/uuid"
+
+// ExpensePeriodSnapshot ...
+// A finished period of an expense that rolls over. CarriedIn came
+// from the period before, and CarriedOut, what was left of Limit
+// and CarriedIn after Actual was spent, goes to the next one.
+// Dates are YYYY-MM-DD and PeriodEnd is the last day of the period
+type ExpensePeriodSnapshot struct {
+	SnapshotID  uuid.UUID `json:"snapshot_id"`
+	ExpenseID   uuid.UUID `json:"expense_id"`
+	PeriodStart string    `json:"period_start"`
+	PeriodEnd   string    `json:"period_end"`
+	Limit       float64   `json:"limit"`
+	CarriedIn   float64   `json:"carried_in"`
+	Actual      float64   `json:"actual"`
+	CarriedOut  float64   `json:"carried_out"`
+}
@@ -1,19 +1,28 @@
 package models
 
-import "github.com/plaid/plaid-go/plaid"
-
 // ExpenseSummary ...
+// Spending of an expense in the current period of its charge
+// cycle, which runs from PeriodStart to PeriodEnd inclusive.
+// Available is the limit plus what rolled over from the
+// period before, which is negative after overspending.
+// Forecast is only given for the current period
 type ExpenseSummary struct {
 	ExpenseName            string                   `json:"expense_name"`
 	ExpenseChargeCycleDays int                      `json:"expense_charge_cycle_days"`
+	PeriodStart            string                   `json:"period_start"`
+	PeriodEnd              string                   `json:"period_end"`
 	ExpenseLimit           float64                  `json:"expense_limit"`
+	CarriedOver            float64                  `json:"carried_over"`
+	Available              float64                  `json:"available"`
 	CurrentExpense         float64                  `json:"current_expense"`
+	Forecast               *ExpenseForecast         `json:"forecast,omitempty"`
 	ExpenseCategories      []ExpenseCategorySummary `json:"categories"`
 }
 
 // ExpenseCategorySummary ...
-//
+// The transactions of a category with their merchants
+// named the way the budget's aliases name them
 type ExpenseCategorySummary struct {
-	CategoryName string              `json:"category_name"`
-	Transactions []plaid.Transaction `json:"transactions"`
+	CategoryName string        `json:"category_name"`
+	Transactions []Transaction `json:"transactions"`
 }
@@ -0,0 +1,26 @@
+package models
+
+import "github.com/google/uuid"
+
+// ExpensePeriod ...
+// An expense's limit and spending in one period of its charge
+// cycle. CarriedOver is what rolled over from the period before.
+// Variance is what was spent over the limit and carried amount,
+// negative when spending stayed below them
+type ExpensePeriod struct {
+	PeriodStart string  `json:"period_start"`
+	PeriodEnd   string  `json:"period_end"`
+	Limit       float64 `json:"limit"`
+	CarriedOver float64 `json:"carried_over"`
+	Actual      float64 `json:"actual"`
+	Variance    float64 `json:"variance"`
+}
+
+// ExpenseTrend ...
+// The last periods of an expense, oldest first.
+// The last one is the current, unfinished period
+type ExpenseTrend struct {
+	ExpenseID   uuid.UUID       `json:"expense_id"`
+	ExpenseName string          `json:"expense_name"`
+	Periods     []ExpensePeriod `json:"periods"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "github.com/google/uuid"
+
+// IncomeSource ...
+// Money a budget expects to come in, ExpectedAmount every period
+// of ChargeCycle. Deposits belong to the source when Payer is part
+// of their merchant or name, ignoring case, and when they went
+// into ExternalAccountID if it's set
+type IncomeSource struct {
+	IncomeSourceID    uuid.UUID          `json:"income_source_id,omitempty"`
+	BudgetID          uuid.UUID          `json:"budget_id"`
+	IncomeSourceName  string             `json:"income_source_name"`
+	ExpectedAmount    float64            `json:"expected_amount"`
+	ChargeCycle       ExpenseChargeCycle `json:"charge_cycle"`
+	Payer             string             `json:"payer"`
+	ExternalAccountID *uuid.UUID         `json:"external_account_id,omitempty"`
+}
+
+// ZeroBasedPayload ...
+// Turns zero-based budgeting on or off for a budget
+type ZeroBasedPayload struct {
+	BudgetID  uuid.UUID `json:"budget_id"`
+	ZeroBased bool      `json:"zero_based"`
+}
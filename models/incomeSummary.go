@@ -0,0 +1,39 @@
+package models
+
+import "github.com/google/uuid"
+
+// IncomeSourceSummary ...
+// What an income source was expected to bring in over a
+// summary's window and the deposits that matched it
+type IncomeSourceSummary struct {
+	IncomeSourceID   uuid.UUID     `json:"income_source_id"`
+	IncomeSourceName string        `json:"income_source_name"`
+	Expected         float64       `json:"expected"`
+	Received         float64       `json:"received"`
+	Deposits         []Transaction `json:"deposits"`
+}
+
+// IncomeSummary ...
+// Income of a budget between a summary's PeriodStart and
+// PeriodEnd. Expected is prorated over the window the way
+// expense limits are. OtherDeposits came in without matching
+// a source and aren't refunds, transfers or card payments
+type IncomeSummary struct {
+	Sources            []IncomeSourceSummary `json:"sources"`
+	Expected           float64               `json:"expected"`
+	Received           float64               `json:"received"`
+	OtherDeposits      []Transaction         `json:"other_deposits"`
+	OtherDepositsTotal float64               `json:"other_deposits_total"`
+}
+
+// ZeroBasedSummary ...
+// Where every dollar of a zero-based budget's income went over a
+// summary's window. Income counts what each source received or,
+// while it's short, what it was expected to bring in. Planned is
+// what the expenses may spend in the window and Unassigned is
+// Income minus Planned, which a zero-based budget keeps at zero
+type ZeroBasedSummary struct {
+	Income     float64 `json:"income"`
+	Planned    float64 `json:"planned"`
+	Unassigned float64 `json:"unassigned"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "github.com/google/uuid"
+
+// MerchantAlias ...
+// Renames a merchant within a budget. Merchant is what a
+// transaction's name was cleaned up to, matched ignoring
+// case, and Alias what the budget calls it instead, e.g.
+// "Starbucks Store Seattle WA" to "Starbucks"
+type MerchantAlias struct {
+	AliasID  uuid.UUID `json:"alias_id"`
+	BudgetID uuid.UUID `json:"budget_id"`
+	Merchant string    `json:"merchant"`
+	Alias    string    `json:"alias"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "github.com/google/uuid"
+
+// Notification ...
+// A message for a user. Email and WebhookURL say where the
+// email and webhook channels deliver it and aren't kept
+type Notification struct {
+	NotificationID uuid.UUID  `json:"notification_id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	BudgetID       *uuid.UUID `json:"budget_id,omitempty"`
+	Title          string     `json:"title"`
+	Message        string     `json:"message"`
+	CreatedAt      string     `json:"created_at,omitempty"`
+	ReadAt         *string    `json:"read_at,omitempty"`
+	Email          string     `json:"-"`
+	WebhookURL     string     `json:"-"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import "github.com/google/uuid"
+
+// PlaidCategoryMapping ...
+// Puts transactions of a budget no categorization rule matched
+// in a transaction category by their Plaid category. A mapping
+// is for a Plaid category id or a Plaid category path like
+// ["Food and Drink", "Restaurants"], which also matches every
+// category below it. Ids win over paths and longer paths
+// over shorter ones
+type PlaidCategoryMapping struct {
+	MappingID                   uuid.UUID `json:"mapping_id"`
+	BudgetID                    uuid.UUID `json:"budget_id"`
+	BudgetTransactionCategoryID uuid.UUID `json:"budget_transaction_category_id"`
+	CategoryName                string    `json:"category_name,omitempty"`
+	PlaidCategoryID             string    `json:"plaid_category_id,omitempty"`
+	PlaidCategory               []string  `json:"plaid_category,omitempty"`
+}
+
+// PlaidCategoryDefaultsPayload ...
+// Budget to seed with the default Plaid category mappings
+type PlaidCategoryDefaultsPayload struct {
+	BudgetID uuid.UUID `json:"budget_id"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Plaid item statuses
+const (
+	PlaidItemStatusHealthy           = "healthy"
+	PlaidItemStatusLoginRequired     = "login_required"
+	PlaidItemStatusPendingExpiration = "pending_expiration"
+	PlaidItemStatusRevoked           = "revoked"
+)
+
+// PlaidItemNeedsAttention ...
+// Whether an item in the given status needs the
+// user to renew or relink it
+func PlaidItemNeedsAttention(status string) bool {
+	return status != "" && status != PlaidItemStatusHealthy
+}
+
+// PlaidItem ...
+// A login at an institution linked through Plaid.
+// Every external account of the item shares its access token
+type PlaidItem struct {
+	PlaidItemID      uuid.UUID  `json:"plaid_item_id"`
+	ItemID           string     `json:"item_id,omitempty"`
+	UserID           uuid.UUID  `json:"user_id"`
+	InstitutionID    string     `json:"institution_id,omitempty"`
+	InstitutionName  string     `json:"institution_name,omitempty"`
+	AccessToken      string     `json:"-"`
+	Status           string     `json:"status"`
+	NeedsAttention   bool       `json:"needs_attention"`
+	ErrorCode        string     `json:"error_code,omitempty"`
+	ConsentExpiresAt *time.Time `json:"consent_expires_at,omitempty"`
+	LastSyncedAt     *time.Time `json:"last_synced_at,omitempty"`
+	SyncCursor       string     `json:"-"`
+}
+
+// PlaidItemAccounts ...
+// A Plaid item along with its registered external accounts
+type PlaidItemAccounts struct {
+	PlaidItem
+	Accounts []Account `json:"accounts"`
+}
+
+// PlaidItemBalances ...
+// Live balances of the registered accounts of a Plaid item.
+// Items whose balances couldn't be loaded have no accounts
+// and a message saying why
+type PlaidItemBalances struct {
+	PlaidGetBalancesResponse
+	PlaidItemID     uuid.UUID `json:"plaid_item_id"`
+	InstitutionName string    `json:"institution_name,omitempty"`
+	ItemStatus      string    `json:"item_status"`
+	Message         string    `json:"message,omitempty"`
+}
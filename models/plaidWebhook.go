@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PlaidWebhookError ...
+// Error attached to ITEM ERROR webhooks
+type PlaidWebhookError struct {
+	ErrorType      string `json:"error_type"`
+	ErrorCode      string `json:"error_code"`
+	ErrorMessage   string `json:"error_message"`
+	DisplayMessage string `json:"display_message"`
+}
+
+// PlaidWebhook ...
+// Fields shared by the Plaid webhooks we handle
+type PlaidWebhook struct {
+	WebhookType           string             `json:"webhook_type"`
+	WebhookCode           string             `json:"webhook_code"`
+	ItemID                string             `json:"item_id"`
+	Error                 *PlaidWebhookError `json:"error,omitempty"`
+	NewTransactions       int                `json:"new_transactions,omitempty"`
+	RemovedTransactions   []string           `json:"removed_transactions,omitempty"`
+	ConsentExpirationTime string             `json:"consent_expiration_time,omitempty"`
+	AccountID             string             `json:"account_id,omitempty"`
+}
+
+// PlaidWebhookEvent ...
+// A received webhook as recorded in the database
+type PlaidWebhookEvent struct {
+	WebhookEventID uuid.UUID       `json:"webhook_event_id"`
+	Webhook        PlaidWebhook    `json:"webhook"`
+	Payload        json.RawMessage `json:"payload"`
+	ReceivedAt     time.Time       `json:"received_at"`
+}
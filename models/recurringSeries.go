@@ -0,0 +1,44 @@
+package models
+
+import "github.com/google/uuid"
+
+// RecurringPriceChange ...
+// A recurring series charging a new amount from Date on
+type RecurringPriceChange struct {
+	Date      string  `json:"date"`
+	OldAmount float64 `json:"old_amount"`
+	NewAmount float64 `json:"new_amount"`
+}
+
+// RecurringSeries ...
+// Charges by the same merchant to an account at a regular
+// cadence, like a subscription. Frequency is the charge cycle
+// unit the cadence matches and TypicalAmount what the series
+// charges now. SeriesID stays the same as long as the account
+// and merchant do. CategoryName is the budget category its
+// latest charge landed in, empty when it's uncategorized
+type RecurringSeries struct {
+	SeriesID          uuid.UUID              `json:"series_id"`
+	ExternalAccountID uuid.UUID              `json:"external_account_id"`
+	Merchant          string                 `json:"merchant"`
+	Frequency         string                 `json:"frequency"`
+	TypicalAmount     float64                `json:"typical_amount"`
+	LastAmount        float64                `json:"last_amount"`
+	LastDate          string                 `json:"last_date"`
+	NextExpectedDate  string                 `json:"next_expected_date"`
+	Occurrences       int                    `json:"occurrences"`
+	PriceChanges      []RecurringPriceChange `json:"price_changes"`
+	CategoryName      string                 `json:"category_name,omitempty"`
+}
+
+// SubscriptionConversionPayload ...
+// Turns a detected recurring series of a budget into an expense.
+// ExpenseName defaults to the merchant. Without a category the
+// expense gets a category of its own, under the one the series
+// lands in now, with a rule sending the merchant's charges to it
+type SubscriptionConversionPayload struct {
+	BudgetID                    uuid.UUID  `json:"budget_id"`
+	SeriesID                    uuid.UUID  `json:"series_id"`
+	ExpenseName                 string     `json:"expense_name,omitempty"`
+	BudgetTransactionCategoryID *uuid.UUID `json:"budget_transaction_category_id,omitempty"`
+}
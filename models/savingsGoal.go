@@ -0,0 +1,57 @@
+package models
+
+import "github.com/google/uuid"
+
+// Savings goal statuses
+const (
+	GoalStatusAchieved = "achieved"
+	GoalStatusOnTrack  = "on_track"
+	GoalStatusBehind   = "behind"
+	GoalStatusUnknown  = "unknown"
+)
+
+// SavingsGoal ...
+// An amount a budget is saving up by TargetDate (YYYY-MM-DD).
+// Goals with an ExternalAccountID are as far along as the
+// account's balance, others add up their contributions.
+// StartDate is the day the goal was created
+type SavingsGoal struct {
+	GoalID            uuid.UUID  `json:"goal_id,omitempty"`
+	BudgetID          uuid.UUID  `json:"budget_id"`
+	GoalName          string     `json:"goal_name"`
+	TargetAmount      float64    `json:"target_amount"`
+	TargetDate        string     `json:"target_date"`
+	ExternalAccountID *uuid.UUID `json:"external_account_id,omitempty"`
+	StartDate         string     `json:"start_date,omitempty"`
+}
+
+// GoalContribution ...
+// Money put towards a goal on ContributionDate (YYYY-MM-DD),
+// or taken out of it when Amount is negative. UserID is
+// who recorded it, unset once they delete their account
+type GoalContribution struct {
+	ContributionID   uuid.UUID  `json:"contribution_id,omitempty"`
+	GoalID           uuid.UUID  `json:"goal_id"`
+	UserID           *uuid.UUID `json:"user_id,omitempty"`
+	Amount           float64    `json:"amount"`
+	ContributionDate string     `json:"contribution_date,omitempty"`
+	Note             string     `json:"note,omitempty"`
+}
+
+// SavingsGoalProgress ...
+// How far along a goal is. RequiredMonthly is what has to be
+// saved every month left to reach the target on time, all of
+// what remains once the target date passed. Goals are on track
+// while they've saved at least their share of the target for the
+// time since they started. Status is unknown, with Message
+// saying why, when a linked account's balance can't be loaded
+type SavingsGoalProgress struct {
+	Goal            SavingsGoal `json:"goal"`
+	Saved           float64     `json:"saved"`
+	Remaining       float64     `json:"remaining"`
+	PercentComplete float64     `json:"percent_complete"`
+	MonthsLeft      int         `json:"months_left"`
+	RequiredMonthly float64     `json:"required_monthly"`
+	Status          string      `json:"status"`
+	Message         string      `json:"message,omitempty"`
+}
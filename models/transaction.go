@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// Transaction ...
+// Plaid transaction stored locally along with the external
+// account and item it belongs to and the merchant its
+// name was normalized to
+type Transaction struct {
+	plaid.Transaction
+	ExternalAccountID uuid.UUID `json:"external_account_id"`
+	ItemID            string    `json:"item_id"`
+	Merchant          string    `json:"merchant"`
+}
+
+// TransactionSyncResult ...
+// Counts of changes applied by a transaction sync of a Plaid
+// item. Items that couldn't be synced have a message saying why
+type TransactionSyncResult struct {
+	PlaidItemID     uuid.UUID `json:"plaid_item_id"`
+	ItemID          string    `json:"item_id"`
+	InstitutionName string    `json:"institution_name,omitempty"`
+	ItemStatus      string    `json:"item_status"`
+	Added           int       `json:"added"`
+	Modified        int       `json:"modified"`
+	Removed         int       `json:"removed"`
+	Message         string    `json:"message,omitempty"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "github.com/google/uuid"
+
+// InboxTransaction ...
+// An uncategorized transaction listed in a budget's inbox
+type InboxTransaction struct {
+	TransactionID     string    `json:"transaction_id"`
+	TransactionName   string    `json:"transaction_name"`
+	Amount            float64   `json:"amount"`
+	Date              string    `json:"date"`
+	ExternalAccountID uuid.UUID `json:"external_account_id"`
+}
+
+// UncategorizedMerchantGroup ...
+// The uncategorized transactions of a budget at one merchant
+type UncategorizedMerchantGroup struct {
+	Merchant         string             `json:"merchant"`
+	TransactionCount int                `json:"transaction_count"`
+	TotalAmount      float64            `json:"total_amount"`
+	Transactions     []InboxTransaction `json:"transactions"`
+}
+
+// UncategorizedInbox ...
+// A page of a budget's uncategorized spending grouped by
+// merchant, largest total first. Totals cover every page.
+// Sources whose transactions couldn't be read are listed in
+// SourceIssues, their spending is missing from the groups
+type UncategorizedInbox struct {
+	TotalGroups       int                          `json:"total_groups"`
+	TotalTransactions int                          `json:"total_transactions"`
+	TotalAmount       float64                      `json:"total_amount"`
+	TotalPages        int                          `json:"total_pages"`
+	PageIndex         int                          `json:"page_index"`
+	Groups            []UncategorizedMerchantGroup `json:"groups"`
+	SourceIssues      []BudgetSourceIssue          `json:"source_issues"`
+}
+
+// BulkCategoryAssignment ...
+// Puts transactions, picked by id or by the merchant group
+// they are in, in a category. With CreateRule set a merchant
+// rule is created too so future transactions follow
+type BulkCategoryAssignment struct {
+	BudgetTransactionCategoryID uuid.UUID `json:"budget_transaction_category_id"`
+	TransactionIDs              []string  `json:"transaction_ids,omitempty"`
+	Merchants                   []string  `json:"merchants,omitempty"`
+	CreateRule                  bool      `json:"create_rule,omitempty"`
+	RulePriority                int       `json:"rule_priority,omitempty"`
+}
+
+// BulkCategorizationPayload ...
+// Category assignments to apply to a budget together. Merchant
+// groups are those of the inbox covering the same number of days
+type BulkCategorizationPayload struct {
+	BudgetID    uuid.UUID                `json:"budget_id"`
+	Days        int                      `json:"days,omitempty"`
+	Assignments []BulkCategoryAssignment `json:"assignments"`
+}
+
+// BulkCategorizationResult ...
+// What a bulk categorization changed. Merchant groups
+// leave out the transactions of the sources in SourceIssues
+type BulkCategorizationResult struct {
+	TransactionsCategorized int                  `json:"transactions_categorized"`
+	NamesTagged             int                  `json:"names_tagged"`
+	RulesCreated            []CategorizationRule `json:"rules_created"`
+	SourceIssues            []BudgetSourceIssue  `json:"source_issues"`
+}
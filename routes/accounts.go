@@ -2,6 +2,7 @@ package routes
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	uuid "github.com/google/uuid"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	accountsService "github.com/lakshay35/finlit-backend/services/account"
+	budgetService "github.com/lakshay35/finlit-backend/services/budget"
 	"github.com/lakshay35/finlit-backend/utils/logging"
 	"github.com/lakshay35/finlit-backend/utils/requests"
 )
@@ -23,6 +25,7 @@ import (
 // @Security Google AccessToken
 // @Success 200 {object} models.PlaidAccount
 // @Failure  400 {object} models.Error
+// @Failure  403 {object} models.Error
 // @Router /account/get-account-details [post]
 func GetAccountInformation(c *gin.Context) {
 
@@ -33,7 +36,13 @@ func GetAccountInformation(c *gin.Context) {
 		return
 	}
 
-	account, getAccountInformationError := accountsService.GetAccountInformation(json.ExternalAccountID)
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	account, getAccountInformationError := accountsService.GetAccountInformation(c.Request.Context(), user.UserID, json.ExternalAccountID)
 
 	if getAccountInformationError != nil {
 		requests.ThrowError(
@@ -50,44 +59,61 @@ func GetAccountInformation(c *gin.Context) {
 
 // GetCurrentBalances ...
 // @Summary Get Current A/c Balances
-// @Description Retrieves live account balances for all accounts attached to an external account registratiom
+// @Description Retrieves live balances for all of the user's external accounts, one entry per bank login. Accounts are matched to external accounts by account_id, which is their institutional_id. Logins whose balances couldn't be loaded come with a message instead of accounts. Balances are cached per Plaid item unless force_refresh is set
 // @Tags External Accounts
 // @Accept  json
 // @Produce  json
+// @Param force_refresh query bool false "Bypass the balance cache"
 // @Security Google AccessToken
-// @Success 200 {array} models.PlaidGetBalancesResponse
-// @Failure  501 {object} models.Error
-// @Router /account/live-balances [get]
-// TODO: Implement method
+// @Success 200 {array} models.PlaidItemBalances
+// @Failure 400 {object} models.Error
+// @Failure 403 {object} models.Error
+// @Router /account/live-balances [post]
 func GetCurrentBalances(c *gin.Context) {
-	requests.ThrowError(
-		c,
-		http.StatusNotImplemented,
-		"METHOD NOT IMPLEMENTED",
-	)
-	// response, err := plaidService.PlaidClient().GetBalances("accessToken")
+	user, userError := requests.GetUserFromContext(c)
+
+	if userError != nil {
+		panic(userError)
+	}
+
+	forceRefresh, parseErr := strconv.ParseBool(c.DefaultQuery("force_refresh", "false"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"force_refresh must be a boolean",
+		)
+
+		return
+	}
+
+	balances, balancesErr := accountsService.GetCurrentBalances(c.Request.Context(), user.UserID, forceRefresh)
+
+	if balancesErr != nil {
+		requests.ThrowError(
+			c,
+			balancesErr.StatusCode,
+			balancesErr.Message,
+		)
 
-	// if err != nil {
-	// 	requests.ThrowError(
-	// 		c,
-	// 		http.StatusBadRequest,
-	// 		err.Error(),
-	// 	)
-	// }
+		return
+	}
 
-	// c.JSON(http.StatusOK, response.Accounts)
+	c.JSON(http.StatusOK, balances)
 }
 
 // DeleteAccount ...
 // @Summary Delete Account
-// @Description Deletes an external account registration
+// @Description Disconnects an external account: removes it from every budget using it, stops income sources from only counting deposits into it, deletes the categorization rules limited to it and its stored transactions and revokes its bank connection with Plaid when it was the last account of the login
 // @Tags External Accounts
 // @Accept  json
 // @Produce  json
 // @Param external-account-id path string true "External Account Id"
 // @Security Google AccessToken
-// @Success 200 {array} models.Budget
+// @Success 200 {object} models.AccountDisconnection
 // @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
 // @Failure 400 {object} models.Error
 // @Router /account/delete/{external-account-id} [delete]
 func DeleteAccount(c *gin.Context) {
@@ -107,7 +133,13 @@ func DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	deleteErr := accountsService.DeleteExternalAccount(externalAccountID)
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	disconnection, deleteErr := budgetService.DisconnectExternalAccount(c.Request.Context(), user.UserID, externalAccountID)
 
 	if deleteErr != nil {
 		requests.ThrowError(
@@ -119,7 +151,7 @@ func DeleteAccount(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, disconnection)
 }
 
 // GetAccountByID ...
@@ -136,7 +168,7 @@ func DeleteAccount(c *gin.Context) {
 // @Failure 403 {object} models.Error
 // @Router /account/get-account/{external-account-id} [get]
 func GetAccountByID(c *gin.Context) {
-	param := c.Param("external-account-id path")
+	param := c.Param("external-account-id")
 	externalAccountID, parseErr := uuid.Parse(param)
 
 	if parseErr != nil {
@@ -149,7 +181,13 @@ func GetAccountByID(c *gin.Context) {
 		return
 	}
 
-	account, getErr := accountsService.GetExternalAccount(externalAccountID)
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	account, getErr := accountsService.GetUserExternalAccount(c.Request.Context(), user.UserID, externalAccountID)
 
 	if getErr != nil {
 		requests.ThrowError(
@@ -166,13 +204,16 @@ func GetAccountByID(c *gin.Context) {
 
 // GetTransactions ...
 // @Summary Get Transactions
-// @Description Gets all transactions for the  past 30 days
+// @Description Gets synced transactions between start_date and end_date, defaulting to the past 30 days
 // @Tags External Accounts
 // @Accept  json
 // @Produce  json
 // @Param body body models.Account true "Account payload to identify transactions with"
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
 // @Security Google AccessToken
 // @Success 200 {array} models.PlaidTransaction
+// @Failure 400 {object} models.Error
 // @Failure 403 {object} models.Error
 // @Router /account/transactions [post]
 func GetTransactions(c *gin.Context) {
@@ -186,10 +227,33 @@ func GetTransactions(c *gin.Context) {
 		return
 	}
 
+	startDate := c.DefaultQuery("start_date", time.Now().Local().Add(-30*24*time.Hour).Format("2006-01-02"))
+	endDate := c.DefaultQuery("end_date", time.Now().Local().Format("2006-01-02"))
+
+	for _, date := range []string{startDate, endDate} {
+		if _, parseErr := time.Parse("2006-01-02", date); parseErr != nil {
+			requests.ThrowError(
+				c,
+				http.StatusBadRequest,
+				"Dates must be formatted as YYYY-MM-DD",
+			)
+
+			return
+		}
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
 	transactions, transactionsError := accountsService.GetTransactions(
+		c.Request.Context(),
+		user.UserID,
 		json.ExternalAccountID,
-		time.Now().Local().Add(-30*24*time.Hour).Format("2006-01-02"),
-		time.Now().Local().Format("2006-01-02"),
+		startDate,
+		endDate,
 	)
 
 	if transactionsError != nil {
@@ -208,17 +272,52 @@ func GetTransactions(c *gin.Context) {
 	)
 }
 
+// SyncTransactions ...
+// @Summary Sync Transactions
+// @Description Pulls new, modified and removed transactions from Plaid for every registered item of the user, one entry per item. Each item syncs on its own, and items that fail come with a message instead of failing the others
+// @Tags External Accounts
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Success 200 {array} models.TransactionSyncResult
+// @Failure 403 {object} models.Error
+// @Failure 500 {object} models.Error
+// @Router /account/sync-transactions [post]
+func SyncTransactions(c *gin.Context) {
+	user, userError := requests.GetUserFromContext(c)
+
+	if userError != nil {
+		panic(userError)
+	}
+
+	results, syncErr := accountsService.SyncUserTransactions(c.Request.Context(), user.UserID)
+
+	if syncErr != nil {
+		requests.ThrowError(
+			c,
+			syncErr.StatusCode,
+			syncErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
 // RenewAccessToken ...
 // Renews Access token
 // @Summary Renew Access Token
-// @Description Creates a link token to setup UI for renewing access tokens
+// @Description Creates an update mode link token so the user can log in again to the Plaid item of an external account
 // @Tags External Accounts
 // @Accept  json
 // @Produce  json
 // @Security Google AccessToken
-// @Param account body models.AccessTokenPayload true "Access Token payload"
-// @Success 200 {object} models.AccountIdPayload
+// @Param account body models.AccountIdPayload true "External account of the item to renew"
+// @Success 200 {object} models.LinkTokenPayload
 // @Failure 400 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 409 {object} models.Error
 // @Router /account/renew-access-token [post]
 func RenewAccessToken(c *gin.Context) {
 	var body models.AccountIdPayload
@@ -235,11 +334,64 @@ func RenewAccessToken(c *gin.Context) {
 		panic(userError)
 	}
 
+	linkToken, linkTokenErr := accountsService.GetAccessTokenRenewalLinkToken(c.Request.Context(), user.UserID, body.ExternalAccountID)
+
+	if linkTokenErr != nil {
+		requests.ThrowError(
+			c,
+			linkTokenErr.StatusCode,
+			linkTokenErr.Message,
+		)
+		return
+	}
+
 	c.JSON(http.StatusOK, &models.LinkTokenPayload{
-		LinkToken: accountsService.GetAccessTokenRenewalLinkToken(user.UserID.String(), body.ExternalAccountID),
+		LinkToken: linkToken,
 	})
 }
 
+// CompleteAccessTokenRenewal ...
+// @Summary Complete Access Token Renewal
+// @Description Marks the Plaid item of an external account healthy again after update mode Link succeeds and syncs its transactions
+// @Tags External Accounts
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param account body models.AccountIdPayload true "External account of the renewed item"
+// @Success 200 {object} models.PlaidItem
+// @Failure 400 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 409 {object} models.Error
+// @Router /account/renew-access-token/complete [post]
+func CompleteAccessTokenRenewal(c *gin.Context) {
+	var body models.AccountIdPayload
+
+	parseError := requests.ParseBody(c, &body)
+
+	if parseError != nil {
+		return
+	}
+
+	user, userError := requests.GetUserFromContext(c)
+
+	if userError != nil {
+		panic(userError)
+	}
+
+	item, renewErr := accountsService.CompleteItemRenewal(c.Request.Context(), user.UserID, body.ExternalAccountID)
+
+	if renewErr != nil {
+		requests.ThrowError(
+			c,
+			renewErr.StatusCode,
+			renewErr.Message,
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
 // CreateLinkToken ...
 // Creates link token
 // @Summary Create Link Token
@@ -276,7 +428,7 @@ func CreateLinkToken(c *gin.Context) {
 
 // GetAllAccounts ...
 // @Summary Get all registered external accounts
-// @Description Gets a list of all external accounts registered via Plaid
+// @Description Gets a list of all external accounts registered via Plaid. needs_attention marks accounts whose Plaid item must be renewed or relinked
 // @Tags External Accounts
 // @Accept  json
 // @Produce  json
@@ -292,7 +444,7 @@ func GetAllAccounts(c *gin.Context) {
 		panic(err)
 	}
 
-	accounts, err := accountsService.GetAllExternalAccounts(user.UserID)
+	accounts, err := accountsService.GetAllExternalAccounts(c.Request.Context(), user.UserID)
 
 	if err != nil {
 		requests.ThrowError(
@@ -306,6 +458,38 @@ func GetAllAccounts(c *gin.Context) {
 	c.JSON(http.StatusOK, accounts)
 }
 
+// GetItems ...
+// @Summary Get linked Plaid items
+// @Description Gets every Plaid item the user has linked with its status and the external accounts registered under it
+// @Tags External Accounts
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Success 200 {array} models.PlaidItemAccounts
+// @Failure 403 {object} models.Error
+// @Failure 500 {object} models.Error
+// @Router /account/items [get]
+func GetItems(c *gin.Context) {
+	user, err := requests.GetUserFromContext(c)
+
+	if err != nil {
+		panic(err)
+	}
+
+	items, itemsErr := accountsService.GetUserItems(c.Request.Context(), user.UserID)
+
+	if itemsErr != nil {
+		requests.ThrowError(
+			c,
+			itemsErr.StatusCode,
+			itemsErr.Message,
+		)
+		return
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
 // RegisterAccessToken ...
 // @Summary Register Access Token
 // @Description Creates a permanent access token based on public token
@@ -336,7 +520,7 @@ func RegisterAccessToken(c *gin.Context) {
 		panic(err)
 	}
 
-	accessTokenError := accountsService.RegisterAccessToken(json.Token, user.UserID)
+	accessTokenError := accountsService.RegisterAccessToken(c.Request.Context(), json.Token, user.UserID)
 
 	if accessTokenError != nil {
 		requests.ThrowError(
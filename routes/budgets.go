@@ -2,6 +2,7 @@ package routes
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	uuid "github.com/google/uuid"
@@ -37,7 +38,7 @@ func CreateBudget(c *gin.Context) {
 		panic(err)
 	}
 
-	budget, budgetCreationError := budgetService.CreateBudget(user.UserID, json.BudgetName)
+	budget, budgetCreationError := budgetService.CreateBudget(c.Request.Context(), user.UserID, json.BudgetName)
 
 	if budgetCreationError != nil {
 		requests.ThrowError(
@@ -70,7 +71,7 @@ func GetBudgets(c *gin.Context) {
 		panic(getUserErr)
 	}
 
-	result, getAllBudgetsError := budgetService.GetAllBudgets(user.UserID)
+	result, getAllBudgetsError := budgetService.GetAllBudgets(c.Request.Context(), user.UserID)
 
 	if getAllBudgetsError != nil {
 		requests.ThrowError(
@@ -110,7 +111,7 @@ func GetBudgetTransactionSources(c *gin.Context) {
 		return
 	}
 
-	result, err := budgetService.GetBudgetTransactionSources(budgetID)
+	result, err := budgetService.GetBudgetTransactionSources(c.Request.Context(), budgetID)
 
 	if err != nil {
 		requests.ThrowError(
@@ -145,7 +146,7 @@ func CreateBudgetTransactionSource(c *gin.Context) {
 		return
 	}
 
-	res, createBudgetTransactionError := budgetService.CreateBudgetTransactionSource(json)
+	res, createBudgetTransactionError := budgetService.CreateBudgetTransactionSource(c.Request.Context(), json)
 
 	if createBudgetTransactionError != nil {
 		requests.ThrowError(
@@ -193,7 +194,7 @@ func DeleteBudgetTransactionSource(c *gin.Context) {
 		panic(getUserErr)
 	}
 
-	deleteBudgetError := budgetService.DeleteBudgetTransactionSource(budgetTransactionSourceID, user.UserID)
+	deleteBudgetError := budgetService.DeleteBudgetTransactionSource(c.Request.Context(), budgetTransactionSourceID, user.UserID)
 
 	if deleteBudgetError != nil {
 		requests.ThrowError(
@@ -240,7 +241,7 @@ func DeleteBudget(c *gin.Context) {
 		panic(getUserErr)
 	}
 
-	deleteBudgetError := budgetService.DeleteBudget(budgetID, user.UserID)
+	deleteBudgetError := budgetService.DeleteBudget(c.Request.Context(), budgetID, user.UserID)
 
 	if deleteBudgetError != nil {
 		requests.ThrowError(
@@ -257,14 +258,23 @@ func DeleteBudget(c *gin.Context) {
 
 // GetBudgetExpenseSummary ...
 // @Summary Get Budget Expense summary
-// @Description Gets data about user spending vs budget
+// @Description Gets data about user spending vs budget. Transaction sources that are skipped or going stale because of their bank login are listed in source_issues.
+// @Description By default every expense is summarized over the current period of its charge cycle. start_date and end_date summarize a window
+// @Description of at most 36 months instead, prorating limits over it, and periods_ago the period of every charge cycle that many periods back
+// @Description The current period also comes with a forecast of each expense's end-of-period total and how far over or under it would land
+// @Description Refunds net against the category they came from. Income lists what each income source expected and received over the window
+// @Description and deposits that matched no source, and zero-based budgets also get their income minus planned expenses as unassigned
 // @Tags Budgets
 // @Accept  json
 // @Produce  json
 // @Param Budget-ID header string true "Budget ID to get expense summary for"
+// @Param start_date query string false "First day of the window (YYYY-MM-DD)"
+// @Param end_date query string false "Last day of the window (YYYY-MM-DD)"
+// @Param periods_ago query int false "Number of charge cycle periods to go back, at most 36"
 // @Security Google AccessToken
-// Success 200 {array} models.ExpenseSummary
+// @Success 200 {object} models.BudgetExpenseSummary
 // @Failure 403 {object} errors.Error
+// @Failure 400 {object} errors.Error
 // @Router /budget/get-expense-summary [get]
 func GetBudgetExpenseSummary(c *gin.Context) {
 	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
@@ -285,7 +295,28 @@ func GetBudgetExpenseSummary(c *gin.Context) {
 		panic(getUserErr)
 	}
 
-	summary, summaryErr := budgetService.GetBudgetExpenseSummary(budgetID, user.UserID)
+	period := models.SummaryPeriod{
+		StartDate: c.Query("start_date"),
+		EndDate:   c.Query("end_date"),
+	}
+
+	if c.Query("periods_ago") != "" {
+		periodsAgo, parseErr := strconv.Atoi(c.Query("periods_ago"))
+
+		if parseErr != nil {
+			requests.ThrowError(
+				c,
+				http.StatusBadRequest,
+				"Query parameter 'periods_ago' must be an integer",
+			)
+
+			return
+		}
+
+		period.PeriodsAgo = periodsAgo
+	}
+
+	summary, summaryErr := budgetService.GetBudgetExpenseSummary(c.Request.Context(), budgetID, period, user.UserID)
 
 	if summaryErr != nil {
 		requests.ThrowError(
@@ -324,7 +355,7 @@ func GetTransactionCategories(c *gin.Context) {
 		return
 	}
 
-	categories, err := budgetService.GetTransactionCategories(budgetID)
+	categories, err := budgetService.GetTransactionCategories(c.Request.Context(), budgetID)
 
 	if err != nil {
 		requests.ThrowError(
@@ -341,14 +372,19 @@ func GetTransactionCategories(c *gin.Context) {
 
 // DeleteBudgetTransactionCategory ...
 // @Summary Delete budget transaction category
-// @Description Deletes a budget transaction category
+// @Description Deletes a budget transaction category. With reassign_to, its tagged transactions, rules, Plaid category mappings,
+// @Description expense links and subcategories move to that category, otherwise they are removed and subcategories move up a level.
+// @Description With dry_run nothing is deleted and the rows that would be affected are counted
 // @Tags Budgets
 // @Accept  json
 // @Produce  json
 // @Security Google AccessToken
 // @Param budget-transaction-category-id path string true "Budget Transaction Category Id"
-// @Success 200
+// @Param reassign_to query string false "Budget Transaction Category Id to reassign to"
+// @Param dry_run query bool false "Only preview the rows affected"
+// @Success 200 {object} models.TransactionCategoryChange
 // @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
 // @Failure 400 {object} models.Error
 // @Router /budget/transaction-categories/delete/{budget-transaction-category-id} [delete]
 func DeleteBudgetTransactionCategory(c *gin.Context) {
@@ -365,7 +401,49 @@ func DeleteBudgetTransactionCategory(c *gin.Context) {
 		return
 	}
 
-	error := budgetService.DeleteTransactionCategory(budgetTransactionCategoryID)
+	var reassignTo *uuid.UUID
+
+	if c.Query("reassign_to") != "" {
+		targetID, targetErr := uuid.Parse(c.Query("reassign_to"))
+
+		if targetErr != nil {
+			requests.ThrowError(
+				c,
+				http.StatusBadRequest,
+				"Query parameter 'reassign_to' must be a UUID",
+			)
+
+			return
+		}
+
+		reassignTo = &targetID
+	}
+
+	dryRun := false
+
+	if c.Query("dry_run") != "" {
+		value, dryRunErr := strconv.ParseBool(c.Query("dry_run"))
+
+		if dryRunErr != nil {
+			requests.ThrowError(
+				c,
+				http.StatusBadRequest,
+				"Query parameter 'dry_run' must be a boolean",
+			)
+
+			return
+		}
+
+		dryRun = value
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	change, error := budgetService.DeleteTransactionCategory(c.Request.Context(), budgetTransactionCategoryID, reassignTo, dryRun, user.UserID)
 
 	if error != nil {
 		requests.ThrowError(
@@ -377,7 +455,7 @@ func DeleteBudgetTransactionCategory(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusOK)
+	c.JSON(http.StatusOK, change)
 }
 
 // CreateBudgetTransactionCategory ...
@@ -390,6 +468,7 @@ func DeleteBudgetTransactionCategory(c *gin.Context) {
 // @Security Google AccessToken
 // @Success 200 {object} models.BudgetTransactionCategory
 // @Failure 403 {object} models.Error
+// @Failure 409 {object} models.Error
 // @Failure 400 {object} models.Error
 // @Router /budget/transaction-categories/create [post]
 func CreateBudgetTransactionCategory(c *gin.Context) {
@@ -406,7 +485,7 @@ func CreateBudgetTransactionCategory(c *gin.Context) {
 		panic(userErr)
 	}
 
-	category, creationErr := budgetService.CreateTransactionCategory(json, user.UserID)
+	category, creationErr := budgetService.CreateTransactionCategory(c.Request.Context(), json, user.UserID)
 
 	if creationErr != nil {
 		requests.ThrowError(
@@ -420,3 +499,1341 @@ func CreateBudgetTransactionCategory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, category)
 }
+
+// GetCategorizationRules ...
+// @Summary Get categorization rules
+// @Description Gets the categorization rules of a budget in the order they are tried
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get rules for"
+// @Security Google AccessToken
+// @Success 200 {array} models.CategorizationRule
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/categorization-rules [get]
+func GetCategorizationRules(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	rules, err := budgetService.GetCategorizationRules(c.Request.Context(), budgetID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateCategorizationRule ...
+// @Summary Create a categorization rule
+// @Description Creates a rule putting every budget transaction matching all of its conditions in a category.
+// @Description Name conditions are contains, prefix and regex, the rest are merchant, amount range, account and Plaid category.
+// @Description Rules with lower priorities are tried first
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param rule body models.CategorizationRule true "Categorization rule"
+// @Security Google AccessToken
+// @Success 200 {object} models.CategorizationRule
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/categorization-rules/create [post]
+func CreateCategorizationRule(c *gin.Context) {
+	var json models.CategorizationRule
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	rule, creationErr := budgetService.CreateCategorizationRule(c.Request.Context(), json, user.UserID)
+
+	if creationErr != nil {
+		requests.ThrowError(
+			c,
+			creationErr.StatusCode,
+			creationErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateCategorizationRule ...
+// @Summary Update a categorization rule
+// @Description Replaces the category, priority and conditions of a categorization rule
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param rule body models.CategorizationRule true "Categorization rule"
+// @Security Google AccessToken
+// @Success 200 {object} models.CategorizationRule
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/categorization-rules/update [put]
+func UpdateCategorizationRule(c *gin.Context) {
+	var json models.CategorizationRule
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	rule, updateErr := budgetService.UpdateCategorizationRule(c.Request.Context(), json, user.UserID)
+
+	if updateErr != nil {
+		requests.ThrowError(
+			c,
+			updateErr.StatusCode,
+			updateErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteCategorizationRule ...
+// @Summary Delete a categorization rule
+// @Description Deletes a categorization rule
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param rule-id path string true "Categorization Rule Id"
+// @Success 200
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/categorization-rules/delete/{rule-id} [delete]
+func DeleteCategorizationRule(c *gin.Context) {
+	ruleID, parseErr := uuid.Parse(c.Param("rule-id"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Categorization Rule ID must be a UUID",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	deleteErr := budgetService.DeleteCategorizationRule(c.Request.Context(), ruleID, user.UserID)
+
+	if deleteErr != nil {
+		requests.ThrowError(
+			c,
+			deleteErr.StatusCode,
+			deleteErr.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// TestCategorizationRules ...
+// @Summary Test categorization rules
+// @Description Runs a synced transaction, or one described by its fields, through a budget's categorization.
+// @Description Shows the category it lands in, the rule that put it there and every other rule it matches
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param transaction body models.CategorizationRuleTestPayload true "Transaction to categorize"
+// @Security Google AccessToken
+// @Success 200 {object} models.TransactionCategorization
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/categorization-rules/test [post]
+func TestCategorizationRules(c *gin.Context) {
+	var json models.CategorizationRuleTestPayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	result, testErr := budgetService.TestCategorizationRules(c.Request.Context(), json, user.UserID)
+
+	if testErr != nil {
+		requests.ThrowError(
+			c,
+			testErr.StatusCode,
+			testErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetCategorySuggestions ...
+// @Summary Get category suggestions
+// @Description Suggests categories, ranked by confidence, for the budget's uncategorized spending of the past 30 days.
+// @Description Suggestions are learned from transactions tagged through /transaction/categorize, which also accepts or corrects them.
+// @Description Transaction sources that can't be read are left out, the uncategorized inbox lists them
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get suggestions for"
+// @Security Google AccessToken
+// @Success 200 {array} models.TransactionCategorySuggestions
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/categorization-suggestions [get]
+func GetCategorySuggestions(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	suggestions, err := budgetService.GetCategorySuggestions(c.Request.Context(), budgetID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// ApplyCategorySuggestions ...
+// @Summary Apply category suggestions
+// @Description Tags every uncategorized transaction whose top suggestion is at least threshold confident with the suggested category
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param payload body models.CategorySuggestionApplyPayload true "Budget and confidence threshold"
+// @Security Google AccessToken
+// @Success 200 {array} models.TransactionCategorySuggestions
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/categorization-suggestions/apply [post]
+func ApplyCategorySuggestions(c *gin.Context) {
+	var json models.CategorySuggestionApplyPayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	suggestions, applyErr := budgetService.ApplyCategorySuggestions(c.Request.Context(), json, user.UserID)
+
+	if applyErr != nil {
+		requests.ThrowError(
+			c,
+			applyErr.StatusCode,
+			applyErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// GetUncategorizedInbox ...
+// @Summary Get uncategorized inbox
+// @Description Lists the budget's uncategorized spending across all its transaction sources, grouped by merchant with counts and totals.
+// @Description Groups are sorted by total, largest first, and paged starting from page 0. Transaction sources that couldn't be read are listed in source_issues
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get the inbox of"
+// @Param page query number false "Page index, starting from 0"
+// @Param page_size query number false "Merchant groups per page, 20 by default and at most 100"
+// @Param days query number false "Days to look back, 30 by default and at most 365"
+// @Security Google AccessToken
+// @Success 200 {object} models.UncategorizedInbox
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/inbox [get]
+func GetUncategorizedInbox(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	params := make(map[string]int)
+
+	for _, name := range []string{"page", "page_size", "days"} {
+		if c.Query(name) == "" {
+			continue
+		}
+
+		value, parseErr := strconv.Atoi(c.Query(name))
+
+		if parseErr != nil {
+			requests.ThrowError(
+				c,
+				http.StatusBadRequest,
+				"Query parameter '"+name+"' must be an integer",
+			)
+
+			return
+		}
+
+		params[name] = value
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	inbox, err := budgetService.GetUncategorizedInbox(
+		c.Request.Context(),
+		budgetID,
+		user.UserID,
+		params["days"],
+		params["page"],
+		params["page_size"],
+	)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, inbox)
+}
+
+// BulkCategorize ...
+// @Summary Bulk categorize transactions
+// @Description Assigns many transactions, by id or by merchant group, to categories in one atomic request.
+// @Description Each assignment can also create a merchant rule so future transactions follow it. Merchant groups leave out the transaction sources listed in source_issues
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param payload body models.BulkCategorizationPayload true "Category assignments"
+// @Security Google AccessToken
+// @Success 200 {object} models.BulkCategorizationResult
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/inbox/categorize [post]
+func BulkCategorize(c *gin.Context) {
+	var json models.BulkCategorizationPayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	result, categorizeErr := budgetService.BulkCategorize(c.Request.Context(), json, user.UserID)
+
+	if categorizeErr != nil {
+		requests.ThrowError(
+			c,
+			categorizeErr.StatusCode,
+			categorizeErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// MoveBudgetTransactionCategory ...
+// @Summary Move a budget transaction category
+// @Description Moves a transaction category, along with its subcategories, under another category of the budget.
+// @Description A null parent_category_id moves it to the top level
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param payload body models.BudgetTransactionCategoryMovePayload true "Category and its new parent"
+// @Security Google AccessToken
+// @Success 200 {object} models.BudgetTransactionCategory
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/transaction-categories/move [put]
+func MoveBudgetTransactionCategory(c *gin.Context) {
+	var json models.BudgetTransactionCategoryMovePayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	category, moveErr := budgetService.MoveTransactionCategory(c.Request.Context(), json, user.UserID)
+
+	if moveErr != nil {
+		requests.ThrowError(
+			c,
+			moveErr.StatusCode,
+			moveErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// GetPlaidCategoryMappings ...
+// @Summary Get Plaid category mappings
+// @Description Gets the mappings from Plaid categories to categories of a budget that categorize transactions no rule matches
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get mappings for"
+// @Security Google AccessToken
+// @Success 200 {array} models.PlaidCategoryMapping
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/plaid-category-mappings [get]
+func GetPlaidCategoryMappings(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	mappings, err := budgetService.GetPlaidCategoryMappings(c.Request.Context(), budgetID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, mappings)
+}
+
+// CreatePlaidCategoryMapping ...
+// @Summary Create a Plaid category mapping
+// @Description Maps a Plaid category id, or a Plaid category path and everything below it, to a category of a budget.
+// @Description Id mappings win over path mappings and longer paths over shorter ones
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param mapping body models.PlaidCategoryMapping true "Plaid category mapping"
+// @Security Google AccessToken
+// @Success 200 {object} models.PlaidCategoryMapping
+// @Failure 403 {object} models.Error
+// @Failure 409 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/plaid-category-mappings/create [post]
+func CreatePlaidCategoryMapping(c *gin.Context) {
+	var json models.PlaidCategoryMapping
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	mapping, creationErr := budgetService.CreatePlaidCategoryMapping(c.Request.Context(), json, user.UserID)
+
+	if creationErr != nil {
+		requests.ThrowError(
+			c,
+			creationErr.StatusCode,
+			creationErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// DeletePlaidCategoryMapping ...
+// @Summary Delete a Plaid category mapping
+// @Description Deletes a Plaid category mapping
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param mapping-id path string true "Plaid Category Mapping Id"
+// @Success 200
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/plaid-category-mappings/delete/{mapping-id} [delete]
+func DeletePlaidCategoryMapping(c *gin.Context) {
+	mappingID, parseErr := uuid.Parse(c.Param("mapping-id"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Plaid Category Mapping ID must be a UUID",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	deleteErr := budgetService.DeletePlaidCategoryMapping(c.Request.Context(), mappingID, user.UserID)
+
+	if deleteErr != nil {
+		requests.ThrowError(
+			c,
+			deleteErr.StatusCode,
+			deleteErr.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ApplyDefaultPlaidCategoryMappings ...
+// @Summary Apply the default Plaid category mappings
+// @Description Seeds a budget with the default categories and Plaid category mappings new budgets start out with.
+// @Description Existing categories are reused by name and Plaid categories already mapped are left alone
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param payload body models.PlaidCategoryDefaultsPayload true "Budget to seed"
+// @Security Google AccessToken
+// @Success 200 {array} models.PlaidCategoryMapping
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/plaid-category-mappings/defaults [post]
+func ApplyDefaultPlaidCategoryMappings(c *gin.Context) {
+	var json models.PlaidCategoryDefaultsPayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	mappings, applyErr := budgetService.ApplyDefaultPlaidCategoryMappings(c.Request.Context(), json.BudgetID, user.UserID)
+
+	if applyErr != nil {
+		requests.ThrowError(
+			c,
+			applyErr.StatusCode,
+			applyErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, mappings)
+}
+
+// RenameBudgetTransactionCategory ...
+// @Summary Rename a budget transaction category
+// @Description Renames a transaction category. Names are unique within a budget.
+// @Description With dry_run nothing is renamed and the rows referring to the category are counted
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param payload body models.BudgetTransactionCategoryRenamePayload true "Category and its new name"
+// @Security Google AccessToken
+// @Success 200 {object} models.TransactionCategoryChange
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 409 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/transaction-categories/rename [put]
+func RenameBudgetTransactionCategory(c *gin.Context) {
+	var json models.BudgetTransactionCategoryRenamePayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	change, renameErr := budgetService.RenameTransactionCategory(c.Request.Context(), json, user.UserID)
+
+	if renameErr != nil {
+		requests.ThrowError(
+			c,
+			renameErr.StatusCode,
+			renameErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// MergeBudgetTransactionCategories ...
+// @Summary Merge budget transaction categories
+// @Description Merges the source category into the target category, moving its tagged transactions, rules,
+// @Description Plaid category mappings, expense links and subcategories, then deletes it.
+// @Description With dry_run nothing is merged and the rows that would move are counted
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param payload body models.BudgetTransactionCategoryMergePayload true "Source and target categories"
+// @Security Google AccessToken
+// @Success 200 {object} models.TransactionCategoryChange
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/transaction-categories/merge [post]
+func MergeBudgetTransactionCategories(c *gin.Context) {
+	var json models.BudgetTransactionCategoryMergePayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	change, mergeErr := budgetService.MergeTransactionCategories(c.Request.Context(), json, user.UserID)
+
+	if mergeErr != nil {
+		requests.ThrowError(
+			c,
+			mergeErr.StatusCode,
+			mergeErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// GetMerchantAliases ...
+// @Summary Get merchant aliases
+// @Description Gets the names a budget gave merchants
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get merchant aliases for"
+// @Security Google AccessToken
+// @Success 200 {array} models.MerchantAlias
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/merchant-aliases [get]
+func GetMerchantAliases(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	aliases, err := budgetService.GetMerchantAliases(c.Request.Context(), budgetID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, aliases)
+}
+
+// CreateMerchantAlias ...
+// @Summary Create a merchant alias
+// @Description Renames a merchant within a budget. Rules, the inbox and suggestions see the alias instead of the merchant.
+// @Description The merchant can be given as a raw transaction name
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param alias body models.MerchantAlias true "Merchant alias"
+// @Security Google AccessToken
+// @Success 200 {object} models.MerchantAlias
+// @Failure 403 {object} models.Error
+// @Failure 409 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/merchant-aliases/create [post]
+func CreateMerchantAlias(c *gin.Context) {
+	var json models.MerchantAlias
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	alias, creationErr := budgetService.CreateMerchantAlias(c.Request.Context(), json, user.UserID)
+
+	if creationErr != nil {
+		requests.ThrowError(
+			c,
+			creationErr.StatusCode,
+			creationErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, alias)
+}
+
+// DeleteMerchantAlias ...
+// @Summary Delete a merchant alias
+// @Description Deletes a merchant alias
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param alias-id path string true "Merchant Alias Id"
+// @Success 200
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/merchant-aliases/delete/{alias-id} [delete]
+func DeleteMerchantAlias(c *gin.Context) {
+	aliasID, parseErr := uuid.Parse(c.Param("alias-id"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Merchant Alias ID must be a UUID",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	deleteErr := budgetService.DeleteMerchantAlias(c.Request.Context(), aliasID, user.UserID)
+
+	if deleteErr != nil {
+		requests.ThrowError(
+			c,
+			deleteErr.StatusCode,
+			deleteErr.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetBudgetExpenseTrends ...
+// @Summary Get budget expense trends
+// @Description Gets the limit, actual spending and variance of every expense of a budget in the last periods of its charge cycle, oldest first.
+// @Description The last period is the current one. Variance is positive when spending went over the limit
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get expense trends for"
+// @Param periods query int false "Number of periods, 6 by default"
+// @Security Google AccessToken
+// @Success 200 {array} models.ExpenseTrend
+// @Failure 403 {object} errors.Error
+// @Failure 400 {object} errors.Error
+// @Router /budget/expense-trends [get]
+func GetBudgetExpenseTrends(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	periods, parseErr := strconv.Atoi(c.DefaultQuery("periods", "6"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Query parameter 'periods' must be an integer",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	trends, err := budgetService.GetBudgetExpenseTrends(c.Request.Context(), budgetID, periods, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, trends)
+}
+
+// GetAlertRules ...
+// @Summary Get alert rules
+// @Description Gets the alert rules of a budget
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get alert rules for"
+// @Security Google AccessToken
+// @Success 200 {array} models.AlertRule
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/alert-rules [get]
+func GetAlertRules(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	rules, err := budgetService.GetAlertRules(c.Request.Context(), budgetID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// CreateAlertRule ...
+// @Summary Create an alert rule
+// @Description Alerts the user creating the rule when an expense reaches thresholds, percentages of its value, in a period
+// @Description or when a transaction is over an amount. Rules without an expense_id watch the whole budget.
+// @Description Alerts are delivered by email, to a public https webhook_url or in-app, and each one is only sent once
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param rule body models.AlertRule true "Alert rule"
+// @Security Google AccessToken
+// @Success 200 {object} models.AlertRule
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/alert-rules/create [post]
+func CreateAlertRule(c *gin.Context) {
+	var json models.AlertRule
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	rule, creationErr := budgetService.CreateAlertRule(c.Request.Context(), json, user.UserID)
+
+	if creationErr != nil {
+		requests.ThrowError(
+			c,
+			creationErr.StatusCode,
+			creationErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAlertRule ...
+// @Summary Delete an alert rule
+// @Description Deletes an alert rule
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param alert-rule-id path string true "Alert Rule Id"
+// @Success 200
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/alert-rules/delete/{alert-rule-id} [delete]
+func DeleteAlertRule(c *gin.Context) {
+	alertRuleID, parseErr := uuid.Parse(c.Param("alert-rule-id"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Alert Rule ID must be a UUID",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	deleteErr := budgetService.DeleteAlertRule(c.Request.Context(), alertRuleID, user.UserID)
+
+	if deleteErr != nil {
+		requests.ThrowError(
+			c,
+			deleteErr.StatusCode,
+			deleteErr.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetRecurringSeries ...
+// @Summary Get recurring series
+// @Description Detects recurring charges, like subscriptions, in the transactions of a budget's sources with their cadence,
+// @Description typical amount, next expected charge and price changes. Series that missed their last charge are left out
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to detect recurring series for"
+// @Security Google AccessToken
+// @Success 200 {array} models.RecurringSeries
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/recurring-series [get]
+func GetRecurringSeries(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	series, err := budgetService.GetRecurringSeries(c.Request.Context(), budgetID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// ConvertRecurringSeries ...
+// @Summary Convert a recurring series into an expense
+// @Description Creates an expense tracking a detected recurring series, valued at what it charges now with a charge cycle
+// @Description matching its cadence. Without a budget_transaction_category_id the expense gets a category of its own
+// @Description and a rule sending the series' charges to it
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param conversion body models.SubscriptionConversionPayload true "Series to convert"
+// @Security Google AccessToken
+// @Success 200 {object} models.Expense
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/recurring-series/convert [post]
+func ConvertRecurringSeries(c *gin.Context) {
+	var json models.SubscriptionConversionPayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	expense, conversionErr := budgetService.ConvertRecurringSeries(c.Request.Context(), json, user.UserID)
+
+	if conversionErr != nil {
+		requests.ThrowError(
+			c,
+			conversionErr.StatusCode,
+			conversionErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, expense)
+}
+
+// GetIncomeSources ...
+// @Summary Get income sources
+// @Description Gets the income sources of a budget
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get income sources for"
+// @Security Google AccessToken
+// @Success 200 {array} models.IncomeSource
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/income-sources [get]
+func GetIncomeSources(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	sources, err := budgetService.GetIncomeSources(c.Request.Context(), budgetID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, sources)
+}
+
+// CreateIncomeSource ...
+// @Summary Create an income source
+// @Description Adds income a budget expects, expected_amount every period of charge_cycle. Deposits belong to the source
+// @Description when payer is part of their merchant or name, ignoring case, and when they went into external_account_id if it's given
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param source body models.IncomeSource true "Income source"
+// @Security Google AccessToken
+// @Success 200 {object} models.IncomeSource
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/income-sources/create [post]
+func CreateIncomeSource(c *gin.Context) {
+	var json models.IncomeSource
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	source, creationErr := budgetService.CreateIncomeSource(c.Request.Context(), json, user.UserID)
+
+	if creationErr != nil {
+		requests.ThrowError(
+			c,
+			creationErr.StatusCode,
+			creationErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, source)
+}
+
+// DeleteIncomeSource ...
+// @Summary Delete an income source
+// @Description Deletes an income source
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param income-source-id path string true "Income Source Id"
+// @Success 200
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/income-sources/delete/{income-source-id} [delete]
+func DeleteIncomeSource(c *gin.Context) {
+	incomeSourceID, parseErr := uuid.Parse(c.Param("income-source-id"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Income Source ID must be a UUID",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	deleteErr := budgetService.DeleteIncomeSource(c.Request.Context(), incomeSourceID, user.UserID)
+
+	if deleteErr != nil {
+		requests.ThrowError(
+			c,
+			deleteErr.StatusCode,
+			deleteErr.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// SetBudgetZeroBased ...
+// @Summary Turn zero-based budgeting on or off
+// @Description Zero-based budgets plan every dollar of their income. Their expense summaries report income minus planned expenses as unassigned
+// @Tags Budgets
+// @Accept  json
+// @Produce  json
+// @Param zero-based body models.ZeroBasedPayload true "Zero-based setting"
+// @Security Google AccessToken
+// @Success 200
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /budget/zero-based [post]
+func SetBudgetZeroBased(c *gin.Context) {
+	var json models.ZeroBasedPayload
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	if setErr := budgetService.SetBudgetZeroBased(c.Request.Context(), json, user.UserID); setErr != nil {
+		requests.ThrowError(
+			c,
+			setErr.StatusCode,
+			setErr.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -38,7 +38,7 @@ func AddExpense(c *gin.Context) {
 		panic(err)
 	}
 
-	expense, addExpenseError := expenseService.AddExpenseToBudget(&json, user.UserID)
+	expense, addExpenseError := expenseService.AddExpenseToBudget(c.Request.Context(), &json, user.UserID)
 
 	if addExpenseError != nil {
 		requests.ThrowError(
@@ -84,7 +84,7 @@ func GetAllExpenses(c *gin.Context) {
 		panic(err)
 	}
 
-	expenses, getExpensesError := expenseService.GetAllExpensesForBudget(budgetID, user.UserID)
+	expenses, getExpensesError := expenseService.GetAllExpensesForBudget(c.Request.Context(), budgetID, user.UserID)
 
 	if getExpensesError != nil {
 		requests.ThrowError(
@@ -122,7 +122,7 @@ func UpdateExpense(c *gin.Context) {
 	}
 
 	// Ensure expense exists
-	_, getExpenseError := expenseService.GetExpense(json.ExpenseID)
+	_, getExpenseError := expenseService.GetExpense(c.Request.Context(), json.ExpenseID)
 	if getExpenseError != nil {
 		requests.ThrowError(
 			c,
@@ -139,6 +139,7 @@ func UpdateExpense(c *gin.Context) {
 	}
 
 	updateExpenseError := expenseService.UpdateExpense(
+		c.Request.Context(),
 		&json,
 		user.UserID,
 	)
@@ -182,7 +183,7 @@ func DeleteExpense(c *gin.Context) {
 		return
 	}
 
-	expense, getExpenseError := expenseService.GetExpense(id)
+	expense, getExpenseError := expenseService.GetExpense(c.Request.Context(), id)
 
 	if getExpenseError != nil {
 		requests.ThrowError(
@@ -198,7 +199,7 @@ func DeleteExpense(c *gin.Context) {
 		panic(getUserError)
 	}
 
-	deleteExpenseError := expenseService.DeleteExpense(id, expense.BudgetID, user.UserID)
+	deleteExpenseError := expenseService.DeleteExpense(c.Request.Context(), id, expense.BudgetID, user.UserID)
 
 	if deleteExpenseError != nil {
 		requests.ThrowError(
@@ -224,5 +225,5 @@ func DeleteExpense(c *gin.Context) {
 // @Failure 403 {object} models.Error
 // @Router /expense/get-expense-charge-cycles [get]
 func GetExpenseChargeCycles(c *gin.Context) {
-	c.JSON(http.StatusOK, expenseService.GetExpenseChargeCycles())
+	c.JSON(http.StatusOK, expenseService.GetExpenseChargeCycles(c.Request.Context()))
 }
@@ -52,7 +52,7 @@ func GetUserFitnessHistory(c *gin.Context) {
 			panic(pageIndexParseErr)
 		}
 
-		history, historyErr := fitness_tracker_history.GetUserFitnessHistory(user.UserID, pageIndex)
+		history, historyErr := fitness_tracker_history.GetUserFitnessHistory(c.Request.Context(), user.UserID, pageIndex)
 
 		if historyErr != nil {
 			requests.ThrowError(
@@ -74,7 +74,7 @@ func GetUserFitnessHistory(c *gin.Context) {
 		panic(monthIndexParseErr)
 	}
 
-	history, historyErr := fitness_tracker_history.GetUserCalendarFitnessHistory(user.UserID, monthIndex)
+	history, historyErr := fitness_tracker_history.GetUserCalendarFitnessHistory(c.Request.Context(), user.UserID, monthIndex)
 
 	if historyErr != nil {
 		requests.ThrowError(
@@ -118,9 +118,9 @@ func CheckIn(c *gin.Context) {
 	var record *models.FitnessHistoryRecord
 	var checkInError *models.Error
 	if !strings.EqualFold("0001-01-01 00:00:00 +0000 UTC", strings.Trim(payload.Date.String(), " ")) {
-		record, checkInError = fitness_tracker_history.CheckIn(user.UserID, payload.ActiveToday, payload.Note, &payload.Date)
+		record, checkInError = fitness_tracker_history.CheckIn(c.Request.Context(), user.UserID, payload.ActiveToday, payload.Note, &payload.Date)
 	} else {
-		record, checkInError = fitness_tracker_history.CheckIn(user.UserID, payload.ActiveToday, payload.Note, nil)
+		record, checkInError = fitness_tracker_history.CheckIn(c.Request.Context(), user.UserID, payload.ActiveToday, payload.Note, nil)
 	}
 
 	if checkInError != nil {
@@ -153,7 +153,7 @@ func CheckInStatus(c *gin.Context) {
 		panic(getUserErr)
 	}
 
-	hasUserCheckedIn := fitness_tracker_history.HasUserCheckedIn(user.UserID, nil)
+	hasUserCheckedIn := fitness_tracker_history.HasUserCheckedIn(c.Request.Context(), user.UserID, nil)
 
 	c.JSON(http.StatusOK, hasUserCheckedIn)
 }
@@ -175,7 +175,7 @@ func GetFitnessRate(c *gin.Context) {
 		panic(getUserErr)
 	}
 
-	userFitnessRate := fitness_tracker_history.GetUserFitnessRate(user.UserID)
+	userFitnessRate := fitness_tracker_history.GetUserFitnessRate(c.Request.Context(), user.UserID)
 
 	c.JSON(http.StatusOK, userFitnessRate)
 }
@@ -197,7 +197,7 @@ func GetWeeklyFitnessRate(c *gin.Context) {
 		panic(getUserErr)
 	}
 
-	userFitnessRate := fitness_tracker_history.GetUserWeeklyFitnessRate(user.UserID)
+	userFitnessRate := fitness_tracker_history.GetUserWeeklyFitnessRate(c.Request.Context(), user.UserID)
 
 	c.JSON(http.StatusOK, userFitnessRate)
 }
@@ -219,7 +219,7 @@ func GetRecentUserFitnessHistory(c *gin.Context) {
 		panic(getUserErr)
 	}
 
-	hasUserCheckedIn := fitness_tracker_history.RecentCheckinHistory(user.UserID)
+	hasUserCheckedIn := fitness_tracker_history.RecentCheckinHistory(c.Request.Context(), user.UserID)
 
 	c.JSON(http.StatusOK, hasUserCheckedIn)
 }
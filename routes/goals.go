@@ -0,0 +1,236 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	goalService "github.com/lakshay35/finlit-backend/services/goal"
+	"github.com/lakshay35/finlit-backend/utils/requests"
+)
+
+// GetBudgetGoals ...
+// @Summary Get savings goals
+// @Description Gets the savings goals of a budget with what they saved, from a linked account's balance or their contributions,
+// @Description the monthly contribution still needed and whether they are on track. Every member of the budget can see them
+// @Tags Savings Goals
+// @Accept  json
+// @Produce  json
+// @Param Budget-ID header string true "Budget ID to get savings goals for"
+// @Security Google AccessToken
+// @Success 200 {array} models.SavingsGoalProgress
+// @Failure 403 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /goal/get [get]
+func GetBudgetGoals(c *gin.Context) {
+	budgetID, budgetIDError := uuid.Parse(c.GetHeader("Budget-ID"))
+
+	if budgetIDError != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Header 'Budget-ID' must contain a valid uuid",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	goals, err := goalService.GetBudgetGoals(c.Request.Context(), budgetID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+// CreateGoal ...
+// @Summary Create a savings goal
+// @Description Creates a goal to save target_amount by target_date. Goals with an external_account_id, which must be
+// @Description one of the user's accounts, track its balance and the rest add up contributions
+// @Tags Savings Goals
+// @Accept  json
+// @Produce  json
+// @Param goal body models.SavingsGoal true "Savings goal"
+// @Security Google AccessToken
+// @Success 200 {object} models.SavingsGoal
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /goal/create [post]
+func CreateGoal(c *gin.Context) {
+	var json models.SavingsGoal
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	goal, creationErr := goalService.CreateGoal(c.Request.Context(), json, user.UserID)
+
+	if creationErr != nil {
+		requests.ThrowError(
+			c,
+			creationErr.StatusCode,
+			creationErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// DeleteGoal ...
+// @Summary Delete a savings goal
+// @Description Deletes a savings goal and its contributions
+// @Tags Savings Goals
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param goal-id path string true "Goal Id"
+// @Success 200
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /goal/delete/{goal-id} [delete]
+func DeleteGoal(c *gin.Context) {
+	goalID, parseErr := uuid.Parse(c.Param("goal-id"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Goal ID must be a UUID",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	if deleteErr := goalService.DeleteGoal(c.Request.Context(), goalID, user.UserID); deleteErr != nil {
+		requests.ThrowError(
+			c,
+			deleteErr.StatusCode,
+			deleteErr.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// GetGoalContributions ...
+// @Summary Get goal contributions
+// @Description Gets the contributions to a savings goal, the earliest first
+// @Tags Savings Goals
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param goal-id path string true "Goal Id"
+// @Success 200 {array} models.GoalContribution
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /goal/contributions/{goal-id} [get]
+func GetGoalContributions(c *gin.Context) {
+	goalID, parseErr := uuid.Parse(c.Param("goal-id"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Goal ID must be a UUID",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	contributions, err := goalService.GetGoalContributions(c.Request.Context(), goalID, user.UserID)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, contributions)
+}
+
+// AddGoalContribution ...
+// @Summary Contribute to a savings goal
+// @Description Records money put towards a goal that doesn't track an account, or taken out of it with a negative amount.
+// @Description contribution_date defaults to today
+// @Tags Savings Goals
+// @Accept  json
+// @Produce  json
+// @Param contribution body models.GoalContribution true "Contribution"
+// @Security Google AccessToken
+// @Success 200 {object} models.GoalContribution
+// @Failure 403 {object} models.Error
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /goal/contributions/create [post]
+func AddGoalContribution(c *gin.Context) {
+	var json models.GoalContribution
+	err := requests.ParseBody(c, &json)
+
+	if err != nil {
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	contribution, creationErr := goalService.AddGoalContribution(c.Request.Context(), json, user.UserID)
+
+	if creationErr != nil {
+		requests.ThrowError(
+			c,
+			creationErr.StatusCode,
+			creationErr.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, contribution)
+}
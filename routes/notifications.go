@@ -0,0 +1,100 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	notifierService "github.com/lakshay35/finlit-backend/services/notifier"
+	"github.com/lakshay35/finlit-backend/utils/requests"
+)
+
+// GetNotifications ...
+// @Summary Get notifications
+// @Description Gets the in-app notifications of the user, newest first
+// @Tags Notifications
+// @Accept  json
+// @Produce  json
+// @Param unread_only query bool false "Only unread notifications, false by default"
+// @Security Google AccessToken
+// @Success 200 {array} models.Notification
+// @Failure 400 {object} models.Error
+// @Router /notification/get [get]
+func GetNotifications(c *gin.Context) {
+	unreadOnly, parseErr := strconv.ParseBool(c.DefaultQuery("unread_only", "false"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"unread_only must be a boolean",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	notifications, err := notifierService.GetNotifications(c.Request.Context(), user.UserID, unreadOnly)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationRead ...
+// @Summary Mark a notification read
+// @Description Marks one of the user's in-app notifications as read
+// @Tags Notifications
+// @Accept  json
+// @Produce  json
+// @Security Google AccessToken
+// @Param notification-id path string true "Notification Id"
+// @Success 200
+// @Failure 404 {object} models.Error
+// @Failure 400 {object} models.Error
+// @Router /notification/read/{notification-id} [post]
+func MarkNotificationRead(c *gin.Context) {
+	notificationID, parseErr := uuid.Parse(c.Param("notification-id"))
+
+	if parseErr != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Notification ID must be a UUID",
+		)
+
+		return
+	}
+
+	user, userErr := requests.GetUserFromContext(c)
+
+	if userErr != nil {
+		panic(userErr)
+	}
+
+	if err := notifierService.MarkNotificationRead(c.Request.Context(), notificationID, user.UserID); err != nil {
+		requests.ThrowError(
+			c,
+			err.StatusCode,
+			err.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
@@ -36,7 +36,7 @@ func AddUserRoleToBudget(c *gin.Context) {
 		panic(err)
 	}
 
-	err = roleService.AddRoleToBudget(user.UserID, json.BudgetID, json.Role)
+	err = roleService.AddRoleToBudget(c.Request.Context(), user.UserID, json.BudgetID, json.Role)
 
 	if err != nil {
 		panic(err)
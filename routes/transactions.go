@@ -28,7 +28,7 @@ func CategorizeExpense(c *gin.Context) {
 		return
 	}
 
-	categorizationErr := transactionService.CategorizeTransaction(payload)
+	categorizationErr := transactionService.CategorizeTransaction(c.Request.Context(), payload)
 
 	if categorizationErr != nil {
 		requests.ThrowError(
@@ -30,7 +30,7 @@ func RegisterUser(c *gin.Context) {
 		return
 	}
 
-	user, userRegistrationError := userService.RegisterUser(json)
+	user, userRegistrationError := userService.RegisterUser(c.Request.Context(), json)
 
 	if userRegistrationError != nil {
 		requests.ThrowError(
@@ -61,7 +61,7 @@ func GetUserProfile(c *gin.Context) {
 		panic(err)
 	}
 
-	res, err := userService.GetUser(user.GoogleID)
+	res, err := userService.GetUser(c.Request.Context(), user.GoogleID)
 
 	if err != nil {
 		requests.ThrowError(
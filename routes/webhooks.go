@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	webhookService "github.com/lakshay35/finlit-backend/services/webhook"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+	"github.com/lakshay35/finlit-backend/utils/requests"
+)
+
+// PlaidWebhook ...
+// @Summary Receive Plaid Webhook
+// @Description Verifies the Plaid-Verification header, records the webhook and dispatches it to the handler for its type
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param Plaid-Verification header string true "Plaid signed JWT"
+// @Success 200
+// @Failure 400 {object} models.Error
+// @Failure 401 {object} models.Error
+// @Failure 500 {object} models.Error
+// @Router /webhooks/plaid [post]
+func PlaidWebhook(c *gin.Context) {
+	body, err := ioutil.ReadAll(c.Request.Body)
+
+	if err != nil {
+		requests.ThrowError(
+			c,
+			http.StatusBadRequest,
+			"Unable to read webhook body",
+		)
+
+		return
+	}
+
+	verifyErr := webhookService.VerifyPlaidWebhook(c.GetHeader("Plaid-Verification"), body, time.Now())
+
+	if verifyErr != nil {
+		logging.WarningLogger.Println(verifyErr)
+
+		requests.ThrowError(
+			c,
+			http.StatusUnauthorized,
+			"Webhook verification failed",
+		)
+
+		return
+	}
+
+	handleErr := webhookService.HandlePlaidWebhook(c.Request.Context(), body)
+
+	if handleErr != nil {
+		requests.ThrowError(
+			c,
+			handleErr.StatusCode,
+			handleErr.Message,
+		)
+
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
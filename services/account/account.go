@@ -1,121 +1,129 @@
 package account
 
 import (
+	"context"
+	"database/sql"
 	"net/http"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/lakshay35/finlit-backend/models"
 	"github.com/lakshay35/finlit-backend/models/errors"
+	merchantService "github.com/lakshay35/finlit-backend/services/merchant"
 	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
 	"github.com/lakshay35/finlit-backend/utils/database"
-	"github.com/lakshay35/finlit-backend/utils/encryption"
 	externalAccountUtils "github.com/lakshay35/finlit-backend/utils/external_account"
+	"github.com/lakshay35/finlit-backend/utils/logging"
 	"github.com/plaid/plaid-go/plaid"
 )
 
-// GetAccountsForAccessToken ...
-// gets account tied to access token
-// from Plaid APIS
-func GetAccountsForAccessToken(accessToken string) []plaid.Account {
-	accounts, err := plaidService.PlaidClient().GetAccounts(accessToken)
+// DeleteExternalAccount ...
+// Deletes an external account the user owns along with its
+// stored transactions. Deleting the last account of a Plaid
+// item removes the item too and revokes it with Plaid once the
+// deletes commit. Reports whether the item was removed. Budget
+// sources using the account must already be gone, see
+// budget.DisconnectExternalAccount
+func DeleteExternalAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (bool, *errors.Error) {
+	externalAccount, getAccountErr := GetUserExternalAccount(ctx, userID, accountID)
 
-	if err != nil {
-		panic("Something went wrong while getting accounts from plaid")
+	if getAccountErr != nil {
+		return false, getAccountErr
 	}
 
-	return accounts.Accounts
-}
+	var removedItem *models.PlaidItem
 
-// DeleteExternalAccount ...
-// Deletes external account with provided id
-func DeleteExternalAccount(accountID uuid.UUID) *errors.Error {
+	deleteErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		if err := store.DeleteAccount(ctx, accountID); err != nil {
+			return err
+		}
 
-	_, getAccountErr := GetAccountInformation(accountID)
+		remaining, err := store.GetItemAccounts(ctx, externalAccount.PlaidItemID)
 
-	if getAccountErr != nil {
-		return getAccountErr
-	}
+		if err != nil || len(remaining) > 0 {
+			return err
+		}
 
-	connection := database.GetConnection()
+		removedItem, err = store.GetItem(ctx, externalAccount.PlaidItemID)
 
-	defer database.CloseConnection(connection)
+		if err != nil {
+			return err
+		}
 
-	query := "DELETE FROM external_accounts where external_account_id = $1"
+		if err := store.DeleteItem(ctx, externalAccount.PlaidItemID); err != nil {
+			return err
+		}
 
-	stmt := database.PrepareStatement(connection, query)
+		// Plaid can't take a revocation back, so it waits
+		// until the item is gone for good
+		database.AfterCommit(ctx, func() {
+			removePlaidItem(removedItem.AccessToken)
+			forgetItemBalances(removedItem.PlaidItemID)
+		})
 
-	_, deleteErr := stmt.Exec(accountID)
+		return nil
+	})
 
 	if deleteErr != nil {
-		return &errors.Error{
+		if serviceErr, ok := deleteErr.(*errors.Error); ok {
+			return false, serviceErr
+		}
+
+		return false, &errors.Error{
 			Message:    deleteErr.Error(),
 			StatusCode: http.StatusBadRequest,
 		}
 	}
 
-	return nil
+	return removedItem != nil, nil
 }
 
-// GetExternalAccount ...
-// Gets external account from DB
-func GetExternalAccount(accountID uuid.UUID) (*models.Account, *errors.Error) {
-	connection := database.GetConnection()
-
-	defer database.CloseConnection(connection)
-
-	query := "SELECT * FROM external_accounts WHERE external_account_id = $1"
+// GetUserExternalAccount ...
+// Gets an external account, making sure the user owns it
+func GetUserExternalAccount(ctx context.Context, userID uuid.UUID, accountID uuid.UUID) (*models.Account, *errors.Error) {
+	externalAccount, err := store.GetAccount(ctx, accountID)
 
-	stmt, err := connection.Prepare(query)
-
-	if err != nil {
-		panic(err)
+	if err == sql.ErrNoRows {
+		return nil, &errors.Error{
+			Message:    "External Account not found",
+			StatusCode: http.StatusNotFound,
+		}
 	}
 
-	var externalAccount models.Account
-
-	err = stmt.QueryRow(accountID).Scan(
-		&externalAccount.ExternalAccountID,
-		&externalAccount.InstitutionalID,
-		&externalAccount.UserID,
-		&externalAccount.AccessToken,
-		&externalAccount.AccountName,
-	)
-
 	if err != nil {
 		return nil, &errors.Error{
 			Message:    err.Error(),
-			StatusCode: http.StatusBadRequest,
+			StatusCode: http.StatusInternalServerError,
 		}
 	}
 
-	return &externalAccount, nil
-}
+	if externalAccount.UserID != userID {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this external account",
+			StatusCode: http.StatusForbidden,
+		}
+	}
 
-// GetAccessTokenRenewalLinkToken...
-func GetAccessTokenRenewalLinkToken(userId string, externalAccountId uuid.UUID) string {
-	countryCodes := strings.Split(plaidService.PlaidCountryCodes, ",")
-	products := strings.Split(plaidService.PlaidProducts, ",")
+	externalAccount.NeedsAttention = models.PlaidItemNeedsAttention(externalAccount.ItemStatus)
 
-	config := plaid.LinkTokenConfigs{
-		User: &plaid.LinkTokenUser{
-			// This should correspond to a unique id for the current user.
-			ClientUserID: userId,
-		},
-		ClientName:   "Plaid Quickstart",
-		Products:     products,
-		CountryCodes: countryCodes,
-		Language:     "en",
-		AccessToken:  GetAccountAccessToken(externalAccountId),
-	}
+	return externalAccount, nil
+}
 
-	renewalLinkToken, renewalLinkTokenErr := plaidService.PlaidClient().CreateLinkToken(config)
+// GetExternalAccount ...
+// Gets external account from DB
+func GetExternalAccount(ctx context.Context, accountID uuid.UUID) (*models.Account, *errors.Error) {
+	externalAccount, err := store.GetAccount(ctx, accountID)
 
-	if renewalLinkTokenErr != nil {
-		panic(renewalLinkTokenErr)
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
 	}
 
-	return renewalLinkToken.LinkToken
+	externalAccount.NeedsAttention = models.PlaidItemNeedsAttention(externalAccount.ItemStatus)
+
+	return externalAccount, nil
 }
 
 // LinkTokenCreate creates a link token using the specified parameters
@@ -136,6 +144,7 @@ func LinkTokenCreate(
 		Language:          "en",
 		RedirectUri:       redirectURI,
 		PaymentInitiation: paymentInitiation,
+		Webhook:           plaidService.PlaidWebhookURL,
 	}
 
 	resp, err := plaidService.PlaidClient().CreateLinkToken(configs)
@@ -153,77 +162,26 @@ func LinkTokenCreate(
 // GetAccountAccessToken ...
 // Get access token for an account based
 // on accountID
-func GetAccountAccessToken(accountID uuid.UUID) string {
-	connection := database.GetConnection()
-
-	query := "SELECT access_token FROM external_accounts WHERE external_account_id = $1"
-
-	stmt, err := connection.Prepare(query)
-
-	if err != nil {
-		panic("Error preparing statement while getting access token for accountID " + accountID.String())
-	}
-
-	var access_token string
-
-	err = stmt.QueryRow(accountID).Scan(&access_token)
+func GetAccountAccessToken(ctx context.Context, accountID uuid.UUID) string {
+	externalAccount, err := store.GetAccount(ctx, accountID)
 
 	if err != nil {
 		panic(err)
 	}
 
-	b64d, b64dError := encryption.DecodeBase64(access_token)
-
-	if b64dError != nil {
-		panic(b64dError)
-	}
-
-	return string(encryption.Decrypt([]byte([]byte(b64d))))
-}
-
-// RegisterExternalAccounts ...
-// Registers external accounts in the database based on acces token
-func RegisterExternalAccounts(accessToken string, userID uuid.UUID) {
-	connection := database.GetConnection()
-
-	defer database.CloseConnection(connection)
-
-	query := "INSERT INTO external_accounts (institutional_id, access_token, account_name, user_id) VALUES ($1, $2, $3, $4)"
-
-	stmt, err := connection.Prepare(query)
+	plaidItem, err := store.GetItem(ctx, externalAccount.PlaidItemID)
 
 	if err != nil {
 		panic(err)
 	}
 
-	accounts := GetAccountsForAccessToken(accessToken)
-
-	for _, act := range accounts {
-		_, err = stmt.Exec(act.AccountID, encryption.EncodeBase64(string(encryption.Encrypt([]byte(accessToken)))), act.OfficialName+" "+act.Name, userID)
-
-		if err != nil {
-			panic("Error occurred when looping over bank accounts from plaid")
-		}
-	}
+	return externalAccountUtils.ConvertToAccessToken(plaidItem.AccessToken)
 }
 
 // GetAllExternalAccounts ...
 // Gets all external accounts tagged with the given userID
-func GetAllExternalAccounts(userID uuid.UUID) ([]models.Account, *errors.Error) {
-	connection := database.GetConnection()
-
-	defer database.CloseConnection(connection)
-
-	query := "SELECT external_account_id, institutional_id, user_id, account_name FROM external_accounts WHERE user_id = $1"
-
-	stmt, err := connection.Prepare(query)
-
-	if err != nil {
-		panic(err)
-
-	}
-
-	rows, err := stmt.Query(userID)
+func GetAllExternalAccounts(ctx context.Context, userID uuid.UUID) ([]models.Account, *errors.Error) {
+	accounts, err := store.GetUserAccounts(ctx, userID)
 
 	if err != nil {
 		return nil, &errors.Error{
@@ -232,33 +190,18 @@ func GetAllExternalAccounts(userID uuid.UUID) ([]models.Account, *errors.Error)
 		}
 	}
 
-	var accounts []models.Account = make([]models.Account, 0)
-
-	for rows.Next() {
-		var temp models.Account
-
-		err = rows.Scan(
-			&temp.ExternalAccountID,
-			&temp.InstitutionalID,
-			&temp.UserID,
-			&temp.AccountName,
-		)
-
-		if err != nil {
-			panic(err)
-		}
-
-		accounts = append(accounts, temp)
-	}
-
-	rows.Close()
+	flagAccountAttention(accounts)
 
 	return accounts, nil
 }
 
 // RegisterAccessToken ...
-// Registers access token after exchanging public token for given userID
-func RegisterAccessToken(token string, userID uuid.UUID) *errors.Error {
+// Registers access token after exchanging public token for given userID.
+// The item is committed before its first sync, both outside the
+// request's unit of work since they call Plaid
+func RegisterAccessToken(ctx context.Context, token string, userID uuid.UUID) *errors.Error {
+	ctx = database.WithoutUnitOfWork(ctx)
+
 	response, err := plaidService.PlaidClient().ExchangePublicToken(token)
 	if err != nil {
 		return &errors.Error{
@@ -267,22 +210,61 @@ func RegisterAccessToken(token string, userID uuid.UUID) *errors.Error {
 		}
 	}
 
-	accessToken := response.AccessToken
+	plaidItem, registerErr := RegisterItem(ctx, response.AccessToken, response.ItemID, userID)
 
-	RegisterExternalAccounts(accessToken, userID)
+	if registerErr != nil {
+		return registerErr
+	}
+
+	// Plaid often hasn't pulled history for a new item yet,
+	// so a failed first sync shouldn't fail the registration
+	if _, syncErr := syncItem(ctx, *plaidItem); syncErr != nil {
+		logging.WarningLogger.Println("initial transaction sync failed:", syncErr.Message)
+	}
 
 	return nil
 }
 
 // GetTransactions ...
-// Gets Transactions for specified time period
+// Gets synced transactions for specified time period
+// from the local transaction store of a user's account
 func GetTransactions(
+	ctx context.Context,
+	userID uuid.UUID,
 	externalAccountID uuid.UUID,
 	startDate string,
 	endDate string,
 ) ([]plaid.Transaction, *errors.Error) {
+	if _, accountErr := GetUserExternalAccount(ctx, userID, externalAccountID); accountErr != nil {
+		return nil, accountErr
+	}
+
+	stored, err := GetStoredTransactions(ctx, externalAccountID, startDate, endDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]plaid.Transaction, 0, len(stored))
+
+	for _, tx := range stored {
+		transactions = append(transactions, tx.Transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetStoredTransactions ...
+// Gets synced transactions for specified time period along
+// with the external account and item they belong to
+func GetStoredTransactions(
+	ctx context.Context,
+	externalAccountID uuid.UUID,
+	startDate string,
+	endDate string,
+) ([]models.Transaction, *errors.Error) {
 
-	externalAccount, GetExternalAccountErr := GetExternalAccount(externalAccountID)
+	_, GetExternalAccountErr := GetExternalAccount(ctx, externalAccountID)
 
 	if GetExternalAccountErr != nil {
 		return nil, &errors.Error{
@@ -291,52 +273,86 @@ func GetTransactions(
 		}
 	}
 
-	response, err := plaidService.PlaidClient().GetTransactions(
-		externalAccountUtils.ConvertToAccessToken(externalAccount.AccessToken),
-		startDate,
-		endDate,
-	)
+	stored, err := store.GetAccountTransactions(ctx, externalAccountID, startDate, endDate)
 
 	if err != nil {
 		return nil, &errors.Error{
 			Message:    err.Error(),
-			StatusCode: http.StatusBadRequest,
+			StatusCode: http.StatusInternalServerError,
 		}
 	}
 
-	var transactions []plaid.Transaction
+	for i := range stored {
+		withMerchant(&stored[i])
+	}
 
-	for _, tx := range response.Transactions {
-		if strings.EqualFold(tx.AccountID, externalAccount.InstitutionalID) {
-			transactions = append(transactions, tx)
+	return stored, nil
+}
+
+// GetStoredTransaction ...
+// Gets a synced transaction by its plaid transaction id
+func GetStoredTransaction(ctx context.Context, transactionID string) (*models.Transaction, *errors.Error) {
+	transaction, err := store.GetTransaction(ctx, transactionID)
+
+	if err == sql.ErrNoRows {
+		return nil, &errors.Error{
+			Message:    "Transaction not found",
+			StatusCode: http.StatusNotFound,
 		}
 	}
 
-	return transactions, nil
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	withMerchant(transaction)
+
+	return transaction, nil
+}
+
+// withMerchant ...
+// Fills in the merchant of transactions synced
+// before merchants were stored with them
+func withMerchant(transaction *models.Transaction) {
+	if transaction.Merchant == "" {
+		transaction.Merchant = merchantService.Normalize(transaction.Name)
+	}
 }
 
 // GetAccountInformation ...
-// Gets account information based on external account ID
+// Gets account information of one of a user's external accounts.
+// Only reads, so it runs outside the request's unit of work and
+// no transaction waits on Plaid
 func GetAccountInformation(
+	ctx context.Context,
+	userID uuid.UUID,
 	externalAccountID uuid.UUID,
 ) (*plaid.Account, *errors.Error) {
-	externalAccount, GetExternalAccountErr := GetExternalAccount(externalAccountID)
+	ctx = database.WithoutUnitOfWork(ctx)
+
+	externalAccount, GetExternalAccountErr := GetUserExternalAccount(ctx, userID, externalAccountID)
 
 	if GetExternalAccountErr != nil {
+		return nil, GetExternalAccountErr
+	}
+	plaidItem, itemErr := store.GetItem(ctx, externalAccount.PlaidItemID)
+
+	if itemErr != nil {
 		return nil, &errors.Error{
-			StatusCode: http.StatusBadRequest,
-			Message:    "External Account not found",
+			Message:    itemErr.Error(),
+			StatusCode: http.StatusInternalServerError,
 		}
 	}
+
 	response, err := plaidService.PlaidClient().GetAccounts(
-		externalAccountUtils.ConvertToAccessToken(externalAccount.AccessToken),
+		externalAccountUtils.ConvertToAccessToken(plaidItem.AccessToken),
 	)
 
 	if err != nil {
-		return nil, &errors.Error{
-			Message:    err.Error(),
-			StatusCode: http.StatusBadRequest,
-		}
+		return nil, itemError(ctx, *plaidItem, err)
 	}
 
 	var account plaid.Account
@@ -0,0 +1,165 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	externalAccountUtils "github.com/lakshay35/finlit-backend/utils/external_account"
+)
+
+func TestDeleteExternalAccountRemovesItemWithItsLastAccount(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	fake := useFakes()
+
+	if err := RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), userID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	accounts, err := store.GetUserAccounts(ctx, userID)
+
+	if err != nil || len(accounts) != 2 {
+		t.Fatalf("accounts = %+v (%v), want two", accounts, err)
+	}
+
+	item, err := store.GetItem(ctx, accounts[0].PlaidItemID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accessToken := externalAccountUtils.ConvertToAccessToken(item.AccessToken)
+
+	// The item still has the other account
+	removed, deleteErr := DeleteExternalAccount(ctx, userID, accounts[0].ExternalAccountID)
+
+	if deleteErr != nil {
+		t.Fatal(deleteErr.Message)
+	}
+
+	if removed {
+		t.Error("item removed while it still has an account")
+	}
+
+	if _, err := store.GetItem(ctx, item.PlaidItemID); err != nil {
+		t.Errorf("item of the remaining account is gone: %v", err)
+	}
+
+	if _, err := fake.GetItem(accessToken); err != nil {
+		t.Errorf("item revoked with Plaid while it still has an account: %v", err)
+	}
+
+	removed, deleteErr = DeleteExternalAccount(ctx, userID, accounts[1].ExternalAccountID)
+
+	if deleteErr != nil {
+		t.Fatal(deleteErr.Message)
+	}
+
+	if !removed {
+		t.Error("item kept after deleting its last account")
+	}
+
+	if _, err := store.GetItem(ctx, item.PlaidItemID); err == nil {
+		t.Error("item still stored")
+	}
+
+	if _, err := fake.GetItem(accessToken); err == nil {
+		t.Error("item not revoked with Plaid")
+	}
+}
+
+func TestDeleteExternalAccountOfRevokedItem(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	fake := useFakes()
+
+	if err := RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), userID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	accounts, err := store.GetUserAccounts(ctx, userID)
+
+	if err != nil || len(accounts) != 2 {
+		t.Fatalf("accounts = %+v (%v), want two", accounts, err)
+	}
+
+	item, err := store.GetItem(ctx, accounts[0].PlaidItemID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Plaid no longer knows the item, which mustn't keep it around
+	if _, err := fake.RemoveItem(externalAccountUtils.ConvertToAccessToken(item.AccessToken)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, act := range accounts {
+		if _, deleteErr := DeleteExternalAccount(ctx, userID, act.ExternalAccountID); deleteErr != nil {
+			t.Fatal(deleteErr.Message)
+		}
+	}
+
+	if _, err := store.GetItem(ctx, item.PlaidItemID); err == nil {
+		t.Error("item still stored")
+	}
+}
+
+func TestRegisterItemRelinkRevokesReplacedItem(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	fake := useFakes()
+
+	if err := RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), userID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	before, err := store.GetUserAccounts(ctx, userID)
+
+	if err != nil || len(before) != 2 {
+		t.Fatalf("accounts = %+v (%v), want two", before, err)
+	}
+
+	replaced, err := store.GetItem(ctx, before[0].PlaidItemID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Linking the same login again replaces the item
+	if err := RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), userID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	after, err := store.GetUserAccounts(ctx, userID)
+
+	if err != nil || len(after) != 2 {
+		t.Fatalf("accounts after relinking = %+v (%v), want two", after, err)
+	}
+
+	for i := range after {
+		if after[i].ExternalAccountID != before[i].ExternalAccountID || after[i].PlaidItemID != replaced.PlaidItemID {
+			t.Errorf("account %d = %+v, want it kept under item %s", i, after[i], replaced.PlaidItemID)
+		}
+	}
+
+	relinked, err := store.GetItem(ctx, replaced.PlaidItemID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if relinked.ItemID == replaced.ItemID {
+		t.Fatal("item still has the replaced Plaid item id")
+	}
+
+	if _, err := fake.GetItem(externalAccountUtils.ConvertToAccessToken(replaced.AccessToken)); err == nil {
+		t.Error("replaced item not revoked with Plaid")
+	}
+
+	if _, err := fake.GetItem(externalAccountUtils.ConvertToAccessToken(relinked.AccessToken)); err != nil {
+		t.Errorf("relinked item revoked with Plaid: %v", err)
+	}
+}
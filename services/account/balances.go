@@ -0,0 +1,222 @@
+package account
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	environment "github.com/lakshay35/finlit-backend/services/environment"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	externalAccountUtils "github.com/lakshay35/finlit-backend/utils/external_account"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// defaultBalanceCacheTTL is used when BALANCE_CACHE_TTL is unset or invalid
+const defaultBalanceCacheTTL = 5 * time.Minute
+
+type cachedBalances struct {
+	response  plaid.GetBalancesResponse
+	fetchedAt time.Time
+}
+
+var (
+	balanceCacheMutex sync.Mutex
+	balanceCache      = make(map[uuid.UUID]cachedBalances)
+	balanceCacheTTL   = defaultBalanceCacheTTL
+)
+
+func init() {
+	if ttl := environment.GetEnvVariable("BALANCE_CACHE_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+
+		if err != nil {
+			logging.WarningLogger.Printf("invalid BALANCE_CACHE_TTL %q, using %s", ttl, defaultBalanceCacheTTL)
+			return
+		}
+
+		balanceCacheTTL = parsed
+	}
+}
+
+// GetCurrentBalances ...
+// Gets live balances for the external accounts of a user, one
+// entry per Plaid item. Plaid is called once per item and results
+// are cached for BALANCE_CACHE_TTL unless forceRefresh is set.
+// Items Plaid can't serve are reported with a message instead of
+// accounts, so one broken link doesn't hide every other balance.
+// Runs outside the request's unit of work since it waits on Plaid
+func GetCurrentBalances(ctx context.Context, userID uuid.UUID, forceRefresh bool) ([]models.PlaidItemBalances, *errors.Error) {
+	ctx = database.WithoutUnitOfWork(ctx)
+
+	items, err := store.GetUserItems(ctx, userID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	balances := make([]models.PlaidItemBalances, 0, len(items))
+
+	for _, item := range items {
+		if item.Status == models.PlaidItemStatusRevoked {
+			continue
+		}
+
+		itemAccounts, accountsErr := store.GetItemAccounts(ctx, item.PlaidItemID)
+
+		if accountsErr != nil {
+			return nil, &errors.Error{
+				Message:    accountsErr.Error(),
+				StatusCode: http.StatusInternalServerError,
+			}
+		}
+
+		itemBalances := models.PlaidItemBalances{
+			PlaidGetBalancesResponse: models.PlaidGetBalancesResponse{Accounts: make([]models.PlaidAccount, 0)},
+			PlaidItemID:              item.PlaidItemID,
+			InstitutionName:          item.InstitutionName,
+			ItemStatus:               item.Status,
+		}
+
+		response, itemErr := getItemBalances(ctx, item, forceRefresh)
+
+		if itemErr != nil {
+			logging.WarningLogger.Printf("balances unavailable for item %s: %s", item.ItemID, itemErr.Message)
+
+			// itemError records broken logins, so report
+			// the status the item is left in
+			if refreshed, getErr := store.GetItem(ctx, item.PlaidItemID); getErr == nil {
+				itemBalances.ItemStatus = refreshed.Status
+			}
+
+			itemBalances.Message = itemErr.Message
+			balances = append(balances, itemBalances)
+
+			continue
+		}
+
+		registered := make(map[string]bool, len(itemAccounts))
+
+		for _, act := range itemAccounts {
+			registered[act.InstitutionalID] = true
+		}
+
+		itemBalances.RequestID = response.RequestID
+
+		for _, act := range response.Accounts {
+			if registered[act.AccountID] {
+				itemBalances.Accounts = append(itemBalances.Accounts, toPlaidAccountModel(act))
+			}
+		}
+
+		balances = append(balances, itemBalances)
+	}
+
+	return balances, nil
+}
+
+// GetAccountBalance ...
+// Gets the live balance of an external account. It shares the
+// balance cache of the account's item, so anyone the owner
+// shares a budget with can see it without calling Plaid again
+func GetAccountBalance(ctx context.Context, externalAccountID uuid.UUID) (*models.PlaidAccountBalances, *errors.Error) {
+	ctx = database.WithoutUnitOfWork(ctx)
+
+	externalAccount, accountErr := GetExternalAccount(ctx, externalAccountID)
+
+	if accountErr != nil {
+		return nil, accountErr
+	}
+
+	item, err := store.GetItem(ctx, externalAccount.PlaidItemID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	response, itemErr := getItemBalances(ctx, *item, false)
+
+	if itemErr != nil {
+		return nil, itemErr
+	}
+
+	for _, act := range response.Accounts {
+		if act.AccountID == externalAccount.InstitutionalID {
+			balance := toPlaidAccountModel(act).Balances
+
+			return &balance, nil
+		}
+	}
+
+	return nil, &errors.Error{
+		Message:    "Balance of external account " + externalAccount.AccountName + " is unavailable",
+		StatusCode: http.StatusBadGateway,
+	}
+}
+
+// getItemBalances ...
+// Returns the balances of every account of
+// an item, from the cache when fresh
+func getItemBalances(ctx context.Context, item models.PlaidItem, forceRefresh bool) (plaid.GetBalancesResponse, *errors.Error) {
+	balanceCacheMutex.Lock()
+	cached, ok := balanceCache[item.PlaidItemID]
+	balanceCacheMutex.Unlock()
+
+	if ok && !forceRefresh && time.Since(cached.fetchedAt) < balanceCacheTTL {
+		return cached.response, nil
+	}
+
+	response, err := plaidService.PlaidClient().GetBalances(
+		externalAccountUtils.ConvertToAccessToken(item.AccessToken),
+	)
+
+	if err != nil {
+		return plaid.GetBalancesResponse{}, itemError(ctx, item, err)
+	}
+
+	balanceCacheMutex.Lock()
+	balanceCache[item.PlaidItemID] = cachedBalances{response: response, fetchedAt: time.Now()}
+	balanceCacheMutex.Unlock()
+
+	return response, nil
+}
+
+// forgetItemBalances ...
+// Drops an item's cached balances once its
+// accounts or access token change
+func forgetItemBalances(plaidItemID uuid.UUID) {
+	balanceCacheMutex.Lock()
+	defer balanceCacheMutex.Unlock()
+
+	delete(balanceCache, plaidItemID)
+}
+
+func toPlaidAccountModel(act plaid.Account) models.PlaidAccount {
+	return models.PlaidAccount{
+		AccountID: act.AccountID,
+		Balances: models.PlaidAccountBalances{
+			Available:              act.Balances.Available,
+			Current:                act.Balances.Current,
+			Limit:                  act.Balances.Limit,
+			ISOCurrencyCode:        act.Balances.ISOCurrencyCode,
+			UnofficialCurrencyCode: act.Balances.UnofficialCurrencyCode,
+		},
+		Mask:               act.Mask,
+		Name:               act.Name,
+		OfficialName:       act.OfficialName,
+		Subtype:            act.Subtype,
+		Type:               act.Type,
+		VerificationStatus: act.VerificationStatus,
+	}
+}
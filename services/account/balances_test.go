@@ -0,0 +1,90 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+func TestGetCurrentBalancesReportsFailedItems(t *testing.T) {
+	ctx := context.Background()
+	fake := useFakes()
+	userID := uuid.New()
+
+	fake.AddInstitution(plaidService.FakeInstitution{
+		InstitutionID: "ins_other_bank",
+		Name:          "Other Bank",
+		Accounts: []plaid.Account{
+			{AccountID: "savings", Name: "Savings", Balances: plaid.AccountBalances{Current: 900}},
+		},
+	})
+
+	for _, institutionID := range []string{"ins_fake_bank", "ins_other_bank"} {
+		if err := RegisterAccessToken(ctx, plaidService.FakePublicToken(institutionID), userID); err != nil {
+			t.Fatal(err.Message)
+		}
+	}
+
+	items, _ := store.GetUserItems(ctx, userID)
+	var broken models.PlaidItem
+
+	for _, item := range items {
+		forgetItemBalances(item.PlaidItemID)
+
+		if item.InstitutionID == "ins_other_bank" {
+			broken = item
+		}
+	}
+
+	fake.SetItemError(broken.ItemID, "ITEM_LOGIN_REQUIRED")
+
+	balances, err := GetCurrentBalances(ctx, userID, true)
+
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	if len(balances) != 2 {
+		t.Fatalf("got balances of %d items, want 2", len(balances))
+	}
+
+	for _, itemBalances := range balances {
+		if itemBalances.PlaidItemID == broken.PlaidItemID {
+			if itemBalances.Message == "" || len(itemBalances.Accounts) != 0 {
+				t.Errorf("broken item = %+v, want a message and no accounts", itemBalances)
+			}
+
+			if itemBalances.ItemStatus != models.PlaidItemStatusLoginRequired {
+				t.Errorf("broken item status = %s, want %s", itemBalances.ItemStatus, models.PlaidItemStatusLoginRequired)
+			}
+
+			continue
+		}
+
+		if itemBalances.Message != "" || len(itemBalances.Accounts) != 2 {
+			t.Errorf("healthy item = %+v, want both accounts", itemBalances)
+		}
+	}
+
+	// Every item failing still reports each of them
+	// rather than failing the whole request
+	for _, item := range items {
+		fake.SetItemError(item.ItemID, "ITEM_LOGIN_REQUIRED")
+	}
+
+	balances, err = GetCurrentBalances(ctx, userID, true)
+
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	for _, itemBalances := range balances {
+		if itemBalances.Message == "" {
+			t.Errorf("item %s has no message", itemBalances.PlaidItemID)
+		}
+	}
+}
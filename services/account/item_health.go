@@ -0,0 +1,235 @@
+package account
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	externalAccountUtils "github.com/lakshay35/finlit-backend/utils/external_account"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// loginRequiredErrorCodes are Plaid errors the user
+// fixes by logging in again through Link update mode
+var loginRequiredErrorCodes = map[string]bool{
+	"ITEM_LOGIN_REQUIRED":      true,
+	"INVALID_CREDENTIALS":      true,
+	"INVALID_MFA":              true,
+	"INVALID_UPDATED_USERNAME": true,
+	"ITEM_LOCKED":              true,
+	"MFA_NOT_SUPPORTED":        true,
+	"NO_ACCOUNTS":              true,
+	"USER_SETUP_REQUIRED":      true,
+}
+
+// revokedErrorCodes are Plaid errors after which the access
+// token never works again and the item has to be linked anew
+var revokedErrorCodes = map[string]bool{
+	"ACCESS_NOT_GRANTED":      true,
+	"INVALID_ACCESS_TOKEN":    true,
+	"ITEM_NOT_FOUND":          true,
+	"USER_PERMISSION_REVOKED": true,
+}
+
+// ItemStatusForErrorCode ...
+// The item status a Plaid error code puts an item in, or
+// an empty string for errors like INSTITUTION_DOWN that
+// clear up without the user doing anything
+func ItemStatusForErrorCode(errorCode string) string {
+	if loginRequiredErrorCodes[errorCode] {
+		return models.PlaidItemStatusLoginRequired
+	}
+
+	if revokedErrorCodes[errorCode] {
+		return models.PlaidItemStatusRevoked
+	}
+
+	return ""
+}
+
+// PlaidItemIsBroken ...
+// Whether Plaid has stopped serving an item in the given
+// status. Items pending expiration still work until then
+func PlaidItemIsBroken(status string) bool {
+	return status == models.PlaidItemStatusLoginRequired || status == models.PlaidItemStatusRevoked
+}
+
+// GetAccessTokenRenewalLinkToken ...
+// Creates an update mode link token for the item an external
+// account belongs to so the user can log in to it again.
+// Revoked items can't be renewed and have to be linked anew
+func GetAccessTokenRenewalLinkToken(ctx context.Context, userID uuid.UUID, externalAccountID uuid.UUID) (string, *errors.Error) {
+	ctx = database.WithoutUnitOfWork(ctx)
+
+	plaidItem, itemErr := userAccountItem(ctx, userID, externalAccountID)
+
+	if itemErr != nil {
+		return "", itemErr
+	}
+
+	if plaidItem.Status == models.PlaidItemStatusRevoked {
+		return "", &errors.Error{
+			Message:    itemAttentionMessage(*plaidItem),
+			StatusCode: http.StatusConflict,
+		}
+	}
+
+	renewalLinkToken, err := plaidService.PlaidClient().CreateLinkToken(plaid.LinkTokenConfigs{
+		User: &plaid.LinkTokenUser{
+			// This should correspond to a unique id for the current user.
+			ClientUserID: userID.String(),
+		},
+		ClientName:   "Plaid Quickstart",
+		Products:     strings.Split(plaidService.PlaidProducts, ","),
+		CountryCodes: strings.Split(plaidService.PlaidCountryCodes, ","),
+		Language:     "en",
+		AccessToken:  externalAccountUtils.ConvertToAccessToken(plaidItem.AccessToken),
+		Webhook:      plaidService.PlaidWebhookURL,
+	})
+
+	if err != nil {
+		return "", itemError(ctx, *plaidItem, err)
+	}
+
+	return renewalLinkToken.LinkToken, nil
+}
+
+// CompleteItemRenewal ...
+// Called once update mode Link succeeds. Checks the item works
+// with Plaid again, clears its error state and consent expiry
+// and catches its transactions up. The renewal commits before
+// the sync, outside the request's unit of work, so no lock on
+// the item is held while the sync talks to Plaid or records
+// an error the item runs into again
+func CompleteItemRenewal(ctx context.Context, userID uuid.UUID, externalAccountID uuid.UUID) (*models.PlaidItem, *errors.Error) {
+	ctx = database.WithoutUnitOfWork(ctx)
+
+	plaidItem, itemErr := userAccountItem(ctx, userID, externalAccountID)
+
+	if itemErr != nil {
+		return nil, itemErr
+	}
+
+	_, err := plaidService.PlaidClient().GetAccounts(
+		externalAccountUtils.ConvertToAccessToken(plaidItem.AccessToken),
+	)
+
+	if err != nil {
+		return nil, itemError(ctx, *plaidItem, err)
+	}
+
+	if renewErr := store.RenewItem(ctx, plaidItem.PlaidItemID); renewErr != nil {
+		return nil, &errors.Error{
+			Message:    renewErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	forgetItemBalances(plaidItem.PlaidItemID)
+
+	renewed, err := store.GetItem(ctx, plaidItem.PlaidItemID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if _, syncErr := syncItem(ctx, *renewed); syncErr != nil {
+		logging.WarningLogger.Printf("sync after renewing item %s failed: %s", renewed.ItemID, syncErr.Message)
+	}
+
+	flagItemAttention(renewed)
+
+	return renewed, nil
+}
+
+// userAccountItem ...
+// Gets the item of an external account the user owns
+func userAccountItem(ctx context.Context, userID uuid.UUID, externalAccountID uuid.UUID) (*models.PlaidItem, *errors.Error) {
+	externalAccount, accountErr := GetUserExternalAccount(ctx, userID, externalAccountID)
+
+	if accountErr != nil {
+		return nil, accountErr
+	}
+
+	plaidItem, err := store.GetItem(ctx, externalAccount.PlaidItemID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return plaidItem, nil
+}
+
+// itemError ...
+// Converts the error of a Plaid call made for an item into a
+// service error. When the error means the user has to renew
+// or relink the item its status is recorded outside the unit
+// of work, so it sticks even though the request fails. Callers
+// must not hold a lock on the item in a unit of work ctx
+// carries, or the status update waits on it forever
+func itemError(ctx context.Context, item models.PlaidItem, err error) *errors.Error {
+	plaidErr, ok := err.(plaid.Error)
+
+	if !ok {
+		return plaidError(err)
+	}
+
+	status := ItemStatusForErrorCode(plaidErr.ErrorCode)
+
+	if status == "" {
+		return plaidError(err)
+	}
+
+	if item.ItemID != "" && item.Status != status {
+		statusErr := store.SetItemStatus(database.WithoutUnitOfWork(ctx), item.ItemID, status, plaidErr.ErrorCode, nil)
+
+		if statusErr != nil {
+			logging.ErrorLogger.Printf("recording status of item %s failed: %s", item.ItemID, statusErr)
+		}
+	}
+
+	item.Status = status
+
+	return &errors.Error{
+		Message:    itemAttentionMessage(item),
+		StatusCode: http.StatusConflict,
+	}
+}
+
+// itemAttentionMessage ...
+// Tells the user what to do about a broken item
+func itemAttentionMessage(item models.PlaidItem) string {
+	institution := item.InstitutionName
+
+	if institution == "" {
+		institution = "this bank"
+	}
+
+	if item.Status == models.PlaidItemStatusRevoked {
+		return "Access to " + institution + " was revoked, link it again to keep it up to date"
+	}
+
+	return "The login for " + institution + " has expired, renew it to keep it up to date"
+}
+
+func flagItemAttention(item *models.PlaidItem) {
+	item.NeedsAttention = models.PlaidItemNeedsAttention(item.Status)
+}
+
+func flagAccountAttention(accounts []models.Account) {
+	for i := range accounts {
+		accounts[i].NeedsAttention = models.PlaidItemNeedsAttention(accounts[i].ItemStatus)
+	}
+}
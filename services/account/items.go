@@ -0,0 +1,340 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	"github.com/lakshay35/finlit-backend/utils/encryption"
+	externalAccountUtils "github.com/lakshay35/finlit-backend/utils/external_account"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// RegisterItem ...
+// Registers the Plaid item behind a newly exchanged access
+// token along with its accounts. Linking an institution again
+// reuses the item whose accounts it overlaps instead of adding
+// duplicates, so external account ids and the budgets using
+// them survive a relink
+func RegisterItem(ctx context.Context, accessToken string, itemID string, userID uuid.UUID) (*models.PlaidItem, *errors.Error) {
+	response, err := plaidService.PlaidClient().GetAccounts(accessToken)
+
+	if err != nil {
+		return nil, plaidError(err)
+	}
+
+	if itemID == "" {
+		itemID = response.Item.ItemID
+	}
+
+	institutionID := response.Item.InstitutionID
+	encryptedAccessToken := encryption.EncodeBase64(string(encryption.Encrypt([]byte(accessToken))))
+	name := institutionName(institutionID)
+
+	var plaidItemID uuid.UUID
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		existing, existingAccounts, findErr := findRelinkedItem(ctx, userID, institutionID, response.Accounts)
+
+		if findErr != nil {
+			return findErr
+		}
+
+		if existing == nil {
+			created, createErr := store.CreateItem(ctx, models.PlaidItem{
+				ItemID:          itemID,
+				UserID:          userID,
+				InstitutionID:   institutionID,
+				InstitutionName: name,
+				AccessToken:     encryptedAccessToken,
+			})
+
+			if createErr != nil {
+				return createErr
+			}
+
+			plaidItemID = created.PlaidItemID
+
+			return store.CreateAccounts(ctx, newAccounts(*created, response.Accounts))
+		}
+
+		plaidItemID = existing.PlaidItemID
+
+		if err := relinkItem(ctx, *existing, existingAccounts, itemID, encryptedAccessToken, response.Accounts); err != nil {
+			return err
+		}
+
+		if existing.ItemID != itemID {
+			replacedAccessToken := existing.AccessToken
+
+			// The old token stays usable until the new one is
+			// stored for good, a rollback still needs it
+			database.AfterCommit(ctx, func() {
+				logging.InfoLogger.Printf("plaid item %s was relinked as %s", plaidItemID, itemID)
+
+				removePlaidItem(replacedAccessToken)
+				forgetItemBalances(plaidItemID)
+			})
+		}
+
+		return nil
+	})
+
+	if txErr != nil {
+		return nil, &errors.Error{
+			Message:    txErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	plaidItem, err := store.GetItem(ctx, plaidItemID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return plaidItem, nil
+}
+
+// GetUserItems ...
+// Gets every Plaid item of a user along
+// with the external accounts registered under it
+func GetUserItems(ctx context.Context, userID uuid.UUID) ([]models.PlaidItemAccounts, *errors.Error) {
+	items, err := store.GetUserItems(ctx, userID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	accounts, err := store.GetUserAccounts(ctx, userID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	grouped := make([]models.PlaidItemAccounts, 0, len(items))
+	index := make(map[uuid.UUID]int, len(items))
+
+	flagAccountAttention(accounts)
+
+	for i, item := range items {
+		flagItemAttention(&item)
+		index[item.PlaidItemID] = i
+		grouped = append(grouped, models.PlaidItemAccounts{
+			PlaidItem: item,
+			Accounts:  make([]models.Account, 0),
+		})
+	}
+
+	for _, act := range accounts {
+		if i, ok := index[act.PlaidItemID]; ok {
+			grouped[i].Accounts = append(grouped[i].Accounts, act)
+		}
+	}
+
+	return grouped, nil
+}
+
+// SetItemStatus ...
+// Records the status Plaid reported for an item
+func SetItemStatus(ctx context.Context, itemID string, status string, errorCode string, consentExpiresAt *time.Time) *errors.Error {
+	err := store.SetItemStatus(ctx, itemID, status, errorCode, consentExpiresAt)
+
+	if err == sql.ErrNoRows {
+		return &errors.Error{
+			Message:    "No plaid item registered with id " + itemID,
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// findRelinkedItem ...
+// Finds the user's item at the institution that shares an
+// account with the newly linked one, if any. Items at the same
+// institution with no accounts in common are separate logins
+func findRelinkedItem(
+	ctx context.Context,
+	userID uuid.UUID,
+	institutionID string,
+	plaidAccounts []plaid.Account,
+) (*models.PlaidItem, []models.Account, error) {
+	if institutionID == "" {
+		return nil, nil, nil
+	}
+
+	items, err := store.GetUserInstitutionItems(ctx, userID, institutionID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range items {
+		accounts, accountsErr := store.GetItemAccounts(ctx, items[i].PlaidItemID)
+
+		if accountsErr != nil {
+			return nil, nil, accountsErr
+		}
+
+		for _, account := range accounts {
+			for _, act := range plaidAccounts {
+				if sameAccount(account, act) {
+					return &items[i], accounts, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// relinkItem ...
+// Moves an existing item over to the newly linked one. Matching
+// accounts keep their ids and take the new Plaid account ids,
+// new ones are added. Plaid issues new transaction ids for a
+// new item, so the old item's transactions are dropped and its
+// cursor cleared for a full sync
+func relinkItem(
+	ctx context.Context,
+	existing models.PlaidItem,
+	existingAccounts []models.Account,
+	itemID string,
+	encryptedAccessToken string,
+	plaidAccounts []plaid.Account,
+) error {
+	matched := make(map[uuid.UUID]bool, len(existingAccounts))
+	unmatched := make([]plaid.Account, 0)
+
+	for _, act := range plaidAccounts {
+		found := false
+
+		for _, account := range existingAccounts {
+			if matched[account.ExternalAccountID] || !sameAccount(account, act) {
+				continue
+			}
+
+			account.InstitutionalID = act.AccountID
+			account.AccountName = act.Name
+			account.OfficialName = act.OfficialName
+			account.Mask = act.Mask
+
+			if err := store.UpdateAccount(ctx, account); err != nil {
+				return err
+			}
+
+			matched[account.ExternalAccountID] = true
+			found = true
+
+			break
+		}
+
+		if !found {
+			unmatched = append(unmatched, act)
+		}
+	}
+
+	if existing.ItemID != "" && existing.ItemID != itemID {
+		if _, err := store.DeleteItemTransactions(ctx, existing.ItemID); err != nil {
+			return err
+		}
+	}
+
+	if err := store.RelinkItem(ctx, existing.PlaidItemID, itemID, encryptedAccessToken); err != nil {
+		return err
+	}
+
+	return store.CreateAccounts(ctx, newAccounts(existing, unmatched))
+}
+
+// sameAccount ...
+// Plaid gives a relinked account a new id, so accounts are
+// matched on their mask, falling back to the name for accounts
+// without one. Accounts registered before names were split
+// were named after the official name and name together
+func sameAccount(account models.Account, act plaid.Account) bool {
+	if account.Mask != "" && act.Mask != "" {
+		return account.Mask == act.Mask
+	}
+
+	return account.AccountName == act.Name || account.AccountName == act.OfficialName+" "+act.Name
+}
+
+// newAccounts ...
+// Builds external accounts for a Plaid item's accounts
+func newAccounts(item models.PlaidItem, plaidAccounts []plaid.Account) []models.Account {
+	accounts := make([]models.Account, 0, len(plaidAccounts))
+
+	for _, act := range plaidAccounts {
+		accounts = append(accounts, models.Account{
+			InstitutionalID: act.AccountID,
+			AccountName:     act.Name,
+			OfficialName:    act.OfficialName,
+			Mask:            act.Mask,
+			UserID:          item.UserID,
+			PlaidItemID:     item.PlaidItemID,
+		})
+	}
+
+	return accounts
+}
+
+// institutionName ...
+// Looks up an institution's display name. Names are
+// cosmetic, so a failed lookup only leaves it blank
+func institutionName(institutionID string) string {
+	if institutionID == "" {
+		return ""
+	}
+
+	response, err := plaidService.PlaidClient().GetInstitutionByID(institutionID)
+
+	if err != nil {
+		logging.WarningLogger.Printf("institution %s lookup failed: %s", institutionID, err)
+		return ""
+	}
+
+	return response.Institution.Name
+}
+
+// removePlaidItem ...
+// Invalidates an item's access token with Plaid once it is
+// no longer stored. Items Plaid no longer knows or that the
+// user already revoked are fine; other failures are only
+// logged since the item is already gone locally
+func removePlaidItem(encryptedAccessToken string) {
+	_, err := plaidService.PlaidClient().RemoveItem(
+		externalAccountUtils.ConvertToAccessToken(encryptedAccessToken),
+	)
+
+	if plaidErr, ok := err.(plaid.Error); ok && ItemStatusForErrorCode(plaidErr.ErrorCode) == models.PlaidItemStatusRevoked {
+		return
+	}
+
+	if err != nil {
+		logging.WarningLogger.Println("removing plaid item failed:", err)
+	}
+}
@@ -0,0 +1,431 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// MemoryAccountStore ...
+// In-memory AccountStore for tests and local development
+type MemoryAccountStore struct {
+	mutex        sync.RWMutex
+	items        map[uuid.UUID]models.PlaidItem
+	accounts     map[uuid.UUID]models.Account
+	transactions map[string]models.Transaction
+	itemOrder    []uuid.UUID
+}
+
+// NewMemoryAccountStore ...
+// Creates an empty in-memory AccountStore
+func NewMemoryAccountStore() *MemoryAccountStore {
+	return &MemoryAccountStore{
+		items:        make(map[uuid.UUID]models.PlaidItem),
+		accounts:     make(map[uuid.UUID]models.Account),
+		transactions: make(map[string]models.Transaction),
+	}
+}
+
+// GetItem ...
+func (s *MemoryAccountStore) GetItem(ctx context.Context, plaidItemID uuid.UUID) (*models.PlaidItem, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	item, ok := s.items[plaidItemID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &item, nil
+}
+
+// GetItemByItemID ...
+func (s *MemoryAccountStore) GetItemByItemID(ctx context.Context, itemID string) (*models.PlaidItem, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, item := range s.items {
+		if itemID != "" && item.ItemID == itemID {
+			return &item, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// GetUserItems ...
+func (s *MemoryAccountStore) GetUserItems(ctx context.Context, userID uuid.UUID) ([]models.PlaidItem, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	items := make([]models.PlaidItem, 0)
+
+	for _, plaidItemID := range s.itemOrder {
+		if item, ok := s.items[plaidItemID]; ok && item.UserID == userID {
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// GetUserInstitutionItems ...
+func (s *MemoryAccountStore) GetUserInstitutionItems(ctx context.Context, userID uuid.UUID, institutionID string) ([]models.PlaidItem, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	items := make([]models.PlaidItem, 0)
+
+	for _, plaidItemID := range s.itemOrder {
+		if item, ok := s.items[plaidItemID]; ok && item.UserID == userID && item.InstitutionID == institutionID {
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// CreateItem ...
+func (s *MemoryAccountStore) CreateItem(ctx context.Context, item models.PlaidItem) (*models.PlaidItem, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if item.PlaidItemID == uuid.Nil {
+		item.PlaidItemID = uuid.New()
+	}
+
+	if item.Status == "" {
+		item.Status = models.PlaidItemStatusHealthy
+	}
+
+	s.items[item.PlaidItemID] = item
+	s.itemOrder = append(s.itemOrder, item.PlaidItemID)
+
+	return &item, nil
+}
+
+// SetItemID ...
+func (s *MemoryAccountStore) SetItemID(ctx context.Context, plaidItemID uuid.UUID, itemID string) error {
+	return s.updateItem(plaidItemID, func(item *models.PlaidItem) {
+		item.ItemID = itemID
+	})
+}
+
+// RelinkItem ...
+func (s *MemoryAccountStore) RelinkItem(ctx context.Context, plaidItemID uuid.UUID, itemID string, accessToken string) error {
+	return s.updateItem(plaidItemID, func(item *models.PlaidItem) {
+		item.ItemID = itemID
+		item.AccessToken = accessToken
+		item.Status = models.PlaidItemStatusHealthy
+		item.ErrorCode = ""
+		item.ConsentExpiresAt = nil
+		item.SyncCursor = ""
+	})
+}
+
+// SetItemStatus ...
+func (s *MemoryAccountStore) SetItemStatus(ctx context.Context, itemID string, status string, errorCode string, consentExpiresAt *time.Time) error {
+	item, err := s.GetItemByItemID(ctx, itemID)
+
+	if err != nil {
+		return err
+	}
+
+	return s.updateItem(item.PlaidItemID, func(item *models.PlaidItem) {
+		item.Status = status
+		item.ErrorCode = errorCode
+
+		if consentExpiresAt != nil {
+			item.ConsentExpiresAt = consentExpiresAt
+		}
+	})
+}
+
+// SetItemSynced ...
+func (s *MemoryAccountStore) SetItemSynced(ctx context.Context, plaidItemID uuid.UUID, cursor string) error {
+	return s.updateItem(plaidItemID, func(item *models.PlaidItem) {
+		syncedAt := time.Now()
+
+		item.SyncCursor = cursor
+		item.LastSyncedAt = &syncedAt
+
+		if item.Status != models.PlaidItemStatusPendingExpiration {
+			item.Status = models.PlaidItemStatusHealthy
+			item.ErrorCode = ""
+		}
+	})
+}
+
+// RenewItem ...
+func (s *MemoryAccountStore) RenewItem(ctx context.Context, plaidItemID uuid.UUID) error {
+	return s.updateItem(plaidItemID, func(item *models.PlaidItem) {
+		item.Status = models.PlaidItemStatusHealthy
+		item.ErrorCode = ""
+		item.ConsentExpiresAt = nil
+	})
+}
+
+// MergeItem ...
+func (s *MemoryAccountStore) MergeItem(ctx context.Context, fromPlaidItemID uuid.UUID, intoPlaidItemID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, account := range s.accounts {
+		if account.PlaidItemID == fromPlaidItemID {
+			account.PlaidItemID = intoPlaidItemID
+			s.accounts[id] = account
+		}
+	}
+
+	delete(s.items, fromPlaidItemID)
+
+	if item, ok := s.items[intoPlaidItemID]; ok {
+		item.SyncCursor = ""
+		s.items[intoPlaidItemID] = item
+	}
+
+	return nil
+}
+
+// DeleteItem ...
+// Also deletes the item's accounts and their
+// transactions like the foreign key cascades do
+func (s *MemoryAccountStore) DeleteItem(ctx context.Context, plaidItemID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.items, plaidItemID)
+
+	for id, account := range s.accounts {
+		if account.PlaidItemID == plaidItemID {
+			s.deleteAccount(id)
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryAccountStore) updateItem(plaidItemID uuid.UUID, update func(item *models.PlaidItem)) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.items[plaidItemID]
+
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	update(&item)
+	s.items[plaidItemID] = item
+
+	return nil
+}
+
+// withItemID fills in the plaid item id and status of the
+// account's item the way the postgres store's join does
+func (s *MemoryAccountStore) withItemID(account models.Account) models.Account {
+	account.ItemID = s.items[account.PlaidItemID].ItemID
+	account.ItemStatus = s.items[account.PlaidItemID].Status
+
+	return account
+}
+
+// GetAccount ...
+func (s *MemoryAccountStore) GetAccount(ctx context.Context, externalAccountID uuid.UUID) (*models.Account, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	account, ok := s.accounts[externalAccountID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	account = s.withItemID(account)
+
+	return &account, nil
+}
+
+// GetUserAccounts ...
+func (s *MemoryAccountStore) GetUserAccounts(ctx context.Context, userID uuid.UUID) ([]models.Account, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	accounts := make([]models.Account, 0)
+
+	for _, account := range s.accounts {
+		if account.UserID == userID {
+			accounts = append(accounts, s.withItemID(account))
+		}
+	}
+
+	return accounts, nil
+}
+
+// GetItemAccounts ...
+func (s *MemoryAccountStore) GetItemAccounts(ctx context.Context, plaidItemID uuid.UUID) ([]models.Account, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	accounts := make([]models.Account, 0)
+
+	for _, account := range s.accounts {
+		if account.PlaidItemID == plaidItemID {
+			accounts = append(accounts, s.withItemID(account))
+		}
+	}
+
+	return accounts, nil
+}
+
+// CreateAccounts ...
+func (s *MemoryAccountStore) CreateAccounts(ctx context.Context, accounts []models.Account) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, account := range accounts {
+		if account.ExternalAccountID == uuid.Nil {
+			account.ExternalAccountID = uuid.New()
+		}
+
+		account.ItemID = ""
+		account.ItemStatus = ""
+		s.accounts[account.ExternalAccountID] = account
+	}
+
+	return nil
+}
+
+// UpdateAccount ...
+func (s *MemoryAccountStore) UpdateAccount(ctx context.Context, account models.Account) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, ok := s.accounts[account.ExternalAccountID]
+
+	if !ok {
+		return nil
+	}
+
+	existing.InstitutionalID = account.InstitutionalID
+	existing.AccountName = account.AccountName
+	existing.OfficialName = account.OfficialName
+	existing.Mask = account.Mask
+	s.accounts[account.ExternalAccountID] = existing
+
+	return nil
+}
+
+// DeleteAccount ...
+// Also deletes the account's transactions
+// like the foreign key cascade does
+func (s *MemoryAccountStore) DeleteAccount(ctx context.Context, externalAccountID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.deleteAccount(externalAccountID)
+
+	return nil
+}
+
+func (s *MemoryAccountStore) deleteAccount(externalAccountID uuid.UUID) {
+	delete(s.accounts, externalAccountID)
+
+	for id, transaction := range s.transactions {
+		if transaction.ExternalAccountID == externalAccountID {
+			delete(s.transactions, id)
+		}
+	}
+}
+
+// UpsertTransaction ...
+func (s *MemoryAccountStore) UpsertTransaction(ctx context.Context, transaction models.Transaction) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, exists := s.transactions[transaction.ID]
+
+	s.transactions[transaction.ID] = transaction
+
+	return !exists, nil
+}
+
+// DeleteItemTransactions ...
+func (s *MemoryAccountStore) DeleteItemTransactions(ctx context.Context, itemID string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	removed := 0
+
+	for id, transaction := range s.transactions {
+		if transaction.ItemID == itemID {
+			delete(s.transactions, id)
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// DeleteAccountTransactions ...
+func (s *MemoryAccountStore) DeleteAccountTransactions(ctx context.Context, externalAccountIDs []uuid.UUID, transactionIDs []string) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	accounts := make(map[uuid.UUID]bool, len(externalAccountIDs))
+
+	for _, id := range externalAccountIDs {
+		accounts[id] = true
+	}
+
+	removed := 0
+
+	for _, id := range transactionIDs {
+		if transaction, ok := s.transactions[id]; ok && accounts[transaction.ExternalAccountID] {
+			delete(s.transactions, id)
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// GetAccountTransactions ...
+func (s *MemoryAccountStore) GetAccountTransactions(ctx context.Context, externalAccountID uuid.UUID, startDate string, endDate string) ([]models.Transaction, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	transactions := make([]models.Transaction, 0)
+
+	for _, transaction := range s.transactions {
+		if transaction.ExternalAccountID == externalAccountID && transaction.Date >= startDate && transaction.Date <= endDate {
+			transactions = append(transactions, transaction)
+		}
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].Date > transactions[j].Date
+	})
+
+	return transactions, nil
+}
+
+// GetTransaction ...
+func (s *MemoryAccountStore) GetTransaction(ctx context.Context, transactionID string) (*models.Transaction, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	transaction, ok := s.transactions[transactionID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &transaction, nil
+}
@@ -0,0 +1,482 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	"github.com/lib/pq"
+)
+
+// PostgresAccountStore ...
+// AccountStore backed by the postgres database
+type PostgresAccountStore struct{}
+
+// NewPostgresAccountStore ...
+// Creates a postgres backed AccountStore
+func NewPostgresAccountStore() *PostgresAccountStore {
+	return &PostgresAccountStore{}
+}
+
+// itemColumns are selected by every plaid item read in scan order
+const itemColumns = `plaid_item_id, COALESCE(item_id, ''), user_id, COALESCE(institution_id, ''), COALESCE(institution_name, ''),
+	access_token, status, COALESCE(error_code, ''), consent_expires_at, last_synced_at, COALESCE(sync_cursor, '')`
+
+// GetItem ...
+func (s *PostgresAccountStore) GetItem(ctx context.Context, plaidItemID uuid.UUID) (*models.PlaidItem, error) {
+	query := "SELECT " + itemColumns + " FROM plaid_items WHERE plaid_item_id = $1"
+
+	return scanItem(database.Conn(ctx).QueryRowContext(ctx, query, plaidItemID))
+}
+
+// GetItemByItemID ...
+func (s *PostgresAccountStore) GetItemByItemID(ctx context.Context, itemID string) (*models.PlaidItem, error) {
+	query := "SELECT " + itemColumns + " FROM plaid_items WHERE item_id = $1"
+
+	return scanItem(database.Conn(ctx).QueryRowContext(ctx, query, itemID))
+}
+
+// GetUserItems ...
+func (s *PostgresAccountStore) GetUserItems(ctx context.Context, userID uuid.UUID) ([]models.PlaidItem, error) {
+	query := "SELECT " + itemColumns + " FROM plaid_items WHERE user_id = $1 ORDER BY created_at"
+
+	return s.queryItems(ctx, query, userID)
+}
+
+// GetUserInstitutionItems ...
+func (s *PostgresAccountStore) GetUserInstitutionItems(ctx context.Context, userID uuid.UUID, institutionID string) ([]models.PlaidItem, error) {
+	query := "SELECT " + itemColumns + " FROM plaid_items WHERE user_id = $1 AND institution_id = $2 ORDER BY created_at"
+
+	return s.queryItems(ctx, query, userID, institutionID)
+}
+
+func (s *PostgresAccountStore) queryItems(ctx context.Context, query string, args ...interface{}) ([]models.PlaidItem, error) {
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := make([]models.PlaidItem, 0)
+
+	for rows.Next() {
+		item, scanErr := scanItem(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		items = append(items, *item)
+	}
+
+	return items, nil
+}
+
+// CreateItem ...
+func (s *PostgresAccountStore) CreateItem(ctx context.Context, item models.PlaidItem) (*models.PlaidItem, error) {
+	query := `INSERT INTO plaid_items (item_id, user_id, institution_id, institution_name, access_token, status)
+	VALUES (NULLIF($1, ''), $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6) RETURNING ` + itemColumns
+
+	if item.Status == "" {
+		item.Status = models.PlaidItemStatusHealthy
+	}
+
+	return scanItem(database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		item.ItemID,
+		item.UserID,
+		item.InstitutionID,
+		item.InstitutionName,
+		item.AccessToken,
+		item.Status,
+	))
+}
+
+// SetItemID ...
+func (s *PostgresAccountStore) SetItemID(ctx context.Context, plaidItemID uuid.UUID, itemID string) error {
+	query := "UPDATE plaid_items SET item_id = $2 WHERE plaid_item_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, plaidItemID, itemID)
+
+	return err
+}
+
+// RelinkItem ...
+func (s *PostgresAccountStore) RelinkItem(ctx context.Context, plaidItemID uuid.UUID, itemID string, accessToken string) error {
+	query := `UPDATE plaid_items SET item_id = NULLIF($2, ''), access_token = $3, status = $4, error_code = NULL,
+	consent_expires_at = NULL, sync_cursor = NULL WHERE plaid_item_id = $1`
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, plaidItemID, itemID, accessToken, models.PlaidItemStatusHealthy)
+
+	return err
+}
+
+// SetItemStatus ...
+func (s *PostgresAccountStore) SetItemStatus(ctx context.Context, itemID string, status string, errorCode string, consentExpiresAt *time.Time) error {
+	query := `UPDATE plaid_items SET status = $2, error_code = NULLIF($3, ''),
+	consent_expires_at = COALESCE($4, consent_expires_at) WHERE item_id = $1`
+
+	res, err := database.Conn(ctx).ExecContext(ctx, query, itemID, status, errorCode, consentExpiresAt)
+
+	if err != nil {
+		return err
+	}
+
+	updated, err := res.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// SetItemSynced ...
+func (s *PostgresAccountStore) SetItemSynced(ctx context.Context, plaidItemID uuid.UUID, cursor string) error {
+	query := `UPDATE plaid_items SET sync_cursor = $2, last_synced_at = current_timestamp,
+	error_code = CASE WHEN status = $3 THEN error_code ELSE NULL END,
+	status = CASE WHEN status = $3 THEN status ELSE $4 END
+	WHERE plaid_item_id = $1`
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, plaidItemID, cursor, models.PlaidItemStatusPendingExpiration, models.PlaidItemStatusHealthy)
+
+	return err
+}
+
+// MergeItem ...
+func (s *PostgresAccountStore) MergeItem(ctx context.Context, fromPlaidItemID uuid.UUID, intoPlaidItemID uuid.UUID) error {
+	conn := database.Conn(ctx)
+
+	if _, err := conn.ExecContext(ctx, "UPDATE external_accounts SET plaid_item_id = $2 WHERE plaid_item_id = $1", fromPlaidItemID, intoPlaidItemID); err != nil {
+		return err
+	}
+
+	if _, err := conn.ExecContext(ctx, "DELETE FROM plaid_items WHERE plaid_item_id = $1", fromPlaidItemID); err != nil {
+		return err
+	}
+
+	_, err := conn.ExecContext(ctx, "UPDATE plaid_items SET sync_cursor = NULL WHERE plaid_item_id = $1", intoPlaidItemID)
+
+	return err
+}
+
+// RenewItem ...
+func (s *PostgresAccountStore) RenewItem(ctx context.Context, plaidItemID uuid.UUID) error {
+	query := "UPDATE plaid_items SET status = $2, error_code = NULL, consent_expires_at = NULL WHERE plaid_item_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, plaidItemID, models.PlaidItemStatusHealthy)
+
+	return err
+}
+
+// DeleteItem ...
+func (s *PostgresAccountStore) DeleteItem(ctx context.Context, plaidItemID uuid.UUID) error {
+	query := "DELETE FROM plaid_items WHERE plaid_item_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, plaidItemID)
+
+	return err
+}
+
+func scanItem(row scanner) (*models.PlaidItem, error) {
+	var item models.PlaidItem
+
+	err := row.Scan(
+		&item.PlaidItemID,
+		&item.ItemID,
+		&item.UserID,
+		&item.InstitutionID,
+		&item.InstitutionName,
+		&item.AccessToken,
+		&item.Status,
+		&item.ErrorCode,
+		&item.ConsentExpiresAt,
+		&item.LastSyncedAt,
+		&item.SyncCursor,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// accountColumns are selected by every external account read in scan order
+const accountColumns = `e.external_account_id, e.institutional_id, e.user_id, e.account_name, COALESCE(e.official_name, ''),
+	COALESCE(e.mask, ''), e.plaid_item_id, COALESCE(p.item_id, ''), p.status
+	FROM external_accounts e JOIN plaid_items p ON p.plaid_item_id = e.plaid_item_id`
+
+// GetAccount ...
+func (s *PostgresAccountStore) GetAccount(ctx context.Context, externalAccountID uuid.UUID) (*models.Account, error) {
+	query := "SELECT " + accountColumns + " WHERE e.external_account_id = $1"
+
+	return scanAccount(database.Conn(ctx).QueryRowContext(ctx, query, externalAccountID))
+}
+
+// GetUserAccounts ...
+func (s *PostgresAccountStore) GetUserAccounts(ctx context.Context, userID uuid.UUID) ([]models.Account, error) {
+	query := "SELECT " + accountColumns + " WHERE e.user_id = $1"
+
+	return s.queryAccounts(ctx, query, userID)
+}
+
+// GetItemAccounts ...
+func (s *PostgresAccountStore) GetItemAccounts(ctx context.Context, plaidItemID uuid.UUID) ([]models.Account, error) {
+	query := "SELECT " + accountColumns + " WHERE e.plaid_item_id = $1"
+
+	return s.queryAccounts(ctx, query, plaidItemID)
+}
+
+func (s *PostgresAccountStore) queryAccounts(ctx context.Context, query string, args ...interface{}) ([]models.Account, error) {
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	accounts := make([]models.Account, 0)
+
+	for rows.Next() {
+		account, scanErr := scanAccount(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		accounts = append(accounts, *account)
+	}
+
+	return accounts, nil
+}
+
+// CreateAccounts ...
+func (s *PostgresAccountStore) CreateAccounts(ctx context.Context, accounts []models.Account) error {
+	query := `INSERT INTO external_accounts (institutional_id, account_name, official_name, mask, user_id, plaid_item_id)
+	VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6)`
+
+	return database.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, act := range accounts {
+			if _, err := database.Conn(ctx).ExecContext(ctx, query, act.InstitutionalID, act.AccountName, act.OfficialName, act.Mask, act.UserID, act.PlaidItemID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdateAccount ...
+func (s *PostgresAccountStore) UpdateAccount(ctx context.Context, account models.Account) error {
+	query := `UPDATE external_accounts SET institutional_id = $2, account_name = $3, official_name = NULLIF($4, ''),
+	mask = NULLIF($5, '') WHERE external_account_id = $1`
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, account.ExternalAccountID, account.InstitutionalID, account.AccountName, account.OfficialName, account.Mask)
+
+	return err
+}
+
+// DeleteAccount ...
+func (s *PostgresAccountStore) DeleteAccount(ctx context.Context, externalAccountID uuid.UUID) error {
+	query := "DELETE FROM external_accounts where external_account_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, externalAccountID)
+
+	return err
+}
+
+func scanAccount(row scanner) (*models.Account, error) {
+	var account models.Account
+
+	err := row.Scan(
+		&account.ExternalAccountID,
+		&account.InstitutionalID,
+		&account.UserID,
+		&account.AccountName,
+		&account.OfficialName,
+		&account.Mask,
+		&account.PlaidItemID,
+		&account.ItemID,
+		&account.ItemStatus,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+// UpsertTransaction ...
+func (s *PostgresAccountStore) UpsertTransaction(ctx context.Context, transaction models.Transaction) (bool, error) {
+	// xmax is only zero on rows this statement inserted
+	query := `INSERT INTO transactions (transaction_id, external_account_id, item_id, institutional_id, name, amount, iso_currency_code,
+	unofficial_currency_code, category, category_id, date, authorized_date, pending, pending_transaction_id, payment_channel, transaction_type, merchant)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, '')::date, $13, $14, $15, $16, NULLIF($17, ''))
+	ON CONFLICT (transaction_id) DO UPDATE SET external_account_id = EXCLUDED.external_account_id, item_id = EXCLUDED.item_id,
+	institutional_id = EXCLUDED.institutional_id, name = EXCLUDED.name, amount = EXCLUDED.amount, iso_currency_code = EXCLUDED.iso_currency_code,
+	unofficial_currency_code = EXCLUDED.unofficial_currency_code, category = EXCLUDED.category, category_id = EXCLUDED.category_id,
+	date = EXCLUDED.date, authorized_date = EXCLUDED.authorized_date, pending = EXCLUDED.pending,
+	pending_transaction_id = EXCLUDED.pending_transaction_id, payment_channel = EXCLUDED.payment_channel,
+	transaction_type = EXCLUDED.transaction_type, merchant = EXCLUDED.merchant, updated_at = current_timestamp
+	RETURNING (xmax = 0)`
+
+	// A nil slice would be written as NULL
+	category := transaction.Category
+
+	if category == nil {
+		category = []string{}
+	}
+
+	var inserted bool
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		transaction.ID,
+		transaction.ExternalAccountID,
+		transaction.ItemID,
+		transaction.AccountID,
+		transaction.Name,
+		transaction.Amount,
+		transaction.ISOCurrencyCode,
+		transaction.UnofficialCurrencyCode,
+		pq.Array(category),
+		transaction.CategoryID,
+		transaction.Date,
+		transaction.AuthorizedDate,
+		transaction.Pending,
+		transaction.PendingTransactionID,
+		transaction.PaymentChannel,
+		transaction.Type,
+		transaction.Merchant,
+	).Scan(&inserted)
+
+	return inserted, err
+}
+
+// DeleteItemTransactions ...
+func (s *PostgresAccountStore) DeleteItemTransactions(ctx context.Context, itemID string) (int, error) {
+	query := "DELETE FROM transactions WHERE item_id = $1"
+
+	res, err := database.Conn(ctx).ExecContext(ctx, query, itemID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	removed, err := res.RowsAffected()
+
+	return int(removed), err
+}
+
+// DeleteAccountTransactions ...
+func (s *PostgresAccountStore) DeleteAccountTransactions(ctx context.Context, externalAccountIDs []uuid.UUID, transactionIDs []string) (int, error) {
+	if len(externalAccountIDs) == 0 || len(transactionIDs) == 0 {
+		return 0, nil
+	}
+
+	accountIDs := make([]string, 0, len(externalAccountIDs))
+
+	for _, id := range externalAccountIDs {
+		accountIDs = append(accountIDs, id.String())
+	}
+
+	query := "DELETE FROM transactions WHERE external_account_id = ANY($1::uuid[]) AND transaction_id = ANY($2)"
+
+	res, err := database.Conn(ctx).ExecContext(ctx, query, pq.Array(accountIDs), pq.Array(transactionIDs))
+
+	if err != nil {
+		return 0, err
+	}
+
+	removed, err := res.RowsAffected()
+
+	return int(removed), err
+}
+
+// transactionColumns are selected by every transaction read in scan order
+const transactionColumns = `transaction_id, external_account_id, item_id, institutional_id, name, amount::float8,
+	COALESCE(iso_currency_code, ''), COALESCE(unofficial_currency_code, ''), category, COALESCE(category_id, ''),
+	to_char(date, 'YYYY-MM-DD'), COALESCE(to_char(authorized_date, 'YYYY-MM-DD'), ''), pending,
+	COALESCE(pending_transaction_id, ''), COALESCE(payment_channel, ''), COALESCE(transaction_type, ''), COALESCE(merchant, '')`
+
+// GetAccountTransactions ...
+func (s *PostgresAccountStore) GetAccountTransactions(ctx context.Context, externalAccountID uuid.UUID, startDate string, endDate string) ([]models.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE external_account_id = $1 AND date >= $2 AND date <= $3 ORDER BY date DESC"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, externalAccountID, startDate, endDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	transactions := make([]models.Transaction, 0)
+
+	for rows.Next() {
+		transaction, scanErr := scanTransaction(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		transactions = append(transactions, *transaction)
+	}
+
+	return transactions, nil
+}
+
+// GetTransaction ...
+func (s *PostgresAccountStore) GetTransaction(ctx context.Context, transactionID string) (*models.Transaction, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE transaction_id = $1"
+
+	return scanTransaction(database.Conn(ctx).QueryRowContext(ctx, query, transactionID))
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTransaction(row scanner) (*models.Transaction, error) {
+	var transaction models.Transaction
+
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.ExternalAccountID,
+		&transaction.ItemID,
+		&transaction.AccountID,
+		&transaction.Name,
+		&transaction.Amount,
+		&transaction.ISOCurrencyCode,
+		&transaction.UnofficialCurrencyCode,
+		pq.Array(&transaction.Category),
+		&transaction.CategoryID,
+		&transaction.Date,
+		&transaction.AuthorizedDate,
+		&transaction.Pending,
+		&transaction.PendingTransactionID,
+		&transaction.PaymentChannel,
+		&transaction.Type,
+		&transaction.Merchant,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
@@ -0,0 +1,89 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// AccountStore ...
+// Persistence operations needed by the account service
+type AccountStore interface {
+	// GetItem returns a plaid item including its
+	// encrypted access token, or sql.ErrNoRows
+	GetItem(ctx context.Context, plaidItemID uuid.UUID) (*models.PlaidItem, error)
+	// GetItemByItemID returns the plaid item with the given
+	// plaid item id, or sql.ErrNoRows
+	GetItemByItemID(ctx context.Context, itemID string) (*models.PlaidItem, error)
+	// GetUserItems returns every plaid item of a user
+	GetUserItems(ctx context.Context, userID uuid.UUID) ([]models.PlaidItem, error)
+	// GetUserInstitutionItems returns a user's plaid items
+	// at an institution, oldest first
+	GetUserInstitutionItems(ctx context.Context, userID uuid.UUID, institutionID string) ([]models.PlaidItem, error)
+	// CreateItem inserts a plaid item whose access token is
+	// already encrypted and returns it with its id set
+	CreateItem(ctx context.Context, item models.PlaidItem) (*models.PlaidItem, error)
+	// SetItemID records the plaid item id of an item
+	// registered before item ids were tracked
+	SetItemID(ctx context.Context, plaidItemID uuid.UUID, itemID string) error
+	// RelinkItem points an item at a new plaid item and access
+	// token, marks it healthy and clears its sync cursor
+	RelinkItem(ctx context.Context, plaidItemID uuid.UUID, itemID string, accessToken string) error
+	// SetItemStatus records the status reported for a plaid item,
+	// or returns sql.ErrNoRows if the item isn't registered
+	SetItemStatus(ctx context.Context, itemID string, status string, errorCode string, consentExpiresAt *time.Time) error
+	// SetItemSynced records how far an item's transactions have
+	// been synced and marks it healthy unless its consent is
+	// pending expiration, which only a renewal clears
+	SetItemSynced(ctx context.Context, plaidItemID uuid.UUID, cursor string) error
+	// RenewItem marks an item healthy after the user renewed
+	// its login, clearing its error and consent expiry
+	RenewItem(ctx context.Context, plaidItemID uuid.UUID) error
+	// MergeItem moves the accounts of an item onto another item
+	// with the same access token, then deletes it. The other
+	// item's sync cursor is cleared so the accounts it gained
+	// get their history on its next sync
+	MergeItem(ctx context.Context, fromPlaidItemID uuid.UUID, intoPlaidItemID uuid.UUID) error
+	// DeleteItem deletes a plaid item and its accounts
+	DeleteItem(ctx context.Context, plaidItemID uuid.UUID) error
+
+	// GetAccount returns the external account with
+	// the given id, or sql.ErrNoRows
+	GetAccount(ctx context.Context, externalAccountID uuid.UUID) (*models.Account, error)
+	// GetUserAccounts returns every external account of a user
+	GetUserAccounts(ctx context.Context, userID uuid.UUID) ([]models.Account, error)
+	// GetItemAccounts returns every external account of a plaid item
+	GetItemAccounts(ctx context.Context, plaidItemID uuid.UUID) ([]models.Account, error)
+	// CreateAccounts inserts external accounts
+	CreateAccounts(ctx context.Context, accounts []models.Account) error
+	// UpdateAccount updates the plaid account an external
+	// account maps to along with its names and mask
+	UpdateAccount(ctx context.Context, account models.Account) error
+	// DeleteAccount deletes an external account
+	DeleteAccount(ctx context.Context, externalAccountID uuid.UUID) error
+
+	// UpsertTransaction inserts or updates a transaction and
+	// reports whether it was newly inserted
+	UpsertTransaction(ctx context.Context, transaction models.Transaction) (bool, error)
+	// DeleteItemTransactions deletes every transaction of a plaid item
+	DeleteItemTransactions(ctx context.Context, itemID string) (int, error)
+	// DeleteAccountTransactions deletes the transactions with the given
+	// ids that belong to one of the given external accounts
+	DeleteAccountTransactions(ctx context.Context, externalAccountIDs []uuid.UUID, transactionIDs []string) (int, error)
+	// GetAccountTransactions returns an account's transactions dated
+	// within [startDate, endDate], newest first
+	GetAccountTransactions(ctx context.Context, externalAccountID uuid.UUID, startDate string, endDate string) ([]models.Transaction, error)
+	// GetTransaction returns a transaction or sql.ErrNoRows
+	GetTransaction(ctx context.Context, transactionID string) (*models.Transaction, error)
+}
+
+var store AccountStore
+
+// SetStore ...
+// Sets the store used by the account service.
+// Called once at startup
+func SetStore(s AccountStore) {
+	store = s
+}
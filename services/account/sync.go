@@ -0,0 +1,434 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	merchantService "github.com/lakshay35/finlit-backend/services/merchant"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	externalAccountUtils "github.com/lakshay35/finlit-backend/utils/external_account"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+const (
+	// syncPageSize is the most changes Plaid returns per call
+	syncPageSize = 500
+
+	// syncRestarts is how many times paging through an item's
+	// changes starts over when they change mid-way
+	syncRestarts = 3
+
+	// mutationDuringPagination is the error Plaid returns when
+	// an item's transactions change while paging through them
+	mutationDuringPagination = "TRANSACTIONS_SYNC_MUTATION_DURING_PAGINATION"
+)
+
+// TransactionsSyncedListener ...
+// Told which external accounts got new or changed
+// transactions once the sync that changed them commits
+type TransactionsSyncedListener func(externalAccountIDs []uuid.UUID)
+
+var (
+	syncListenersMutex sync.RWMutex
+	syncListeners      []TransactionsSyncedListener
+)
+
+// OnTransactionsSynced ...
+// Adds a listener for syncs that add or change transactions.
+// Listeners run on the syncing goroutine, so anything slow
+// should be handed off
+func OnTransactionsSynced(listener TransactionsSyncedListener) {
+	syncListenersMutex.Lock()
+	defer syncListenersMutex.Unlock()
+
+	syncListeners = append(syncListeners, listener)
+}
+
+// SyncUserTransactions ...
+// Pulls new, modified and removed transactions for
+// every Plaid item a user has registered into the
+// local transaction store. Revoked items are skipped.
+// Each item's changes and cursor commit on their own,
+// outside the request's unit of work, and items that
+// fail are reported with a message instead of failing
+// the sync of the others
+func SyncUserTransactions(ctx context.Context, userID uuid.UUID) ([]models.TransactionSyncResult, *errors.Error) {
+	ctx = database.WithoutUnitOfWork(ctx)
+
+	items, err := store.GetUserItems(ctx, userID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	results := make([]models.TransactionSyncResult, 0)
+	synced := make(map[string]int)
+
+	for _, item := range items {
+		// Items merged into one synced earlier are covered by it
+		if _, ok := synced[item.ItemID]; ok || item.Status == models.PlaidItemStatusRevoked {
+			continue
+		}
+
+		result, syncErr := syncItem(ctx, item)
+
+		if syncErr != nil {
+			logging.WarningLogger.Printf("transactions not synced for item %s: %s", item.PlaidItemID, syncErr.Message)
+
+			result = &models.TransactionSyncResult{
+				PlaidItemID:     item.PlaidItemID,
+				ItemID:          item.ItemID,
+				InstitutionName: item.InstitutionName,
+				Message:         syncErr.Message,
+			}
+		}
+
+		// Syncing marks items healthy and itemError records
+		// broken logins, so report the status the item is left in
+		result.ItemStatus = item.Status
+
+		if refreshed, getErr := store.GetItem(ctx, result.PlaidItemID); getErr == nil {
+			result.ItemStatus = refreshed.Status
+		}
+
+		if syncErr != nil {
+			results = append(results, *result)
+			continue
+		}
+
+		// A legacy item merged into one synced earlier syncs it again
+		if i, ok := synced[result.ItemID]; ok {
+			results[i].Added += result.Added
+			results[i].Modified += result.Modified
+			results[i].Removed += result.Removed
+
+			continue
+		}
+
+		synced[result.ItemID] = len(results)
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+// SyncItemTransactions ...
+// Pulls new, modified and removed transactions
+// for a single Plaid item
+func SyncItemTransactions(ctx context.Context, itemID string) (*models.TransactionSyncResult, *errors.Error) {
+	ctx = database.WithoutUnitOfWork(ctx)
+
+	item, err := store.GetItemByItemID(ctx, itemID)
+
+	if err == sql.ErrNoRows {
+		return nil, &errors.Error{
+			Message:    "No plaid item registered with id " + itemID,
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return syncItem(ctx, *item)
+}
+
+// syncItem ...
+// Syncs the transactions of a single Plaid item. The item's
+// cursor is the one /transactions/sync last handed back, so
+// each sync applies just what was added, modified or removed
+// since. Removals only touch the item's own accounts.
+// ctx must not carry a unit of work, since one would be
+// held open during the calls to Plaid
+func syncItem(ctx context.Context, item models.PlaidItem) (*models.TransactionSyncResult, *errors.Error) {
+	accessToken := externalAccountUtils.ConvertToAccessToken(item.AccessToken)
+
+	if item.ItemID == "" {
+		adopted, adoptErr := adoptItemID(ctx, item, accessToken)
+
+		if adoptErr != nil {
+			return nil, adoptErr
+		}
+
+		item = *adopted
+	}
+
+	accounts, err := store.GetItemAccounts(ctx, item.PlaidItemID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	// Everything is fetched before the changes are
+	// written, so their transaction only spans the writes
+	changes, fetchErr := fetchTransactionChanges(accessToken, item.SyncCursor)
+
+	if fetchErr != nil {
+		return nil, itemError(ctx, item, fetchErr)
+	}
+
+	externalAccountIDs := make(map[string]uuid.UUID, len(accounts))
+	itemAccountIDs := make([]uuid.UUID, 0, len(accounts))
+
+	for _, act := range accounts {
+		externalAccountIDs[act.InstitutionalID] = act.ExternalAccountID
+		itemAccountIDs = append(itemAccountIDs, act.ExternalAccountID)
+	}
+
+	result := models.TransactionSyncResult{
+		PlaidItemID:     item.PlaidItemID,
+		ItemID:          item.ItemID,
+		InstitutionName: item.InstitutionName,
+	}
+	changedAccounts := make([]uuid.UUID, 0)
+	changed := make(map[uuid.UUID]bool)
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, tx := range changes.upserted {
+			externalAccountID, registered := externalAccountIDs[tx.AccountID]
+
+			// Accounts of the item the user chose not to register
+			if !registered {
+				continue
+			}
+
+			inserted, err := store.UpsertTransaction(ctx, models.Transaction{
+				Transaction:       tx,
+				ExternalAccountID: externalAccountID,
+				ItemID:            item.ItemID,
+				Merchant:          merchantService.Normalize(tx.Name),
+			})
+
+			if err != nil {
+				return err
+			}
+
+			if inserted {
+				result.Added++
+			} else {
+				result.Modified++
+			}
+
+			if !changed[externalAccountID] {
+				changed[externalAccountID] = true
+				changedAccounts = append(changedAccounts, externalAccountID)
+			}
+		}
+
+		removedIDs := changes.removed
+
+		// Without a cursor Plaid sends everything it has, so
+		// whatever it left out of that is gone
+		if item.SyncCursor == "" {
+			stale, err := staleTransactionIDs(ctx, itemAccountIDs, changes.upserted)
+
+			if err != nil {
+				return err
+			}
+
+			removedIDs = append(removedIDs, stale...)
+		}
+
+		removed, err := store.DeleteAccountTransactions(ctx, itemAccountIDs, removedIDs)
+
+		if err != nil {
+			return err
+		}
+
+		result.Removed = removed
+
+		return store.SetItemSynced(ctx, item.PlaidItemID, changes.cursor)
+	})
+
+	if txErr != nil {
+		return nil, &errors.Error{
+			Message:    txErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if result.Added > 0 || result.Modified > 0 {
+		database.AfterCommit(ctx, func() {
+			notifyTransactionsSynced(changedAccounts)
+		})
+	}
+
+	return &result, nil
+}
+
+// staleTransactionIDs ...
+// The stored transactions of the accounts that aren't among
+// the full set of transactions Plaid returned for them
+func staleTransactionIDs(ctx context.Context, externalAccountIDs []uuid.UUID, current []plaid.Transaction) ([]string, error) {
+	kept := make(map[string]bool, len(current))
+
+	for _, tx := range current {
+		kept[tx.ID] = true
+	}
+
+	stale := make([]string, 0)
+
+	for _, externalAccountID := range externalAccountIDs {
+		stored, err := store.GetAccountTransactions(ctx, externalAccountID, "0001-01-01", "9999-12-31")
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range stored {
+			if !kept[tx.ID] {
+				stale = append(stale, tx.ID)
+			}
+		}
+	}
+
+	return stale, nil
+}
+
+// adoptItemID ...
+// Looks up the Plaid item id of an item registered before
+// item ids were tracked. Accounts linked with the same
+// access token were each given an item of their own back
+// then, so when another of the user's items already has
+// the id this one is merged into it
+func adoptItemID(ctx context.Context, item models.PlaidItem, accessToken string) (*models.PlaidItem, *errors.Error) {
+	response, err := plaidService.PlaidClient().GetItem(accessToken)
+
+	if err != nil {
+		return nil, itemError(ctx, item, err)
+	}
+
+	var adopted *models.PlaidItem
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		existing, err := store.GetItemByItemID(ctx, response.Item.ItemID)
+
+		if err == sql.ErrNoRows {
+			item.ItemID = response.Item.ItemID
+			adopted = &item
+
+			return store.SetItemID(ctx, item.PlaidItemID, item.ItemID)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if existing.UserID != item.UserID {
+			return fmt.Errorf("plaid item %s is registered to another user", response.Item.ItemID)
+		}
+
+		adopted = existing
+		adopted.SyncCursor = ""
+
+		return store.MergeItem(ctx, item.PlaidItemID, existing.PlaidItemID)
+	})
+
+	if txErr != nil {
+		return nil, &errors.Error{
+			Message:    txErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return adopted, nil
+}
+
+// notifyTransactionsSynced ...
+// Tells every listener which accounts a sync changed
+func notifyTransactionsSynced(externalAccountIDs []uuid.UUID) {
+	syncListenersMutex.RLock()
+	listeners := make([]TransactionsSyncedListener, len(syncListeners))
+	copy(listeners, syncListeners)
+	syncListenersMutex.RUnlock()
+
+	for _, listener := range listeners {
+		listener(externalAccountIDs)
+	}
+}
+
+// transactionChanges ...
+// What changed in an item's transactions since a cursor
+// and the cursor to pick up from next time
+type transactionChanges struct {
+	upserted []plaid.Transaction
+	removed  []string
+	cursor   string
+}
+
+// fetchTransactionChanges ...
+// Pages through the changes to an item's transactions since
+// cursor. Paging starts over from cursor when Plaid reports
+// the transactions changed before the last page was read
+func fetchTransactionChanges(accessToken string, cursor string) (*transactionChanges, error) {
+	restarts := 0
+
+paging:
+	for {
+		changes := &transactionChanges{
+			upserted: make([]plaid.Transaction, 0),
+			removed:  make([]string, 0),
+			cursor:   cursor,
+		}
+
+		for {
+			response, err := plaidService.PlaidClient().SyncTransactions(accessToken, changes.cursor, syncPageSize)
+
+			if plaidErr, ok := err.(plaid.Error); ok && plaidErr.ErrorCode == mutationDuringPagination && restarts < syncRestarts {
+				restarts++
+				continue paging
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			changes.upserted = append(changes.upserted, response.Added...)
+			changes.upserted = append(changes.upserted, response.Modified...)
+
+			for _, removed := range response.Removed {
+				changes.removed = append(changes.removed, removed.TransactionID)
+			}
+
+			changes.cursor = response.NextCursor
+
+			if !response.HasMore {
+				return changes, nil
+			}
+		}
+	}
+}
+
+// plaidError ...
+// Surfaces Plaid's own status code when there is one
+func plaidError(err error) *errors.Error {
+	if plaidErr, ok := err.(plaid.Error); ok && plaidErr.StatusCode != 0 {
+		return &errors.Error{
+			Message:    plaidErr.Error(),
+			StatusCode: plaidErr.StatusCode,
+		}
+	}
+
+	return &errors.Error{
+		Message:    err.Error(),
+		StatusCode: http.StatusBadGateway,
+	}
+}
@@ -0,0 +1,285 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	"github.com/lakshay35/finlit-backend/utils/encryption"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// useFakes ...
+// Points the account service at an empty in-memory store
+// and a FakeClient seeded with ins_fake_bank
+func useFakes() *plaidService.FakeClient {
+	fake := plaidService.NewSeededFakeClient()
+
+	SetStore(NewMemoryAccountStore())
+	plaidService.SetClient(fake)
+
+	return fake
+}
+
+func encrypt(accessToken string) string {
+	return encryption.EncodeBase64(string(encryption.Encrypt([]byte(accessToken))))
+}
+
+func storedTransactionIDs(t *testing.T, ctx context.Context, userID uuid.UUID) map[string]bool {
+	accounts, err := store.GetUserAccounts(ctx, userID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make(map[string]bool)
+
+	for _, act := range accounts {
+		transactions, err := store.GetAccountTransactions(ctx, act.ExternalAccountID, "0001-01-01", "9999-12-31")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tx := range transactions {
+			ids[tx.ID] = true
+		}
+	}
+
+	return ids
+}
+
+func TestSyncAppliesChangesSinceCursor(t *testing.T) {
+	ctx := context.Background()
+	fake := useFakes()
+	userID := uuid.New()
+
+	if err := RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), userID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	items, _ := store.GetUserItems(ctx, userID)
+
+	if len(items) != 1 || items[0].SyncCursor == "" {
+		t.Fatalf("items = %+v, want one item with a sync cursor", items)
+	}
+
+	itemID := items[0].ItemID
+
+	if ids := storedTransactionIDs(t, ctx, userID); len(ids) != 11 {
+		t.Fatalf("initial sync stored %d transactions, want 11", len(ids))
+	}
+
+	fake.ModifyTransaction("ins_fake_bank", plaid.Transaction{ID: "groceries-3", AccountID: "credit", Name: "Green Grocer", Amount: 25.10, Date: "2021-03-01"})
+	fake.RemoveTransaction("ins_fake_bank", "coffee-2")
+	fake.AddTransactions("ins_fake_bank", plaid.Transaction{ID: "bakery", AccountID: "credit", Name: "Corner Bakery", Amount: 6.5, Date: "2021-03-02"})
+
+	results, syncErr := SyncUserTransactions(ctx, userID)
+
+	if syncErr != nil {
+		t.Fatal(syncErr.Message)
+	}
+
+	want := models.TransactionSyncResult{
+		PlaidItemID:     items[0].PlaidItemID,
+		ItemID:          itemID,
+		InstitutionName: items[0].InstitutionName,
+		ItemStatus:      models.PlaidItemStatusHealthy,
+		Added:           1,
+		Modified:        1,
+		Removed:         1,
+	}
+
+	if len(results) != 1 || results[0] != want {
+		t.Fatalf("results = %+v, want %+v", results, want)
+	}
+
+	ids := storedTransactionIDs(t, ctx, userID)
+
+	if ids[itemID+"-coffee-2"] || !ids[itemID+"-bakery"] || len(ids) != 11 {
+		t.Errorf("stored transactions = %v, want coffee-2 removed and bakery added", ids)
+	}
+
+	modified, err := store.GetTransaction(ctx, itemID+"-groceries-3")
+
+	if err != nil || modified.Amount != 25.10 {
+		t.Errorf("groceries-3 = %+v (%v), want the modified amount", modified, err)
+	}
+}
+
+func TestSyncMergesLegacyItemsSharingAnAccessToken(t *testing.T) {
+	ctx := context.Background()
+	fake := useFakes()
+	userID := uuid.New()
+
+	exchanged, err := fake.ExchangePublicToken(plaidService.FakePublicToken("ins_fake_bank"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Legacy accounts each had their own copy of the access token,
+	// encrypted with its own nonce, so each got an item of its own
+	for _, accountID := range []string{"checking", "credit"} {
+		item, createErr := store.CreateItem(ctx, models.PlaidItem{
+			UserID:        userID,
+			InstitutionID: "ins_fake_bank",
+			AccessToken:   encrypt(exchanged.AccessToken),
+		})
+
+		if createErr != nil {
+			t.Fatal(createErr)
+		}
+
+		if createErr = store.CreateAccounts(ctx, []models.Account{{
+			InstitutionalID: exchanged.ItemID + "-" + accountID,
+			AccountName:     accountID,
+			UserID:          userID,
+			PlaidItemID:     item.PlaidItemID,
+		}}); createErr != nil {
+			t.Fatal(createErr)
+		}
+	}
+
+	results, syncErr := SyncUserTransactions(ctx, userID)
+
+	if syncErr != nil {
+		t.Fatal(syncErr.Message)
+	}
+
+	if len(results) != 1 || results[0].ItemID != exchanged.ItemID {
+		t.Errorf("results = %+v, want a single sync of %s", results, exchanged.ItemID)
+	}
+
+	items, _ := store.GetUserItems(ctx, userID)
+
+	if len(items) != 1 || items[0].ItemID != exchanged.ItemID {
+		t.Fatalf("items = %+v, want the two legacy items merged into one", items)
+	}
+
+	accounts, _ := store.GetItemAccounts(ctx, items[0].PlaidItemID)
+
+	if len(accounts) != 2 {
+		t.Errorf("merged item has %d accounts, want 2", len(accounts))
+	}
+
+	// Syncing one account must not delete the other's transactions
+	if ids := storedTransactionIDs(t, ctx, userID); len(ids) != 11 {
+		t.Errorf("stored %d transactions, want all 11 of both accounts", len(ids))
+	}
+}
+
+func TestSyncUserTransactionsReportsFailedItems(t *testing.T) {
+	ctx := context.Background()
+	fake := useFakes()
+	userID := uuid.New()
+
+	fake.AddInstitution(plaidService.FakeInstitution{
+		InstitutionID: "ins_other_bank",
+		Name:          "Other Bank",
+		Accounts: []plaid.Account{
+			{AccountID: "savings", Name: "Savings"},
+		},
+	})
+
+	for _, institutionID := range []string{"ins_fake_bank", "ins_other_bank"} {
+		if err := RegisterAccessToken(ctx, plaidService.FakePublicToken(institutionID), userID); err != nil {
+			t.Fatal(err.Message)
+		}
+	}
+
+	// The item synced first fails, the one after it must still sync
+	items, _ := store.GetUserItems(ctx, userID)
+	broken, healthy := items[0], items[1]
+
+	fake.SetItemError(broken.ItemID, "ITEM_LOGIN_REQUIRED")
+	fake.AddTransactions("ins_fake_bank", plaid.Transaction{ID: "bakery", AccountID: "credit", Name: "Corner Bakery", Amount: 6.5, Date: "2021-03-02"})
+	fake.AddTransactions("ins_other_bank", plaid.Transaction{ID: "interest", AccountID: "savings", Name: "Interest", Amount: -1.2, Date: "2021-03-02"})
+
+	results, syncErr := SyncUserTransactions(ctx, userID)
+
+	if syncErr != nil {
+		t.Fatalf("SyncUserTransactions() = %s, want failed items reported", syncErr.Message)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("results = %+v, want one per item", results)
+	}
+
+	for _, result := range results {
+		switch result.PlaidItemID {
+		case broken.PlaidItemID:
+			if result.Message == "" || result.Added != 0 || result.ItemStatus != models.PlaidItemStatusLoginRequired {
+				t.Errorf("broken item = %+v, want a message and %s", result, models.PlaidItemStatusLoginRequired)
+			}
+		case healthy.PlaidItemID:
+			if result.Message != "" || result.Added != 1 || result.ItemStatus != models.PlaidItemStatusHealthy {
+				t.Errorf("healthy item = %+v, want its new transaction", result)
+			}
+		default:
+			t.Errorf("result for unknown item %+v", result)
+		}
+	}
+
+	// The healthy item's cursor moved on, the broken one's stayed
+	for _, item := range []models.PlaidItem{broken, healthy} {
+		stored, err := store.GetItem(ctx, item.PlaidItemID)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if moved := stored.SyncCursor != item.SyncCursor; moved != (item.PlaidItemID == healthy.PlaidItemID) {
+			t.Errorf("item %s cursor moved = %v", item.InstitutionID, moved)
+		}
+	}
+
+	// Once the login is repaired the broken item catches up
+	fake.SetItemError(broken.ItemID, "")
+
+	results, syncErr = SyncUserTransactions(ctx, userID)
+
+	if syncErr != nil {
+		t.Fatal(syncErr.Message)
+	}
+
+	for _, result := range results {
+		if result.PlaidItemID == broken.PlaidItemID && (result.Added != 1 || result.ItemStatus != models.PlaidItemStatusHealthy) {
+			t.Errorf("repaired item = %+v, want the transaction it missed", result)
+		}
+	}
+}
+
+func TestSyncOnlyRemovesTransactionsOfTheItemsAccounts(t *testing.T) {
+	ctx := context.Background()
+	useFakes()
+	userID := uuid.New()
+
+	if err := RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), userID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	items, _ := store.GetUserItems(ctx, userID)
+	accounts, _ := store.GetItemAccounts(ctx, items[0].PlaidItemID)
+	other := uuid.New()
+
+	// A transaction of another account with an id Plaid removed
+	if _, err := store.UpsertTransaction(ctx, models.Transaction{
+		Transaction:       plaid.Transaction{ID: "shared-id", Date: "2021-03-01"},
+		ExternalAccountID: other,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := store.DeleteAccountTransactions(ctx, []uuid.UUID{accounts[0].ExternalAccountID, accounts[1].ExternalAccountID}, []string{"shared-id"})
+
+	if err != nil || removed != 0 {
+		t.Fatalf("removed %d (%v), want the other account's transaction left alone", removed, err)
+	}
+
+	if _, err := store.GetTransaction(ctx, "shared-id"); err != nil {
+		t.Errorf("transaction of another account was deleted: %v", err)
+	}
+}
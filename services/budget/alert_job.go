@@ -0,0 +1,93 @@
+package budget
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+)
+
+// alertQueue ...
+// External accounts with new or changed transactions whose
+// budgets are waiting for their alert rules to be evaluated
+var alertQueue = struct {
+	mutex   sync.Mutex
+	pending map[uuid.UUID]bool
+	wake    chan struct{}
+}{
+	pending: make(map[uuid.UUID]bool),
+	wake:    make(chan struct{}, 1),
+}
+
+// QueueAlertEvaluation ...
+// Queues the budgets using any of the given external accounts
+// for their alert rules to be evaluated. Meant to listen for
+// transaction syncs, so it only queues and returns
+func QueueAlertEvaluation(externalAccountIDs []uuid.UUID) {
+	alertQueue.mutex.Lock()
+
+	for _, externalAccountID := range externalAccountIDs {
+		alertQueue.pending[externalAccountID] = true
+	}
+
+	alertQueue.mutex.Unlock()
+
+	select {
+	case alertQueue.wake <- struct{}{}:
+	default:
+	}
+}
+
+// StartAlertEvaluator ...
+// Evaluates the alert rules of queued budgets in the
+// background until ctx is done
+func StartAlertEvaluator(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-alertQueue.wake:
+				evaluateQueuedAlerts(ctx)
+			}
+		}
+	}()
+}
+
+// evaluateQueuedAlerts ...
+// Evaluates every budget using a queued account once
+func evaluateQueuedAlerts(ctx context.Context) {
+	alertQueue.mutex.Lock()
+	externalAccountIDs := make([]uuid.UUID, 0, len(alertQueue.pending))
+
+	for externalAccountID := range alertQueue.pending {
+		externalAccountIDs = append(externalAccountIDs, externalAccountID)
+	}
+
+	alertQueue.pending = make(map[uuid.UUID]bool)
+	alertQueue.mutex.Unlock()
+
+	evaluated := make(map[uuid.UUID]bool)
+
+	for _, externalAccountID := range externalAccountIDs {
+		budgets, err := store.GetAccountSourceBudgets(ctx, externalAccountID)
+
+		if err != nil {
+			logging.ErrorLogger.Println(err)
+			continue
+		}
+
+		for _, budget := range budgets {
+			if evaluated[budget.BudgetID] {
+				continue
+			}
+
+			evaluated[budget.BudgetID] = true
+
+			if evalErr := EvaluateBudgetAlerts(ctx, budget); evalErr != nil {
+				logging.ErrorLogger.Printf("budget %s alerts: %s", budget.BudgetID, evalErr.Message)
+			}
+		}
+	}
+}
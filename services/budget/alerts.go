@@ -0,0 +1,474 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	notifierService "github.com/lakshay35/finlit-backend/services/notifier"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	userService "github.com/lakshay35/finlit-backend/services/user"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+)
+
+// alertLookbackDays is how far back transaction_over rules look at
+// transactions, so ones that post late still alert once they arrive
+const alertLookbackDays = 30
+
+// alert ...
+// An alert a rule fires, keyed so it only goes out once
+type alert struct {
+	rule    models.AlertRule
+	key     string
+	title   string
+	message string
+}
+
+// GetAlertRules ...
+// Gets the alert rules of a budget
+func GetAlertRules(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.AlertRule, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	rules, err := store.GetAlertRules(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return rules, nil
+}
+
+// CreateAlertRule ...
+// Creates an alert rule notifying the user creating it.
+// Thresholds are kept sorted and without repeats
+func CreateAlertRule(ctx context.Context, rule models.AlertRule, userID uuid.UUID) (*models.AlertRule, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, rule.BudgetID, userID) && !roleService.IsUserOwner(ctx, rule.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to create alert rules for the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	rule.UserID = userID
+
+	if validationErr := validateAlertRule(ctx, &rule); validationErr != nil {
+		return nil, validationErr
+	}
+
+	created, err := store.CreateAlertRule(ctx, rule)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return created, nil
+}
+
+// DeleteAlertRule ...
+// Deletes an alert rule
+func DeleteAlertRule(ctx context.Context, alertRuleID uuid.UUID, userID uuid.UUID) *errors.Error {
+	rule, err := store.GetAlertRule(ctx, alertRuleID)
+
+	if err == sql.ErrNoRows {
+		return &errors.Error{
+			Message:    "Alert rule not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if !roleService.IsUserAdmin(ctx, rule.BudgetID, userID) && !roleService.IsUserOwner(ctx, rule.BudgetID, userID) {
+		return &errors.Error{
+			Message:    "You are not authorized to change alert rules of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if deleteErr := store.DeleteAlertRule(ctx, alertRuleID); deleteErr != nil {
+		return &errors.Error{
+			Message:    deleteErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// validateAlertRule ...
+// Checks a rule's kind, limits, expense and channel
+func validateAlertRule(ctx context.Context, rule *models.AlertRule) *errors.Error {
+	switch rule.Kind {
+	case models.AlertKindThreshold:
+		if len(rule.Thresholds) == 0 || rule.Amount != 0 {
+			return &errors.Error{
+				Message:    "Threshold alert rules need thresholds and no amount",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+
+		sort.Ints(rule.Thresholds)
+		thresholds := make([]int, 0, len(rule.Thresholds))
+
+		for _, threshold := range rule.Thresholds {
+			if threshold < 1 {
+				return &errors.Error{
+					Message:    "Thresholds are percentages of an expense's value and must be at least 1",
+					StatusCode: http.StatusBadRequest,
+				}
+			}
+
+			if len(thresholds) == 0 || thresholds[len(thresholds)-1] != threshold {
+				thresholds = append(thresholds, threshold)
+			}
+		}
+
+		rule.Thresholds = thresholds
+	case models.AlertKindTransactionOver:
+		if rule.Amount <= 0 || len(rule.Thresholds) != 0 {
+			return &errors.Error{
+				Message:    "Transaction alert rules need an amount over 0 and no thresholds",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	default:
+		return &errors.Error{
+			Message:    "kind must be " + models.AlertKindThreshold + " or " + models.AlertKindTransactionOver,
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if rule.ExpenseID != nil {
+		expense, err := expenseService.GetExpense(ctx, *rule.ExpenseID)
+
+		if err != nil || expense.BudgetID != rule.BudgetID {
+			return &errors.Error{
+				Message:    "Expense " + rule.ExpenseID.String() + " is not part of the budget",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+
+	if !notifierService.IsChannel(rule.Channel) {
+		return &errors.Error{
+			Message:    "channel must be " + models.NotificationChannelEmail + ", " + models.NotificationChannelWebhook + " or " + models.NotificationChannelInApp,
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if rule.Channel != models.NotificationChannelWebhook {
+		if rule.WebhookURL != "" {
+			return &errors.Error{
+				Message:    "webhook_url can only be set for the " + models.NotificationChannelWebhook + " channel",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+
+		return nil
+	}
+
+	if err := notifierService.ValidateWebhookURL(rule.WebhookURL); err != nil {
+		return &errors.Error{
+			Message:    "webhook_url is not allowed: " + err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return nil
+}
+
+// EvaluateBudgetAlerts ...
+// Fires the alerts of a budget's rules that haven't fired yet.
+// Threshold rules fire once per threshold and period of each
+// expense they watch and transaction_over rules once per
+// transaction. Rules of users who lost access to the budget
+// are skipped. While sources are skipped spending can only be
+// higher than counted, so threshold alerts still fire but say
+// their figures are provisional
+func EvaluateBudgetAlerts(ctx context.Context, budget models.Budget) *errors.Error {
+	rules, err := store.GetAlertRules(ctx, budget.BudgetID)
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	active := make([]models.AlertRule, 0, len(rules))
+
+	for _, rule := range rules {
+		if roleService.IsUserAdmin(ctx, budget.BudgetID, rule.UserID) || roleService.IsUserOwner(ctx, budget.BudgetID, rule.UserID) {
+			active = append(active, rule)
+		}
+	}
+
+	if len(active) == 0 {
+		return nil
+	}
+
+	expenses, expensesErr := expenseService.GetBudgetExpenses(ctx, budget.BudgetID)
+
+	if expensesErr != nil {
+		return expensesErr
+	}
+
+	now := time.Now().Local()
+	periods := make(map[uuid.UUID]expensePeriod, len(expenses))
+
+	for _, expense := range expenses {
+		start, end := expenseService.ChargeCyclePeriod(expense.ExpenseChargeCycle, now)
+
+		periods[expense.ExpenseID] = expensePeriod{
+			start: start,
+			end:   end,
+			limit: float64(expense.ExpenseValue),
+		}
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	window := expensePeriod{start: today.AddDate(0, 0, -alertLookbackDays), end: today.AddDate(0, 0, 1)}
+	startDate, endDate := periodDates(periods, window)
+
+	sources, sourcesErr := GetBudgetTransactionSources(ctx, budget.BudgetID)
+
+	if sourcesErr != nil {
+		return sourcesErr
+	}
+
+	transactions, sourceIssues := budgetTransactions(ctx, sources, startDate, endDate)
+	skipped := 0
+
+	for _, issue := range sourceIssues {
+		if issue.Skipped {
+			skipped++
+		}
+	}
+
+	categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, budget.BudgetID)
+
+	if categoriesErr != nil {
+		return categoriesErr
+	}
+
+	categorizer, categorizerErr := newCategorizer(ctx, budget.BudgetID)
+
+	if categorizerErr != nil {
+		return categorizerErr
+	}
+
+	mappings, mappingsErr := expenseService.GetBudgetExpenseTransactionCategoryMappings(ctx, budget.BudgetID)
+
+	if mappingsErr != nil {
+		return mappingsErr
+	}
+
+	spending := spendingByCategory(transactions, categorizer, categories)
+	expenseCategories := expenseCategoryNames(newCategoryTree(categories), mappings)
+
+	alerts := make([]alert, 0)
+
+	for _, rule := range active {
+		watched := expenses
+
+		if rule.ExpenseID != nil {
+			watched = make([]models.Expense, 0, 1)
+
+			for _, expense := range expenses {
+				if expense.ExpenseID == *rule.ExpenseID {
+					watched = append(watched, expense)
+				}
+			}
+		}
+
+		switch rule.Kind {
+		case models.AlertKindThreshold:
+			alerts = append(alerts, thresholdAlerts(rule, budget, watched, periods, spending, expenseCategories, skipped)...)
+		case models.AlertKindTransactionOver:
+			ruleTransactions := transactions
+
+			if rule.ExpenseID != nil {
+				ruleTransactions = make([]models.Transaction, 0)
+
+				for _, expense := range watched {
+					for _, category := range expenseCategories[expense.ExpenseID] {
+						ruleTransactions = append(ruleTransactions, spending[category].Transactions...)
+					}
+				}
+			}
+
+			alerts = append(alerts, transactionAlerts(rule, budget, ruleTransactions, window)...)
+		}
+	}
+
+	for _, fired := range alerts {
+		sendAlert(ctx, fired)
+	}
+
+	return nil
+}
+
+// thresholdAlerts ...
+// The alerts of a threshold rule for every threshold the
+// current period of each of its expenses has reached. With
+// skipped sources the spending reported is a lower bound
+func thresholdAlerts(
+	rule models.AlertRule,
+	budget models.Budget,
+	expenses []models.Expense,
+	periods map[uuid.UUID]expensePeriod,
+	spending map[string]models.ExpenseCategorySummary,
+	expenseCategories map[uuid.UUID][]string,
+	skipped int,
+) []alert {
+	alerts := make([]alert, 0)
+	provisional := ""
+
+	if skipped > 0 {
+		provisional = fmt.Sprintf(". This is provisional: %d of the budget's transaction sources can't be read, so spending may be higher", skipped)
+	}
+
+	for _, expense := range expenses {
+		period := periods[expense.ExpenseID]
+
+		if period.limit <= 0 {
+			continue
+		}
+
+		actual := periodSpending(spending, expenseCategories[expense.ExpenseID], period)
+		start := period.start.Format("2006-01-02")
+
+		for _, threshold := range rule.Thresholds {
+			if actual < period.limit*float64(threshold)/100 {
+				break
+			}
+
+			alerts = append(alerts, alert{
+				rule:  rule,
+				key:   fmt.Sprintf("threshold:%s:%s:%d", expense.ExpenseID, start, threshold),
+				title: fmt.Sprintf("%s reached %d%% of its limit", expense.ExpenseName, threshold),
+				message: fmt.Sprintf(
+					"%s in %s has spent %.2f of its %.2f limit for %s to %s%s",
+					expense.ExpenseName,
+					budget.BudgetName,
+					actual,
+					period.limit,
+					start,
+					period.lastDay().Format("2006-01-02"),
+					provisional,
+				),
+			})
+		}
+	}
+
+	return alerts
+}
+
+// transactionAlerts ...
+// The alerts of a transaction_over rule for every transaction
+// over its amount dated in the window and not before the
+// rule was created
+func transactionAlerts(rule models.AlertRule, budget models.Budget, transactions []models.Transaction, window expensePeriod) []alert {
+	alerts := make([]alert, 0)
+	created := ""
+
+	if len(rule.CreatedAt) >= len("2006-01-02") {
+		created = rule.CreatedAt[:len("2006-01-02")]
+	}
+
+	for _, tx := range transactions {
+		if tx.Amount <= rule.Amount || tx.Date < created || !window.contains(tx.Date) {
+			continue
+		}
+
+		merchant := tx.Merchant
+
+		if merchant == "" {
+			merchant = tx.Name
+		}
+
+		alerts = append(alerts, alert{
+			rule:    rule,
+			key:     "transaction:" + tx.ID,
+			title:   fmt.Sprintf("Transaction of %.2f at %s", tx.Amount, merchant),
+			message: fmt.Sprintf("A transaction of %.2f at %s on %s in %s is over %.2f", tx.Amount, merchant, tx.Date, budget.BudgetName, rule.Amount),
+		})
+	}
+
+	return alerts
+}
+
+// sendAlert ...
+// Delivers an alert unless it already went out. The alert is
+// recorded as fired first so evaluations running side by side
+// don't both send it, and the record is dropped again when
+// delivery fails so the next evaluation retries it
+func sendAlert(ctx context.Context, fired alert) {
+	first, err := store.RecordAlertFired(ctx, fired.rule.AlertRuleID, fired.key)
+
+	if err != nil {
+		logging.ErrorLogger.Println(err)
+		return
+	}
+
+	if !first {
+		return
+	}
+
+	if deliverErr := deliverAlert(ctx, fired); deliverErr != nil {
+		logging.ErrorLogger.Printf("alert rule %s: %s", fired.rule.AlertRuleID, deliverErr)
+
+		if forgetErr := store.ForgetAlertFired(ctx, fired.rule.AlertRuleID, fired.key); forgetErr != nil {
+			logging.ErrorLogger.Printf("alert rule %s: %s", fired.rule.AlertRuleID, forgetErr)
+		}
+	}
+}
+
+// deliverAlert ...
+// Sends an alert through its rule's channel
+func deliverAlert(ctx context.Context, fired alert) error {
+	budgetID := fired.rule.BudgetID
+
+	notification := models.Notification{
+		UserID:     fired.rule.UserID,
+		BudgetID:   &budgetID,
+		Title:      fired.title,
+		Message:    fired.message,
+		WebhookURL: fired.rule.WebhookURL,
+	}
+
+	if fired.rule.Channel == models.NotificationChannelEmail {
+		user, userErr := userService.GetUserByID(ctx, fired.rule.UserID)
+
+		if userErr != nil {
+			return userErr
+		}
+
+		notification.Email = user.Email
+	}
+
+	return notifierService.Send(ctx, fired.rule.Channel, notification)
+}
@@ -0,0 +1,169 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	notifierService "github.com/lakshay35/finlit-backend/services/notifier"
+)
+
+// failingNotifier ...
+// Fails every delivery, like an unreachable webhook
+type failingNotifier struct {
+	attempts int
+}
+
+func (n *failingNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	n.attempts++
+
+	return fmt.Errorf("receiver unavailable")
+}
+
+func TestSendAlertRetriesFailedDeliveries(t *testing.T) {
+	ctx := context.Background()
+	useMemoryStores()
+
+	fired := alert{
+		rule: models.AlertRule{
+			AlertRuleID: uuid.New(),
+			BudgetID:    uuid.New(),
+			UserID:      uuid.New(),
+			Channel:     models.NotificationChannelInApp,
+		},
+		key:   "transaction:tx-1",
+		title: "Transaction of 120.00 at Airline",
+	}
+
+	failing := &failingNotifier{}
+	notifierService.SetNotifier(models.NotificationChannelInApp, failing)
+
+	sendAlert(ctx, fired)
+
+	if failing.attempts != 1 {
+		t.Fatalf("delivery attempted %d times, want 1", failing.attempts)
+	}
+
+	recording := notifierService.NewRecordingNotifier()
+	notifierService.SetNotifier(models.NotificationChannelInApp, recording)
+
+	// The failed alert goes out on the next evaluation and only once
+	sendAlert(ctx, fired)
+	sendAlert(ctx, fired)
+
+	if notifications := recording.Notifications(); len(notifications) != 1 || notifications[0].Title != fired.title {
+		t.Fatalf("delivered %+v, want the alert once", notifications)
+	}
+}
+
+// alertingBudget ...
+// Creates an inbox budget whose Green Grocer spending goes to a
+// daily Pantry expense of 10 with a rule alerting in the app at
+// 50% and 100% of it, which today's Green Grocer run reaches
+func alertingBudget(t *testing.T, ctx context.Context, ownerID uuid.UUID) *models.Budget {
+	budget, pantry := inboxBudget(t, ctx, ownerID)
+
+	if err := store.CreateCategoryTransaction(ctx, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: pantry.BudgetTransactionCategoryID,
+		TransactionName:             "Green Grocer",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expense, addErr := expenseService.AddExpenseToBudget(ctx, &models.AddExpensePayload{
+		BudgetID:                     budget.BudgetID,
+		ExpenseName:                  "Pantry",
+		ExpenseValue:                 10,
+		ExpenseChargeCycle:           models.ExpenseChargeCycle{Unit: "daily"},
+		ExpenseTransactionCategories: []string{"Pantry"},
+	}, ownerID)
+
+	if addErr != nil {
+		t.Fatal(addErr.Message)
+	}
+
+	if _, ruleErr := CreateAlertRule(ctx, models.AlertRule{
+		BudgetID:   budget.BudgetID,
+		ExpenseID:  &expense.ExpenseID,
+		Kind:       models.AlertKindThreshold,
+		Thresholds: []int{100, 50},
+		Channel:    models.NotificationChannelInApp,
+	}, ownerID); ruleErr != nil {
+		t.Fatal(ruleErr.Message)
+	}
+
+	return budget
+}
+
+func TestEvaluateBudgetAlertsRetriesFailedDeliveries(t *testing.T) {
+	ctx := context.Background()
+	budget := alertingBudget(t, ctx, uuid.New())
+
+	failing := &failingNotifier{}
+	notifierService.SetNotifier(models.NotificationChannelInApp, failing)
+
+	if err := EvaluateBudgetAlerts(ctx, *budget); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	if failing.attempts != 2 {
+		t.Fatalf("delivery attempted %d times, want once per threshold", failing.attempts)
+	}
+
+	// Both alerts were forgotten and go out once each
+	recording := notifierService.NewRecordingNotifier()
+	notifierService.SetNotifier(models.NotificationChannelInApp, recording)
+
+	for i := 0; i < 2; i++ {
+		if err := EvaluateBudgetAlerts(ctx, *budget); err != nil {
+			t.Fatal(err.Message)
+		}
+	}
+
+	notifications := recording.Notifications()
+
+	if len(notifications) != 2 {
+		t.Fatalf("delivered %+v, want each threshold once", notifications)
+	}
+
+	for _, notification := range notifications {
+		if strings.Contains(notification.Message, "provisional") {
+			t.Errorf("alert %q is provisional while every source was read", notification.Message)
+		}
+	}
+}
+
+func TestEvaluateBudgetAlertsWithSkippedSources(t *testing.T) {
+	ctx := context.Background()
+	budget := alertingBudget(t, ctx, uuid.New())
+
+	if _, err := store.CreateTransactionSource(ctx, models.BudgetTransactionSourceCreationPayload{
+		BudgetID:          budget.BudgetID,
+		ExternalAccountID: uuid.New(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	recording := notifierService.NewRecordingNotifier()
+	notifierService.SetNotifier(models.NotificationChannelInApp, recording)
+
+	if err := EvaluateBudgetAlerts(ctx, *budget); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	notifications := recording.Notifications()
+
+	if len(notifications) != 2 {
+		t.Fatalf("delivered %+v, want each threshold once", notifications)
+	}
+
+	for _, notification := range notifications {
+		if !strings.Contains(notification.Message, "provisional: 1 of the budget's transaction sources") {
+			t.Errorf("alert %q doesn't say it's provisional", notification.Message)
+		}
+	}
+}
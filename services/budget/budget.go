@@ -1,10 +1,13 @@
 package budget
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -43,14 +46,8 @@ func ParseBudget(c *gin.Context, res *models.Budget) error {
 
 // GetBudgetTransactionSources ...
 // Retrieves a list of all budget transaction sources
-func GetBudgetTransactionSources(budgetID uuid.UUID) ([]models.BudgetTransactionSourcePayload, *errors.Error) {
-	connection := database.GetConnection()
-
-	query := "SELECT ea.external_account_id, ea.account_name, bts.budget_id, bts.budget_transaction_source_id FROM external_accounts ea JOIN budget_transaction_sources bts ON bts.external_account_id = ea.external_account_id WHERE bts.budget_id = $1"
-
-	stmt := database.PrepareStatement(connection, query)
-
-	rows, dbError := stmt.Query(budgetID)
+func GetBudgetTransactionSources(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionSourcePayload, *errors.Error) {
+	accounts, dbError := store.GetTransactionSources(ctx, budgetID)
 
 	if dbError != nil {
 		return nil, &errors.Error{
@@ -59,38 +56,13 @@ func GetBudgetTransactionSources(budgetID uuid.UUID) ([]models.BudgetTransaction
 		}
 	}
 
-	accounts := make([]models.BudgetTransactionSourcePayload, 0)
-
-	for rows.Next() {
-		var temp models.BudgetTransactionSourcePayload
-		scanErr := rows.Scan(&temp.ExternalAccountID, &temp.AccountName, &temp.BudgetID, &temp.BudgetTransactionSourceID)
-
-		if scanErr != nil {
-			panic(scanErr)
-		}
-
-		accounts = append(accounts, temp)
-	}
-
 	return accounts, nil
 }
 
 // CreateBudgetTransactionSource ...
 // Creates a budget transaction source
-func CreateBudgetTransactionSource(budgetTransactionSource models.BudgetTransactionSourceCreationPayload) (*models.BudgetTransactionSource, *errors.Error) {
-	connection := database.GetConnection()
-	defer database.CloseConnection(connection)
-
-	query := "INSERT INTO budget_transaction_sources (external_account_id, budget_id) VALUES ($1, $2) RETURNING budget_transaction_source_id"
-
-	stmt := database.PrepareStatement(connection, query)
-
-	var res models.BudgetTransactionSource
-
-	res.BudgetID = budgetTransactionSource.BudgetID
-	res.ExternalAccountID = budgetTransactionSource.ExternalAccountID
-
-	dbError := stmt.QueryRow(budgetTransactionSource.ExternalAccountID, budgetTransactionSource.BudgetID).Scan(&res.BudgetTransactionSourceID)
+func CreateBudgetTransactionSource(ctx context.Context, budgetTransactionSource models.BudgetTransactionSourceCreationPayload) (*models.BudgetTransactionSource, *errors.Error) {
+	res, dbError := store.CreateTransactionSource(ctx, budgetTransactionSource)
 
 	if dbError != nil {
 		return nil, &errors.Error{
@@ -99,30 +71,22 @@ func CreateBudgetTransactionSource(budgetTransactionSource models.BudgetTransact
 		}
 	}
 
-	return &res, nil
+	return res, nil
 }
 
 // DeleteBudgetTransactionSource ...
 // Delete budget transation source
-func DeleteBudgetTransactionSource(budgetTransactionSourceID uuid.UUID, userID uuid.UUID) *errors.Error {
+func DeleteBudgetTransactionSource(ctx context.Context, budgetTransactionSourceID uuid.UUID, userID uuid.UUID) *errors.Error {
 
-	budgetTransactionSource, getBudgetTransactionSourceError := GetBudgetTransactionSource(budgetTransactionSourceID)
+	budgetTransactionSource, getBudgetTransactionSourceError := GetBudgetTransactionSource(ctx, budgetTransactionSourceID)
 
 	if getBudgetTransactionSourceError != nil {
 		return getBudgetTransactionSourceError
 	}
 
-	if roleService.IsUserAdmin(budgetTransactionSource.BudgetID, userID) || roleService.IsUserOwner(budgetTransactionSource.BudgetID, userID) {
-
-		connection := database.GetConnection()
-
-		defer database.CloseConnection(connection)
-
-		query := "DELETE FROM budget_transaction_sources WHERE budget_transaction_source_id = $1"
+	if roleService.IsUserAdmin(ctx, budgetTransactionSource.BudgetID, userID) || roleService.IsUserOwner(ctx, budgetTransactionSource.BudgetID, userID) {
 
-		stmt := database.PrepareStatement(connection, query)
-
-		_, dbError := stmt.Exec(budgetTransactionSourceID)
+		dbError := store.DeleteTransactionSource(ctx, budgetTransactionSourceID)
 
 		if dbError != nil {
 			return &errors.Error{
@@ -140,92 +104,108 @@ func DeleteBudgetTransactionSource(budgetTransactionSourceID uuid.UUID, userID u
 	}
 }
 
-// GetBudgetTransactionSource ...
-// Gets budget transaction source by id
-func GetBudgetTransactionSource(budgetTransactionSourceID uuid.UUID) (*models.BudgetTransactionSource, *errors.Error) {
-	connection := database.GetConnection()
+// DisconnectExternalAccount ...
+// Disconnects a user's external account: unlinks it from every
+// budget and income source using it, then deletes it and its
+// stored transactions, revoking its Plaid item when it was the
+// item's last account. Income sources are kept, matching their
+// payer's deposits into any account, while categorization rules
+// limited to the account are deleted. Reports the budgets that
+// lost a transaction source, the income sources unlinked and
+// the rules deleted
+func DisconnectExternalAccount(ctx context.Context, userID uuid.UUID, externalAccountID uuid.UUID) (*models.AccountDisconnection, *errors.Error) {
+	if _, accountErr := account.GetUserExternalAccount(ctx, userID, externalAccountID); accountErr != nil {
+		return nil, accountErr
+	}
 
-	query := "SELECT * FROM budget_transaction_sources WHERE budget_transaction_source_id = $1"
+	disconnection := models.AccountDisconnection{ExternalAccountID: externalAccountID}
 
-	stmt := database.PrepareStatement(connection, query)
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		budgets, err := store.GetAccountSourceBudgets(ctx, externalAccountID)
 
-	var res models.BudgetTransactionSource
-	err := stmt.QueryRow(budgetTransactionSourceID).Scan(&res.BudgetTransactionSourceID, &res.ExternalAccountID, &res.BudgetID)
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		return nil, &errors.Error{
-			Message:    "No budget transaction source exists with provided id",
-			StatusCode: http.StatusNotFound,
+		if err := store.DeleteAccountTransactionSources(ctx, externalAccountID); err != nil {
+			return err
 		}
-	}
 
-	return &res, nil
-}
+		incomeSources, err := store.UnlinkAccountIncomeSources(ctx, externalAccountID)
 
-// DoesBudgetExist ...
-// Checks if a budget exists
-func DoesBudgetExist(UserID uuid.UUID, budgetName string) bool {
-	connection := database.GetConnection()
+		if err != nil {
+			return err
+		}
 
-	query := "SELECT * FROM budgets WHERE owner_id = $1 AND budget_name = $2"
+		rules, err := store.DeleteAccountCategorizationRules(ctx, externalAccountID)
 
-	stmt, err := connection.Prepare(query)
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		return false
+		itemRemoved, deleteErr := account.DeleteExternalAccount(ctx, userID, externalAccountID)
+
+		if deleteErr != nil {
+			return deleteErr
+		}
+
+		disconnection.AffectedBudgets = budgets
+		disconnection.UnlinkedIncomeSources = incomeSources
+		disconnection.DeletedRules = rules
+		disconnection.PlaidItemRemoved = itemRemoved
+
+		return nil
+	})
+
+	if txErr != nil {
+		return nil, toServiceError(txErr)
 	}
 
-	res, err := stmt.Query(UserID, budgetName)
+	return &disconnection, nil
+}
+
+// GetBudgetTransactionSource ...
+// Gets budget transaction source by id
+func GetBudgetTransactionSource(ctx context.Context, budgetTransactionSourceID uuid.UUID) (*models.BudgetTransactionSource, *errors.Error) {
+	res, err := store.GetTransactionSource(ctx, budgetTransactionSourceID)
 
 	if err != nil {
-		panic(err.Error())
+		return nil, &errors.Error{
+			Message:    "No budget transaction source exists with provided id",
+			StatusCode: http.StatusNotFound,
+		}
 	}
 
-	defer res.Close()
-	defer database.CloseConnection(connection)
+	return res, nil
+}
+
+// DoesBudgetExist ...
+// Checks if a budget exists
+func DoesBudgetExist(ctx context.Context, UserID uuid.UUID, budgetName string) bool {
+	_, err := store.FindBudget(ctx, UserID, budgetName)
 
-	return res.Next()
+	return err == nil
 }
 
 // GetBudget ...
 // Gets budget from db based
 // on given params
-func GetBudget(userID uuid.UUID, budgetName string) models.Budget {
-	connection := database.GetConnection()
-	defer database.CloseConnection(connection)
-
-	query := "SELECT * FROM budgets WHERE owner_id = $1 AND budget_name = $2"
-
-	stmt, err := connection.Prepare(query)
+func GetBudget(ctx context.Context, userID uuid.UUID, budgetName string) models.Budget {
+	res, err := store.FindBudget(ctx, userID, budgetName)
 
 	if err != nil {
-		panic("Something went wrong when preparing query to get budget")
-	}
-
-	rows, err := stmt.Query(userID, budgetName)
-
-	if err != nil || !rows.Next() {
 		fmt.Println(err.Error())
 		return models.Budget{}
 	}
 
-	var res models.Budget
-
-	err = rows.Scan(&res.BudgetID, &res.OwnerID, &res.BudgetName)
-
-	if err != nil {
-		panic(err)
-	}
-
-	rows.Close()
-
-	return res
+	return *res
 }
 
 // CreateBudget ...
-// Creates budget if it doesn't already exist for user
-func CreateBudget(userID uuid.UUID, budgetName string) (*models.Budget, *errors.Error) {
-	if DoesBudgetExist(userID, budgetName) {
+// Creates budget if it doesn't already exist for user,
+// seeded with the default Plaid category template
+func CreateBudget(ctx context.Context, userID uuid.UUID, budgetName string) (*models.Budget, *errors.Error) {
+	if DoesBudgetExist(ctx, userID, budgetName) {
 		return nil, &errors.Error{
 			Message:    "Budget named " + budgetName + " already exists",
 			StatusCode: http.StatusConflict,
@@ -239,49 +219,34 @@ func CreateBudget(userID uuid.UUID, budgetName string) (*models.Budget, *errors.
 		}
 	}
 
-	connection := database.GetConnection()
+	var result *models.Budget
 
-	defer database.CloseConnection(connection)
+	// New budgets start out with the default categories and
+	// their Plaid category mappings
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		created, errr := store.CreateBudget(ctx, userID, budgetName)
 
-	query := "INSERT INTO budgets (owner_id, budget_name) VALUES ($1, $2) RETURNING owner_id, budget_name, budget_id"
-
-	stmt, errr := connection.Prepare(query)
-
-	if errr != nil {
-		panic("Something went wrong when preparing query to create budget")
-	}
+		if errr != nil {
+			return errr
+		}
 
-	var result models.Budget
+		result = created
 
-	errr = stmt.QueryRow(userID, budgetName).Scan(
-		&result.OwnerID,
-		&result.BudgetName,
-		&result.BudgetID,
-	)
+		return seedPlaidCategoryMappings(ctx, created.BudgetID)
+	})
 
-	if errr != nil {
-		panic(errr)
+	if txErr != nil {
+		return nil, toServiceError(txErr)
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 // GetAllBudgets ...
 // Gets all budgets that given userID owns
 // TODO: Get all budgets user owns and has access to, include access type in return object
-func GetAllBudgets(userID uuid.UUID) ([]models.Budget, *errors.Error) {
-	connection := database.GetConnection()
-	defer database.CloseConnection(connection)
-
-	query := "SELECT * FROM budgets where owner_id = $1"
-
-	stmt, errr := connection.Prepare(query)
-
-	if errr != nil {
-		panic("Error preparing query for getting budgets")
-	}
-
-	res, errr := stmt.Query(userID)
+func GetAllBudgets(ctx context.Context, userID uuid.UUID) ([]models.Budget, *errors.Error) {
+	result, errr := store.GetBudgets(ctx, userID)
 
 	if errr != nil {
 		return nil, &errors.Error{
@@ -290,37 +255,13 @@ func GetAllBudgets(userID uuid.UUID) ([]models.Budget, *errors.Error) {
 		}
 	}
 
-	var result []models.Budget = make([]models.Budget, 0)
-
-	for res.Next() {
-		var temp models.Budget
-		err := res.Scan(&temp.BudgetID, &temp.BudgetName, &temp.OwnerID)
-
-		if err != nil {
-			panic(err)
-		}
-
-		// Appends the item to the result
-		result = append(result, temp)
-	}
-
-	res.Close()
-
 	return result, nil
 }
 
 // DeleteAllBudgetTransactionSources ...
 // Deletes all budget transaction sources
-func DeleteAllBudgetTransactionSources(budgetID uuid.UUID) *errors.Error {
-	connection := database.GetConnection()
-
-	defer database.CloseConnection(connection)
-
-	query := "DELETE FROM budget_transaction_sources WHERE budget_id = $1"
-
-	stmt := database.PrepareStatement(connection, query)
-
-	_, stmtErr := stmt.Exec(budgetID)
+func DeleteAllBudgetTransactionSources(ctx context.Context, budgetID uuid.UUID) *errors.Error {
+	stmtErr := store.DeleteAllTransactionSources(ctx, budgetID)
 
 	if stmtErr != nil {
 		return &errors.Error{
@@ -333,200 +274,336 @@ func DeleteAllBudgetTransactionSources(budgetID uuid.UUID) *errors.Error {
 }
 
 // DeleteBudget ...
-// Deletes budget an all associated expenses
-func DeleteBudget(budgetID uuid.UUID, userID uuid.UUID) *errors.Error {
-	if !roleService.IsUserOwner(budgetID, userID) {
+// Deletes budget an all associated expenses,
+// transaction sources and categories
+func DeleteBudget(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) *errors.Error {
+	if !roleService.IsUserOwner(ctx, budgetID, userID) {
 		return &errors.Error{
 			Message:    "User requesting deletion needs to be the owner of budget to proceed",
 			StatusCode: http.StatusUnauthorized,
 		}
 	}
 
-	deleteBTSErr := DeleteAllBudgetTransactionSources(budgetID)
-
-	if deleteBTSErr != nil {
-		return deleteBTSErr
-	}
+	// Sources, expenses and the budget itself go together or not at all
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		if deleteBTSErr := DeleteAllBudgetTransactionSources(ctx, budgetID); deleteBTSErr != nil {
+			return deleteBTSErr
+		}
 
-	err := expenseService.DeleteAllBudgetExpenses(budgetID, userID)
+		if err := expenseService.DeleteAllBudgetExpenses(ctx, budgetID, userID); err != nil {
+			return err
+		}
 
-	if err != nil {
-		return err
-	}
+		// Categories and their tagged transactions don't cascade
+		// with the budget and would keep it from being deleted
+		if err := store.DeleteAllTransactionCategories(ctx, budgetID); err != nil {
+			return err
+		}
 
-	connection := database.GetConnection()
-	defer database.CloseConnection(connection)
+		if errr := store.DeleteBudget(ctx, budgetID); errr != nil {
+			return &errors.Error{
+				Message:    errr.Error(),
+				StatusCode: http.StatusBadRequest,
+			}
+		}
 
-	query := "DELETE FROM budgets where budget_id = $1"
+		database.AfterCommit(ctx, func() {
+			forgetClassifier(budgetID)
+		})
 
-	stmt, errr := connection.Prepare(query)
+		return nil
+	})
 
-	if errr != nil {
-		panic("Error preparing query for deleting budgets")
-	}
+	return toServiceError(txErr)
+}
 
-	_, errrr := stmt.Exec(budgetID)
+// GetBudgetTransactionCategoryTransactions ...
+// Gets budget transaction category transactions that the user has tagged
+func GetBudgetTransactionCategoryTransactions(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategoryTransaction, *errors.Error) {
+	transactions, queryErr := store.GetCategoryTransactions(ctx, budgetID)
 
-	if errrr != nil {
-		return &errors.Error{
-			Message:    errr.Error(),
+	if queryErr != nil {
+		return nil, &errors.Error{
+			Message:    queryErr.Error(),
 			StatusCode: http.StatusBadRequest,
 		}
 	}
 
-	return nil
+	return transactions, nil
 }
 
-// GetBudgetTransactionCategoryTransactions ...
-// Gets budget transaction category transactions that the user has tagged
-func GetBudgetTransactionCategoryTransactions(budgetID uuid.UUID) ([]models.BudgetTransactionCategoryTransaction, *errors.Error) {
-	connection := database.GetConnection()
+// CreateBudgetTransactionCategoryTransaction ...
+// Tags a transaction name with a budget transaction category,
+// replacing the categories its merchant was tagged with before,
+// whatever raw name they were tagged under. The budget's
+// categorization suggestions learn from the choice
+func CreateBudgetTransactionCategoryTransaction(ctx context.Context, budgetID uuid.UUID, categoryTransaction models.BudgetTransactionCategoryTransaction) *errors.Error {
+	tagged, taggedErr := store.GetCategoryTransactions(ctx, budgetID)
 
-	defer database.CloseConnection(connection)
+	if taggedErr != nil {
+		return &errors.Error{
+			Message:    taggedErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
 
-	query := "SELECT btct.transaction_name, btc.category_name FROM budget_transaction_category_transactions btct JOIN budget_transaction_categories btc on btc.budget_transaction_category_id = btct.budget_transaction_category_id WHERE btc.budget_id = $1"
+	key := assignmentKey(categoryTransaction.TransactionName)
+	replaced := make([]models.BudgetTransactionCategoryTransaction, 0)
+	replacedNames := make([]string, 0)
+	seen := make(map[string]bool)
 
-	stmt := database.PrepareStatement(connection, query)
+	for _, previous := range tagged {
+		if assignmentKey(previous.TransactionName) != key {
+			continue
+		}
 
-	res, queryErr := stmt.Query(budgetID)
+		replaced = append(replaced, previous)
 
-	if queryErr != nil {
-		return nil, &errors.Error{
-			Message:    queryErr.Error(),
-			StatusCode: http.StatusBadRequest,
+		if !seen[previous.TransactionName] {
+			seen[previous.TransactionName] = true
+			replacedNames = append(replacedNames, previous.TransactionName)
 		}
 	}
 
-	var transactions []models.BudgetTransactionCategoryTransaction
-
-	for res.Next() {
-		var temp models.BudgetTransactionCategoryTransaction
-
-		scanErr := res.Scan(&temp.TransactionName, &temp.CategoryName)
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, name := range replacedNames {
+			if err := store.DeleteBudgetCategoryTransaction(ctx, budgetID, name); err != nil {
+				return err
+			}
+		}
 
-		if scanErr != nil {
-			panic(scanErr)
+		if err := store.CreateCategoryTransaction(ctx, categoryTransaction); err != nil {
+			return &errors.Error{
+				Message:    err.Error(),
+				StatusCode: http.StatusBadRequest,
+			}
 		}
 
-		transactions = append(transactions, temp)
-	}
+		database.AfterCommit(ctx, func() {
+			learnCategoryTransaction(budgetID, replaced, categoryTransaction)
+		})
 
-	return transactions, nil
+		return nil
+	})
+
+	return toServiceError(txErr)
 }
 
 // GetBudgetExpenseSummary ...
-// Calculates the budget expense summary for the past 30 day period
-func GetBudgetExpenseSummary(budgetID uuid.UUID, userID uuid.UUID) ([]models.ExpenseSummary, *errors.Error) {
-	if !roleService.IsUserAdmin(budgetID, userID) && !roleService.IsUserOwner(budgetID, userID) {
+// Calculates the budget expense summary for a period, the current
+// period of every expense's charge cycle when none is given.
+// Sources whose account is gone or whose Plaid item is broken are
+// left out and reported rather than failing the whole summary
+func GetBudgetExpenseSummary(ctx context.Context, budgetID uuid.UUID, period models.SummaryPeriod, userID uuid.UUID) (*models.BudgetExpenseSummary, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
 		return nil, &errors.Error{
 			Message:    "You are not entitled to this budget",
 			StatusCode: http.StatusForbidden,
 		}
 	}
 
-	budgetTransactionSources, getBudgetTransactionSourcesError := GetBudgetTransactionSources(budgetID)
+	budget, budgetErr := store.GetBudget(ctx, budgetID)
+
+	if budgetErr != nil {
+		return nil, &errors.Error{
+			Message:    budgetErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	budgetTransactionSources, getBudgetTransactionSourcesError := GetBudgetTransactionSources(ctx, budgetID)
 
 	if getBudgetTransactionSourcesError != nil {
 		return nil, getBudgetTransactionSourcesError
 	}
 
-	expenses, expensesErr := expenseService.GetAllExpensesForBudget(budgetID, userID)
+	expenses, expensesErr := expenseService.GetAllExpensesForBudget(ctx, budgetID, userID)
 
 	if expensesErr != nil {
 		return nil, expensesErr
 	}
 
-	var txs = make([]plaid.Transaction, 0)
+	now := time.Now().Local()
+	periods, window, periodErr := summaryPeriods(expenses, period, now)
 
-	for _, bts := range budgetTransactionSources {
-		transactions, getTransactionsErr := account.GetTransactions(bts.ExternalAccountID, time.Now().Local().Add(-30*24*time.Hour).Format("2006-01-02"),
-			time.Now().Local().Format("2006-01-02"))
+	if periodErr != nil {
+		return nil, periodErr
+	}
 
-		if getTransactionsErr != nil {
-			return nil, getTransactionsErr
-		}
+	snapshots, snapshotsErr := expenseService.GetBudgetPeriodSnapshots(ctx, budgetID)
 
-		txs = append(txs, transactions...)
+	if snapshotsErr != nil {
+		return nil, snapshotsErr
+	}
+
+	startDate, endDate := periodDates(periods, window)
+
+	// Finished periods of expenses that roll over are settled first
+	if unsettled := unsettledStartDate(expenses, snapshots, now); unsettled != "" && unsettled < startDate {
+		startDate = unsettled
+	}
+
+	// The current period is forecast from the ones before it
+	current := period.StartDate == "" && period.EndDate == "" && period.PeriodsAgo == 0
+
+	if history := forecastStartDate(expenses, now); current && history != "" && history < startDate {
+		startDate = history
 	}
 
-	budgetTransactionCategories, budgetTransactionCategoriesErr := GetAllBudgetTransactionCategories(budgetID)
+	txs, sourceIssues := budgetTransactions(
+		ctx,
+		budgetTransactionSources,
+		startDate,
+		endDate,
+	)
+
+	budgetTransactionCategories, budgetTransactionCategoriesErr := GetAllBudgetTransactionCategories(ctx, budgetID)
 
 	if budgetTransactionCategoriesErr != nil {
 		return nil, budgetTransactionCategoriesErr
 	}
 
-	budgetTransactionCategoryTransactions, budgetTransactionCategoryTransactionsErr := GetBudgetTransactionCategoryTransactions(budgetID)
+	categorizer, categorizerErr := newCategorizer(ctx, budgetID)
 
-	if budgetTransactionCategoryTransactionsErr != nil {
-		return nil, budgetTransactionCategoryTransactionsErr
+	if categorizerErr != nil {
+		return nil, categorizerErr
 	}
 
-	budgetExpenseTransactionCategoryMappings, budgetExpenseTransactionCategoryMappingsErr := getBudgetExpenseTransactionCategoryMappings(budgetID)
+	budgetExpenseTransactionCategoryMappings, budgetExpenseTransactionCategoryMappingsErr := expenseService.GetBudgetExpenseTransactionCategoryMappings(ctx, budgetID)
 
 	if budgetExpenseTransactionCategoryMappingsErr != nil {
 		return nil, budgetExpenseTransactionCategoryMappingsErr
 	}
 
-	summary := calculatedBudgetExpenseSummaryUsingTransactionsAndExpenses(
+	tree := newCategoryTree(budgetTransactionCategories)
+	spending := spendingByCategory(txs, categorizer, budgetTransactionCategories)
+	expenseCategories := expenseCategoryNames(tree, budgetExpenseTransactionCategoryMappings)
+
+	carried, snapshots, settleErr := settleRollovers(ctx, expenses, snapshots, spending, expenseCategories, blocksSettlement(sourceIssues), now)
+
+	if settleErr != nil {
+		return nil, settleErr
+	}
+
+	// Past periods were carried into as their snapshots say,
+	// and explicit windows don't carry anything
+	if period.PeriodsAgo > 0 {
+		for _, expense := range expenses {
+			if expense.Rollover {
+				carried[expense.ExpenseID] = carriedInto(expense, snapshots, periods[expense.ExpenseID])
+			}
+		}
+	} else if period.StartDate != "" {
+		carried = make(map[uuid.UUID]float64)
+	}
+
+	summary, categoryRollups, uncategorizedTotal := calculatedBudgetExpenseSummaryUsingTransactionsAndExpenses(
 		expenses,
-		txs,
-		budgetTransactionCategoryTransactions,
-		budgetTransactionCategories,
-		budgetExpenseTransactionCategoryMappings,
+		spending,
+		tree,
+		expenseCategories,
+		periods,
+		carried,
+		window,
 	)
 
-	return summary, nil
-	// plaidSerivce.PlaidClient().
+	if current {
+		forecastExpenses(expenses, summary, spending, expenseCategories, periods, now)
+	}
+
+	income := incomeSummary(txs, categorizer, window)
 
-	// return "hello", nil
+	var zeroBased *models.ZeroBasedSummary
+
+	if budget.ZeroBased {
+		zeroBased = zeroBasedSummary(income, expenses, window)
+	}
+
+	return &models.BudgetExpenseSummary{
+		PeriodStart:        window.start.Format("2006-01-02"),
+		PeriodEnd:          window.lastDay().Format("2006-01-02"),
+		Expenses:           summary,
+		Categories:         categoryRollups,
+		UncategorizedTotal: uncategorizedTotal,
+		Income:             income,
+		ZeroBased:          zeroBased,
+		SourceIssues:       sourceIssues,
+	}, nil
 }
 
-func getBudgetExpenseTransactionCategoryMappings(budgetID uuid.UUID) ([]models.ExpenseBudgetTransactionCategory, *errors.Error) {
-	connection := database.GetConnection()
+// budgetTransactions ...
+// Gets the synced transactions of a budget's sources dated
+// between startDate and endDate. Sources whose account is gone
+// or whose Plaid item is broken are skipped, and they and
+// sources about to break are reported as issues
+func budgetTransactions(
+	ctx context.Context,
+	budgetTransactionSources []models.BudgetTransactionSourcePayload,
+	startDate string,
+	endDate string,
+) ([]models.Transaction, []models.BudgetSourceIssue) {
+	txs := make([]models.Transaction, 0)
+	sourceIssues := make([]models.BudgetSourceIssue, 0)
 
-	defer database.CloseConnection(connection)
+	for _, bts := range budgetTransactionSources {
+		issue := models.BudgetSourceIssue{
+			BudgetTransactionSourceID: bts.BudgetTransactionSourceID,
+			ExternalAccountID:         bts.ExternalAccountID,
+			AccountName:               bts.AccountName,
+		}
 
-	query := "select expense_id, betc.budget_transaction_category_id, category_name from budget_expense_transaction_categories betc join budget_transaction_categories btci on btci.budget_transaction_category_id = betc.budget_transaction_category_id WHERE btci.budget_id = $1"
+		externalAccount, externalAccountErr := account.GetExternalAccount(ctx, bts.ExternalAccountID)
 
-	stmt := database.PrepareStatement(connection, query)
+		if externalAccountErr != nil {
+			issue.Message = "External account is no longer registered"
+			issue.Skipped = true
+			sourceIssues = append(sourceIssues, issue)
 
-	res, mappingRetrievalErr := stmt.Query(budgetID)
+			continue
+		}
 
-	if mappingRetrievalErr != nil {
-		return nil, &errors.Error{
-			StatusCode: http.StatusBadRequest,
-			Message:    mappingRetrievalErr.Error(),
+		issue.ItemStatus = externalAccount.ItemStatus
+
+		if account.PlaidItemIsBroken(externalAccount.ItemStatus) {
+			issue.Message = "Bank login must be renewed before this account's transactions can be used"
+			issue.Skipped = true
+			sourceIssues = append(sourceIssues, issue)
+
+			continue
 		}
-	}
 
-	expenseMappings := make([]models.ExpenseBudgetTransactionCategory, 0)
+		transactions, getTransactionsErr := account.GetStoredTransactions(ctx, bts.ExternalAccountID, startDate, endDate)
 
-	for res.Next() {
-		var temp models.ExpenseBudgetTransactionCategory
+		if getTransactionsErr != nil {
+			issue.Message = getTransactionsErr.Message
+			issue.Skipped = true
+			sourceIssues = append(sourceIssues, issue)
 
-		scanErr := res.Scan(&temp.ExpenseID, &temp.BudgeTransactionCategoryID, &temp.CategoryName)
+			continue
+		}
 
-		if scanErr != nil {
-			panic(scanErr)
+		if externalAccount.NeedsAttention {
+			issue.Message = "Bank login expires soon and must be renewed to keep this account up to date"
+			sourceIssues = append(sourceIssues, issue)
 		}
 
-		expenseMappings = append(expenseMappings, temp)
+		txs = append(txs, transactions...)
 	}
 
-	return expenseMappings, nil
+	return txs, sourceIssues
+}
+
+// isSpending ...
+// Whether a transaction is money spent, as opposed to
+// income, refunds, card payments and transfers
+func isSpending(tx plaid.Transaction) bool {
+	return tx.Amount > 0 && !isTransfer(tx)
 }
 
 // GetAllBudgetTransactionCategories ...
 // Gets all budget transaction categories
-func GetAllBudgetTransactionCategories(budgetID uuid.UUID) ([]models.BudgetTransactionCategory, *errors.Error) {
-	connection := database.GetConnection()
-	defer database.CloseConnection(connection)
-
-	query := "SELECT * FROM budget_transaction_categories WHERE budget_id = $1"
-
-	stmt := database.PrepareStatement(connection, query)
-
-	res, execErr := stmt.Query(budgetID)
+func GetAllBudgetTransactionCategories(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategory, *errors.Error) {
+	categories, execErr := store.GetTransactionCategories(ctx, budgetID)
 
 	if execErr != nil {
 		return nil, &errors.Error{
@@ -535,120 +612,42 @@ func GetAllBudgetTransactionCategories(budgetID uuid.UUID) ([]models.BudgetTrans
 		}
 	}
 
-	categories := make([]models.BudgetTransactionCategory, 0)
-
-	for res.Next() {
-		var temp models.BudgetTransactionCategory
-
-		scanErr := res.Scan(&temp.BudgetTransactionCategoryID, &temp.BudgetID, &temp.CategoryName)
-
-		if scanErr != nil {
-			panic(scanErr)
-		}
-
-		categories = append(categories, temp)
-	}
-
 	return categories, nil
 }
 
 func calculatedBudgetExpenseSummaryUsingTransactionsAndExpenses(
 	expenses []models.Expense,
-	transactions []plaid.Transaction,
-	categories []models.BudgetTransactionCategoryTransaction,
-	budgetTransactionCategories []models.BudgetTransactionCategory,
-	budgetExpenseTransactionCategories []models.ExpenseBudgetTransactionCategory,
-) []models.ExpenseSummary {
+	res map[string]models.ExpenseCategorySummary,
+	tree *categoryTree,
+	expenseCategories map[uuid.UUID][]string,
+	periods map[uuid.UUID]expensePeriod,
+	carried map[uuid.UUID]float64,
+	window expensePeriod,
+) ([]models.ExpenseSummary, []models.CategoryRollup, float64) {
 
 	summary := make([]models.ExpenseSummary, 0)
 
-	// map that holds transaction categories
-	transactionCategoriesMap := make(map[string]string)
-	for _, cat := range categories {
-		transactionCategoriesMap[cat.TransactionName] = cat.CategoryName
-	}
-
-	res := make(map[string]models.ExpenseCategorySummary)
-
-	// Add uncategorized transaction category
-	res["Uncategorized"] = models.ExpenseCategorySummary{
-		CategoryName: "Uncategorized",
-		Transactions: make([]plaid.Transaction, 0),
-	}
-
-	// Populate transaction categories in map
-	for _, cat := range budgetTransactionCategories {
-		res[cat.CategoryName] = models.ExpenseCategorySummary{
-			CategoryName: cat.CategoryName,
-			Transactions: make([]plaid.Transaction, 0),
-		}
-	}
-
-	// For each transaction, add transaction tactionCategoriesMapo trans
-	for _, tx := range transactions {
-		if tx.Amount > 0 && tx.Category[0] != "Payment" && tx.Category[0] != "Transfer" {
-
-			var temp models.ExpenseCategorySummary
-
-			transactionCategory := transactionCategoriesMap[tx.Name]
-
-			if transactionCategory != "" {
-				if res[transactionCategory].CategoryName != "" {
-					temp := res[transactionCategory]
-					// temp.CurrentExpense = temp.CurrentExpense + tx.Amount
-					temp.Transactions = append(temp.Transactions, tx)
-					res[transactionCategory] = temp
-				} else {
-					temp.CategoryName = transactionCategory
-					txs := make([]plaid.Transaction, 0)
-					temp.Transactions = append(txs, tx)
-					res[transactionCategory] = temp
-				}
-			} else {
-				temp := res["Uncategorized"]
-				// temp.CurrentExpense += tx.Amount
-				temp.Transactions = append(temp.Transactions, tx)
-				res["Uncategorized"] = temp
-			}
-		}
-	}
-
-	// other.CurrentExpense = value
-
-	budgetExpenseTransactionCategoriesMap := make(map[uuid.UUID][]string)
-
-	for _, cat := range budgetExpenseTransactionCategories {
-		if len(budgetExpenseTransactionCategoriesMap[cat.ExpenseID]) == 0 {
-			arr := make([]string, 0)
-			arr = append(arr, cat.CategoryName)
-			budgetExpenseTransactionCategoriesMap[cat.ExpenseID] = arr
-		} else {
-			budgetExpenseTransactionCategoriesMap[cat.ExpenseID] = append(budgetExpenseTransactionCategoriesMap[cat.ExpenseID], cat.CategoryName)
-		}
-	}
-
 	for _, expense := range expenses {
-		categories := budgetExpenseTransactionCategoriesMap[expense.ExpenseID]
+		categories := expenseCategories[expense.ExpenseID]
+
+		period := periods[expense.ExpenseID]
 
 		sum := models.ExpenseSummary{
 			ExpenseName:            expense.ExpenseName,
-			ExpenseLimit:           float64(expense.ExpenseValue),
+			ExpenseLimit:           period.limit,
+			CarriedOver:            carried[expense.ExpenseID],
+			Available:              period.limit + carried[expense.ExpenseID],
 			ExpenseChargeCycleDays: expense.ExpenseChargeCycle.Days,
+			PeriodStart:            period.start.Format("2006-01-02"),
+			PeriodEnd:              period.lastDay().Format("2006-01-02"),
 		}
 
 		for _, category := range categories {
 
-			countedTransactions := make([]plaid.Transaction, 0)
+			countedTransactions := make([]models.Transaction, 0)
 
 			for _, tx := range res[category].Transactions {
-
-				txDate, txDateErr := time.Parse("2006-01-02", tx.Date)
-
-				if txDateErr != nil {
-					panic(txDateErr)
-				}
-
-				if !txDate.Before(time.Now().AddDate(0, 0, -expense.ExpenseChargeCycle.Days)) {
+				if period.contains(tx.Date) {
 					countedTransactions = append(countedTransactions, tx)
 					sum.CurrentExpense += tx.Amount
 				}
@@ -666,82 +665,156 @@ func calculatedBudgetExpenseSummaryUsingTransactionsAndExpenses(
 
 	}
 
-	return summary
-}
-
-// GetTransactionCategories ...
-// Get transaction categories for a given budget
-func GetTransactionCategories(budgetID uuid.UUID) ([]models.BudgetTransactionCategory, *errors.Error) {
+	totals := make(map[uuid.UUID]float64)
+	counts := make(map[uuid.UUID]int)
 
-	connection := database.GetConnection()
+	for name, categorySummary := range res {
+		categoryID, ok := tree.byName[name]
 
-	defer database.CloseConnection(connection)
+		if !ok {
+			continue
+		}
 
-	query := "SELECT * FROM budget_transaction_categories WHERE budget_id = $1"
+		for _, tx := range categorySummary.Transactions {
+			if !window.contains(tx.Date) {
+				continue
+			}
 
-	stmt := database.PrepareStatement(connection, query)
+			totals[categoryID] += tx.Amount
+			counts[categoryID]++
+		}
+	}
 
-	res, queryErr := stmt.Query(budgetID)
+	uncategorizedTotal := 0.0
 
-	if queryErr != nil {
-		return nil, &errors.Error{
-			Message:    queryErr.Error(),
-			StatusCode: http.StatusBadRequest,
+	for _, tx := range res["Uncategorized"].Transactions {
+		if !window.contains(tx.Date) {
+			continue
 		}
+
+		uncategorizedTotal += tx.Amount
 	}
 
-	categories := make([]models.BudgetTransactionCategory, 0)
+	return summary, tree.rollups(totals, counts), uncategorizedTotal
+}
+
+// spendingByCategory ...
+// Groups spending transactions by the category the categorizer
+// puts them in, with the rest under "Uncategorized". Refunds are
+// kept as negative amounts in their category so they net against
+// its spending. Merchants are named the way the budget's aliases
+// name them
+func spendingByCategory(
+	transactions []models.Transaction,
+	categorizer *categorizer,
+	budgetTransactionCategories []models.BudgetTransactionCategory,
+) map[string]models.ExpenseCategorySummary {
+	res := make(map[string]models.ExpenseCategorySummary)
+
+	// Add uncategorized transaction category
+	res["Uncategorized"] = models.ExpenseCategorySummary{
+		CategoryName: "Uncategorized",
+		Transactions: make([]models.Transaction, 0),
+	}
+
+	// Populate transaction categories in map
+	for _, cat := range budgetTransactionCategories {
+		res[cat.CategoryName] = models.ExpenseCategorySummary{
+			CategoryName: cat.CategoryName,
+			Transactions: make([]models.Transaction, 0),
+		}
+	}
 
-	for res.Next() {
-		var temp models.BudgetTransactionCategory
+	// For each transaction, add it to the category the categorizer puts it in
+	for _, tx := range transactions {
+		var transactionCategory string
 
-		scanErr := res.Scan(&temp.BudgetTransactionCategoryID, &temp.BudgetID, &temp.CategoryName)
+		if isSpending(tx.Transaction) {
+			transactionCategory = categorizer.categorize(tx, categorizer.merchant(tx)).CategoryName
 
-		if scanErr != nil {
-			panic(scanErr)
+			if transactionCategory == "" {
+				transactionCategory = "Uncategorized"
+			}
+		} else if transactionCategory = categorizer.refundCategory(tx); transactionCategory == "" {
+			continue
 		}
 
-		categories = append(categories, temp)
+		tx.Merchant = categorizer.merchant(tx)
+
+		temp := res[transactionCategory]
+		temp.CategoryName = transactionCategory
+		temp.Transactions = append(temp.Transactions, tx)
+		res[transactionCategory] = temp
 	}
 
-	return categories, nil
+	return res
 }
 
-// DeleteTransactionCategoryTransactions ...
-func DeleteTransactionCategoryTransactions(transactionCategoryID uuid.UUID) *errors.Error {
-	connection := database.GetConnection()
+// expenseCategoryNames ...
+// The names of the transaction categories each expense tracks.
+// Expenses tracking a category track everything below it too
+func expenseCategoryNames(tree *categoryTree, budgetExpenseTransactionCategories []models.ExpenseBudgetTransactionCategory) map[uuid.UUID][]string {
+	names := make(map[uuid.UUID][]string)
 
-	defer database.CloseConnection(connection)
+	for _, cat := range budgetExpenseTransactionCategories {
+		names[cat.ExpenseID] = append(names[cat.ExpenseID], cat.CategoryName)
+	}
 
-	// TODO: Delete all transaction associations with category (CASCADE ACTION)
-	query := "DELETE FROM budget_transaction_category_transactions where budget_transaction_category_id = $1"
+	for expenseID := range names {
+		names[expenseID] = expandCategoryNames(tree, names[expenseID])
+	}
 
-	stmt := database.PrepareStatement(connection, query)
+	return names
+}
 
-	_, err := stmt.Exec(transactionCategoryID)
+// expandCategoryNames ...
+// Adds the names of every category below the given
+// ones, leaving out names already listed
+func expandCategoryNames(tree *categoryTree, names []string) []string {
+	expanded := make([]string, 0, len(names))
+	seen := make(map[string]bool)
 
-	if err != nil {
-		return &errors.Error{
-			Message:    err.Error(),
-			StatusCode: http.StatusBadRequest,
+	for _, name := range names {
+		categoryID, ok := tree.byName[name]
+
+		if !ok {
+			if !seen[name] {
+				seen[name] = true
+				expanded = append(expanded, name)
+			}
+
+			continue
+		}
+
+		for _, category := range tree.descendants(categoryID) {
+			if !seen[category.CategoryName] {
+				seen[category.CategoryName] = true
+				expanded = append(expanded, category.CategoryName)
+			}
 		}
 	}
 
-	return nil
+	return expanded
 }
 
-// DeleteBudgetTransactionCategoryExpense ...
-func DeleteBudgetTransactionCategoryExpense(transactionCategoryID uuid.UUID) *errors.Error {
-	connection := database.GetConnection()
-
-	defer database.CloseConnection(connection)
+// GetTransactionCategories ...
+// Get transaction categories for a given budget
+func GetTransactionCategories(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategory, *errors.Error) {
+	categories, queryErr := store.GetTransactionCategories(ctx, budgetID)
 
-	// TODO: Delete all transaction associations with category (CASCADE ACTION)
-	query := "DELETE FROM expenses where budget_transaction_category_id = $1"
+	if queryErr != nil {
+		return nil, &errors.Error{
+			Message:    queryErr.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
 
-	stmt := database.PrepareStatement(connection, query)
+	return categories, nil
+}
 
-	_, err := stmt.Exec(transactionCategoryID)
+// DeleteTransactionCategoryTransactions ...
+func DeleteTransactionCategoryTransactions(ctx context.Context, transactionCategoryID uuid.UUID) *errors.Error {
+	err := store.DeleteCategoryTransactions(ctx, transactionCategoryID)
 
 	if err != nil {
 		return &errors.Error{
@@ -753,71 +826,133 @@ func DeleteBudgetTransactionCategoryExpense(transactionCategoryID uuid.UUID) *er
 	return nil
 }
 
-// DeleteTransactionCategory ...
-// Deletes transaction category
-func DeleteTransactionCategory(categoryID uuid.UUID) *errors.Error {
+// toServiceError ...
+// Converts an error returned from a transaction back
+// into the service error that caused it
+func toServiceError(err error) *errors.Error {
+	if err == nil {
+		return nil
+	}
 
-	connection := database.GetConnection()
+	if serviceErr, ok := err.(*errors.Error); ok {
+		return serviceErr
+	}
 
-	defer database.CloseConnection(connection)
+	return &errors.Error{
+		Message:    err.Error(),
+		StatusCode: http.StatusInternalServerError,
+	}
+}
 
-	deleteExpenseErr := DeleteBudgetTransactionCategoryExpense(categoryID)
+// CreateTransactionCategory ...
+// Creates a transaction category for the given budget
+func CreateTransactionCategory(ctx context.Context, category models.BudgetTransactionCategoryCreationPayload, userID uuid.UUID) (*models.BudgetTransactionCategory, *errors.Error) {
 
-	if deleteExpenseErr != nil {
-		return deleteExpenseErr
+	if !roleService.IsUserAdmin(ctx, category.BudgetID, userID) && !roleService.IsUserOwner(ctx, category.BudgetID, userID) {
+		return nil, &errors.Error{
+			StatusCode: http.StatusForbidden,
+			Message:    "You are not authorized to create a transaction category for the given budget",
+		}
 	}
 
-	deleteTransactionCategoryTransactionsErr := DeleteTransactionCategoryTransactions(categoryID)
+	existing, existingErr := GetAllBudgetTransactionCategories(ctx, category.BudgetID)
 
-	if deleteTransactionCategoryTransactionsErr != nil {
-		return deleteTransactionCategoryTransactionsErr
+	if existingErr != nil {
+		return nil, existingErr
 	}
 
-	query := "DELETE FROM budget_transaction_categories where budget_transaction_category_id = $1"
+	for _, other := range existing {
+		if strings.EqualFold(other.CategoryName, category.CategoryName) {
+			return nil, &errors.Error{
+				Message:    "Category named " + category.CategoryName + " already exists",
+				StatusCode: http.StatusConflict,
+			}
+		}
+	}
 
-	stmt := database.PrepareStatement(connection, query)
+	if category.ParentCategoryID != nil {
+		parent, parentErr := store.GetTransactionCategory(ctx, *category.ParentCategoryID)
 
-	_, err := stmt.Exec(categoryID)
+		if parentErr != nil || parent.BudgetID != category.BudgetID {
+			return nil, &errors.Error{
+				Message:    "Parent category not found",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
 
-	if err != nil {
-		return &errors.Error{
-			Message:    err.Error(),
+	temp, scanErr := store.CreateTransactionCategory(ctx, category)
+
+	if scanErr != nil {
+		return nil, &errors.Error{
+			Message:    scanErr.Error(),
 			StatusCode: http.StatusBadRequest,
 		}
 	}
 
-	return nil
+	return temp, nil
 }
 
-// CreateTransactionCategory ...
-// Creates a transaction category for the given budget
-func CreateTransactionCategory(category models.BudgetTransactionCategoryCreationPayload, userID uuid.UUID) (*models.BudgetTransactionCategory, *errors.Error) {
+// MoveTransactionCategory ...
+// Moves a transaction category, along with everything below it,
+// under another category of its budget or to the top level.
+// A category can't be moved under one of its own descendants
+func MoveTransactionCategory(ctx context.Context, payload models.BudgetTransactionCategoryMovePayload, userID uuid.UUID) (*models.BudgetTransactionCategory, *errors.Error) {
+	category, err := store.GetTransactionCategory(ctx, payload.BudgetTransactionCategoryID)
 
-	if !roleService.IsUserAdmin(category.BudgetID, userID) && !roleService.IsUserOwner(category.BudgetID, userID) {
+	if err == sql.ErrNoRows {
 		return nil, &errors.Error{
-			StatusCode: http.StatusForbidden,
-			Message:    "You are not authorized to create a transaction category for the given budget",
+			Message:    "Transaction category not found",
+			StatusCode: http.StatusNotFound,
 		}
 	}
 
-	connection := database.GetConnection()
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
 
-	defer database.CloseConnection(connection)
+	if !roleService.IsUserAdmin(ctx, category.BudgetID, userID) && !roleService.IsUserOwner(ctx, category.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to move transaction categories of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
 
-	query := "INSERT INTO budget_transaction_categories (budget_id, category_name) VALUES ($1, $2) RETURNING budget_transaction_category_id, budget_id, category_name"
+	if payload.ParentCategoryID != nil {
+		categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, category.BudgetID)
 
-	stmt := database.PrepareStatement(connection, query)
+		if categoriesErr != nil {
+			return nil, categoriesErr
+		}
 
-	var temp models.BudgetTransactionCategory
+		tree := newCategoryTree(categories)
 
-	scanErr := stmt.QueryRow(category.BudgetID, category.CategoryName).Scan(&temp.BudgetTransactionCategoryID, &temp.BudgetID, &temp.CategoryName)
+		if _, ok := tree.categories[*payload.ParentCategoryID]; !ok {
+			return nil, &errors.Error{
+				Message:    "Parent category not found",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
 
-	if scanErr != nil {
+		if *payload.ParentCategoryID == category.BudgetTransactionCategoryID || tree.isDescendant(*payload.ParentCategoryID, category.BudgetTransactionCategoryID) {
+			return nil, &errors.Error{
+				Message:    "A category can't be moved under itself or one of its descendants",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+
+	if moveErr := store.SetTransactionCategoryParent(ctx, category.BudgetTransactionCategoryID, payload.ParentCategoryID); moveErr != nil {
 		return nil, &errors.Error{
-			Message:    scanErr.Error(),
-			StatusCode: http.StatusBadRequest,
+			Message:    moveErr.Error(),
+			StatusCode: http.StatusInternalServerError,
 		}
 	}
 
-	return &temp, nil
+	category.ParentCategoryID = payload.ParentCategoryID
+
+	return category, nil
 }
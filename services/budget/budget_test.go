@@ -0,0 +1,393 @@
+package budget
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// useMemoryStores ...
+// Points the budget, expense and role services at fresh in-memory
+// stores and returns the role store so tests can seed owners
+func useMemoryStores() *roleService.MemoryRoleStore {
+	roles := roleService.NewMemoryRoleStore()
+
+	budgets := NewMemoryBudgetStore()
+
+	SetStore(budgets)
+	expenseService.SetStore(expenseService.NewMemoryExpenseStore(budgets.GetTransactionCategories))
+	roleService.SetStore(roles)
+
+	return roles
+}
+
+func transaction(name string, date string, amount float64) models.Transaction {
+	return models.Transaction{
+		Transaction: plaid.Transaction{
+			ID:     uuid.New().String(),
+			Name:   name,
+			Date:   date,
+			Amount: amount,
+		},
+	}
+}
+
+func day(date string) time.Time {
+	parsed, err := time.Parse("2006-01-02", date)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
+
+func TestCalculatedBudgetExpenseSummary(t *testing.T) {
+	food := models.BudgetTransactionCategory{BudgetTransactionCategoryID: uuid.New(), CategoryName: "Food"}
+	groceries := models.BudgetTransactionCategory{BudgetTransactionCategoryID: uuid.New(), CategoryName: "Groceries", ParentCategoryID: &food.BudgetTransactionCategoryID}
+	travel := models.BudgetTransactionCategory{BudgetTransactionCategoryID: uuid.New(), CategoryName: "Travel"}
+	tree := newCategoryTree([]models.BudgetTransactionCategory{food, groceries, travel})
+
+	eating := models.Expense{ExpenseID: uuid.New(), ExpenseName: "Eating", ExpenseChargeCycle: models.ExpenseChargeCycle{Unit: "monthly", Days: 30}}
+	trips := models.Expense{ExpenseID: uuid.New(), ExpenseName: "Trips", ExpenseChargeCycle: models.ExpenseChargeCycle{Unit: "weekly", Days: 7}}
+
+	res := map[string]models.ExpenseCategorySummary{
+		"Food": {CategoryName: "Food", Transactions: []models.Transaction{
+			transaction("Diner", "2021-03-02", 20),
+			transaction("Diner", "2021-02-27", 15),
+		}},
+		"Groceries": {CategoryName: "Groceries", Transactions: []models.Transaction{
+			transaction("Market", "2021-03-10", 50),
+			transaction("Market refund", "2021-03-12", -10),
+		}},
+		"Travel": {CategoryName: "Travel", Transactions: []models.Transaction{
+			transaction("Airline", "2021-03-08", 300),
+			transaction("Airline", "2021-03-20", 100),
+		}},
+		"Uncategorized": {CategoryName: "Uncategorized", Transactions: []models.Transaction{
+			transaction("Unknown", "2021-03-05", 7),
+			transaction("Unknown", "2021-04-01", 9),
+		}},
+	}
+
+	expenseCategories := map[uuid.UUID][]string{
+		eating.ExpenseID: {"Food", "Groceries"},
+		trips.ExpenseID:  {"Travel"},
+	}
+
+	periods := map[uuid.UUID]expensePeriod{
+		eating.ExpenseID: {start: day("2021-03-01"), end: day("2021-04-01"), limit: 200},
+		trips.ExpenseID:  {start: day("2021-03-08"), end: day("2021-03-15"), limit: 250},
+	}
+
+	carried := map[uuid.UUID]float64{eating.ExpenseID: 25}
+	window := expensePeriod{start: day("2021-03-01"), end: day("2021-04-01")}
+
+	summary, rollups, uncategorized := calculatedBudgetExpenseSummaryUsingTransactionsAndExpenses(
+		[]models.Expense{eating, trips},
+		res,
+		tree,
+		expenseCategories,
+		periods,
+		carried,
+		window,
+	)
+
+	expected := []struct {
+		name      string
+		limit     float64
+		carried   float64
+		available float64
+		current   float64
+		periodEnd string
+		counted   []int
+	}{
+		{name: "Eating", limit: 200, carried: 25, available: 225, current: 60, periodEnd: "2021-03-31", counted: []int{1, 2}},
+		{name: "Trips", limit: 250, available: 250, current: 300, periodEnd: "2021-03-14", counted: []int{1}},
+	}
+
+	if len(summary) != len(expected) {
+		t.Fatalf("got %d expense summaries, want %d", len(summary), len(expected))
+	}
+
+	for i, want := range expected {
+		got := summary[i]
+
+		if got.ExpenseName != want.name || got.ExpenseLimit != want.limit || got.CarriedOver != want.carried ||
+			got.Available != want.available || got.CurrentExpense != want.current || got.PeriodEnd != want.periodEnd {
+			t.Errorf("summary[%d] = %+v, want %+v", i, got, want)
+		}
+
+		if len(got.ExpenseCategories) != len(want.counted) {
+			t.Fatalf("summary[%d] has %d categories, want %d", i, len(got.ExpenseCategories), len(want.counted))
+		}
+
+		for j, count := range want.counted {
+			if len(got.ExpenseCategories[j].Transactions) != count {
+				t.Errorf("summary[%d] category %s counted %d transactions, want %d", i, got.ExpenseCategories[j].CategoryName, len(got.ExpenseCategories[j].Transactions), count)
+			}
+		}
+	}
+
+	if uncategorized != 7 {
+		t.Errorf("uncategorized total = %v, want 7", uncategorized)
+	}
+
+	// Roots are sorted by name, children roll up into their parent
+	if len(rollups) != 2 || rollups[0].CategoryName != "Food" || rollups[1].CategoryName != "Travel" {
+		t.Fatalf("rollups = %+v, want Food and Travel roots", rollups)
+	}
+
+	if rollups[0].OwnTotal != 20 || rollups[0].Total != 60 || rollups[0].TransactionCount != 3 {
+		t.Errorf("Food rollup = %+v, want own 20, total 60 over 3 transactions", rollups[0])
+	}
+
+	if len(rollups[0].Children) != 1 || rollups[0].Children[0].Total != 40 {
+		t.Errorf("Groceries rollup = %+v, want a child totalling 40", rollups[0].Children)
+	}
+
+	if rollups[1].Total != 400 || rollups[1].TransactionCount != 2 {
+		t.Errorf("Travel rollup = %+v, want 400 over 2 transactions", rollups[1])
+	}
+}
+
+func TestDeleteBudget(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	adminID := uuid.New()
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		wantStatus int
+	}{
+		{name: "owner", userID: ownerID},
+		{name: "admin", userID: adminID, wantStatus: http.StatusUnauthorized},
+		{name: "stranger", userID: uuid.New(), wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			roles := useMemoryStores()
+
+			budget, createErr := CreateBudget(ctx, ownerID, "Household")
+
+			if createErr != nil {
+				t.Fatal(createErr.Message)
+			}
+
+			roles.SetBudgetOwner(budget.BudgetID, ownerID)
+
+			if err := roles.AddUserRole(ctx, budget.BudgetID, adminID, "Full Rights"); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := store.CreateTransactionSource(ctx, models.BudgetTransactionSourceCreationPayload{
+				BudgetID:          budget.BudgetID,
+				ExternalAccountID: uuid.New(),
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := expenseService.AddExpenseToBudget(ctx, &models.AddExpensePayload{
+				BudgetID:           budget.BudgetID,
+				ExpenseName:        "Groceries",
+				ExpenseValue:       400,
+				ExpenseChargeCycle: models.ExpenseChargeCycle{Unit: "monthly"},
+			}, ownerID); err != nil {
+				t.Fatal(err.Message)
+			}
+
+			categories, _ := store.GetTransactionCategories(ctx, budget.BudgetID)
+
+			if len(categories) == 0 {
+				t.Fatal("budget was created without categories")
+			}
+
+			if err := store.CreateCategoryTransaction(ctx, models.BudgetTransactionCategoryTransaction{
+				BudgetTransactionCategoryID: categories[0].BudgetTransactionCategoryID,
+				TransactionName:             "Corner Market",
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			deleteErr := DeleteBudget(ctx, budget.BudgetID, test.userID)
+
+			if test.wantStatus != 0 {
+				if deleteErr == nil || deleteErr.StatusCode != test.wantStatus {
+					t.Fatalf("DeleteBudget() = %v, want status %d", deleteErr, test.wantStatus)
+				}
+
+				if _, err := store.GetBudget(ctx, budget.BudgetID); err != nil {
+					t.Fatalf("budget was deleted by an unauthorized user: %v", err)
+				}
+
+				return
+			}
+
+			if deleteErr != nil {
+				t.Fatalf("DeleteBudget() = %s", deleteErr.Message)
+			}
+
+			if _, err := store.GetBudget(ctx, budget.BudgetID); err == nil {
+				t.Error("budget still exists")
+			}
+
+			if sources, _ := store.GetTransactionSources(ctx, budget.BudgetID); len(sources) != 0 {
+				t.Errorf("%d transaction sources left", len(sources))
+			}
+
+			if categories, _ := store.GetTransactionCategories(ctx, budget.BudgetID); len(categories) != 0 {
+				t.Errorf("%d transaction categories left", len(categories))
+			}
+
+			if tagged, _ := store.GetCategoryTransactions(ctx, budget.BudgetID); len(tagged) != 0 {
+				t.Errorf("%d tagged transactions left", len(tagged))
+			}
+
+			if mappings, _ := store.GetPlaidCategoryMappings(ctx, budget.BudgetID); len(mappings) != 0 {
+				t.Errorf("%d Plaid category mappings left", len(mappings))
+			}
+
+			if expenses, _ := expenseService.GetBudgetExpenses(ctx, budget.BudgetID); len(expenses) != 0 {
+				t.Errorf("%d expenses left", len(expenses))
+			}
+		})
+	}
+}
+
+func TestDeleteTransactionCategory(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	roles := useMemoryStores()
+
+	budget, createErr := CreateBudget(ctx, ownerID, "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	roles.SetBudgetOwner(budget.BudgetID, ownerID)
+
+	food, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Food"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groceries, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{
+		BudgetID:         budget.BudgetID,
+		CategoryName:     "Groceries",
+		ParentCategoryID: &food.BudgetTransactionCategoryID,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.CreateCategoryTransaction(ctx, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: food.BudgetTransactionCategoryID,
+		TransactionName:             "Diner",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The store refuses like the foreign keys do while
+	// the category is still referenced
+	if err := store.DeleteTransactionCategory(ctx, food.BudgetTransactionCategoryID); err == nil {
+		t.Fatal("store deleted a category that still has tagged transactions and subcategories")
+	}
+
+	if _, deleteErr := DeleteTransactionCategory(ctx, food.BudgetTransactionCategoryID, nil, false, ownerID); deleteErr != nil {
+		t.Fatalf("DeleteTransactionCategory() = %s", deleteErr.Message)
+	}
+
+	if _, err := store.GetTransactionCategory(ctx, food.BudgetTransactionCategoryID); err == nil {
+		t.Error("category still exists")
+	}
+
+	moved, err := store.GetTransactionCategory(ctx, groceries.BudgetTransactionCategoryID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if moved.ParentCategoryID != nil {
+		t.Errorf("subcategory parent = %v, want it moved to the top", moved.ParentCategoryID)
+	}
+
+	tagged, _ := store.GetCategoryTransactions(ctx, budget.BudgetID)
+
+	for _, categoryTransaction := range tagged {
+		if categoryTransaction.TransactionName == "Diner" {
+			t.Error("tagged transaction of the deleted category is left")
+		}
+	}
+}
+
+func TestCreateBudgetTransactionCategoryTransactionReplacesMerchant(t *testing.T) {
+	ctx := context.Background()
+	useMemoryStores()
+
+	budget, createErr := CreateBudget(ctx, uuid.New(), "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	defer forgetClassifier(budget.BudgetID)
+
+	food, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Food"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coffee, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Coffee"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tagErr := CreateBudgetTransactionCategoryTransaction(ctx, budget.BudgetID, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: food.BudgetTransactionCategoryID,
+		TransactionName:             "STARBUCKS #123",
+	}); tagErr != nil {
+		t.Fatal(tagErr.Message)
+	}
+
+	trained, err := budgetClassifier(ctx, budget.BudgetID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same merchant under another raw name replaces the first tag
+	if tagErr := CreateBudgetTransactionCategoryTransaction(ctx, budget.BudgetID, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: coffee.BudgetTransactionCategoryID,
+		TransactionName:             "Starbucks 456",
+	}); tagErr != nil {
+		t.Fatal(tagErr.Message)
+	}
+
+	tagged, _ := store.GetCategoryTransactions(ctx, budget.BudgetID)
+
+	if len(tagged) != 1 || tagged[0].TransactionName != "Starbucks 456" || tagged[0].BudgetTransactionCategoryID != coffee.BudgetTransactionCategoryID {
+		t.Fatalf("tagged transactions = %+v, want only Starbucks 456 in Coffee", tagged)
+	}
+
+	if _, learned := trained.classDocs[food.BudgetTransactionCategoryID]; learned {
+		t.Error("classifier still knows the replaced Food tag")
+	}
+
+	if trained.classDocs[coffee.BudgetTransactionCategoryID] != 1 {
+		t.Errorf("classifier Coffee documents = %d, want 1", trained.classDocs[coffee.BudgetTransactionCategoryID])
+	}
+}
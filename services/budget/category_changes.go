@@ -0,0 +1,349 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// RenameTransactionCategory ...
+// Renames a transaction category. Names are unique within a
+// budget, ignoring case, since expenses and tagged transactions
+// are matched to categories by name
+func RenameTransactionCategory(ctx context.Context, payload models.BudgetTransactionCategoryRenamePayload, userID uuid.UUID) (*models.TransactionCategoryChange, *errors.Error) {
+	category, getErr := getUserCategory(ctx, payload.BudgetTransactionCategoryID, userID)
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	categoryName := strings.TrimSpace(payload.CategoryName)
+
+	if categoryName == "" {
+		return nil, &errors.Error{
+			Message:    "Category name needs to be a non-empty string",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, category.BudgetID)
+
+	if categoriesErr != nil {
+		return nil, categoriesErr
+	}
+
+	for _, other := range categories {
+		if other.BudgetTransactionCategoryID != category.BudgetTransactionCategoryID && strings.EqualFold(other.CategoryName, categoryName) {
+			return nil, &errors.Error{
+				Message:    "Category named " + categoryName + " already exists",
+				StatusCode: http.StatusConflict,
+			}
+		}
+	}
+
+	change, previewErr := previewCategoryChange(ctx, *category, categories)
+
+	if previewErr != nil {
+		return nil, previewErr
+	}
+
+	change.NewCategoryName = categoryName
+	change.DryRun = payload.DryRun
+
+	if payload.DryRun {
+		return change, nil
+	}
+
+	// Expenses link categories by id, so only the category changes
+	if err := store.RenameTransactionCategory(ctx, category.BudgetTransactionCategoryID, categoryName); err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return change, nil
+}
+
+// MergeTransactionCategories ...
+// Merges a category into another one of its budget. Its tagged
+// transactions, rules, Plaid category mappings, expense links
+// and subcategories move to the target and it is deleted
+func MergeTransactionCategories(ctx context.Context, payload models.BudgetTransactionCategoryMergePayload, userID uuid.UUID) (*models.TransactionCategoryChange, *errors.Error) {
+	source, getErr := getUserCategory(ctx, payload.SourceCategoryID, userID)
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	target, targetErr := getTargetCategory(ctx, *source, payload.TargetCategoryID)
+
+	if targetErr != nil {
+		return nil, targetErr
+	}
+
+	return replaceTransactionCategory(ctx, *source, target, payload.DryRun)
+}
+
+// DeleteTransactionCategory ...
+// Deletes transaction category. With a category to reassign to,
+// everything referring to it moves there like in a merge. Without
+// one its tagged transactions, rules, Plaid category mappings and
+// expense links are removed and its subcategories move up to
+// the category's own parent
+func DeleteTransactionCategory(ctx context.Context, categoryID uuid.UUID, reassignTo *uuid.UUID, dryRun bool, userID uuid.UUID) (*models.TransactionCategoryChange, *errors.Error) {
+	category, getErr := getUserCategory(ctx, categoryID, userID)
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	var target *models.BudgetTransactionCategory
+
+	if reassignTo != nil {
+		reassignTarget, targetErr := getTargetCategory(ctx, *category, *reassignTo)
+
+		if targetErr != nil {
+			return nil, targetErr
+		}
+
+		target = reassignTarget
+	}
+
+	return replaceTransactionCategory(ctx, *category, target, dryRun)
+}
+
+// replaceTransactionCategory ...
+// Deletes a category after moving everything referring to
+// it to target, or removing it when target is nil
+func replaceTransactionCategory(
+	ctx context.Context,
+	category models.BudgetTransactionCategory,
+	target *models.BudgetTransactionCategory,
+	dryRun bool,
+) (*models.TransactionCategoryChange, *errors.Error) {
+	categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, category.BudgetID)
+
+	if categoriesErr != nil {
+		return nil, categoriesErr
+	}
+
+	change, previewErr := previewCategoryChange(ctx, category, categories)
+
+	if previewErr != nil {
+		return nil, previewErr
+	}
+
+	change.DryRun = dryRun
+
+	if target != nil {
+		change.TargetCategoryID = &target.BudgetTransactionCategoryID
+		change.TargetCategoryName = target.CategoryName
+	}
+
+	if dryRun {
+		return change, nil
+	}
+
+	tree := newCategoryTree(categories)
+	categoryID := category.BudgetTransactionCategoryID
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		newParentID := category.ParentCategoryID
+
+		if target != nil {
+			newParentID = &target.BudgetTransactionCategoryID
+
+			// A target below the category takes its place in the
+			// hierarchy first so its new children can't contain it
+			if tree.isDescendant(target.BudgetTransactionCategoryID, categoryID) {
+				if err := store.SetTransactionCategoryParent(ctx, target.BudgetTransactionCategoryID, category.ParentCategoryID); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, childID := range tree.children[categoryID] {
+			if target != nil && childID == target.BudgetTransactionCategoryID {
+				continue
+			}
+
+			if err := store.SetTransactionCategoryParent(ctx, childID, newParentID); err != nil {
+				return err
+			}
+		}
+
+		if target != nil {
+			if err := store.ReassignTransactionCategory(ctx, categoryID, target.BudgetTransactionCategoryID); err != nil {
+				return err
+			}
+
+			if err := expenseService.ReassignTransactionCategory(ctx, category, *target); err != nil {
+				return err
+			}
+		} else {
+			if err := DeleteTransactionCategoryTransactions(ctx, categoryID); err != nil {
+				return err
+			}
+
+			if err := expenseService.UnlinkTransactionCategory(ctx, category); err != nil {
+				return err
+			}
+		}
+
+		if err := store.DeleteTransactionCategory(ctx, categoryID); err != nil {
+			return &errors.Error{
+				Message:    err.Error(),
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+
+		database.AfterCommit(ctx, func() {
+			forgetCategoryClassifiers(categoryID)
+		})
+
+		return nil
+	})
+
+	if txErr != nil {
+		return nil, toServiceError(txErr)
+	}
+
+	return change, nil
+}
+
+// previewCategoryChange ...
+// Counts the rows referring to a category
+func previewCategoryChange(ctx context.Context, category models.BudgetTransactionCategory, categories []models.BudgetTransactionCategory) (*models.TransactionCategoryChange, *errors.Error) {
+	categoryID := category.BudgetTransactionCategoryID
+
+	change := &models.TransactionCategoryChange{
+		BudgetTransactionCategoryID: categoryID,
+		CategoryName:                category.CategoryName,
+	}
+
+	tagged, err := store.GetCategoryTransactions(ctx, category.BudgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	for _, categoryTransaction := range tagged {
+		if categoryTransaction.BudgetTransactionCategoryID == categoryID {
+			change.TransactionMappings++
+		}
+	}
+
+	rules, err := store.GetCategorizationRules(ctx, category.BudgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.BudgetTransactionCategoryID == categoryID {
+			change.CategorizationRules++
+		}
+	}
+
+	mappings, err := store.GetPlaidCategoryMappings(ctx, category.BudgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	for _, mapping := range mappings {
+		if mapping.BudgetTransactionCategoryID == categoryID {
+			change.PlaidCategoryMappings++
+		}
+	}
+
+	expenseLinks, expenseLinksErr := expenseService.GetBudgetExpenseTransactionCategoryMappings(ctx, category.BudgetID)
+
+	if expenseLinksErr != nil {
+		return nil, expenseLinksErr
+	}
+
+	for _, link := range expenseLinks {
+		if link.BudgeTransactionCategoryID == categoryID {
+			change.ExpenseLinks++
+		}
+	}
+
+	for _, other := range categories {
+		if other.ParentCategoryID != nil && *other.ParentCategoryID == categoryID {
+			change.Subcategories++
+		}
+	}
+
+	return change, nil
+}
+
+// getUserCategory ...
+// Gets a transaction category of a budget the user administers
+func getUserCategory(ctx context.Context, categoryID uuid.UUID, userID uuid.UUID) (*models.BudgetTransactionCategory, *errors.Error) {
+	category, err := store.GetTransactionCategory(ctx, categoryID)
+
+	if err == sql.ErrNoRows {
+		return nil, &errors.Error{
+			Message:    "Transaction category not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if !roleService.IsUserAdmin(ctx, category.BudgetID, userID) && !roleService.IsUserOwner(ctx, category.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to change transaction categories of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	return category, nil
+}
+
+// getTargetCategory ...
+// Gets the category another category's rows are moved to,
+// which has to be a different one of the same budget
+func getTargetCategory(ctx context.Context, category models.BudgetTransactionCategory, targetID uuid.UUID) (*models.BudgetTransactionCategory, *errors.Error) {
+	if targetID == category.BudgetTransactionCategoryID {
+		return nil, &errors.Error{
+			Message:    "A category can't be merged into itself",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	target, err := store.GetTransactionCategory(ctx, targetID)
+
+	if err != nil || target.BudgetID != category.BudgetID {
+		return nil, &errors.Error{
+			Message:    "Target category not found",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return target, nil
+}
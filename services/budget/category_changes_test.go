@@ -0,0 +1,184 @@
+package budget
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+)
+
+// expenseCategories ...
+// Gets the categories each expense of a budget tracks by expense name
+func expenseCategories(t *testing.T, ctx context.Context, budgetID uuid.UUID) map[string][]string {
+	expenses, err := expenseService.GetBudgetExpenses(ctx, budgetID)
+
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	tracked := make(map[string][]string)
+
+	for _, expense := range expenses {
+		categories := append([]string{}, expense.ExpenseTransactionCategories...)
+		sort.Strings(categories)
+		tracked[expense.ExpenseName] = categories
+	}
+
+	return tracked
+}
+
+// categoryChangeBudget ...
+// Creates a budget with Lunch and Dinner categories, a Work
+// Lunches expense tracking Lunch, a Meals expense tracking
+// both and a transaction name tagged with Lunch
+func categoryChangeBudget(t *testing.T, ctx context.Context, ownerID uuid.UUID) (*models.Budget, *models.BudgetTransactionCategory, *models.BudgetTransactionCategory) {
+	roles := useMemoryStores()
+
+	budget, createErr := CreateBudget(ctx, ownerID, "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	roles.SetBudgetOwner(budget.BudgetID, ownerID)
+
+	lunch, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Lunch"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dinner, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Dinner"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, categories := range map[string][]string{
+		"Work Lunches": {"Lunch"},
+		"Meals":        {"Lunch", "Dinner"},
+	} {
+		if _, addErr := expenseService.AddExpenseToBudget(ctx, &models.AddExpensePayload{
+			BudgetID:                     budget.BudgetID,
+			ExpenseName:                  name,
+			ExpenseValue:                 200,
+			ExpenseChargeCycle:           models.ExpenseChargeCycle{Unit: "monthly"},
+			ExpenseTransactionCategories: categories,
+		}, ownerID); addErr != nil {
+			t.Fatal(addErr.Message)
+		}
+	}
+
+	if err := store.CreateCategoryTransaction(ctx, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: lunch.BudgetTransactionCategoryID,
+		TransactionName:             "Corner Deli",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return budget, lunch, dinner
+}
+
+func TestRenameTransactionCategory(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget, lunch, _ := categoryChangeBudget(t, ctx, ownerID)
+
+	preview, previewErr := RenameTransactionCategory(ctx, models.BudgetTransactionCategoryRenamePayload{
+		BudgetTransactionCategoryID: lunch.BudgetTransactionCategoryID,
+		CategoryName:                "Midday",
+		DryRun:                      true,
+	}, ownerID)
+
+	if previewErr != nil {
+		t.Fatal(previewErr.Message)
+	}
+
+	if !preview.DryRun || preview.NewCategoryName != "Midday" || preview.ExpenseLinks != 2 || preview.TransactionMappings != 1 {
+		t.Errorf("preview = %+v, want 2 expense links and 1 transaction mapping", preview)
+	}
+
+	if renamed, _ := store.GetTransactionCategory(ctx, lunch.BudgetTransactionCategoryID); renamed.CategoryName != "Lunch" {
+		t.Errorf("dry run renamed the category to %s", renamed.CategoryName)
+	}
+
+	if _, renameErr := RenameTransactionCategory(ctx, models.BudgetTransactionCategoryRenamePayload{
+		BudgetTransactionCategoryID: lunch.BudgetTransactionCategoryID,
+		CategoryName:                "dinner",
+	}, ownerID); renameErr == nil || renameErr.StatusCode != http.StatusConflict {
+		t.Errorf("renaming to another category's name = %v, want a conflict", renameErr)
+	}
+
+	if _, renameErr := RenameTransactionCategory(ctx, models.BudgetTransactionCategoryRenamePayload{
+		BudgetTransactionCategoryID: lunch.BudgetTransactionCategoryID,
+		CategoryName:                "Midday",
+	}, ownerID); renameErr != nil {
+		t.Fatal(renameErr.Message)
+	}
+
+	want := map[string][]string{
+		"Work Lunches": {"Midday"},
+		"Meals":        {"Dinner", "Midday"},
+	}
+
+	if got := expenseCategories(t, ctx, budget.BudgetID); !reflect.DeepEqual(got, want) {
+		t.Errorf("expense categories = %v, want %v", got, want)
+	}
+}
+
+func TestMergeTransactionCategories(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget, lunch, dinner := categoryChangeBudget(t, ctx, ownerID)
+	before := expenseCategories(t, ctx, budget.BudgetID)
+
+	preview, previewErr := MergeTransactionCategories(ctx, models.BudgetTransactionCategoryMergePayload{
+		SourceCategoryID: lunch.BudgetTransactionCategoryID,
+		TargetCategoryID: dinner.BudgetTransactionCategoryID,
+		DryRun:           true,
+	}, ownerID)
+
+	if previewErr != nil {
+		t.Fatal(previewErr.Message)
+	}
+
+	if !preview.DryRun || preview.TargetCategoryName != "Dinner" || preview.ExpenseLinks != 2 || preview.TransactionMappings != 1 {
+		t.Errorf("preview = %+v, want 2 expense links and 1 transaction mapping", preview)
+	}
+
+	if got := expenseCategories(t, ctx, budget.BudgetID); !reflect.DeepEqual(got, before) {
+		t.Errorf("dry run changed expense categories to %v", got)
+	}
+
+	if _, mergeErr := MergeTransactionCategories(ctx, models.BudgetTransactionCategoryMergePayload{
+		SourceCategoryID: lunch.BudgetTransactionCategoryID,
+		TargetCategoryID: dinner.BudgetTransactionCategoryID,
+	}, ownerID); mergeErr != nil {
+		t.Fatal(mergeErr.Message)
+	}
+
+	// Meals tracked both and keeps a single link
+	want := map[string][]string{
+		"Work Lunches": {"Dinner"},
+		"Meals":        {"Dinner"},
+	}
+
+	if got := expenseCategories(t, ctx, budget.BudgetID); !reflect.DeepEqual(got, want) {
+		t.Errorf("expense categories = %v, want %v", got, want)
+	}
+
+	tagged, _ := store.GetCategoryTransactions(ctx, budget.BudgetID)
+
+	if len(tagged) != 1 || tagged[0].BudgetTransactionCategoryID != dinner.BudgetTransactionCategoryID {
+		t.Errorf("tagged transactions = %+v, want Corner Deli moved to Dinner", tagged)
+	}
+
+	if _, err := store.GetTransactionCategory(ctx, lunch.BudgetTransactionCategoryID); err == nil {
+		t.Error("merged category still exists")
+	}
+}
@@ -0,0 +1,140 @@
+package budget
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// categoryTree ...
+// The transaction categories of a budget arranged by parent.
+// Categories whose parent is missing are treated as top level
+type categoryTree struct {
+	categories map[uuid.UUID]models.BudgetTransactionCategory
+	children   map[uuid.UUID][]uuid.UUID
+	roots      []uuid.UUID
+	byName     map[string]uuid.UUID
+}
+
+func newCategoryTree(categories []models.BudgetTransactionCategory) *categoryTree {
+	tree := &categoryTree{
+		categories: make(map[uuid.UUID]models.BudgetTransactionCategory, len(categories)),
+		children:   make(map[uuid.UUID][]uuid.UUID),
+		roots:      make([]uuid.UUID, 0),
+		byName:     make(map[string]uuid.UUID, len(categories)),
+	}
+
+	sorted := make([]models.BudgetTransactionCategory, len(categories))
+	copy(sorted, categories)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].CategoryName) < strings.ToLower(sorted[j].CategoryName)
+	})
+
+	for _, category := range sorted {
+		tree.categories[category.BudgetTransactionCategoryID] = category
+		tree.byName[category.CategoryName] = category.BudgetTransactionCategoryID
+	}
+
+	for _, category := range sorted {
+		parentID := category.ParentCategoryID
+
+		if parentID == nil || *parentID == category.BudgetTransactionCategoryID {
+			tree.roots = append(tree.roots, category.BudgetTransactionCategoryID)
+			continue
+		}
+
+		if _, ok := tree.categories[*parentID]; !ok {
+			tree.roots = append(tree.roots, category.BudgetTransactionCategoryID)
+			continue
+		}
+
+		tree.children[*parentID] = append(tree.children[*parentID], category.BudgetTransactionCategoryID)
+	}
+
+	return tree
+}
+
+// descendants ...
+// A category followed by every category below it, depth first
+func (t *categoryTree) descendants(categoryID uuid.UUID) []models.BudgetTransactionCategory {
+	found := make([]models.BudgetTransactionCategory, 0)
+	visited := make(map[uuid.UUID]bool)
+
+	var walk func(id uuid.UUID)
+
+	walk = func(id uuid.UUID) {
+		category, ok := t.categories[id]
+
+		if !ok || visited[id] {
+			return
+		}
+
+		visited[id] = true
+		found = append(found, category)
+
+		for _, childID := range t.children[id] {
+			walk(childID)
+		}
+	}
+
+	walk(categoryID)
+
+	return found
+}
+
+// isDescendant ...
+// Whether a category sits anywhere below another one
+func (t *categoryTree) isDescendant(categoryID uuid.UUID, ancestorID uuid.UUID) bool {
+	for _, category := range t.descendants(ancestorID) {
+		if category.BudgetTransactionCategoryID == categoryID && categoryID != ancestorID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rollups ...
+// Rolls spending per category up the hierarchy
+func (t *categoryTree) rollups(totals map[uuid.UUID]float64, counts map[uuid.UUID]int) []models.CategoryRollup {
+	visited := make(map[uuid.UUID]bool)
+
+	var rollup func(id uuid.UUID) models.CategoryRollup
+
+	rollup = func(id uuid.UUID) models.CategoryRollup {
+		visited[id] = true
+
+		node := models.CategoryRollup{
+			BudgetTransactionCategoryID: id,
+			CategoryName:                t.categories[id].CategoryName,
+			OwnTotal:                    totals[id],
+			Total:                       totals[id],
+			TransactionCount:            counts[id],
+			Children:                    make([]models.CategoryRollup, 0),
+		}
+
+		for _, childID := range t.children[id] {
+			if visited[childID] {
+				continue
+			}
+
+			child := rollup(childID)
+			node.Total += child.Total
+			node.TransactionCount += child.TransactionCount
+			node.Children = append(node.Children, child)
+		}
+
+		return node
+	}
+
+	rollups := make([]models.CategoryRollup, 0, len(t.roots))
+
+	for _, rootID := range t.roots {
+		rollups = append(rollups, rollup(rootID))
+	}
+
+	return rollups
+}
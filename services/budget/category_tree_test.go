@@ -0,0 +1,272 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// treeBudget ...
+// Creates a budget owned by the given user
+func treeBudget(t *testing.T, ctx context.Context, ownerID uuid.UUID) *models.Budget {
+	t.Helper()
+
+	roles := useMemoryStores()
+	budget, createErr := CreateBudget(ctx, ownerID, "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	roles.SetBudgetOwner(budget.BudgetID, ownerID)
+
+	return budget
+}
+
+// subcategory ...
+// Creates a category under the given parent through the service
+func subcategory(t *testing.T, ctx context.Context, ownerID uuid.UUID, budgetID uuid.UUID, name string, parentID *uuid.UUID) *models.BudgetTransactionCategory {
+	t.Helper()
+
+	category, createErr := CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{
+		BudgetID:         budgetID,
+		CategoryName:     name,
+		ParentCategoryID: parentID,
+	}, ownerID)
+
+	if createErr != nil {
+		t.Fatalf("CreateTransactionCategory(%s) = %s", name, createErr.Message)
+	}
+
+	return category
+}
+
+// parentOf ...
+// The stored parent of a category
+func parentOf(t *testing.T, ctx context.Context, categoryID uuid.UUID) *uuid.UUID {
+	t.Helper()
+
+	category, err := store.GetTransactionCategory(ctx, categoryID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return category.ParentCategoryID
+}
+
+func TestMoveTransactionCategoryRejectsCycles(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget := treeBudget(t, ctx, ownerID)
+
+	food := subcategory(t, ctx, ownerID, budget.BudgetID, "Food", nil)
+	supermarket := subcategory(t, ctx, ownerID, budget.BudgetID, "Supermarket", &food.BudgetTransactionCategoryID)
+	organic := subcategory(t, ctx, ownerID, budget.BudgetID, "Organic", &supermarket.BudgetTransactionCategoryID)
+
+	for _, parent := range []*models.BudgetTransactionCategory{food, supermarket, organic} {
+		_, moveErr := MoveTransactionCategory(ctx, models.BudgetTransactionCategoryMovePayload{
+			BudgetTransactionCategoryID: food.BudgetTransactionCategoryID,
+			ParentCategoryID:            &parent.BudgetTransactionCategoryID,
+		}, ownerID)
+
+		if moveErr == nil || moveErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("moving Food under %s = %v, want a bad request", parent.CategoryName, moveErr)
+		}
+	}
+
+	if parentID := parentOf(t, ctx, food.BudgetTransactionCategoryID); parentID != nil {
+		t.Errorf("Food parent = %v after rejected moves, want it still at the top", parentID)
+	}
+
+	// Moving the other way round is fine
+	if _, moveErr := MoveTransactionCategory(ctx, models.BudgetTransactionCategoryMovePayload{
+		BudgetTransactionCategoryID: organic.BudgetTransactionCategoryID,
+		ParentCategoryID:            &food.BudgetTransactionCategoryID,
+	}, ownerID); moveErr != nil {
+		t.Fatalf("MoveTransactionCategory() = %s", moveErr.Message)
+	}
+
+	if parentID := parentOf(t, ctx, organic.BudgetTransactionCategoryID); parentID == nil || *parentID != food.BudgetTransactionCategoryID {
+		t.Errorf("Organic parent = %v, want Food", parentID)
+	}
+}
+
+func TestTransactionCategoryParentFromAnotherBudget(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	roles := useMemoryStores()
+
+	household, createErr := CreateBudget(ctx, ownerID, "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	travel, createErr := CreateBudget(ctx, ownerID, "Travel")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	roles.SetBudgetOwner(household.BudgetID, ownerID)
+	roles.SetBudgetOwner(travel.BudgetID, ownerID)
+
+	food := subcategory(t, ctx, ownerID, household.BudgetID, "Food", nil)
+	flights := subcategory(t, ctx, ownerID, travel.BudgetID, "Flights", nil)
+
+	_, createErr = CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{
+		BudgetID:         travel.BudgetID,
+		CategoryName:     "Snacks",
+		ParentCategoryID: &food.BudgetTransactionCategoryID,
+	}, ownerID)
+
+	if createErr == nil || createErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("creating under another budget's category = %v, want a bad request", createErr)
+	}
+
+	_, moveErr := MoveTransactionCategory(ctx, models.BudgetTransactionCategoryMovePayload{
+		BudgetTransactionCategoryID: flights.BudgetTransactionCategoryID,
+		ParentCategoryID:            &food.BudgetTransactionCategoryID,
+	}, ownerID)
+
+	if moveErr == nil || moveErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("moving under another budget's category = %v, want a bad request", moveErr)
+	}
+
+	if parentID := parentOf(t, ctx, flights.BudgetTransactionCategoryID); parentID != nil {
+		t.Errorf("Flights parent = %v, want it still at the top", parentID)
+	}
+}
+
+func TestCategoryTreeArbitraryDepth(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget := treeBudget(t, ctx, ownerID)
+
+	const depth = 40
+
+	chain := make([]*models.BudgetTransactionCategory, 0, depth)
+	var parentID *uuid.UUID
+
+	for level := 0; level < depth; level++ {
+		category := subcategory(t, ctx, ownerID, budget.BudgetID, fmt.Sprintf("Level %d", level), parentID)
+		chain = append(chain, category)
+		parentID = &category.BudgetTransactionCategoryID
+	}
+
+	top, leaf := chain[0], chain[depth-1]
+
+	// The cycle check reaches the bottom of the chain
+	_, moveErr := MoveTransactionCategory(ctx, models.BudgetTransactionCategoryMovePayload{
+		BudgetTransactionCategoryID: top.BudgetTransactionCategoryID,
+		ParentCategoryID:            &leaf.BudgetTransactionCategoryID,
+	}, ownerID)
+
+	if moveErr == nil || moveErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("moving the top under the deepest category = %v, want a bad request", moveErr)
+	}
+
+	categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, budget.BudgetID)
+
+	if categoriesErr != nil {
+		t.Fatal(categoriesErr.Message)
+	}
+
+	tree := newCategoryTree(categories)
+
+	if got := len(tree.descendants(top.BudgetTransactionCategoryID)); got != depth {
+		t.Errorf("descendants of the top = %d, want %d", got, depth)
+	}
+
+	rollups := tree.rollups(
+		map[uuid.UUID]float64{leaf.BudgetTransactionCategoryID: 25},
+		map[uuid.UUID]int{leaf.BudgetTransactionCategoryID: 1},
+	)
+
+	var node *models.CategoryRollup
+
+	for i := range rollups {
+		if rollups[i].BudgetTransactionCategoryID == top.BudgetTransactionCategoryID {
+			node = &rollups[i]
+		}
+	}
+
+	for level := 0; level < depth; level++ {
+		if node == nil || node.BudgetTransactionCategoryID != chain[level].BudgetTransactionCategoryID {
+			t.Fatalf("level %d missing from the rollups", level)
+		}
+
+		if node.Total != 25 || node.TransactionCount != 1 {
+			t.Errorf("level %d rollup = %v over %d transactions, want 25 over 1", level, node.Total, node.TransactionCount)
+		}
+
+		if len(node.Children) == 0 {
+			node = nil
+			continue
+		}
+
+		node = &node.Children[0]
+	}
+}
+
+func TestCategoryTreeToleratesStoredCycles(t *testing.T) {
+	first, second := uuid.New(), uuid.New()
+
+	tree := newCategoryTree([]models.BudgetTransactionCategory{
+		{BudgetTransactionCategoryID: first, CategoryName: "First", ParentCategoryID: &second},
+		{BudgetTransactionCategoryID: second, CategoryName: "Second", ParentCategoryID: &first},
+	})
+
+	if got := len(tree.descendants(first)); got != 2 {
+		t.Errorf("descendants = %d, want 2", got)
+	}
+
+	if !tree.isDescendant(second, first) {
+		t.Error("Second isn't below First")
+	}
+
+	if tree.isDescendant(first, first) {
+		t.Error("a category counts as its own descendant")
+	}
+}
+
+func TestDeleteTransactionCategoryReparentsChildren(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget := treeBudget(t, ctx, ownerID)
+
+	food := subcategory(t, ctx, ownerID, budget.BudgetID, "Food", nil)
+	supermarket := subcategory(t, ctx, ownerID, budget.BudgetID, "Supermarket", &food.BudgetTransactionCategoryID)
+	organic := subcategory(t, ctx, ownerID, budget.BudgetID, "Organic", &supermarket.BudgetTransactionCategoryID)
+	bulk := subcategory(t, ctx, ownerID, budget.BudgetID, "Bulk", &supermarket.BudgetTransactionCategoryID)
+
+	// Deleting a category in the middle hands its children to its parent
+	if _, deleteErr := DeleteTransactionCategory(ctx, supermarket.BudgetTransactionCategoryID, nil, false, ownerID); deleteErr != nil {
+		t.Fatalf("DeleteTransactionCategory() = %s", deleteErr.Message)
+	}
+
+	for _, child := range []*models.BudgetTransactionCategory{organic, bulk} {
+		if parentID := parentOf(t, ctx, child.BudgetTransactionCategoryID); parentID == nil || *parentID != food.BudgetTransactionCategoryID {
+			t.Errorf("%s parent = %v, want Food", child.CategoryName, parentID)
+		}
+	}
+
+	// Reassigning to a descendant moves it into the deleted
+	// category's place and its siblings under it
+	if _, deleteErr := DeleteTransactionCategory(ctx, food.BudgetTransactionCategoryID, &organic.BudgetTransactionCategoryID, false, ownerID); deleteErr != nil {
+		t.Fatalf("DeleteTransactionCategory() = %s", deleteErr.Message)
+	}
+
+	if parentID := parentOf(t, ctx, organic.BudgetTransactionCategoryID); parentID != nil {
+		t.Errorf("Organic parent = %v, want it moved to the top", parentID)
+	}
+
+	if parentID := parentOf(t, ctx, bulk.BudgetTransactionCategoryID); parentID == nil || *parentID != organic.BudgetTransactionCategoryID {
+		t.Errorf("Bulk parent = %v, want Organic", parentID)
+	}
+}
@@ -0,0 +1,299 @@
+package budget
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	merchantService "github.com/lakshay35/finlit-backend/services/merchant"
+)
+
+// classifierTTL bounds how long a budget's classifier is kept
+// before it is rebuilt from the store, so choices made through
+// other instances of the API are picked up eventually
+const classifierTTL = time.Hour
+
+// maxSuggestions is the number of categories suggested per transaction
+const maxSuggestions = 3
+
+// classifier ...
+// Naive Bayes over the tokens of the transactions users tagged
+// with a category by hand. Each category is a class and each
+// tagged transaction name one document
+type classifier struct {
+	mutex       sync.Mutex
+	builtAt     time.Time
+	documents   int
+	classDocs   map[uuid.UUID]int
+	classTokens map[uuid.UUID]int
+	tokenCounts map[uuid.UUID]map[string]int
+	vocabulary  map[string]int
+}
+
+var (
+	classifiersMutex sync.Mutex
+	classifiers      = make(map[uuid.UUID]*classifier)
+)
+
+// budgetClassifier ...
+// Gets the classifier of a budget, training it from the
+// budget's tagged transactions when there is none yet
+func budgetClassifier(ctx context.Context, budgetID uuid.UUID) (*classifier, error) {
+	classifiersMutex.Lock()
+	cached, ok := classifiers[budgetID]
+	classifiersMutex.Unlock()
+
+	if ok && time.Since(cached.builtAt) < classifierTTL {
+		return cached, nil
+	}
+
+	tagged, err := store.GetCategoryTransactions(ctx, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	trained := newClassifier()
+
+	for _, categoryTransaction := range tagged {
+		trained.learn(categoryTransaction, 1)
+	}
+
+	classifiersMutex.Lock()
+	classifiers[budgetID] = trained
+	classifiersMutex.Unlock()
+
+	return trained, nil
+}
+
+// learnCategoryTransaction ...
+// Retrains a budget's classifier on a transaction name users
+// tagged, forgetting the categories it was tagged with before.
+// Budgets without a classifier yet train on it when first used
+func learnCategoryTransaction(
+	budgetID uuid.UUID,
+	replaced []models.BudgetTransactionCategoryTransaction,
+	categoryTransaction models.BudgetTransactionCategoryTransaction,
+) {
+	classifiersMutex.Lock()
+	cached, ok := classifiers[budgetID]
+	classifiersMutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	for _, previous := range replaced {
+		cached.learn(previous, -1)
+	}
+
+	cached.learn(categoryTransaction, 1)
+}
+
+// forgetClassifier ...
+// Drops a budget's classifier so it's trained anew when next used
+func forgetClassifier(budgetID uuid.UUID) {
+	classifiersMutex.Lock()
+	defer classifiersMutex.Unlock()
+
+	delete(classifiers, budgetID)
+}
+
+// forgetCategoryClassifiers ...
+// Drops the classifiers that learned a category
+func forgetCategoryClassifiers(budgetTransactionCategoryID uuid.UUID) {
+	classifiersMutex.Lock()
+	defer classifiersMutex.Unlock()
+
+	for budgetID, cached := range classifiers {
+		cached.mutex.Lock()
+		_, learned := cached.classDocs[budgetTransactionCategoryID]
+		cached.mutex.Unlock()
+
+		if learned {
+			delete(classifiers, budgetID)
+		}
+	}
+}
+
+func newClassifier() *classifier {
+	return &classifier{
+		builtAt:     time.Now(),
+		classDocs:   make(map[uuid.UUID]int),
+		classTokens: make(map[uuid.UUID]int),
+		tokenCounts: make(map[uuid.UUID]map[string]int),
+		vocabulary:  make(map[string]int),
+	}
+}
+
+// learn ...
+// Adds a tagged transaction to the counts, or takes it
+// back out again when weight is negative
+func (c *classifier) learn(categoryTransaction models.BudgetTransactionCategoryTransaction, weight int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	class := categoryTransaction.BudgetTransactionCategoryID
+	tokens := transactionTokens(
+		categoryTransaction.TransactionName,
+		merchantService.Normalize(categoryTransaction.TransactionName),
+		categoryTransaction.PlaidCategory,
+	)
+
+	if weight < 0 && c.classDocs[class] == 0 {
+		return
+	}
+
+	c.documents += weight
+	c.classDocs[class] += weight
+
+	if c.tokenCounts[class] == nil {
+		c.tokenCounts[class] = make(map[string]int)
+	}
+
+	for _, token := range tokens {
+		c.tokenCounts[class][token] += weight
+		c.classTokens[class] += weight
+		c.vocabulary[token] += weight
+
+		if c.tokenCounts[class][token] <= 0 {
+			delete(c.tokenCounts[class], token)
+		}
+
+		if c.vocabulary[token] <= 0 {
+			delete(c.vocabulary, token)
+		}
+	}
+
+	if c.classDocs[class] <= 0 {
+		delete(c.classDocs, class)
+		delete(c.classTokens, class)
+		delete(c.tokenCounts, class)
+	}
+}
+
+// classes ...
+// The number of categories the classifier has learned
+func (c *classifier) classes() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.classDocs)
+}
+
+// suggest ...
+// Ranks the given categories for a transaction by their
+// posterior probability, which is reported as the confidence.
+// Categories the classifier hasn't learned aren't suggested.
+// Tagged transactions are only known by name, so the merchant
+// has to be cleaned up from the name rather than aliased
+func (c *classifier) suggest(tx models.Transaction, merchant string, categories []models.BudgetTransactionCategory) []models.CategorySuggestion {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	suggestions := make([]models.CategorySuggestion, 0)
+
+	if c.documents <= 0 {
+		return suggestions
+	}
+
+	tokens := transactionTokens(tx.Name, merchant, tx.Category)
+	scores := make([]float64, 0, len(categories))
+	vocabularySize := float64(len(c.vocabulary))
+
+	for _, category := range categories {
+		class := category.BudgetTransactionCategoryID
+		docs := c.classDocs[class]
+
+		if docs <= 0 {
+			continue
+		}
+
+		score := math.Log(float64(docs) / float64(c.documents))
+		denominator := float64(c.classTokens[class]) + vocabularySize
+
+		for _, token := range tokens {
+			// Tokens never seen with any category say nothing
+			// about which one fits, so they are left out
+			if c.vocabulary[token] == 0 {
+				continue
+			}
+
+			score += math.Log(float64(c.tokenCounts[class][token]+1) / denominator)
+		}
+
+		suggestions = append(suggestions, models.CategorySuggestion{
+			BudgetTransactionCategoryID: class,
+			CategoryName:                category.CategoryName,
+		})
+		scores = append(scores, score)
+	}
+
+	if len(suggestions) == 0 {
+		return suggestions
+	}
+
+	best := scores[0]
+
+	for _, score := range scores {
+		best = math.Max(best, score)
+	}
+
+	total := 0.0
+
+	for i := range scores {
+		scores[i] = math.Exp(scores[i] - best)
+		total += scores[i]
+	}
+
+	for i := range suggestions {
+		suggestions[i].Confidence = scores[i] / total
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+
+	return suggestions
+}
+
+// transactionTokens ...
+// The features of a transaction: the words of its name, its
+// merchant and every level of its Plaid category. Numbers like
+// store ids and card suffixes vary between otherwise identical
+// transactions, so words with digits in them are dropped
+func transactionTokens(name string, merchant string, plaidCategory []string) []string {
+	tokens := make([]string, 0)
+
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		if len(word) < 2 || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			continue
+		}
+
+		tokens = append(tokens, "name:"+word)
+	}
+
+	if merchant != "" {
+		tokens = append(tokens, "merchant:"+strings.ToLower(merchant))
+	}
+
+	for _, level := range plaidCategory {
+		tokens = append(tokens, "category:"+strings.ToLower(level))
+	}
+
+	return tokens
+}
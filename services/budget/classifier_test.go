@@ -0,0 +1,178 @@
+package budget
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// trainedClassifier ...
+// A classifier that learned two Green Grocer transactions
+// as Groceries and one Corner Coffee as Coffee
+func trainedClassifier() (*classifier, []models.BudgetTransactionCategory) {
+	categories := []models.BudgetTransactionCategory{
+		{BudgetTransactionCategoryID: uuid.New(), CategoryName: "Groceries"},
+		{BudgetTransactionCategoryID: uuid.New(), CategoryName: "Coffee"},
+	}
+
+	trained := newClassifier()
+
+	for _, tagged := range []models.BudgetTransactionCategoryTransaction{
+		{BudgetTransactionCategoryID: categories[0].BudgetTransactionCategoryID, TransactionName: "Green Grocer"},
+		{BudgetTransactionCategoryID: categories[0].BudgetTransactionCategoryID, TransactionName: "GREEN GROCER #12"},
+		{BudgetTransactionCategoryID: categories[1].BudgetTransactionCategoryID, TransactionName: "Corner Coffee"},
+	} {
+		trained.learn(tagged, 1)
+	}
+
+	return trained, categories
+}
+
+func TestClassifierSuggest(t *testing.T) {
+	trained, categories := trainedClassifier()
+
+	for _, test := range []struct {
+		name string
+		top  string
+	}{
+		{"Green Grocer 0042", "Groceries"},
+		{"CORNER COFFEE", "Coffee"},
+	} {
+		suggestions := trained.suggest(transaction(test.name, "2021-04-01", 10), test.name, categories)
+
+		if len(suggestions) != 2 || suggestions[0].CategoryName != test.top {
+			t.Errorf("suggest(%s) = %+v, want %s first", test.name, suggestions, test.top)
+			continue
+		}
+
+		total := suggestions[0].Confidence + suggestions[1].Confidence
+
+		if math.Abs(total-1) > 1e-9 || suggestions[0].Confidence <= suggestions[1].Confidence {
+			t.Errorf("suggest(%s) confidences = %+v, want them ranked and summing to 1", test.name, suggestions)
+		}
+
+		// Smoothing keeps a category that never saw the tokens possible
+		if suggestions[1].Confidence <= 0 {
+			t.Errorf("suggest(%s) ruled %s out", test.name, suggestions[1].CategoryName)
+		}
+	}
+}
+
+func TestClassifierSuggestUnseenTokens(t *testing.T) {
+	trained, categories := trainedClassifier()
+
+	// Tokens no category has seen leave only the priors
+	suggestions := trained.suggest(transaction("Quick Fuel", "2021-04-01", 40), "Quick Fuel", categories)
+
+	if len(suggestions) != 2 {
+		t.Fatalf("suggestions = %+v, want both categories", suggestions)
+	}
+
+	if math.Abs(suggestions[0].Confidence-2.0/3) > 1e-9 || math.Abs(suggestions[1].Confidence-1.0/3) > 1e-9 {
+		t.Errorf("confidences = %+v, want the priors 2/3 and 1/3", suggestions)
+	}
+}
+
+func TestClassifierWithoutTraining(t *testing.T) {
+	categories := []models.BudgetTransactionCategory{{BudgetTransactionCategoryID: uuid.New(), CategoryName: "Groceries"}}
+	empty := newClassifier()
+
+	if suggestions := empty.suggest(transaction("Green Grocer", "2021-04-01", 10), "Green Grocer", categories); suggestions == nil || len(suggestions) != 0 {
+		t.Errorf("untrained suggestions = %#v, want none", suggestions)
+	}
+
+	// Learning a transaction and taking it back empties it again
+	tagged := models.BudgetTransactionCategoryTransaction{BudgetTransactionCategoryID: categories[0].BudgetTransactionCategoryID, TransactionName: "Green Grocer"}
+	empty.learn(tagged, 1)
+	empty.learn(tagged, -1)
+	empty.learn(tagged, -1)
+
+	if empty.classes() != 0 || empty.documents != 0 || len(empty.vocabulary) != 0 {
+		t.Errorf("classifier after untraining = %d classes, %d documents, %d tokens, want none", empty.classes(), empty.documents, len(empty.vocabulary))
+	}
+
+	// Categories it never learned aren't suggested
+	trained, _ := trainedClassifier()
+
+	if suggestions := trained.suggest(transaction("Green Grocer", "2021-04-01", 10), "Green Grocer", categories); len(suggestions) != 0 {
+		t.Errorf("suggestions of unlearned categories = %+v, want none", suggestions)
+	}
+}
+
+func TestBudgetClassifierFollowsTagging(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	roles := useMemoryStores()
+
+	budget, createErr := CreateBudget(ctx, ownerID, "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	roles.SetBudgetOwner(budget.BudgetID, ownerID)
+	defer forgetClassifier(budget.BudgetID)
+
+	groceries, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Groceries"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fuel, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Fuel"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tagErr := CreateBudgetTransactionCategoryTransaction(ctx, budget.BudgetID, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: groceries.BudgetTransactionCategoryID,
+		TransactionName:             "Green Grocer",
+	}); tagErr != nil {
+		t.Fatal(tagErr.Message)
+	}
+
+	cached, err := budgetClassifier(ctx, budget.BudgetID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cached.classes() != 1 {
+		t.Fatalf("classifier learned %d categories, want 1", cached.classes())
+	}
+
+	// Tagging retrains the cached classifier in place
+	if tagErr := CreateBudgetTransactionCategoryTransaction(ctx, budget.BudgetID, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: fuel.BudgetTransactionCategoryID,
+		TransactionName:             "Quick Fuel",
+	}); tagErr != nil {
+		t.Fatal(tagErr.Message)
+	}
+
+	retrained, _ := budgetClassifier(ctx, budget.BudgetID)
+
+	if retrained != cached || retrained.classes() != 2 {
+		t.Fatalf("classifier after tagging = %p with %d categories, want %p with 2", retrained, retrained.classes(), cached)
+	}
+
+	categories := []models.BudgetTransactionCategory{*groceries, *fuel}
+
+	if suggestions := retrained.suggest(transaction("QUICK FUEL 88", "2021-04-01", 40), "Quick Fuel", categories); len(suggestions) == 0 || suggestions[0].CategoryName != "Fuel" {
+		t.Errorf("suggestions = %+v, want Fuel first", suggestions)
+	}
+
+	// Deleting a category it learned drops it for a rebuild
+	if _, deleteErr := DeleteTransactionCategory(ctx, fuel.BudgetTransactionCategoryID, nil, false, ownerID); deleteErr != nil {
+		t.Fatal(deleteErr.Message)
+	}
+
+	rebuilt, _ := budgetClassifier(ctx, budget.BudgetID)
+
+	if rebuilt == cached || rebuilt.classes() != 1 {
+		t.Errorf("classifier after deleting a category = %p with %d categories, want a new one with 1", rebuilt, rebuilt.classes())
+	}
+}
@@ -0,0 +1,320 @@
+package budget
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+)
+
+const (
+	// forecastHistoryPeriods is how many periods before the
+	// current one forecasts learn from
+	forecastHistoryPeriods = 3
+
+	// forecastHistoryDays is as far back as transactions are synced,
+	// periods starting before it would look like nothing was spent
+	forecastHistoryDays = 730
+
+	// forecastBandZ makes the forecast band cover about 80%
+	// of outcomes when daily spending is roughly normal
+	forecastBandZ = 1.28
+
+	// recurringAmountTolerance is how far a merchant's charges can
+	// stray from their median and still count as the same charge
+	recurringAmountTolerance = 0.2
+)
+
+// forecastHistory ...
+// The periods before the current one of an expense's
+// cycle that forecasts learn from, most recent first
+func forecastHistory(expense models.Expense, now time.Time) []expensePeriod {
+	oldest := now.AddDate(0, 0, -forecastHistoryDays)
+	history := make([]expensePeriod, 0, forecastHistoryPeriods)
+
+	for ago := 1; ago <= forecastHistoryPeriods; ago++ {
+		start, end := expenseService.PreviousChargeCyclePeriod(expense.ExpenseChargeCycle, now, ago)
+
+		if start.Before(oldest) {
+			break
+		}
+
+		history = append(history, expensePeriod{start: start, end: end})
+	}
+
+	return history
+}
+
+// forecastStartDate ...
+// The first day transactions are needed from
+// to forecast every expense. Empty without expenses
+func forecastStartDate(expenses []models.Expense, now time.Time) string {
+	startDate := ""
+
+	for _, expense := range expenses {
+		history := forecastHistory(expense, now)
+
+		if len(history) == 0 {
+			continue
+		}
+
+		if date := history[len(history)-1].start.Format("2006-01-02"); startDate == "" || date < startDate {
+			startDate = date
+		}
+	}
+
+	return startDate
+}
+
+// forecastExpenses ...
+// Forecasts the current period of every expense
+func forecastExpenses(
+	expenses []models.Expense,
+	summary []models.ExpenseSummary,
+	spending map[string]models.ExpenseCategorySummary,
+	expenseCategories map[uuid.UUID][]string,
+	periods map[uuid.UUID]expensePeriod,
+	now time.Time,
+) {
+	for i, expense := range expenses {
+		transactions := make([]models.Transaction, 0)
+
+		for _, category := range expenseCategories[expense.ExpenseID] {
+			transactions = append(transactions, spending[category].Transactions...)
+		}
+
+		summary[i].Forecast = forecastExpense(
+			transactions,
+			periods[expense.ExpenseID],
+			forecastHistory(expense, now),
+			summary[i].Available,
+			now,
+		)
+	}
+}
+
+// forecastExpense ...
+// Projects what an expense will have spent by the end of its
+// current period. Charges that recur in most earlier periods are
+// projected on their own, as what they usually come to if they
+// haven't been charged yet. Everything else is projected from the
+// pace so far, leaning on what earlier periods spent in total
+// while little of the period has passed
+func forecastExpense(
+	transactions []models.Transaction,
+	period expensePeriod,
+	history []expensePeriod,
+	available float64,
+	now time.Time,
+) *models.ExpenseForecast {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	totalDays := daysBetween(period.start, period.end)
+	elapsedDays := daysBetween(period.start, today) + 1
+
+	if elapsedDays > totalDays {
+		elapsedDays = totalDays
+	}
+
+	if elapsedDays < 1 {
+		elapsedDays = 1
+	}
+
+	recurring := recurringCharges(transactions, history)
+
+	actual := 0.0
+	spentSoFar := 0.0
+	charged := make(map[string]bool)
+	daily := make(map[string]float64)
+
+	for _, tx := range transactions {
+		if !period.contains(tx.Date) {
+			continue
+		}
+
+		actual += tx.Amount
+
+		if _, ok := recurring[merchantKey(tx.Merchant)]; ok {
+			charged[merchantKey(tx.Merchant)] = true
+			continue
+		}
+
+		spentSoFar += tx.Amount
+		daily[tx.Date] += tx.Amount
+	}
+
+	recurringDue := 0.0
+
+	for merchant, amount := range recurring {
+		if !charged[merchant] {
+			recurringDue += amount
+		}
+	}
+
+	// Spending outside recurring charges in each earlier
+	// period, and every day of them for the band
+	historyTotals := make([]float64, 0, len(history))
+	dailyAmounts := make([]float64, 0)
+
+	for _, past := range history {
+		total := 0.0
+		pastDaily := make(map[string]float64)
+
+		for _, tx := range transactions {
+			if !past.contains(tx.Date) {
+				continue
+			}
+
+			if _, ok := recurring[merchantKey(tx.Merchant)]; ok {
+				continue
+			}
+
+			total += tx.Amount
+			pastDaily[tx.Date] += tx.Amount
+		}
+
+		historyTotals = append(historyTotals, total)
+
+		for day := past.start; day.Before(past.end); day = day.AddDate(0, 0, 1) {
+			dailyAmounts = append(dailyAmounts, pastDaily[day.Format("2006-01-02")])
+		}
+	}
+
+	for day := period.start; day.Before(period.start.AddDate(0, 0, elapsedDays)); day = day.AddDate(0, 0, 1) {
+		dailyAmounts = append(dailyAmounts, daily[day.Format("2006-01-02")])
+	}
+
+	progress := float64(elapsedDays) / float64(totalDays)
+	projectedSpending := spentSoFar / progress
+
+	if len(historyTotals) > 0 {
+		projectedSpending = progress*projectedSpending + (1-progress)*mean(historyTotals)
+	}
+
+	if projectedSpending < spentSoFar {
+		projectedSpending = spentSoFar
+	}
+
+	projected := actual + projectedSpending - spentSoFar + recurringDue
+	spread := forecastBandZ * standardDeviation(dailyAmounts) * math.Sqrt(float64(totalDays-elapsedDays))
+
+	low := projected - spread
+
+	if floor := actual + recurringDue; low < floor {
+		low = floor
+	}
+
+	return &models.ExpenseForecast{
+		ProjectedTotal:     roundCents(projected),
+		Low:                roundCents(low),
+		High:               roundCents(projected + spread),
+		RecurringDue:       roundCents(recurringDue),
+		ProjectedOverUnder: roundCents(projected - available),
+		HistoryPeriods:     len(history),
+	}
+}
+
+// recurringCharges ...
+// What each merchant charged at about the same amount in most of
+// the earlier periods usually charges per period, keyed by
+// merchantKey. Needs at least two earlier periods
+func recurringCharges(transactions []models.Transaction, history []expensePeriod) map[string]float64 {
+	recurring := make(map[string]float64)
+
+	if len(history) < 2 {
+		return recurring
+	}
+
+	perPeriod := make(map[string][]float64)
+
+	for _, past := range history {
+		totals := make(map[string]float64)
+
+		for _, tx := range transactions {
+			if past.contains(tx.Date) && tx.Merchant != "" {
+				totals[merchantKey(tx.Merchant)] += tx.Amount
+			}
+		}
+
+		for merchant, total := range totals {
+			perPeriod[merchant] = append(perPeriod[merchant], total)
+		}
+	}
+
+	for merchant, totals := range perPeriod {
+		if len(totals)*2 <= len(history) {
+			continue
+		}
+
+		typical := median(totals)
+		similar := true
+
+		for _, total := range totals {
+			if math.Abs(total-typical) > recurringAmountTolerance*typical {
+				similar = false
+				break
+			}
+		}
+
+		if similar {
+			recurring[merchant] = typical
+		}
+	}
+
+	return recurring
+}
+
+// daysBetween ...
+// The number of days from start up to end
+func daysBetween(start time.Time, end time.Time) int {
+	return int(math.Round(end.Sub(start).Hours() / 24))
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	total := 0.0
+
+	for _, value := range values {
+		total += value
+	}
+
+	return total / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	middle := len(sorted) / 2
+
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+
+	return sorted[middle]
+}
+
+func standardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	average := mean(values)
+	variance := 0.0
+
+	for _, value := range values {
+		variance += (value - average) * (value - average)
+	}
+
+	return math.Sqrt(variance / float64(len(values)-1))
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
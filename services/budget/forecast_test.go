@@ -0,0 +1,190 @@
+package budget
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+func merchantTransaction(merchant string, date string, amount float64) models.Transaction {
+	tx := transaction(merchant, date, amount)
+	tx.Merchant = merchant
+
+	return tx
+}
+
+func TestForecastExpense(t *testing.T) {
+	april := expensePeriod{start: day("2021-04-01"), end: day("2021-05-01")}
+	history := []expensePeriod{
+		{start: day("2021-03-01"), end: day("2021-04-01")},
+		{start: day("2021-02-01"), end: day("2021-03-01")},
+		{start: day("2021-01-01"), end: day("2021-02-01")},
+	}
+
+	pastSpending := []models.Transaction{
+		merchantTransaction("Grocer", "2021-03-10", 300),
+		merchantTransaction("Grocer", "2021-02-10", 400),
+		merchantTransaction("Grocer", "2021-01-10", 500),
+		merchantTransaction("StreamFlix", "2021-03-05", 15),
+		merchantTransaction("StreamFlix", "2021-02-05", 15),
+		merchantTransaction("StreamFlix", "2021-01-05", 15),
+	}
+
+	tests := []struct {
+		name          string
+		transactions  []models.Transaction
+		history       []expensePeriod
+		available     float64
+		now           string
+		wantProjected float64
+		wantRecurring float64
+		wantOverUnder float64
+	}{
+		{
+			name:          "no history or spending",
+			available:     300,
+			now:           "2021-04-10",
+			wantProjected: 0,
+			wantOverUnder: -300,
+		},
+		{
+			name:          "no history projects the pace so far",
+			transactions:  []models.Transaction{merchantTransaction("Grocer", "2021-04-03", 100)},
+			available:     250,
+			now:           "2021-04-10",
+			wantProjected: 300,
+			wantOverUnder: 50,
+		},
+		{
+			name:          "halfway leans on history and expects recurring charges",
+			transactions:  append([]models.Transaction{merchantTransaction("Grocer", "2021-04-03", 100)}, pastSpending...),
+			history:       history,
+			available:     500,
+			now:           "2021-04-15",
+			wantProjected: 315,
+			wantRecurring: 15,
+			wantOverUnder: -185,
+		},
+		{
+			name: "recurring charges already made aren't due again",
+			transactions: append([]models.Transaction{
+				merchantTransaction("Grocer", "2021-04-03", 100),
+				merchantTransaction("StreamFlix", "2021-04-05", 15),
+			}, pastSpending...),
+			history:       history,
+			available:     500,
+			now:           "2021-04-15",
+			wantProjected: 315,
+			wantRecurring: 0,
+			wantOverUnder: -185,
+		},
+		{
+			name:          "last day is the actual spending",
+			transactions:  []models.Transaction{merchantTransaction("Grocer", "2021-04-03", 100)},
+			history:       history[:1],
+			available:     80,
+			now:           "2021-04-30",
+			wantProjected: 100,
+			wantOverUnder: 20,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			forecast := forecastExpense(test.transactions, april, test.history, test.available, day(test.now))
+
+			if forecast.ProjectedTotal != test.wantProjected {
+				t.Errorf("projected %.2f, want %.2f", forecast.ProjectedTotal, test.wantProjected)
+			}
+
+			if forecast.RecurringDue != test.wantRecurring {
+				t.Errorf("recurring due %.2f, want %.2f", forecast.RecurringDue, test.wantRecurring)
+			}
+
+			if forecast.ProjectedOverUnder != test.wantOverUnder {
+				t.Errorf("over/under %.2f, want %.2f", forecast.ProjectedOverUnder, test.wantOverUnder)
+			}
+
+			if forecast.Low > forecast.ProjectedTotal || forecast.High < forecast.ProjectedTotal {
+				t.Errorf("band %.2f to %.2f doesn't hold %.2f", forecast.Low, forecast.High, forecast.ProjectedTotal)
+			}
+
+			if forecast.HistoryPeriods != len(test.history) {
+				t.Errorf("history periods %d, want %d", forecast.HistoryPeriods, len(test.history))
+			}
+		})
+	}
+}
+
+func TestForecastHistory(t *testing.T) {
+	now := day("2021-04-15")
+
+	tests := []struct {
+		name      string
+		cycle     models.ExpenseChargeCycle
+		want      int
+		wantStart string
+	}{
+		{name: "monthly", cycle: models.ExpenseChargeCycle{Unit: "monthly"}, want: 3, wantStart: "2021-01-01"},
+		{name: "annual stops at synced history", cycle: models.ExpenseChargeCycle{Unit: "annually"}, want: 1, wantStart: "2020-01-01"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expense := models.Expense{ExpenseID: uuid.New(), ExpenseChargeCycle: test.cycle}
+			history := forecastHistory(expense, now)
+
+			if len(history) != test.want {
+				t.Fatalf("got %d history periods, want %d", len(history), test.want)
+			}
+
+			if start := history[len(history)-1].start.Format("2006-01-02"); start != test.wantStart {
+				t.Errorf("oldest period starts %s, want %s", start, test.wantStart)
+			}
+
+			if startDate := forecastStartDate([]models.Expense{expense}, now); startDate != test.wantStart {
+				t.Errorf("forecastStartDate() = %s, want %s", startDate, test.wantStart)
+			}
+		})
+	}
+
+	if startDate := forecastStartDate(nil, now); startDate != "" {
+		t.Errorf("forecastStartDate() without expenses = %q, want none", startDate)
+	}
+}
+
+func TestSummaryPeriodsProrateLimits(t *testing.T) {
+	rent := models.Expense{ExpenseID: uuid.New(), ExpenseValue: 1200, ExpenseChargeCycle: models.ExpenseChargeCycle{Unit: "monthly"}}
+	gym := models.Expense{ExpenseID: uuid.New(), ExpenseValue: 70, ExpenseChargeCycle: models.ExpenseChargeCycle{Unit: "weekly"}}
+
+	periods, window, err := summaryPeriods(
+		[]models.Expense{rent, gym},
+		models.SummaryPeriod{StartDate: "2021-04-01", EndDate: "2021-04-15"},
+		day("2021-04-20"),
+	)
+
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	if window.start != day("2021-04-01") || window.end != day("2021-04-16") {
+		t.Errorf("window %s to %s, want April 1st through 15th", window.start, window.end)
+	}
+
+	// 15 of April's 30 days and 15 days of weeks
+	if limit := roundCents(periods[rent.ExpenseID].limit); limit != 600 {
+		t.Errorf("rent limit %.2f, want 600", limit)
+	}
+
+	if limit := roundCents(periods[gym.ExpenseID].limit); limit != 150 {
+		t.Errorf("gym limit %.2f, want 150", limit)
+	}
+
+	// Whole periods aren't prorated
+	periods, _, _ = summaryPeriods([]models.Expense{rent}, models.SummaryPeriod{}, day("2021-04-20"))
+
+	if limit := periods[rent.ExpenseID].limit; limit != 1200 {
+		t.Errorf("current rent limit %.2f, want 1200", limit)
+	}
+}
@@ -0,0 +1,425 @@
+package budget
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	"github.com/lakshay35/finlit-backend/services/account"
+	merchantService "github.com/lakshay35/finlit-backend/services/merchant"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// Inbox defaults and limits
+const (
+	defaultInboxDays     = 30
+	maxInboxDays         = 365
+	defaultInboxPageSize = 20
+	maxInboxPageSize     = 100
+)
+
+// GetUncategorizedInbox ...
+// Lists the budget's uncategorized spending of the past days
+// across all of its transaction sources, grouped by merchant.
+// Sources that can't be read are reported rather than failing
+// the whole inbox, like in the expense summary
+func GetUncategorizedInbox(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID, days int, pageIndex int, pageSize int) (*models.UncategorizedInbox, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if pageSize == 0 {
+		pageSize = defaultInboxPageSize
+	}
+
+	if pageSize < 0 || pageSize > maxInboxPageSize {
+		return nil, &errors.Error{
+			Message:    "page_size must be between 1 and " + strconv.Itoa(maxInboxPageSize),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if pageIndex < 0 {
+		return nil, &errors.Error{
+			Message:    "Page index is out of bounds",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	days, daysErr := inboxDays(days)
+
+	if daysErr != nil {
+		return nil, daysErr
+	}
+
+	transactions, sourceIssues, err := uncategorizedSpending(ctx, budgetID, days)
+
+	if err != nil {
+		return nil, err
+	}
+
+	groups := merchantGroups(transactions)
+
+	inbox := models.UncategorizedInbox{
+		TotalGroups:  len(groups),
+		TotalPages:   (len(groups) + pageSize - 1) / pageSize,
+		PageIndex:    pageIndex,
+		Groups:       make([]models.UncategorizedMerchantGroup, 0),
+		SourceIssues: sourceIssues,
+	}
+
+	for _, group := range groups {
+		inbox.TotalTransactions += group.TransactionCount
+		inbox.TotalAmount += group.TotalAmount
+	}
+
+	if start := pageIndex * pageSize; start < len(groups) {
+		end := start + pageSize
+
+		if end > len(groups) {
+			end = len(groups)
+		}
+
+		inbox.Groups = groups[start:end]
+	}
+
+	return &inbox, nil
+}
+
+// BulkCategorize ...
+// Applies many category assignments to a budget at once. Either
+// every assignment is applied or, when one of them is invalid,
+// none of them are. Assigning a transaction tags its name, as
+// /transaction/categorize does, so the classifier learns from it
+func BulkCategorize(ctx context.Context, payload models.BulkCategorizationPayload, userID uuid.UUID) (*models.BulkCategorizationResult, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, payload.BudgetID, userID) && !roleService.IsUserOwner(ctx, payload.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to categorize transactions of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if len(payload.Assignments) == 0 {
+		return nil, &errors.Error{
+			Message:    "At least one assignment is required",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	days, daysErr := inboxDays(payload.Days)
+
+	if daysErr != nil {
+		return nil, daysErr
+	}
+
+	categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, payload.BudgetID)
+
+	if categoriesErr != nil {
+		return nil, categoriesErr
+	}
+
+	categoryNames := make(map[uuid.UUID]string, len(categories))
+
+	for _, cat := range categories {
+		categoryNames[cat.BudgetTransactionCategoryID] = cat.CategoryName
+	}
+
+	uncategorized, sourceIssues, err := uncategorizedSpending(ctx, payload.BudgetID, days)
+
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, aliasesErr := loadMerchantAliases(ctx, payload.BudgetID)
+
+	if aliasesErr != nil {
+		return nil, aliasesErr
+	}
+
+	groupTransactions := make(map[string][]models.Transaction)
+
+	for _, tx := range uncategorized {
+		key := merchantKey(tx.Merchant)
+		groupTransactions[key] = append(groupTransactions[key], tx)
+	}
+
+	// Every transaction is resolved and checked before anything
+	// is written, so a bad assignment fails the whole request
+	tags := make([]models.BudgetTransactionCategoryTransaction, 0)
+	taggedCategories := make(map[string]uuid.UUID)
+	rules := make([]models.CategorizationRule, 0)
+	result := models.BulkCategorizationResult{
+		RulesCreated: make([]models.CategorizationRule, 0),
+		SourceIssues: sourceIssues,
+	}
+
+	for _, assignment := range payload.Assignments {
+		categoryName, ok := categoryNames[assignment.BudgetTransactionCategoryID]
+
+		if !ok {
+			return nil, &errors.Error{
+				Message:    "Provided category not found",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+
+		if len(assignment.TransactionIDs) == 0 && len(assignment.Merchants) == 0 {
+			return nil, &errors.Error{
+				Message:    "Each assignment needs transaction_ids or merchants",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+
+		transactions := make([]models.Transaction, 0)
+		ruleMerchants := make([]string, 0)
+
+		for _, transactionID := range assignment.TransactionIDs {
+			tx, txErr := account.GetStoredTransaction(ctx, transactionID)
+
+			if txErr != nil {
+				return nil, txErr
+			}
+
+			if !isBudgetSource(ctx, payload.BudgetID, tx.ExternalAccountID) {
+				return nil, &errors.Error{
+					Message:    "Transaction " + transactionID + " does not belong to any of the budget's transaction sources",
+					StatusCode: http.StatusNotFound,
+				}
+			}
+
+			transactions = append(transactions, *tx)
+			ruleMerchants = append(ruleMerchants, aliases.merchant(*tx))
+		}
+
+		for _, merchant := range assignment.Merchants {
+			group, ok := groupTransactions[merchantKey(merchant)]
+
+			if !ok {
+				return nil, &errors.Error{
+					Message:    "No uncategorized transactions at merchant " + merchant,
+					StatusCode: http.StatusNotFound,
+				}
+			}
+
+			transactions = append(transactions, group...)
+			ruleMerchants = append(ruleMerchants, group[0].Merchant)
+		}
+
+		for _, tx := range transactions {
+			if tagged, ok := taggedCategories[assignmentKey(tx.Name)]; ok {
+				if tagged != assignment.BudgetTransactionCategoryID {
+					return nil, &errors.Error{
+						Message:    "Merchant " + merchantService.Normalize(tx.Name) + " is assigned to more than one category",
+						StatusCode: http.StatusBadRequest,
+					}
+				}
+
+				result.TransactionsCategorized++
+
+				continue
+			}
+
+			taggedCategories[assignmentKey(tx.Name)] = assignment.BudgetTransactionCategoryID
+			tags = append(tags, models.BudgetTransactionCategoryTransaction{
+				BudgetTransactionCategoryID: assignment.BudgetTransactionCategoryID,
+				TransactionName:             tx.Name,
+				CategoryName:                categoryName,
+				PlaidCategory:               tx.Category,
+			})
+			result.TransactionsCategorized++
+		}
+
+		if !assignment.CreateRule {
+			continue
+		}
+
+		for _, merchant := range ruleMerchants {
+			rules = appendMerchantRule(rules, models.CategorizationRule{
+				BudgetID:                    payload.BudgetID,
+				BudgetTransactionCategoryID: assignment.BudgetTransactionCategoryID,
+				Priority:                    assignment.RulePriority,
+				Merchant:                    merchant,
+			})
+		}
+	}
+
+	existingRules, rulesErr := store.GetCategorizationRules(ctx, payload.BudgetID)
+
+	if rulesErr != nil {
+		return nil, &errors.Error{
+			Message:    rulesErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, tag := range tags {
+			if tagErr := CreateBudgetTransactionCategoryTransaction(ctx, payload.BudgetID, tag); tagErr != nil {
+				return tagErr
+			}
+		}
+
+		for _, rule := range rules {
+			if hasMerchantRule(existingRules, rule) {
+				continue
+			}
+
+			if validationErr := validateRule(ctx, rule); validationErr != nil {
+				return validationErr
+			}
+
+			created, createErr := store.CreateCategorizationRule(ctx, rule)
+
+			if createErr != nil {
+				return createErr
+			}
+
+			result.RulesCreated = append(result.RulesCreated, *created)
+		}
+
+		return nil
+	})
+
+	if txErr != nil {
+		return nil, toServiceError(txErr)
+	}
+
+	result.NamesTagged = len(tags)
+
+	return &result, nil
+}
+
+// uncategorizedSpending ...
+// The budget's spending of the past days that neither a tagged
+// transaction name, a rule nor a Plaid category mapping puts
+// in a category. Merchants are named the way the budget's
+// aliases name them. Sources that couldn't be read are
+// returned as issues, see budgetTransactions
+func uncategorizedSpending(ctx context.Context, budgetID uuid.UUID, days int) ([]models.Transaction, []models.BudgetSourceIssue, *errors.Error) {
+	sources, sourcesErr := GetBudgetTransactionSources(ctx, budgetID)
+
+	if sourcesErr != nil {
+		return nil, nil, sourcesErr
+	}
+
+	categorizer, categorizerErr := newCategorizer(ctx, budgetID)
+
+	if categorizerErr != nil {
+		return nil, nil, categorizerErr
+	}
+
+	transactions, sourceIssues := budgetTransactions(
+		ctx,
+		sources,
+		time.Now().Local().AddDate(0, 0, -days).Format("2006-01-02"),
+		time.Now().Local().Format("2006-01-02"),
+	)
+
+	uncategorized := make([]models.Transaction, 0)
+
+	for _, tx := range transactions {
+		tx.Merchant = categorizer.merchant(tx)
+
+		if isSpending(tx.Transaction) && categorizer.categorize(tx, tx.Merchant).CategoryName == "" {
+			uncategorized = append(uncategorized, tx)
+		}
+	}
+
+	return uncategorized, sourceIssues, nil
+}
+
+// merchantGroups ...
+// Groups transactions by merchant, largest total first
+func merchantGroups(transactions []models.Transaction) []models.UncategorizedMerchantGroup {
+	groups := make([]models.UncategorizedMerchantGroup, 0)
+	index := make(map[string]int)
+
+	for _, tx := range transactions {
+		merchant := tx.Merchant
+		key := merchantKey(merchant)
+
+		i, ok := index[key]
+
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, models.UncategorizedMerchantGroup{
+				Merchant:     merchant,
+				Transactions: make([]models.InboxTransaction, 0),
+			})
+		}
+
+		groups[i].TransactionCount++
+		groups[i].TotalAmount += tx.Amount
+		groups[i].Transactions = append(groups[i].Transactions, models.InboxTransaction{
+			TransactionID:     tx.ID,
+			TransactionName:   tx.Name,
+			Amount:            tx.Amount,
+			Date:              tx.Date,
+			ExternalAccountID: tx.ExternalAccountID,
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].TotalAmount != groups[j].TotalAmount {
+			return groups[i].TotalAmount > groups[j].TotalAmount
+		}
+
+		return merchantKey(groups[i].Merchant) < merchantKey(groups[j].Merchant)
+	})
+
+	return groups
+}
+
+// appendMerchantRule ...
+// Adds a merchant rule unless an equal one is already listed
+func appendMerchantRule(rules []models.CategorizationRule, rule models.CategorizationRule) []models.CategorizationRule {
+	if hasMerchantRule(rules, rule) {
+		return rules
+	}
+
+	return append(rules, rule)
+}
+
+// hasMerchantRule ...
+// Whether rules hold one putting the same merchant, and
+// nothing else, in the same category
+func hasMerchantRule(rules []models.CategorizationRule, rule models.CategorizationRule) bool {
+	for _, existing := range rules {
+		if existing.BudgetTransactionCategoryID == rule.BudgetTransactionCategoryID &&
+			merchantKey(existing.Merchant) == merchantKey(rule.Merchant) &&
+			existing.NameContains == "" && existing.NamePrefix == "" && existing.NameRegex == "" &&
+			existing.MinAmount == nil && existing.MaxAmount == nil &&
+			existing.ExternalAccountID == nil && existing.PlaidCategory == "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inboxDays ...
+// Checks the number of days the inbox looks back, defaulting it
+func inboxDays(days int) (int, *errors.Error) {
+	if days == 0 {
+		return defaultInboxDays, nil
+	}
+
+	if days < 0 || days > maxInboxDays {
+		return 0, &errors.Error{
+			Message:    "days must be between 1 and " + strconv.Itoa(maxInboxDays),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return days, nil
+}
@@ -0,0 +1,200 @@
+package budget
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/services/account"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+)
+
+// inboxBudget ...
+// Creates a budget using both accounts of a synced fake bank
+// link, without the default Plaid category mappings so its
+// spending is uncategorized, and a Pantry category
+func inboxBudget(t *testing.T, ctx context.Context, ownerID uuid.UUID) (*models.Budget, *models.BudgetTransactionCategory) {
+	roles := useMemoryStores()
+	accounts := linkFakeBank(t, ctx, ownerID)
+
+	if _, syncErr := account.SyncUserTransactions(ctx, ownerID); syncErr != nil {
+		t.Fatal(syncErr.Message)
+	}
+
+	budget, createErr := CreateBudget(ctx, ownerID, "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	roles.SetBudgetOwner(budget.BudgetID, ownerID)
+
+	for _, act := range accounts {
+		if _, err := store.CreateTransactionSource(ctx, models.BudgetTransactionSourceCreationPayload{
+			BudgetID:          budget.BudgetID,
+			ExternalAccountID: act.ExternalAccountID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mappings, err := store.GetPlaidCategoryMappings(ctx, budget.BudgetID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mapping := range mappings {
+		if err := store.DeletePlaidCategoryMapping(ctx, mapping.MappingID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pantry, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Pantry"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return budget, pantry
+}
+
+func TestGetUncategorizedInboxReportsSourceIssues(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget, _ := inboxBudget(t, ctx, ownerID)
+
+	gone, err := store.CreateTransactionSource(ctx, models.BudgetTransactionSourceCreationPayload{
+		BudgetID:          budget.BudgetID,
+		ExternalAccountID: uuid.New(),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inbox, inboxErr := GetUncategorizedInbox(ctx, budget.BudgetID, ownerID, 0, 0, 0)
+
+	if inboxErr != nil {
+		t.Fatal(inboxErr.Message)
+	}
+
+	if inbox.TotalGroups == 0 {
+		t.Error("inbox lost the spending of the readable sources")
+	}
+
+	if len(inbox.SourceIssues) != 1 || inbox.SourceIssues[0].BudgetTransactionSourceID != gone.BudgetTransactionSourceID || !inbox.SourceIssues[0].Skipped {
+		t.Errorf("source issues = %+v, want the unregistered account skipped", inbox.SourceIssues)
+	}
+}
+
+func TestBulkCategorize(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget, pantry := inboxBudget(t, ctx, ownerID)
+
+	result, bulkErr := BulkCategorize(ctx, models.BulkCategorizationPayload{
+		BudgetID: budget.BudgetID,
+		Assignments: []models.BulkCategoryAssignment{
+			{BudgetTransactionCategoryID: pantry.BudgetTransactionCategoryID, Merchants: []string{"green grocer"}, CreateRule: true},
+		},
+	}, ownerID)
+
+	if bulkErr != nil {
+		t.Fatal(bulkErr.Message)
+	}
+
+	if result.TransactionsCategorized != 3 || result.NamesTagged != 1 || len(result.RulesCreated) != 1 {
+		t.Errorf("result = %+v, want 3 transactions under 1 name and 1 rule", result)
+	}
+
+	if result.SourceIssues == nil || len(result.SourceIssues) != 0 {
+		t.Errorf("source issues = %#v, want none", result.SourceIssues)
+	}
+
+	inbox, _ := GetUncategorizedInbox(ctx, budget.BudgetID, ownerID, 0, 0, 0)
+
+	for _, group := range inbox.Groups {
+		if group.Merchant == "Green Grocer" {
+			t.Error("Green Grocer is still uncategorized")
+		}
+	}
+}
+
+func TestBulkCategorizeWritesNothingOnInvalidInput(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget, pantry := inboxBudget(t, ctx, ownerID)
+
+	// Another user's transaction isn't in any of the budget's sources
+	otherID := uuid.New()
+
+	if err := account.RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), otherID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	if _, syncErr := account.SyncUserTransactions(ctx, otherID); syncErr != nil {
+		t.Fatal(syncErr.Message)
+	}
+
+	otherAccounts, _ := account.GetAllExternalAccounts(ctx, otherID)
+	foreign, _ := account.GetStoredTransactions(ctx, otherAccounts[1].ExternalAccountID, "0001-01-01", "9999-12-31")
+
+	if len(foreign) == 0 {
+		t.Fatal("other user has no transactions")
+	}
+
+	valid := models.BulkCategoryAssignment{
+		BudgetTransactionCategoryID: pantry.BudgetTransactionCategoryID,
+		Merchants:                   []string{"Green Grocer"},
+		CreateRule:                  true,
+	}
+
+	for _, test := range []struct {
+		name       string
+		assignment models.BulkCategoryAssignment
+		status     int
+	}{
+		{
+			name:       "unknown category",
+			assignment: models.BulkCategoryAssignment{BudgetTransactionCategoryID: uuid.New(), Merchants: []string{"Quick Fuel"}},
+			status:     http.StatusBadRequest,
+		},
+		{
+			name:       "foreign transaction",
+			assignment: models.BulkCategoryAssignment{BudgetTransactionCategoryID: pantry.BudgetTransactionCategoryID, TransactionIDs: []string{foreign[0].ID}},
+			status:     http.StatusNotFound,
+		},
+		{
+			name:       "nothing to assign",
+			assignment: models.BulkCategoryAssignment{BudgetTransactionCategoryID: pantry.BudgetTransactionCategoryID},
+			status:     http.StatusBadRequest,
+		},
+		{
+			name:       "unknown merchant",
+			assignment: models.BulkCategoryAssignment{BudgetTransactionCategoryID: pantry.BudgetTransactionCategoryID, Merchants: []string{"Nowhere Shop"}},
+			status:     http.StatusNotFound,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, bulkErr := BulkCategorize(ctx, models.BulkCategorizationPayload{
+				BudgetID:    budget.BudgetID,
+				Assignments: []models.BulkCategoryAssignment{valid, test.assignment},
+			}, ownerID)
+
+			if bulkErr == nil || bulkErr.StatusCode != test.status {
+				t.Fatalf("BulkCategorize() = %v, want status %d", bulkErr, test.status)
+			}
+
+			if tagged, _ := store.GetCategoryTransactions(ctx, budget.BudgetID); len(tagged) != 0 {
+				t.Errorf("tagged transactions = %+v, want none", tagged)
+			}
+
+			if rules, _ := store.GetCategorizationRules(ctx, budget.BudgetID); len(rules) != 0 {
+				t.Errorf("rules = %+v, want none", rules)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// GetIncomeSources ...
+// Gets the income sources of a budget
+func GetIncomeSources(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.IncomeSource, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	sources, err := store.GetIncomeSources(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return sources, nil
+}
+
+// CreateIncomeSource ...
+// Creates an income source for a budget
+func CreateIncomeSource(ctx context.Context, source models.IncomeSource, userID uuid.UUID) (*models.IncomeSource, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, source.BudgetID, userID) && !roleService.IsUserOwner(ctx, source.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to add income sources to the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	source.IncomeSourceName = strings.TrimSpace(source.IncomeSourceName)
+	source.Payer = strings.TrimSpace(source.Payer)
+
+	if source.IncomeSourceName == "" || source.Payer == "" {
+		return nil, &errors.Error{
+			Message:    "Income sources need an income_source_name and a payer",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if source.ExpectedAmount <= 0 {
+		return nil, &errors.Error{
+			Message:    "expected_amount must be greater than 0",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	cycle, cycleErr := expenseService.ResolveChargeCycle(ctx, source.ChargeCycle)
+
+	if cycleErr != nil {
+		return nil, cycleErr
+	}
+
+	source.ChargeCycle = cycle
+
+	if source.ExternalAccountID != nil && !isBudgetSource(ctx, source.BudgetID, *source.ExternalAccountID) {
+		return nil, &errors.Error{
+			Message:    "Provided external account is not a transaction source of the budget",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	created, err := store.CreateIncomeSource(ctx, source)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return created, nil
+}
+
+// DeleteIncomeSource ...
+// Deletes an income source
+func DeleteIncomeSource(ctx context.Context, incomeSourceID uuid.UUID, userID uuid.UUID) *errors.Error {
+	source, err := store.GetIncomeSource(ctx, incomeSourceID)
+
+	if err == sql.ErrNoRows {
+		return &errors.Error{
+			Message:    "Income source not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if !roleService.IsUserAdmin(ctx, source.BudgetID, userID) && !roleService.IsUserOwner(ctx, source.BudgetID, userID) {
+		return &errors.Error{
+			Message:    "You are not authorized to change income sources of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if deleteErr := store.DeleteIncomeSource(ctx, incomeSourceID); deleteErr != nil {
+		return &errors.Error{
+			Message:    deleteErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// SetBudgetZeroBased ...
+// Turns zero-based budgeting on or off for a budget
+func SetBudgetZeroBased(ctx context.Context, payload models.ZeroBasedPayload, userID uuid.UUID) *errors.Error {
+	if !roleService.IsUserAdmin(ctx, payload.BudgetID, userID) && !roleService.IsUserOwner(ctx, payload.BudgetID, userID) {
+		return &errors.Error{
+			Message:    "You are not authorized to change the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if err := store.SetBudgetZeroBased(ctx, payload.BudgetID, payload.ZeroBased); err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// isTransfer ...
+// Whether Plaid files a transaction under card
+// payments or transfers between accounts
+func isTransfer(tx plaid.Transaction) bool {
+	return len(tx.Category) > 0 && (tx.Category[0] == "Payment" || tx.Category[0] == "Transfer")
+}
+
+// isDeposit ...
+// Whether a transaction is money coming in that could be income.
+// Transfers only count when Plaid calls them payroll or deposits
+func isDeposit(tx plaid.Transaction) bool {
+	return tx.Amount < 0 && (!isTransfer(tx) || inPlaidCategory(tx.Category, "Payroll") || inPlaidCategory(tx.Category, "Deposit"))
+}
+
+// incomeSource ...
+// The first income source of the budget a deposit belongs to
+func (c *categorizer) incomeSource(tx models.Transaction) *models.IncomeSource {
+	if !isDeposit(tx.Transaction) {
+		return nil
+	}
+
+	merchant := strings.ToLower(c.merchant(tx))
+	name := strings.ToLower(tx.Name)
+
+	for i := range c.incomeSources {
+		source := &c.incomeSources[i]
+
+		if source.ExternalAccountID != nil && *source.ExternalAccountID != tx.ExternalAccountID {
+			continue
+		}
+
+		payer := strings.ToLower(source.Payer)
+
+		if strings.Contains(merchant, payer) || strings.Contains(name, payer) {
+			return source
+		}
+	}
+
+	return nil
+}
+
+// refundCategory ...
+// The category a credit nets against as a refund. Empty for
+// spending, transfers, deposits of income sources and credits
+// nothing traces back to a category
+func (c *categorizer) refundCategory(tx models.Transaction) string {
+	if tx.Amount >= 0 || isTransfer(tx.Transaction) || c.incomeSource(tx) != nil {
+		return ""
+	}
+
+	return c.categorize(tx, c.merchant(tx)).CategoryName
+}
+
+// incomeSummary ...
+// What every income source of a budget was expected to bring in
+// over the window and the deposits in it, matched to sources
+func incomeSummary(transactions []models.Transaction, categorizer *categorizer, window expensePeriod) models.IncomeSummary {
+	summary := models.IncomeSummary{
+		Sources:       make([]models.IncomeSourceSummary, 0, len(categorizer.incomeSources)),
+		OtherDeposits: make([]models.Transaction, 0),
+	}
+
+	sourceIndex := make(map[uuid.UUID]int, len(categorizer.incomeSources))
+
+	for i, source := range categorizer.incomeSources {
+		expected := expenseService.ChargeCycleLimit(source.ChargeCycle, source.ExpectedAmount, window.start, window.end)
+
+		summary.Sources = append(summary.Sources, models.IncomeSourceSummary{
+			IncomeSourceID:   source.IncomeSourceID,
+			IncomeSourceName: source.IncomeSourceName,
+			Expected:         roundCents(expected),
+			Deposits:         make([]models.Transaction, 0),
+		})
+
+		summary.Expected += expected
+		sourceIndex[source.IncomeSourceID] = i
+	}
+
+	for _, tx := range transactions {
+		if !window.contains(tx.Date) || !isDeposit(tx.Transaction) {
+			continue
+		}
+
+		tx.Merchant = categorizer.merchant(tx)
+
+		if source := categorizer.incomeSource(tx); source != nil {
+			sourceSummary := &summary.Sources[sourceIndex[source.IncomeSourceID]]
+			sourceSummary.Received = roundCents(sourceSummary.Received - tx.Amount)
+			sourceSummary.Deposits = append(sourceSummary.Deposits, tx)
+			summary.Received -= tx.Amount
+
+			continue
+		}
+
+		// Refunds are netted against spending instead
+		if categorizer.refundCategory(tx) != "" {
+			continue
+		}
+
+		summary.OtherDeposits = append(summary.OtherDeposits, tx)
+		summary.OtherDepositsTotal -= tx.Amount
+	}
+
+	summary.Expected = roundCents(summary.Expected)
+	summary.Received = roundCents(summary.Received)
+	summary.OtherDepositsTotal = roundCents(summary.OtherDepositsTotal)
+
+	return summary
+}
+
+// zeroBasedSummary ...
+// Income of a zero-based budget over the window less what
+// its expenses may spend in it. Sources count what they
+// received, or what they were expected to while short of it
+func zeroBasedSummary(income models.IncomeSummary, expenses []models.Expense, window expensePeriod) *models.ZeroBasedSummary {
+	total := 0.0
+
+	for _, source := range income.Sources {
+		total += math.Max(source.Expected, source.Received)
+	}
+
+	planned := 0.0
+
+	for _, expense := range expenses {
+		planned += expenseService.ChargeCycleLimit(expense.ExpenseChargeCycle, float64(expense.ExpenseValue), window.start, window.end)
+	}
+
+	return &models.ZeroBasedSummary{
+		Income:     roundCents(total),
+		Planned:    roundCents(planned),
+		Unassigned: roundCents(total - planned),
+	}
+}
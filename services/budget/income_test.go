@@ -0,0 +1,101 @@
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/services/account"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+)
+
+// linkFakeBank ...
+// Links the checking and credit accounts of the fake
+// Plaid client's ins_fake_bank item for a user
+func linkFakeBank(t *testing.T, ctx context.Context, userID uuid.UUID) []models.Account {
+	account.SetStore(account.NewMemoryAccountStore())
+	plaidService.SetClient(plaidService.NewSeededFakeClient())
+
+	if err := account.RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), userID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	accounts, accountsErr := account.GetAllExternalAccounts(ctx, userID)
+
+	if accountsErr != nil || len(accounts) != 2 {
+		t.Fatalf("accounts = %+v (%v), want two", accounts, accountsErr)
+	}
+
+	return accounts
+}
+
+func TestDisconnectExternalAccountKeepsIncomeSources(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	roles := useMemoryStores()
+
+	accounts := linkFakeBank(t, ctx, ownerID)
+	linked, other := accounts[0], accounts[1]
+
+	budget, createErr := CreateBudget(ctx, ownerID, "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	roles.SetBudgetOwner(budget.BudgetID, ownerID)
+
+	for _, act := range accounts {
+		if _, err := store.CreateTransactionSource(ctx, models.BudgetTransactionSourceCreationPayload{
+			BudgetID:          budget.BudgetID,
+			ExternalAccountID: act.ExternalAccountID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paycheck, sourceErr := CreateIncomeSource(ctx, models.IncomeSource{
+		BudgetID:          budget.BudgetID,
+		IncomeSourceName:  "Paycheck",
+		ExpectedAmount:    4200,
+		ChargeCycle:       models.ExpenseChargeCycle{Unit: "monthly"},
+		Payer:             "ACME",
+		ExternalAccountID: &linked.ExternalAccountID,
+	}, ownerID)
+
+	if sourceErr != nil {
+		t.Fatal(sourceErr.Message)
+	}
+
+	disconnection, disconnectErr := DisconnectExternalAccount(ctx, ownerID, linked.ExternalAccountID)
+
+	if disconnectErr != nil {
+		t.Fatal(disconnectErr.Message)
+	}
+
+	if len(disconnection.UnlinkedIncomeSources) != 1 || disconnection.UnlinkedIncomeSources[0].IncomeSourceID != paycheck.IncomeSourceID {
+		t.Fatalf("unlinked income sources = %+v, want the paycheck", disconnection.UnlinkedIncomeSources)
+	}
+
+	sources, _ := GetIncomeSources(ctx, budget.BudgetID, ownerID)
+
+	if len(sources) != 1 || sources[0].ExternalAccountID != nil {
+		t.Fatalf("income sources = %+v, want the paycheck without an account", sources)
+	}
+
+	// Deposits into the budget's remaining account count now
+	categorizer, categorizerErr := newCategorizer(ctx, budget.BudgetID)
+
+	if categorizerErr != nil {
+		t.Fatal(categorizerErr.Message)
+	}
+
+	deposit := transaction("ACME Payroll", "2021-04-01", -2100)
+	deposit.ExternalAccountID = other.ExternalAccountID
+	deposit.Category = []string{"Transfer", "Payroll"}
+
+	if source := categorizer.incomeSource(deposit); source == nil || source.IncomeSourceID != paycheck.IncomeSourceID {
+		t.Errorf("deposit matched %+v, want the paycheck", source)
+	}
+}
@@ -0,0 +1,947 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+type memoryCategoryTransaction struct {
+	categoryID      uuid.UUID
+	transactionName string
+	plaidCategory   []string
+}
+
+// MemoryBudgetStore ...
+// In-memory BudgetStore for tests and local development
+type MemoryBudgetStore struct {
+	mutex                sync.RWMutex
+	budgets              map[uuid.UUID]models.Budget
+	sources              map[uuid.UUID]models.BudgetTransactionSourcePayload
+	categories           map[uuid.UUID]models.BudgetTransactionCategory
+	categoryTransactions []memoryCategoryTransaction
+	rules                map[uuid.UUID]models.CategorizationRule
+	ruleOrder            []uuid.UUID
+	mappings             map[uuid.UUID]models.PlaidCategoryMapping
+	mappingOrder         []uuid.UUID
+	aliases              map[uuid.UUID]models.MerchantAlias
+	aliasOrder           []uuid.UUID
+	alertRules           map[uuid.UUID]models.AlertRule
+	alertRuleOrder       []uuid.UUID
+	alertsFired          map[uuid.UUID]map[string]bool
+	incomeSources        map[uuid.UUID]models.IncomeSource
+	incomeSourceOrder    []uuid.UUID
+}
+
+// NewMemoryBudgetStore ...
+// Creates an empty in-memory BudgetStore
+func NewMemoryBudgetStore() *MemoryBudgetStore {
+	return &MemoryBudgetStore{
+		budgets:       make(map[uuid.UUID]models.Budget),
+		sources:       make(map[uuid.UUID]models.BudgetTransactionSourcePayload),
+		categories:    make(map[uuid.UUID]models.BudgetTransactionCategory),
+		rules:         make(map[uuid.UUID]models.CategorizationRule),
+		mappings:      make(map[uuid.UUID]models.PlaidCategoryMapping),
+		aliases:       make(map[uuid.UUID]models.MerchantAlias),
+		alertRules:    make(map[uuid.UUID]models.AlertRule),
+		alertsFired:   make(map[uuid.UUID]map[string]bool),
+		incomeSources: make(map[uuid.UUID]models.IncomeSource),
+	}
+}
+
+// FindBudget ...
+func (s *MemoryBudgetStore) FindBudget(ctx context.Context, ownerID uuid.UUID, budgetName string) (*models.Budget, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, budget := range s.budgets {
+		if budget.OwnerID == ownerID && budget.BudgetName == budgetName {
+			return &budget, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// GetBudget ...
+func (s *MemoryBudgetStore) GetBudget(ctx context.Context, budgetID uuid.UUID) (*models.Budget, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	budget, ok := s.budgets[budgetID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &budget, nil
+}
+
+// GetBudgets ...
+func (s *MemoryBudgetStore) GetBudgets(ctx context.Context, ownerID uuid.UUID) ([]models.Budget, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	budgets := make([]models.Budget, 0)
+
+	for _, budget := range s.budgets {
+		if budget.OwnerID == ownerID {
+			budgets = append(budgets, budget)
+		}
+	}
+
+	return budgets, nil
+}
+
+// CreateBudget ...
+func (s *MemoryBudgetStore) CreateBudget(ctx context.Context, ownerID uuid.UUID, budgetName string) (*models.Budget, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	budget := models.Budget{
+		BudgetID:   uuid.New(),
+		OwnerID:    ownerID,
+		BudgetName: budgetName,
+	}
+
+	s.budgets[budget.BudgetID] = budget
+
+	return &budget, nil
+}
+
+// SetBudgetZeroBased ...
+func (s *MemoryBudgetStore) SetBudgetZeroBased(ctx context.Context, budgetID uuid.UUID, zeroBased bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if budget, ok := s.budgets[budgetID]; ok {
+		budget.ZeroBased = zeroBased
+		s.budgets[budgetID] = budget
+	}
+
+	return nil
+}
+
+// DeleteBudget ...
+// Transaction sources and categories don't cascade with
+// the budget, so like Postgres it can't be deleted until
+// they are gone. Everything else cascades
+func (s *MemoryBudgetStore) DeleteBudget(ctx context.Context, budgetID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, source := range s.sources {
+		if source.BudgetID == budgetID {
+			return fmt.Errorf("budget %s is still referenced by transaction source %s", budgetID, source.BudgetTransactionSourceID)
+		}
+	}
+
+	for _, category := range s.categories {
+		if category.BudgetID == budgetID {
+			return fmt.Errorf("budget %s is still referenced by transaction category %s", budgetID, category.BudgetTransactionCategoryID)
+		}
+	}
+
+	delete(s.budgets, budgetID)
+
+	for ruleID, rule := range s.rules {
+		if rule.BudgetID == budgetID {
+			delete(s.rules, ruleID)
+		}
+	}
+
+	for mappingID, mapping := range s.mappings {
+		if mapping.BudgetID == budgetID {
+			delete(s.mappings, mappingID)
+		}
+	}
+
+	for aliasID, alias := range s.aliases {
+		if alias.BudgetID == budgetID {
+			delete(s.aliases, aliasID)
+		}
+	}
+
+	for alertRuleID, rule := range s.alertRules {
+		if rule.BudgetID == budgetID {
+			delete(s.alertRules, alertRuleID)
+			delete(s.alertsFired, alertRuleID)
+		}
+	}
+
+	for incomeSourceID, source := range s.incomeSources {
+		if source.BudgetID == budgetID {
+			delete(s.incomeSources, incomeSourceID)
+		}
+	}
+
+	return nil
+}
+
+// GetTransactionSources ...
+func (s *MemoryBudgetStore) GetTransactionSources(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionSourcePayload, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sources := make([]models.BudgetTransactionSourcePayload, 0)
+
+	for _, source := range s.sources {
+		if source.BudgetID == budgetID {
+			sources = append(sources, source)
+		}
+	}
+
+	return sources, nil
+}
+
+// GetTransactionSource ...
+func (s *MemoryBudgetStore) GetTransactionSource(ctx context.Context, budgetTransactionSourceID uuid.UUID) (*models.BudgetTransactionSource, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	source, ok := s.sources[budgetTransactionSourceID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &models.BudgetTransactionSource{
+		BudgetTransactionSourceID: source.BudgetTransactionSourceID,
+		ExternalAccountID:         source.ExternalAccountID,
+		BudgetID:                  source.BudgetID,
+	}, nil
+}
+
+// CreateTransactionSource ...
+func (s *MemoryBudgetStore) CreateTransactionSource(ctx context.Context, source models.BudgetTransactionSourceCreationPayload) (*models.BudgetTransactionSource, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	res := models.BudgetTransactionSource{
+		BudgetTransactionSourceID: uuid.New(),
+		ExternalAccountID:         source.ExternalAccountID,
+		BudgetID:                  source.BudgetID,
+	}
+
+	s.sources[res.BudgetTransactionSourceID] = models.BudgetTransactionSourcePayload{
+		BudgetTransactionSourceID: res.BudgetTransactionSourceID,
+		ExternalAccountID:         res.ExternalAccountID,
+		BudgetID:                  res.BudgetID,
+	}
+
+	return &res, nil
+}
+
+// DeleteTransactionSource ...
+func (s *MemoryBudgetStore) DeleteTransactionSource(ctx context.Context, budgetTransactionSourceID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.sources, budgetTransactionSourceID)
+
+	return nil
+}
+
+// DeleteAllTransactionSources ...
+func (s *MemoryBudgetStore) DeleteAllTransactionSources(ctx context.Context, budgetID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, source := range s.sources {
+		if source.BudgetID == budgetID {
+			delete(s.sources, id)
+		}
+	}
+
+	return nil
+}
+
+// GetAccountSourceBudgets ...
+func (s *MemoryBudgetStore) GetAccountSourceBudgets(ctx context.Context, externalAccountID uuid.UUID) ([]models.Budget, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	budgets := make([]models.Budget, 0)
+	seen := make(map[uuid.UUID]bool)
+
+	for _, source := range s.sources {
+		if source.ExternalAccountID != externalAccountID || seen[source.BudgetID] {
+			continue
+		}
+
+		if budget, ok := s.budgets[source.BudgetID]; ok {
+			budgets = append(budgets, budget)
+			seen[source.BudgetID] = true
+		}
+	}
+
+	return budgets, nil
+}
+
+// DeleteAccountTransactionSources ...
+func (s *MemoryBudgetStore) DeleteAccountTransactionSources(ctx context.Context, externalAccountID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, source := range s.sources {
+		if source.ExternalAccountID == externalAccountID {
+			delete(s.sources, id)
+		}
+	}
+
+	return nil
+}
+
+// GetTransactionCategories ...
+func (s *MemoryBudgetStore) GetTransactionCategories(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategory, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	categories := make([]models.BudgetTransactionCategory, 0)
+
+	for _, category := range s.categories {
+		if category.BudgetID == budgetID {
+			categories = append(categories, category)
+		}
+	}
+
+	return categories, nil
+}
+
+// CreateTransactionCategory ...
+func (s *MemoryBudgetStore) CreateTransactionCategory(ctx context.Context, category models.BudgetTransactionCategoryCreationPayload) (*models.BudgetTransactionCategory, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	res := models.BudgetTransactionCategory{
+		BudgetTransactionCategoryID: uuid.New(),
+		BudgetID:                    category.BudgetID,
+		CategoryName:                category.CategoryName,
+		ParentCategoryID:            category.ParentCategoryID,
+	}
+
+	s.categories[res.BudgetTransactionCategoryID] = res
+
+	return &res, nil
+}
+
+// GetTransactionCategory ...
+func (s *MemoryBudgetStore) GetTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID) (*models.BudgetTransactionCategory, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	category, ok := s.categories[budgetTransactionCategoryID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &category, nil
+}
+
+// SetTransactionCategoryParent ...
+func (s *MemoryBudgetStore) SetTransactionCategoryParent(ctx context.Context, budgetTransactionCategoryID uuid.UUID, parentCategoryID *uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	category, ok := s.categories[budgetTransactionCategoryID]
+
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	category.ParentCategoryID = parentCategoryID
+	s.categories[budgetTransactionCategoryID] = category
+
+	return nil
+}
+
+// RenameTransactionCategory ...
+func (s *MemoryBudgetStore) RenameTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID, categoryName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	category, ok := s.categories[budgetTransactionCategoryID]
+
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	category.CategoryName = categoryName
+	s.categories[budgetTransactionCategoryID] = category
+
+	return nil
+}
+
+// ReassignTransactionCategory ...
+func (s *MemoryBudgetStore) ReassignTransactionCategory(ctx context.Context, fromCategoryID uuid.UUID, toCategoryID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.categoryTransactions {
+		if s.categoryTransactions[i].categoryID == fromCategoryID {
+			s.categoryTransactions[i].categoryID = toCategoryID
+		}
+	}
+
+	for ruleID, rule := range s.rules {
+		if rule.BudgetTransactionCategoryID == fromCategoryID {
+			rule.BudgetTransactionCategoryID = toCategoryID
+			s.rules[ruleID] = rule
+		}
+	}
+
+	for mappingID, mapping := range s.mappings {
+		if mapping.BudgetTransactionCategoryID == fromCategoryID {
+			mapping.BudgetTransactionCategoryID = toCategoryID
+			s.mappings[mappingID] = mapping
+		}
+	}
+
+	return nil
+}
+
+// DeleteTransactionCategory ...
+// Like Postgres, a category can't be deleted while transactions
+// are tagged with it or subcategories sit under it. Its rules
+// and Plaid category mappings cascade
+func (s *MemoryBudgetStore) DeleteTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, tagged := range s.categoryTransactions {
+		if tagged.categoryID == budgetTransactionCategoryID {
+			return fmt.Errorf("transaction category %s is still referenced by tagged transaction %s", budgetTransactionCategoryID, tagged.transactionName)
+		}
+	}
+
+	for _, category := range s.categories {
+		if category.ParentCategoryID != nil && *category.ParentCategoryID == budgetTransactionCategoryID {
+			return fmt.Errorf("transaction category %s is still referenced by subcategory %s", budgetTransactionCategoryID, category.BudgetTransactionCategoryID)
+		}
+	}
+
+	delete(s.categories, budgetTransactionCategoryID)
+
+	for ruleID, rule := range s.rules {
+		if rule.BudgetTransactionCategoryID == budgetTransactionCategoryID {
+			delete(s.rules, ruleID)
+		}
+	}
+
+	for mappingID, mapping := range s.mappings {
+		if mapping.BudgetTransactionCategoryID == budgetTransactionCategoryID {
+			delete(s.mappings, mappingID)
+		}
+	}
+
+	return nil
+}
+
+// DeleteAllTransactionCategories ...
+// Rules and Plaid category mappings of the categories go
+// with them like the foreign key cascades do
+func (s *MemoryBudgetStore) DeleteAllTransactionCategories(ctx context.Context, budgetID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	deleted := make(map[uuid.UUID]bool)
+
+	for categoryID, category := range s.categories {
+		if category.BudgetID == budgetID {
+			deleted[categoryID] = true
+			delete(s.categories, categoryID)
+		}
+	}
+
+	kept := s.categoryTransactions[:0]
+
+	for _, tagged := range s.categoryTransactions {
+		if !deleted[tagged.categoryID] {
+			kept = append(kept, tagged)
+		}
+	}
+
+	s.categoryTransactions = kept
+
+	for ruleID, rule := range s.rules {
+		if deleted[rule.BudgetTransactionCategoryID] {
+			delete(s.rules, ruleID)
+		}
+	}
+
+	for mappingID, mapping := range s.mappings {
+		if deleted[mapping.BudgetTransactionCategoryID] {
+			delete(s.mappings, mappingID)
+		}
+	}
+
+	return nil
+}
+
+// GetCategoryTransactions ...
+func (s *MemoryBudgetStore) GetCategoryTransactions(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategoryTransaction, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var transactions []models.BudgetTransactionCategoryTransaction
+
+	for _, tagged := range s.categoryTransactions {
+		category, ok := s.categories[tagged.categoryID]
+
+		if !ok || category.BudgetID != budgetID {
+			continue
+		}
+
+		transactions = append(transactions, models.BudgetTransactionCategoryTransaction{
+			BudgetTransactionCategoryID: tagged.categoryID,
+			TransactionName:             tagged.transactionName,
+			CategoryName:                category.CategoryName,
+			PlaidCategory:               tagged.plaidCategory,
+		})
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].TransactionName < transactions[j].TransactionName
+	})
+
+	return transactions, nil
+}
+
+// CreateCategoryTransaction ...
+func (s *MemoryBudgetStore) CreateCategoryTransaction(ctx context.Context, categoryTransaction models.BudgetTransactionCategoryTransaction) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.categoryTransactions = append(s.categoryTransactions, memoryCategoryTransaction{
+		categoryID:      categoryTransaction.BudgetTransactionCategoryID,
+		transactionName: categoryTransaction.TransactionName,
+		plaidCategory:   categoryTransaction.PlaidCategory,
+	})
+
+	return nil
+}
+
+// DeleteBudgetCategoryTransaction ...
+func (s *MemoryBudgetStore) DeleteBudgetCategoryTransaction(ctx context.Context, budgetID uuid.UUID, transactionName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	kept := s.categoryTransactions[:0]
+
+	for _, tagged := range s.categoryTransactions {
+		if tagged.transactionName != transactionName || s.categories[tagged.categoryID].BudgetID != budgetID {
+			kept = append(kept, tagged)
+		}
+	}
+
+	s.categoryTransactions = kept
+
+	return nil
+}
+
+// DeleteCategoryTransactions ...
+func (s *MemoryBudgetStore) DeleteCategoryTransactions(ctx context.Context, budgetTransactionCategoryID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	kept := s.categoryTransactions[:0]
+
+	for _, tagged := range s.categoryTransactions {
+		if tagged.categoryID != budgetTransactionCategoryID {
+			kept = append(kept, tagged)
+		}
+	}
+
+	s.categoryTransactions = kept
+
+	return nil
+}
+
+// GetCategorizationRules ...
+func (s *MemoryBudgetStore) GetCategorizationRules(ctx context.Context, budgetID uuid.UUID) ([]models.CategorizationRule, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rules := make([]models.CategorizationRule, 0)
+
+	for _, ruleID := range s.ruleOrder {
+		rule, ok := s.rules[ruleID]
+
+		if ok && rule.BudgetID == budgetID {
+			rules = append(rules, s.withCategoryName(rule))
+		}
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority < rules[j].Priority
+	})
+
+	return rules, nil
+}
+
+// GetCategorizationRule ...
+func (s *MemoryBudgetStore) GetCategorizationRule(ctx context.Context, ruleID uuid.UUID) (*models.CategorizationRule, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rule, ok := s.rules[ruleID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	rule = s.withCategoryName(rule)
+
+	return &rule, nil
+}
+
+// CreateCategorizationRule ...
+func (s *MemoryBudgetStore) CreateCategorizationRule(ctx context.Context, rule models.CategorizationRule) (*models.CategorizationRule, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rule.RuleID = uuid.New()
+	s.rules[rule.RuleID] = rule
+	s.ruleOrder = append(s.ruleOrder, rule.RuleID)
+
+	rule = s.withCategoryName(rule)
+
+	return &rule, nil
+}
+
+// UpdateCategorizationRule ...
+func (s *MemoryBudgetStore) UpdateCategorizationRule(ctx context.Context, rule models.CategorizationRule) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, ok := s.rules[rule.RuleID]
+
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	rule.BudgetID = existing.BudgetID
+	s.rules[rule.RuleID] = rule
+
+	return nil
+}
+
+// DeleteCategorizationRule ...
+func (s *MemoryBudgetStore) DeleteCategorizationRule(ctx context.Context, ruleID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.rules, ruleID)
+
+	return nil
+}
+
+// DeleteAccountCategorizationRules ...
+func (s *MemoryBudgetStore) DeleteAccountCategorizationRules(ctx context.Context, externalAccountID uuid.UUID) ([]models.CategorizationRule, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rules := make([]models.CategorizationRule, 0)
+
+	for _, ruleID := range s.ruleOrder {
+		rule, ok := s.rules[ruleID]
+
+		if !ok || rule.ExternalAccountID == nil || *rule.ExternalAccountID != externalAccountID {
+			continue
+		}
+
+		delete(s.rules, ruleID)
+		rules = append(rules, s.withCategoryName(rule))
+	}
+
+	return rules, nil
+}
+
+// GetPlaidCategoryMappings ...
+func (s *MemoryBudgetStore) GetPlaidCategoryMappings(ctx context.Context, budgetID uuid.UUID) ([]models.PlaidCategoryMapping, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	mappings := make([]models.PlaidCategoryMapping, 0)
+
+	for _, mappingID := range s.mappingOrder {
+		mapping, ok := s.mappings[mappingID]
+
+		if ok && mapping.BudgetID == budgetID {
+			mapping.CategoryName = s.categories[mapping.BudgetTransactionCategoryID].CategoryName
+			mappings = append(mappings, mapping)
+		}
+	}
+
+	return mappings, nil
+}
+
+// GetPlaidCategoryMapping ...
+func (s *MemoryBudgetStore) GetPlaidCategoryMapping(ctx context.Context, mappingID uuid.UUID) (*models.PlaidCategoryMapping, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	mapping, ok := s.mappings[mappingID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	mapping.CategoryName = s.categories[mapping.BudgetTransactionCategoryID].CategoryName
+
+	return &mapping, nil
+}
+
+// CreatePlaidCategoryMapping ...
+func (s *MemoryBudgetStore) CreatePlaidCategoryMapping(ctx context.Context, mapping models.PlaidCategoryMapping) (*models.PlaidCategoryMapping, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	mapping.MappingID = uuid.New()
+	s.mappings[mapping.MappingID] = mapping
+	s.mappingOrder = append(s.mappingOrder, mapping.MappingID)
+
+	mapping.CategoryName = s.categories[mapping.BudgetTransactionCategoryID].CategoryName
+
+	return &mapping, nil
+}
+
+// DeletePlaidCategoryMapping ...
+func (s *MemoryBudgetStore) DeletePlaidCategoryMapping(ctx context.Context, mappingID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.mappings, mappingID)
+
+	return nil
+}
+
+// GetMerchantAliases ...
+func (s *MemoryBudgetStore) GetMerchantAliases(ctx context.Context, budgetID uuid.UUID) ([]models.MerchantAlias, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	aliases := make([]models.MerchantAlias, 0)
+
+	for _, aliasID := range s.aliasOrder {
+		alias, ok := s.aliases[aliasID]
+
+		if ok && alias.BudgetID == budgetID {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases, nil
+}
+
+// GetMerchantAlias ...
+func (s *MemoryBudgetStore) GetMerchantAlias(ctx context.Context, aliasID uuid.UUID) (*models.MerchantAlias, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	alias, ok := s.aliases[aliasID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &alias, nil
+}
+
+// CreateMerchantAlias ...
+func (s *MemoryBudgetStore) CreateMerchantAlias(ctx context.Context, alias models.MerchantAlias) (*models.MerchantAlias, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	alias.AliasID = uuid.New()
+	s.aliases[alias.AliasID] = alias
+	s.aliasOrder = append(s.aliasOrder, alias.AliasID)
+
+	return &alias, nil
+}
+
+// DeleteMerchantAlias ...
+func (s *MemoryBudgetStore) DeleteMerchantAlias(ctx context.Context, aliasID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.aliases, aliasID)
+
+	return nil
+}
+
+// withCategoryName ...
+// Fills in the name of a rule's category the way the
+// postgres store joins it in. Callers hold the mutex
+func (s *MemoryBudgetStore) withCategoryName(rule models.CategorizationRule) models.CategorizationRule {
+	rule.CategoryName = s.categories[rule.BudgetTransactionCategoryID].CategoryName
+
+	return rule
+}
+
+// GetAlertRules ...
+func (s *MemoryBudgetStore) GetAlertRules(ctx context.Context, budgetID uuid.UUID) ([]models.AlertRule, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rules := make([]models.AlertRule, 0)
+
+	for _, alertRuleID := range s.alertRuleOrder {
+		rule, ok := s.alertRules[alertRuleID]
+
+		if ok && rule.BudgetID == budgetID {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, nil
+}
+
+// GetAlertRule ...
+func (s *MemoryBudgetStore) GetAlertRule(ctx context.Context, alertRuleID uuid.UUID) (*models.AlertRule, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	rule, ok := s.alertRules[alertRuleID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &rule, nil
+}
+
+// CreateAlertRule ...
+func (s *MemoryBudgetStore) CreateAlertRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rule.AlertRuleID = uuid.New()
+	rule.CreatedAt = time.Now().Format(time.RFC3339)
+	s.alertRules[rule.AlertRuleID] = rule
+	s.alertRuleOrder = append(s.alertRuleOrder, rule.AlertRuleID)
+
+	return &rule, nil
+}
+
+// DeleteAlertRule ...
+func (s *MemoryBudgetStore) DeleteAlertRule(ctx context.Context, alertRuleID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.alertRules, alertRuleID)
+	delete(s.alertsFired, alertRuleID)
+
+	return nil
+}
+
+// RecordAlertFired ...
+func (s *MemoryBudgetStore) RecordAlertFired(ctx context.Context, alertRuleID uuid.UUID, alertKey string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.alertsFired[alertRuleID] == nil {
+		s.alertsFired[alertRuleID] = make(map[string]bool)
+	}
+
+	if s.alertsFired[alertRuleID][alertKey] {
+		return false, nil
+	}
+
+	s.alertsFired[alertRuleID][alertKey] = true
+
+	return true, nil
+}
+
+// ForgetAlertFired ...
+func (s *MemoryBudgetStore) ForgetAlertFired(ctx context.Context, alertRuleID uuid.UUID, alertKey string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.alertsFired[alertRuleID], alertKey)
+
+	return nil
+}
+
+// GetIncomeSources ...
+func (s *MemoryBudgetStore) GetIncomeSources(ctx context.Context, budgetID uuid.UUID) ([]models.IncomeSource, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	sources := make([]models.IncomeSource, 0)
+
+	for _, incomeSourceID := range s.incomeSourceOrder {
+		source, ok := s.incomeSources[incomeSourceID]
+
+		if ok && source.BudgetID == budgetID {
+			sources = append(sources, source)
+		}
+	}
+
+	return sources, nil
+}
+
+// GetIncomeSource ...
+func (s *MemoryBudgetStore) GetIncomeSource(ctx context.Context, incomeSourceID uuid.UUID) (*models.IncomeSource, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	source, ok := s.incomeSources[incomeSourceID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &source, nil
+}
+
+// CreateIncomeSource ...
+func (s *MemoryBudgetStore) CreateIncomeSource(ctx context.Context, source models.IncomeSource) (*models.IncomeSource, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	source.IncomeSourceID = uuid.New()
+	s.incomeSources[source.IncomeSourceID] = source
+	s.incomeSourceOrder = append(s.incomeSourceOrder, source.IncomeSourceID)
+
+	return &source, nil
+}
+
+// DeleteIncomeSource ...
+func (s *MemoryBudgetStore) DeleteIncomeSource(ctx context.Context, incomeSourceID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.incomeSources, incomeSourceID)
+
+	return nil
+}
+
+// UnlinkAccountIncomeSources ...
+func (s *MemoryBudgetStore) UnlinkAccountIncomeSources(ctx context.Context, externalAccountID uuid.UUID) ([]models.IncomeSource, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	sources := make([]models.IncomeSource, 0)
+
+	for _, incomeSourceID := range s.incomeSourceOrder {
+		source, ok := s.incomeSources[incomeSourceID]
+
+		if !ok || source.ExternalAccountID == nil || *source.ExternalAccountID != externalAccountID {
+			continue
+		}
+
+		source.ExternalAccountID = nil
+		s.incomeSources[incomeSourceID] = source
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,182 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	merchantService "github.com/lakshay35/finlit-backend/services/merchant"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+)
+
+// GetMerchantAliases ...
+// Gets the merchant aliases of a budget
+func GetMerchantAliases(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.MerchantAlias, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	aliases, err := store.GetMerchantAliases(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return aliases, nil
+}
+
+// CreateMerchantAlias ...
+// Renames a merchant within a budget. The merchant can be
+// given as a raw transaction name, it's cleaned up the way
+// synced transaction names are. Every merchant can only
+// have one alias per budget
+func CreateMerchantAlias(ctx context.Context, alias models.MerchantAlias, userID uuid.UUID) (*models.MerchantAlias, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, alias.BudgetID, userID) && !roleService.IsUserOwner(ctx, alias.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to alias merchants for the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	alias.Merchant = merchantService.Normalize(alias.Merchant)
+	alias.Alias = strings.TrimSpace(alias.Alias)
+
+	if alias.Merchant == "" || alias.Alias == "" {
+		return nil, &errors.Error{
+			Message:    "Both merchant and alias need to be non-empty strings",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	existing, err := store.GetMerchantAliases(ctx, alias.BudgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	for _, other := range existing {
+		if merchantKey(other.Merchant) == merchantKey(alias.Merchant) {
+			return nil, &errors.Error{
+				Message:    "Merchant " + alias.Merchant + " already has an alias",
+				StatusCode: http.StatusConflict,
+			}
+		}
+	}
+
+	created, err := store.CreateMerchantAlias(ctx, alias)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return created, nil
+}
+
+// DeleteMerchantAlias ...
+// Deletes a merchant alias
+func DeleteMerchantAlias(ctx context.Context, aliasID uuid.UUID, userID uuid.UUID) *errors.Error {
+	alias, err := store.GetMerchantAlias(ctx, aliasID)
+
+	if err == sql.ErrNoRows {
+		return &errors.Error{
+			Message:    "Merchant alias not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if !roleService.IsUserAdmin(ctx, alias.BudgetID, userID) && !roleService.IsUserOwner(ctx, alias.BudgetID, userID) {
+		return &errors.Error{
+			Message:    "You are not authorized to change merchant aliases of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if deleteErr := store.DeleteMerchantAlias(ctx, aliasID); deleteErr != nil {
+		return &errors.Error{
+			Message:    deleteErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// merchantAliases ...
+// The names a budget gave merchants, keyed by merchantKey
+type merchantAliases map[string]string
+
+// loadMerchantAliases ...
+// Loads the merchant aliases of a budget
+func loadMerchantAliases(ctx context.Context, budgetID uuid.UUID) (merchantAliases, *errors.Error) {
+	aliases, err := store.GetMerchantAliases(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	loaded := make(merchantAliases, len(aliases))
+
+	for _, alias := range aliases {
+		loaded[merchantKey(alias.Merchant)] = alias.Alias
+	}
+
+	return loaded, nil
+}
+
+// merchant ...
+// The merchant of a transaction as the budget calls it
+func (a merchantAliases) merchant(tx models.Transaction) string {
+	merchant := tx.Merchant
+
+	if merchant == "" {
+		merchant = merchantService.Normalize(tx.Name)
+	}
+
+	if alias, ok := a[merchantKey(merchant)]; ok {
+		return alias
+	}
+
+	return merchant
+}
+
+// merchantKey ...
+// Merchants are matched ignoring case
+func merchantKey(merchant string) string {
+	return strings.ToLower(strings.TrimSpace(merchant))
+}
+
+// assignmentKey ...
+// Assignments are only known by transaction name, so they are
+// matched on the merchant cleaned up from it rather than the
+// stored or aliased one. Names of the same merchant differ in
+// store ids and dates, e.g. "BLUE BOTTLE 4411" and "SQ *BLUE
+// BOTTLE #123", and should still get the same category
+func assignmentKey(name string) string {
+	return merchantKey(merchantService.Normalize(name))
+}
@@ -0,0 +1,279 @@
+package budget
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+)
+
+// maxTrendPeriods caps how many periods expense trends
+// and summaries of past periods go back
+const maxTrendPeriods = 36
+
+// maxSummaryMonths caps how long an explicit summary
+// window can be, since limits are worked out period
+// by period over all of it
+const maxSummaryMonths = 36
+
+// expensePeriod ...
+// The days from start up to the day before end
+// and what an expense may spend in them
+type expensePeriod struct {
+	start time.Time
+	end   time.Time
+	limit float64
+}
+
+// contains ...
+// Whether a YYYY-MM-DD date falls in the period
+func (p expensePeriod) contains(date string) bool {
+	return date >= p.start.Format("2006-01-02") && date < p.end.Format("2006-01-02")
+}
+
+// lastDay ...
+// The last day of the period
+func (p expensePeriod) lastDay() time.Time {
+	return p.end.AddDate(0, 0, -1)
+}
+
+// GetBudgetExpenseTrends ...
+// The limit, spending and variance of every expense of a budget
+// in the last periods of its charge cycle, the current one included.
+// Sources that can't be used are left out like in the summary
+func GetBudgetExpenseTrends(ctx context.Context, budgetID uuid.UUID, periods int, userID uuid.UUID) ([]models.ExpenseTrend, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if periods < 1 || periods > maxTrendPeriods {
+		return nil, &errors.Error{
+			Message:    "periods must be between 1 and " + strconv.Itoa(maxTrendPeriods),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	sources, sourcesErr := GetBudgetTransactionSources(ctx, budgetID)
+
+	if sourcesErr != nil {
+		return nil, sourcesErr
+	}
+
+	expenses, expensesErr := expenseService.GetAllExpensesForBudget(ctx, budgetID, userID)
+
+	if expensesErr != nil {
+		return nil, expensesErr
+	}
+
+	now := time.Now().Local()
+	expensePeriods := make(map[uuid.UUID][]expensePeriod, len(expenses))
+	window := currentCategoryWindow(now, 0)
+	oldest := make(map[uuid.UUID]expensePeriod)
+
+	for _, expense := range expenses {
+		for ago := periods - 1; ago >= 0; ago-- {
+			start, end := expenseService.PreviousChargeCyclePeriod(expense.ExpenseChargeCycle, now, ago)
+
+			period := expensePeriod{
+				start: start,
+				end:   end,
+				limit: float64(expense.ExpenseValue),
+			}
+
+			expensePeriods[expense.ExpenseID] = append(expensePeriods[expense.ExpenseID], period)
+
+			if ago == periods-1 {
+				oldest[expense.ExpenseID] = period
+			}
+		}
+	}
+
+	snapshots, snapshotsErr := expenseService.GetBudgetPeriodSnapshots(ctx, budgetID)
+
+	if snapshotsErr != nil {
+		return nil, snapshotsErr
+	}
+
+	startDate, endDate := periodDates(oldest, window)
+
+	if unsettled := unsettledStartDate(expenses, snapshots, now); unsettled != "" && unsettled < startDate {
+		startDate = unsettled
+	}
+
+	transactions, sourceIssues := budgetTransactions(ctx, sources, startDate, endDate)
+
+	categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, budgetID)
+
+	if categoriesErr != nil {
+		return nil, categoriesErr
+	}
+
+	categorizer, categorizerErr := newCategorizer(ctx, budgetID)
+
+	if categorizerErr != nil {
+		return nil, categorizerErr
+	}
+
+	mappings, mappingsErr := expenseService.GetBudgetExpenseTransactionCategoryMappings(ctx, budgetID)
+
+	if mappingsErr != nil {
+		return nil, mappingsErr
+	}
+
+	spending := spendingByCategory(transactions, categorizer, categories)
+	expenseCategories := expenseCategoryNames(newCategoryTree(categories), mappings)
+
+	carried, snapshots, settleErr := settleRollovers(ctx, expenses, snapshots, spending, expenseCategories, blocksSettlement(sourceIssues), now)
+
+	if settleErr != nil {
+		return nil, settleErr
+	}
+
+	trends := make([]models.ExpenseTrend, 0, len(expenses))
+
+	for _, expense := range expenses {
+		trend := models.ExpenseTrend{
+			ExpenseID:   expense.ExpenseID,
+			ExpenseName: expense.ExpenseName,
+			Periods:     make([]models.ExpensePeriod, 0, periods),
+		}
+
+		for i, period := range expensePeriods[expense.ExpenseID] {
+			actual := periodSpending(spending, expenseCategories[expense.ExpenseID], period)
+			carriedIn := 0.0
+
+			if expense.Rollover && i == len(expensePeriods[expense.ExpenseID])-1 {
+				carriedIn = carried[expense.ExpenseID]
+			} else if expense.Rollover {
+				carriedIn = carriedInto(expense, snapshots, period)
+			}
+
+			trend.Periods = append(trend.Periods, models.ExpensePeriod{
+				PeriodStart: period.start.Format("2006-01-02"),
+				PeriodEnd:   period.lastDay().Format("2006-01-02"),
+				Limit:       period.limit,
+				CarriedOver: carriedIn,
+				Actual:      actual,
+				Variance:    actual - period.limit - carriedIn,
+			})
+		}
+
+		trends = append(trends, trend)
+	}
+
+	return trends, nil
+}
+
+// summaryPeriods ...
+// The period each expense is summarized over and the window category
+// totals cover. An explicit window is used for both, with every
+// expense's limit prorated over the periods of its charge cycle the
+// window touches. Otherwise each expense gets the period of its cycle
+// PeriodsAgo periods back and categories the window of the past
+// 30 days moved back as many windows
+func summaryPeriods(expenses []models.Expense, period models.SummaryPeriod, now time.Time) (map[uuid.UUID]expensePeriod, expensePeriod, *errors.Error) {
+	periods := make(map[uuid.UUID]expensePeriod, len(expenses))
+
+	if period.StartDate == "" && period.EndDate == "" {
+		if period.PeriodsAgo < 0 || period.PeriodsAgo > maxTrendPeriods {
+			return nil, expensePeriod{}, &errors.Error{
+				Message:    "periods_ago must be between 0 and " + strconv.Itoa(maxTrendPeriods),
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+
+		for _, expense := range expenses {
+			start, end := expenseService.PreviousChargeCyclePeriod(expense.ExpenseChargeCycle, now, period.PeriodsAgo)
+
+			periods[expense.ExpenseID] = expensePeriod{
+				start: start,
+				end:   end,
+				limit: float64(expense.ExpenseValue),
+			}
+		}
+
+		return periods, currentCategoryWindow(now, period.PeriodsAgo), nil
+	}
+
+	if period.PeriodsAgo != 0 {
+		return nil, expensePeriod{}, &errors.Error{
+			Message:    "periods_ago can't be combined with start_date and end_date",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	start, startErr := time.Parse("2006-01-02", period.StartDate)
+	end, endErr := time.Parse("2006-01-02", period.EndDate)
+
+	if startErr != nil || endErr != nil {
+		return nil, expensePeriod{}, &errors.Error{
+			Message:    "start_date and end_date must both be dates formatted as YYYY-MM-DD",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if end.Before(start) {
+		return nil, expensePeriod{}, &errors.Error{
+			Message:    "end_date can't be before start_date",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if end.After(start.AddDate(0, maxSummaryMonths, 0)) {
+		return nil, expensePeriod{}, &errors.Error{
+			Message:    "start_date and end_date can't be more than " + strconv.Itoa(maxSummaryMonths) + " months apart",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	window := expensePeriod{start: start, end: end.AddDate(0, 0, 1)}
+
+	for _, expense := range expenses {
+		periods[expense.ExpenseID] = expensePeriod{
+			start: window.start,
+			end:   window.end,
+			limit: expenseService.ChargeCycleLimit(expense.ExpenseChargeCycle, float64(expense.ExpenseValue), window.start, window.end),
+		}
+	}
+
+	return periods, window, nil
+}
+
+// currentCategoryWindow ...
+// The days from 30 days ago through today, moved
+// back the given number of such windows
+func currentCategoryWindow(now time.Time, periodsAgo int) expensePeriod {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	end := today.AddDate(0, 0, 1-31*periodsAgo)
+
+	return expensePeriod{start: end.AddDate(0, 0, -31), end: end}
+}
+
+// periodDates ...
+// The first and last day transactions are needed for
+// to cover every period and the category window
+func periodDates(periods map[uuid.UUID]expensePeriod, window expensePeriod) (string, string) {
+	start := window.start
+	end := window.end
+
+	for _, period := range periods {
+		if period.start.Before(start) {
+			start = period.start
+		}
+
+		if period.end.After(end) {
+			end = period.end
+		}
+	}
+
+	return start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02")
+}
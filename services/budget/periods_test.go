@@ -0,0 +1,82 @@
+package budget
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+func TestSummaryPeriodsAgoBounds(t *testing.T) {
+	now := day("2021-04-15")
+	rent := models.Expense{ExpenseID: uuid.New(), ExpenseValue: 1200, ExpenseChargeCycle: models.ExpenseChargeCycle{Unit: "monthly", Days: 30}}
+
+	tests := []struct {
+		name       string
+		periodsAgo int
+		wantStart  string
+		wantStatus int
+	}{
+		{name: "current", periodsAgo: 0, wantStart: "2021-04-01"},
+		{name: "last month", periodsAgo: 1, wantStart: "2021-03-01"},
+		{name: "at the cap", periodsAgo: maxTrendPeriods, wantStart: "2018-04-01"},
+		{name: "over the cap", periodsAgo: maxTrendPeriods + 1, wantStatus: http.StatusBadRequest},
+		{name: "huge", periodsAgo: 1 << 40, wantStatus: http.StatusBadRequest},
+		{name: "negative", periodsAgo: -1, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			periods, _, err := summaryPeriods([]models.Expense{rent}, models.SummaryPeriod{PeriodsAgo: test.periodsAgo}, now)
+
+			if test.wantStatus != 0 {
+				if err == nil || err.StatusCode != test.wantStatus {
+					t.Fatalf("summaryPeriods() = %v, want status %d", err, test.wantStatus)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err.Message)
+			}
+
+			if start := periods[rent.ExpenseID].start.Format("2006-01-02"); start != test.wantStart {
+				t.Errorf("period starts %s, want %s", start, test.wantStart)
+			}
+		})
+	}
+}
+
+func TestSummaryPeriodsWindowBounds(t *testing.T) {
+	now := day("2021-04-15")
+	gym := models.Expense{ExpenseID: uuid.New(), ExpenseValue: 70, ExpenseChargeCycle: models.ExpenseChargeCycle{Unit: "weekly"}}
+
+	tests := []struct {
+		name       string
+		startDate  string
+		endDate    string
+		wantStatus int
+	}{
+		{name: "one day", startDate: "2021-04-01", endDate: "2021-04-01"},
+		{name: "at the cap", startDate: "2018-04-01", endDate: "2021-04-01"},
+		{name: "a day over the cap", startDate: "2018-04-01", endDate: "2021-04-02", wantStatus: http.StatusBadRequest},
+		{name: "centuries", startDate: "2001-01-01", endDate: "2400-01-01", wantStatus: http.StatusBadRequest},
+		{name: "backwards", startDate: "2021-04-02", endDate: "2021-04-01", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := summaryPeriods([]models.Expense{gym}, models.SummaryPeriod{StartDate: test.startDate, EndDate: test.endDate}, now)
+
+			if test.wantStatus == 0 && err != nil {
+				t.Fatalf("summaryPeriods() = %s, want no error", err.Message)
+			}
+
+			if test.wantStatus != 0 && (err == nil || err.StatusCode != test.wantStatus) {
+				t.Fatalf("summaryPeriods() = %v, want status %d", err, test.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// defaultPlaidCategory ...
+// A budget category of the default template
+// and the Plaid categories mapped to it
+type defaultPlaidCategory struct {
+	categoryName    string
+	parentName      string
+	plaidCategories [][]string
+}
+
+// defaultPlaidCategories is the template new budgets are seeded
+// with. Parents come before their children, and paths below one
+// mapped elsewhere carve out a part of it, e.g. groceries out of shops
+var defaultPlaidCategories = []defaultPlaidCategory{
+	{categoryName: "Food and Drink", plaidCategories: [][]string{{"Food and Drink"}}},
+	{categoryName: "Restaurants", parentName: "Food and Drink", plaidCategories: [][]string{{"Food and Drink", "Restaurants"}}},
+	{categoryName: "Bars", parentName: "Food and Drink", plaidCategories: [][]string{{"Food and Drink", "Bar"}, {"Food and Drink", "Nightlife"}}},
+	{categoryName: "Groceries", parentName: "Food and Drink", plaidCategories: [][]string{{"Shops", "Supermarkets and Groceries"}, {"Shops", "Food and Beverage Store"}}},
+	{categoryName: "Shopping", plaidCategories: [][]string{{"Shops"}}},
+	{categoryName: "Transportation", plaidCategories: [][]string{{"Travel", "Gas Stations"}, {"Travel", "Public Transportation Services"}, {"Travel", "Taxi"}, {"Travel", "Parking"}, {"Travel", "Car Service"}, {"Service", "Automotive"}}},
+	{categoryName: "Travel", plaidCategories: [][]string{{"Travel"}}},
+	{categoryName: "Utilities", plaidCategories: [][]string{{"Service", "Utilities"}, {"Service", "Telecommunication Services"}, {"Service", "Cable"}, {"Service", "Internet Services"}}},
+	{categoryName: "Health", plaidCategories: [][]string{{"Healthcare"}, {"Recreation", "Gyms and Fitness Centers"}}},
+	{categoryName: "Entertainment", plaidCategories: [][]string{{"Recreation"}, {"Service", "Entertainment"}}},
+	{categoryName: "Personal Care", plaidCategories: [][]string{{"Service", "Personal Care"}}},
+	{categoryName: "Subscriptions", plaidCategories: [][]string{{"Service", "Subscription"}}},
+	{categoryName: "Education", plaidCategories: [][]string{{"Service", "Education"}}},
+	{categoryName: "Fees", plaidCategories: [][]string{{"Bank Fees"}}},
+	{categoryName: "Taxes", plaidCategories: [][]string{{"Tax"}}},
+	{categoryName: "Giving", plaidCategories: [][]string{{"Community"}}},
+}
+
+// GetPlaidCategoryMappings ...
+// Gets the Plaid category mappings of a budget
+func GetPlaidCategoryMappings(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.PlaidCategoryMapping, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	mappings, err := store.GetPlaidCategoryMappings(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return mappings, nil
+}
+
+// CreatePlaidCategoryMapping ...
+// Maps a Plaid category id or path to a category of a budget.
+// Every id and path can only be mapped once per budget
+func CreatePlaidCategoryMapping(ctx context.Context, mapping models.PlaidCategoryMapping, userID uuid.UUID) (*models.PlaidCategoryMapping, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, mapping.BudgetID, userID) && !roleService.IsUserOwner(ctx, mapping.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to map Plaid categories for the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	mapping.PlaidCategoryID = strings.TrimSpace(mapping.PlaidCategoryID)
+
+	for i := range mapping.PlaidCategory {
+		mapping.PlaidCategory[i] = strings.TrimSpace(mapping.PlaidCategory[i])
+
+		if mapping.PlaidCategory[i] == "" {
+			return nil, &errors.Error{
+				Message:    "plaid_category can't contain empty levels",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+
+	if (mapping.PlaidCategoryID == "") == (len(mapping.PlaidCategory) == 0) {
+		return nil, &errors.Error{
+			Message:    "Exactly one of plaid_category_id and plaid_category must be provided",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	category, categoryErr := store.GetTransactionCategory(ctx, mapping.BudgetTransactionCategoryID)
+
+	if categoryErr != nil || category.BudgetID != mapping.BudgetID {
+		return nil, &errors.Error{
+			Message:    "Provided category not found",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	existing, err := store.GetPlaidCategoryMappings(ctx, mapping.BudgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if isPlaidCategoryMapped(existing, mapping) {
+		return nil, &errors.Error{
+			Message:    "Plaid category is already mapped to a category of this budget",
+			StatusCode: http.StatusConflict,
+		}
+	}
+
+	created, err := store.CreatePlaidCategoryMapping(ctx, mapping)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return created, nil
+}
+
+// DeletePlaidCategoryMapping ...
+// Deletes a Plaid category mapping
+func DeletePlaidCategoryMapping(ctx context.Context, mappingID uuid.UUID, userID uuid.UUID) *errors.Error {
+	mapping, err := store.GetPlaidCategoryMapping(ctx, mappingID)
+
+	if err == sql.ErrNoRows {
+		return &errors.Error{
+			Message:    "Plaid category mapping not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if !roleService.IsUserAdmin(ctx, mapping.BudgetID, userID) && !roleService.IsUserOwner(ctx, mapping.BudgetID, userID) {
+		return &errors.Error{
+			Message:    "You are not authorized to change Plaid category mappings of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if deleteErr := store.DeletePlaidCategoryMapping(ctx, mappingID); deleteErr != nil {
+		return &errors.Error{
+			Message:    deleteErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// ApplyDefaultPlaidCategoryMappings ...
+// Seeds a budget with the default template the way new budgets
+// are, for budgets created before there was one or whose mappings
+// were deleted. Categories are matched by name and Plaid
+// categories the budget already maps are left alone
+func ApplyDefaultPlaidCategoryMappings(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.PlaidCategoryMapping, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to map Plaid categories for the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		return seedPlaidCategoryMappings(ctx, budgetID)
+	})
+
+	if txErr != nil {
+		return nil, toServiceError(txErr)
+	}
+
+	return GetPlaidCategoryMappings(ctx, budgetID, userID)
+}
+
+// seedPlaidCategoryMappings ...
+// Creates the categories of the default template a budget
+// doesn't have yet and maps the template's Plaid categories
+func seedPlaidCategoryMappings(ctx context.Context, budgetID uuid.UUID) error {
+	categories, err := store.GetTransactionCategories(ctx, budgetID)
+
+	if err != nil {
+		return err
+	}
+
+	mappings, err := store.GetPlaidCategoryMappings(ctx, budgetID)
+
+	if err != nil {
+		return err
+	}
+
+	categoryIDs := make(map[string]uuid.UUID, len(categories))
+
+	for _, category := range categories {
+		categoryIDs[strings.ToLower(category.CategoryName)] = category.BudgetTransactionCategoryID
+	}
+
+	for _, template := range defaultPlaidCategories {
+		categoryID, ok := categoryIDs[strings.ToLower(template.categoryName)]
+
+		if !ok {
+			payload := models.BudgetTransactionCategoryCreationPayload{
+				BudgetID:     budgetID,
+				CategoryName: template.categoryName,
+			}
+
+			if parentID, ok := categoryIDs[strings.ToLower(template.parentName)]; ok && template.parentName != "" {
+				payload.ParentCategoryID = &parentID
+			}
+
+			created, createErr := store.CreateTransactionCategory(ctx, payload)
+
+			if createErr != nil {
+				return createErr
+			}
+
+			categoryID = created.BudgetTransactionCategoryID
+			categoryIDs[strings.ToLower(template.categoryName)] = categoryID
+		}
+
+		for _, plaidCategory := range template.plaidCategories {
+			mapping := models.PlaidCategoryMapping{
+				BudgetID:                    budgetID,
+				BudgetTransactionCategoryID: categoryID,
+				PlaidCategory:               plaidCategory,
+			}
+
+			if isPlaidCategoryMapped(mappings, mapping) {
+				continue
+			}
+
+			created, createErr := store.CreatePlaidCategoryMapping(ctx, mapping)
+
+			if createErr != nil {
+				return createErr
+			}
+
+			mappings = append(mappings, *created)
+		}
+	}
+
+	return nil
+}
+
+// isPlaidCategoryMapped ...
+// Whether the Plaid category id or path of a mapping is in mappings
+func isPlaidCategoryMapped(mappings []models.PlaidCategoryMapping, mapping models.PlaidCategoryMapping) bool {
+	for _, existing := range mappings {
+		if mapping.PlaidCategoryID != "" && existing.PlaidCategoryID == mapping.PlaidCategoryID {
+			return true
+		}
+
+		if len(mapping.PlaidCategory) > 0 && len(existing.PlaidCategory) == len(mapping.PlaidCategory) &&
+			hasPlaidCategoryPrefix(existing.PlaidCategory, mapping.PlaidCategory) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchPlaidCategoryMapping ...
+// Finds the mapping for a transaction's Plaid category. A mapping
+// for its id wins, then the one with the longest path the
+// transaction's category starts with. Nil when none matches
+func matchPlaidCategoryMapping(mappings []models.PlaidCategoryMapping, tx models.Transaction) *models.PlaidCategoryMapping {
+	var matched *models.PlaidCategoryMapping
+
+	for i := range mappings {
+		mapping := &mappings[i]
+
+		if mapping.PlaidCategoryID != "" && mapping.PlaidCategoryID == tx.CategoryID {
+			return mapping
+		}
+
+		if len(mapping.PlaidCategory) == 0 || !hasPlaidCategoryPrefix(tx.Category, mapping.PlaidCategory) {
+			continue
+		}
+
+		if matched == nil || len(mapping.PlaidCategory) > len(matched.PlaidCategory) {
+			matched = mapping
+		}
+	}
+
+	return matched
+}
+
+// hasPlaidCategoryPrefix ...
+// Whether a Plaid category hierarchy starts with
+// the levels of prefix, ignoring case
+func hasPlaidCategoryPrefix(hierarchy []string, prefix []string) bool {
+	if len(prefix) > len(hierarchy) {
+		return false
+	}
+
+	for i, level := range prefix {
+		if !strings.EqualFold(hierarchy[i], level) {
+			return false
+		}
+	}
+
+	return true
+}
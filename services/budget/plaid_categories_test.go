@@ -0,0 +1,203 @@
+package budget
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// categoryNamed ...
+// Finds a category of a budget by name
+func categoryNamed(t *testing.T, ctx context.Context, budgetID uuid.UUID, name string) models.BudgetTransactionCategory {
+	t.Helper()
+
+	categories, err := store.GetTransactionCategories(ctx, budgetID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, category := range categories {
+		if strings.EqualFold(category.CategoryName, name) {
+			return category
+		}
+	}
+
+	t.Fatalf("budget has no category named %s", name)
+
+	return models.BudgetTransactionCategory{}
+}
+
+// plaidTransaction ...
+// A transaction Plaid put in the given category
+func plaidTransaction(name string, categoryID string, category ...string) models.Transaction {
+	tx := transaction(name, "2021-04-01", 30)
+	tx.CategoryID = categoryID
+	tx.Category = category
+
+	return tx
+}
+
+func TestMatchPlaidCategoryMapping(t *testing.T) {
+	shopping, groceries, produce := uuid.New(), uuid.New(), uuid.New()
+
+	mappings := []models.PlaidCategoryMapping{
+		{BudgetTransactionCategoryID: shopping, CategoryName: "Shopping", PlaidCategory: []string{"Shops"}},
+		{BudgetTransactionCategoryID: groceries, CategoryName: "Groceries", PlaidCategory: []string{"Shops", "Supermarkets and Groceries"}},
+		{BudgetTransactionCategoryID: produce, CategoryName: "Produce", PlaidCategoryID: "19047001"},
+	}
+
+	tests := []struct {
+		name string
+		tx   models.Transaction
+		want string
+	}{
+		{name: "top level", tx: plaidTransaction("TARGET", "19000000", "Shops"), want: "Shopping"},
+		{name: "other subcategory", tx: plaidTransaction("GAP", "19012000", "Shops", "Clothing and Accessories"), want: "Shopping"},
+		{name: "most specific path", tx: plaidTransaction("SAFEWAY", "19047000", "Shops", "Supermarkets and Groceries"), want: "Groceries"},
+		{name: "below the most specific path", tx: plaidTransaction("SAFEWAY", "19047002", "Shops", "Supermarkets and Groceries", "Deli"), want: "Groceries"},
+		{name: "case", tx: plaidTransaction("SAFEWAY", "", "SHOPS", "supermarkets and groceries"), want: "Groceries"},
+		{name: "id over path", tx: plaidTransaction("FARMERS MARKET", "19047001", "Shops", "Supermarkets and Groceries"), want: "Produce"},
+		{name: "unmapped", tx: plaidTransaction("UNITED", "22001000", "Travel", "Airlines and Aviation Services"), want: ""},
+		{name: "no category", tx: plaidTransaction("CHECK 1042", ""), want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ""
+
+			if mapping := matchPlaidCategoryMapping(mappings, test.tx); mapping != nil {
+				got = mapping.CategoryName
+			}
+
+			if got != test.want {
+				t.Errorf("matched %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCategorizerPrefersAssignmentsThenRulesThenPlaid(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget := treeBudget(t, ctx, ownerID)
+
+	pantry := subcategory(t, ctx, ownerID, budget.BudgetID, "Pantry", nil)
+	dining := subcategory(t, ctx, ownerID, budget.BudgetID, "Dining", nil)
+
+	tx := plaidTransaction("WHOLE FOODS 1021", "19047000", "Shops", "Supermarkets and Groceries")
+
+	categorize := func() models.TransactionCategorization {
+		categorizer, categorizerErr := newCategorizer(ctx, budget.BudgetID)
+
+		if categorizerErr != nil {
+			t.Fatal(categorizerErr.Message)
+		}
+
+		return categorizer.categorize(tx, categorizer.merchant(tx))
+	}
+
+	// The default template maps the transaction's Plaid category
+	if result := categorize(); result.CategoryName != "Groceries" || result.Source != models.CategorizationSourcePlaid || result.MatchedMapping == nil {
+		t.Fatalf("categorized as %q by %s, want Groceries by the Plaid mapping", result.CategoryName, result.Source)
+	}
+
+	if _, ruleErr := CreateCategorizationRule(ctx, models.CategorizationRule{
+		BudgetID:                    budget.BudgetID,
+		BudgetTransactionCategoryID: pantry.BudgetTransactionCategoryID,
+		NameContains:                "whole foods",
+	}, ownerID); ruleErr != nil {
+		t.Fatal(ruleErr.Message)
+	}
+
+	if result := categorize(); result.CategoryName != "Pantry" || result.Source != models.CategorizationSourceRule {
+		t.Fatalf("categorized as %q by %s, want Pantry by the rule", result.CategoryName, result.Source)
+	}
+
+	if err := store.CreateCategoryTransaction(ctx, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: dining.BudgetTransactionCategoryID,
+		TransactionName:             tx.Name,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := categorize(); result.CategoryName != "Dining" || result.Source != models.CategorizationSourceAssignment {
+		t.Fatalf("categorized as %q by %s, want Dining by the assignment", result.CategoryName, result.Source)
+	}
+}
+
+func TestPlaidCategoryMappingOfDeletedCategory(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget := treeBudget(t, ctx, ownerID)
+
+	groceries := categoryNamed(t, ctx, budget.BudgetID, "Groceries")
+	tx := plaidTransaction("SAFEWAY", "19047000", "Shops", "Supermarkets and Groceries")
+
+	if _, deleteErr := DeleteTransactionCategory(ctx, groceries.BudgetTransactionCategoryID, nil, false, ownerID); deleteErr != nil {
+		t.Fatalf("DeleteTransactionCategory() = %s", deleteErr.Message)
+	}
+
+	mappings, err := store.GetPlaidCategoryMappings(ctx, budget.BudgetID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mapping := range mappings {
+		if mapping.BudgetTransactionCategoryID == groceries.BudgetTransactionCategoryID {
+			t.Errorf("mapping %v still points at the deleted category", mapping.PlaidCategory)
+		}
+	}
+
+	// The broader mapping of shops takes over
+	categorizer, categorizerErr := newCategorizer(ctx, budget.BudgetID)
+
+	if categorizerErr != nil {
+		t.Fatal(categorizerErr.Message)
+	}
+
+	if result := categorizer.categorize(tx, categorizer.merchant(tx)); result.CategoryName != "Shopping" {
+		t.Errorf("categorized as %q, want Shopping", result.CategoryName)
+	}
+
+	// A new mapping can't point at the deleted category
+	_, createErr := CreatePlaidCategoryMapping(ctx, models.PlaidCategoryMapping{
+		BudgetID:                    budget.BudgetID,
+		BudgetTransactionCategoryID: groceries.BudgetTransactionCategoryID,
+		PlaidCategory:               []string{"Shops", "Supermarkets and Groceries"},
+	}, ownerID)
+
+	if createErr == nil {
+		t.Error("mapped a Plaid category to a deleted category")
+	}
+}
+
+func TestPlaidCategoryMappingsFollowMergedCategory(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budget := treeBudget(t, ctx, ownerID)
+
+	groceries := categoryNamed(t, ctx, budget.BudgetID, "Groceries")
+	shopping := categoryNamed(t, ctx, budget.BudgetID, "Shopping")
+	tx := plaidTransaction("SAFEWAY", "19047000", "Shops", "Supermarkets and Groceries")
+
+	if _, deleteErr := DeleteTransactionCategory(ctx, groceries.BudgetTransactionCategoryID, &shopping.BudgetTransactionCategoryID, false, ownerID); deleteErr != nil {
+		t.Fatalf("DeleteTransactionCategory() = %s", deleteErr.Message)
+	}
+
+	categorizer, categorizerErr := newCategorizer(ctx, budget.BudgetID)
+
+	if categorizerErr != nil {
+		t.Fatal(categorizerErr.Message)
+	}
+
+	result := categorizer.categorize(tx, categorizer.merchant(tx))
+
+	if result.CategoryName != "Shopping" || result.MatchedMapping == nil || len(result.MatchedMapping.PlaidCategory) != 2 {
+		t.Errorf("categorized as %q by %+v, want Shopping by the moved groceries mapping", result.CategoryName, result.MatchedMapping)
+	}
+}
@@ -0,0 +1,1045 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	"github.com/lib/pq"
+)
+
+// PostgresBudgetStore ...
+// BudgetStore backed by the postgres database
+type PostgresBudgetStore struct{}
+
+// NewPostgresBudgetStore ...
+// Creates a postgres backed BudgetStore
+func NewPostgresBudgetStore() *PostgresBudgetStore {
+	return &PostgresBudgetStore{}
+}
+
+// FindBudget ...
+func (s *PostgresBudgetStore) FindBudget(ctx context.Context, ownerID uuid.UUID, budgetName string) (*models.Budget, error) {
+	query := "SELECT budget_id, owner_id, budget_name, zero_based FROM budgets WHERE owner_id = $1 AND budget_name = $2"
+
+	var res models.Budget
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, ownerID, budgetName).Scan(&res.BudgetID, &res.OwnerID, &res.BudgetName, &res.ZeroBased)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetBudget ...
+func (s *PostgresBudgetStore) GetBudget(ctx context.Context, budgetID uuid.UUID) (*models.Budget, error) {
+	query := "SELECT budget_id, owner_id, budget_name, zero_based FROM budgets WHERE budget_id = $1"
+
+	var res models.Budget
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, budgetID).Scan(&res.BudgetID, &res.OwnerID, &res.BudgetName, &res.ZeroBased)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// GetBudgets ...
+func (s *PostgresBudgetStore) GetBudgets(ctx context.Context, ownerID uuid.UUID) ([]models.Budget, error) {
+	query := "SELECT budget_id, budget_name, owner_id, zero_based FROM budgets where owner_id = $1"
+
+	res, err := database.Conn(ctx).QueryContext(ctx, query, ownerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	var result []models.Budget = make([]models.Budget, 0)
+
+	for res.Next() {
+		var temp models.Budget
+
+		if scanErr := res.Scan(&temp.BudgetID, &temp.BudgetName, &temp.OwnerID, &temp.ZeroBased); scanErr != nil {
+			return nil, scanErr
+		}
+
+		result = append(result, temp)
+	}
+
+	return result, nil
+}
+
+// CreateBudget ...
+func (s *PostgresBudgetStore) CreateBudget(ctx context.Context, ownerID uuid.UUID, budgetName string) (*models.Budget, error) {
+	query := "INSERT INTO budgets (owner_id, budget_name) VALUES ($1, $2) RETURNING owner_id, budget_name, budget_id, zero_based"
+
+	var result models.Budget
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, ownerID, budgetName).Scan(
+		&result.OwnerID,
+		&result.BudgetName,
+		&result.BudgetID,
+		&result.ZeroBased,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// SetBudgetZeroBased ...
+func (s *PostgresBudgetStore) SetBudgetZeroBased(ctx context.Context, budgetID uuid.UUID, zeroBased bool) error {
+	query := "UPDATE budgets SET zero_based = $2 WHERE budget_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, budgetID, zeroBased)
+
+	return err
+}
+
+// DeleteBudget ...
+func (s *PostgresBudgetStore) DeleteBudget(ctx context.Context, budgetID uuid.UUID) error {
+	query := "DELETE FROM budgets where budget_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, budgetID)
+
+	return err
+}
+
+// GetTransactionSources ...
+func (s *PostgresBudgetStore) GetTransactionSources(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionSourcePayload, error) {
+	query := "SELECT ea.external_account_id, ea.account_name, bts.budget_id, bts.budget_transaction_source_id FROM external_accounts ea JOIN budget_transaction_sources bts ON bts.external_account_id = ea.external_account_id WHERE bts.budget_id = $1"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	accounts := make([]models.BudgetTransactionSourcePayload, 0)
+
+	for rows.Next() {
+		var temp models.BudgetTransactionSourcePayload
+
+		if scanErr := rows.Scan(&temp.ExternalAccountID, &temp.AccountName, &temp.BudgetID, &temp.BudgetTransactionSourceID); scanErr != nil {
+			return nil, scanErr
+		}
+
+		accounts = append(accounts, temp)
+	}
+
+	return accounts, nil
+}
+
+// GetTransactionSource ...
+func (s *PostgresBudgetStore) GetTransactionSource(ctx context.Context, budgetTransactionSourceID uuid.UUID) (*models.BudgetTransactionSource, error) {
+	query := "SELECT budget_transaction_source_id, external_account_id, budget_id FROM budget_transaction_sources WHERE budget_transaction_source_id = $1"
+
+	var res models.BudgetTransactionSource
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, budgetTransactionSourceID).Scan(&res.BudgetTransactionSourceID, &res.ExternalAccountID, &res.BudgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// CreateTransactionSource ...
+func (s *PostgresBudgetStore) CreateTransactionSource(ctx context.Context, source models.BudgetTransactionSourceCreationPayload) (*models.BudgetTransactionSource, error) {
+	query := "INSERT INTO budget_transaction_sources (external_account_id, budget_id) VALUES ($1, $2) RETURNING budget_transaction_source_id"
+
+	res := models.BudgetTransactionSource{
+		BudgetID:          source.BudgetID,
+		ExternalAccountID: source.ExternalAccountID,
+	}
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, source.ExternalAccountID, source.BudgetID).Scan(&res.BudgetTransactionSourceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// DeleteTransactionSource ...
+func (s *PostgresBudgetStore) DeleteTransactionSource(ctx context.Context, budgetTransactionSourceID uuid.UUID) error {
+	query := "DELETE FROM budget_transaction_sources WHERE budget_transaction_source_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, budgetTransactionSourceID)
+
+	return err
+}
+
+// DeleteAllTransactionSources ...
+func (s *PostgresBudgetStore) DeleteAllTransactionSources(ctx context.Context, budgetID uuid.UUID) error {
+	query := "DELETE FROM budget_transaction_sources WHERE budget_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, budgetID)
+
+	return err
+}
+
+// GetAccountSourceBudgets ...
+func (s *PostgresBudgetStore) GetAccountSourceBudgets(ctx context.Context, externalAccountID uuid.UUID) ([]models.Budget, error) {
+	query := `SELECT DISTINCT b.budget_id, b.budget_name, b.owner_id, b.zero_based FROM budgets b
+	JOIN budget_transaction_sources bts ON bts.budget_id = b.budget_id WHERE bts.external_account_id = $1`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, externalAccountID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	budgets := make([]models.Budget, 0)
+
+	for rows.Next() {
+		var temp models.Budget
+
+		if scanErr := rows.Scan(&temp.BudgetID, &temp.BudgetName, &temp.OwnerID, &temp.ZeroBased); scanErr != nil {
+			return nil, scanErr
+		}
+
+		budgets = append(budgets, temp)
+	}
+
+	return budgets, nil
+}
+
+// DeleteAccountTransactionSources ...
+func (s *PostgresBudgetStore) DeleteAccountTransactionSources(ctx context.Context, externalAccountID uuid.UUID) error {
+	query := "DELETE FROM budget_transaction_sources WHERE external_account_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, externalAccountID)
+
+	return err
+}
+
+// GetTransactionCategories ...
+func (s *PostgresBudgetStore) GetTransactionCategories(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategory, error) {
+	query := "SELECT budget_transaction_category_id, budget_id, category_name, parent_category_id FROM budget_transaction_categories WHERE budget_id = $1"
+
+	res, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	categories := make([]models.BudgetTransactionCategory, 0)
+
+	for res.Next() {
+		var temp models.BudgetTransactionCategory
+
+		if scanErr := res.Scan(&temp.BudgetTransactionCategoryID, &temp.BudgetID, &temp.CategoryName, &temp.ParentCategoryID); scanErr != nil {
+			return nil, scanErr
+		}
+
+		categories = append(categories, temp)
+	}
+
+	return categories, nil
+}
+
+// GetTransactionCategory ...
+func (s *PostgresBudgetStore) GetTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID) (*models.BudgetTransactionCategory, error) {
+	query := "SELECT budget_transaction_category_id, budget_id, category_name, parent_category_id FROM budget_transaction_categories WHERE budget_transaction_category_id = $1"
+
+	var temp models.BudgetTransactionCategory
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, budgetTransactionCategoryID).Scan(&temp.BudgetTransactionCategoryID, &temp.BudgetID, &temp.CategoryName, &temp.ParentCategoryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &temp, nil
+}
+
+// CreateTransactionCategory ...
+func (s *PostgresBudgetStore) CreateTransactionCategory(ctx context.Context, category models.BudgetTransactionCategoryCreationPayload) (*models.BudgetTransactionCategory, error) {
+	query := "INSERT INTO budget_transaction_categories (budget_id, category_name, parent_category_id) VALUES ($1, $2, $3) RETURNING budget_transaction_category_id, budget_id, category_name, parent_category_id"
+
+	var temp models.BudgetTransactionCategory
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, category.BudgetID, category.CategoryName, category.ParentCategoryID).Scan(&temp.BudgetTransactionCategoryID, &temp.BudgetID, &temp.CategoryName, &temp.ParentCategoryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &temp, nil
+}
+
+// SetTransactionCategoryParent ...
+func (s *PostgresBudgetStore) SetTransactionCategoryParent(ctx context.Context, budgetTransactionCategoryID uuid.UUID, parentCategoryID *uuid.UUID) error {
+	query := "UPDATE budget_transaction_categories SET parent_category_id = $2 WHERE budget_transaction_category_id = $1"
+
+	result, err := database.Conn(ctx).ExecContext(ctx, query, budgetTransactionCategoryID, parentCategoryID)
+
+	if err != nil {
+		return err
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// RenameTransactionCategory ...
+func (s *PostgresBudgetStore) RenameTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID, categoryName string) error {
+	query := "UPDATE budget_transaction_categories SET category_name = $2 WHERE budget_transaction_category_id = $1"
+
+	result, err := database.Conn(ctx).ExecContext(ctx, query, budgetTransactionCategoryID, categoryName)
+
+	if err != nil {
+		return err
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ReassignTransactionCategory ...
+func (s *PostgresBudgetStore) ReassignTransactionCategory(ctx context.Context, fromCategoryID uuid.UUID, toCategoryID uuid.UUID) error {
+	queries := []string{
+		"UPDATE budget_transaction_category_transactions SET budget_transaction_category_id = $2 WHERE budget_transaction_category_id = $1",
+		"UPDATE budget_categorization_rules SET budget_transaction_category_id = $2 WHERE budget_transaction_category_id = $1",
+		"UPDATE budget_plaid_category_mappings SET budget_transaction_category_id = $2 WHERE budget_transaction_category_id = $1",
+	}
+
+	for _, query := range queries {
+		if _, err := database.Conn(ctx).ExecContext(ctx, query, fromCategoryID, toCategoryID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteTransactionCategory ...
+func (s *PostgresBudgetStore) DeleteTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID) error {
+	query := "DELETE FROM budget_transaction_categories where budget_transaction_category_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, budgetTransactionCategoryID)
+
+	return err
+}
+
+// DeleteAllTransactionCategories ...
+// Categories are deleted in one statement so parents
+// and their children go together
+func (s *PostgresBudgetStore) DeleteAllTransactionCategories(ctx context.Context, budgetID uuid.UUID) error {
+	queries := []string{
+		`DELETE FROM budget_transaction_category_transactions btct USING budget_transaction_categories btc
+	WHERE btc.budget_transaction_category_id = btct.budget_transaction_category_id AND btc.budget_id = $1`,
+		"DELETE FROM budget_transaction_categories WHERE budget_id = $1",
+	}
+
+	for _, query := range queries {
+		if _, err := database.Conn(ctx).ExecContext(ctx, query, budgetID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetCategoryTransactions ...
+func (s *PostgresBudgetStore) GetCategoryTransactions(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategoryTransaction, error) {
+	query := "SELECT btct.budget_transaction_category_id, btct.transaction_name, btc.category_name, btct.plaid_category FROM budget_transaction_category_transactions btct JOIN budget_transaction_categories btc on btc.budget_transaction_category_id = btct.budget_transaction_category_id WHERE btc.budget_id = $1 ORDER BY btct.transaction_name, btct.budget_transaction_category_transaction_id"
+
+	res, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	var transactions []models.BudgetTransactionCategoryTransaction
+
+	for res.Next() {
+		var temp models.BudgetTransactionCategoryTransaction
+
+		if scanErr := res.Scan(&temp.BudgetTransactionCategoryID, &temp.TransactionName, &temp.CategoryName, pq.Array(&temp.PlaidCategory)); scanErr != nil {
+			return nil, scanErr
+		}
+
+		transactions = append(transactions, temp)
+	}
+
+	return transactions, nil
+}
+
+// CreateCategoryTransaction ...
+func (s *PostgresBudgetStore) CreateCategoryTransaction(ctx context.Context, categoryTransaction models.BudgetTransactionCategoryTransaction) error {
+	query := "INSERT INTO budget_transaction_category_transactions (budget_transaction_category_id, transaction_name, plaid_category) VALUES ($1, $2, $3)"
+
+	plaidCategory := categoryTransaction.PlaidCategory
+
+	if plaidCategory == nil {
+		plaidCategory = []string{}
+	}
+
+	_, err := database.Conn(ctx).ExecContext(
+		ctx,
+		query,
+		categoryTransaction.BudgetTransactionCategoryID,
+		categoryTransaction.TransactionName,
+		pq.Array(plaidCategory),
+	)
+
+	return err
+}
+
+// DeleteBudgetCategoryTransaction ...
+func (s *PostgresBudgetStore) DeleteBudgetCategoryTransaction(ctx context.Context, budgetID uuid.UUID, transactionName string) error {
+	query := `DELETE FROM budget_transaction_category_transactions btct USING budget_transaction_categories btc
+	WHERE btc.budget_transaction_category_id = btct.budget_transaction_category_id AND btc.budget_id = $1 AND btct.transaction_name = $2`
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, budgetID, transactionName)
+
+	return err
+}
+
+// DeleteCategoryTransactions ...
+func (s *PostgresBudgetStore) DeleteCategoryTransactions(ctx context.Context, budgetTransactionCategoryID uuid.UUID) error {
+	query := "DELETE FROM budget_transaction_category_transactions where budget_transaction_category_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, budgetTransactionCategoryID)
+
+	return err
+}
+
+// ruleColumns are selected by every categorization rule read in scan order
+const ruleColumns = `r.rule_id, r.budget_id, r.budget_transaction_category_id, btc.category_name, r.priority,
+	COALESCE(r.name_contains, ''), COALESCE(r.name_prefix, ''), COALESCE(r.name_regex, ''), COALESCE(r.merchant, ''),
+	r.min_amount, r.max_amount, r.external_account_id, COALESCE(r.plaid_category, '')
+	FROM budget_categorization_rules r JOIN budget_transaction_categories btc
+	ON btc.budget_transaction_category_id = r.budget_transaction_category_id`
+
+// GetCategorizationRules ...
+func (s *PostgresBudgetStore) GetCategorizationRules(ctx context.Context, budgetID uuid.UUID) ([]models.CategorizationRule, error) {
+	query := "SELECT " + ruleColumns + " WHERE r.budget_id = $1 ORDER BY r.priority, r.created_at"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rules := make([]models.CategorizationRule, 0)
+
+	for rows.Next() {
+		rule, scanErr := scanRule(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		rules = append(rules, *rule)
+	}
+
+	return rules, nil
+}
+
+// GetCategorizationRule ...
+func (s *PostgresBudgetStore) GetCategorizationRule(ctx context.Context, ruleID uuid.UUID) (*models.CategorizationRule, error) {
+	query := "SELECT " + ruleColumns + " WHERE r.rule_id = $1"
+
+	return scanRule(database.Conn(ctx).QueryRowContext(ctx, query, ruleID))
+}
+
+// CreateCategorizationRule ...
+func (s *PostgresBudgetStore) CreateCategorizationRule(ctx context.Context, rule models.CategorizationRule) (*models.CategorizationRule, error) {
+	query := `INSERT INTO budget_categorization_rules (budget_id, budget_transaction_category_id, priority, name_contains,
+	name_prefix, name_regex, merchant, min_amount, max_amount, external_account_id, plaid_category)
+	VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10, NULLIF($11, ''))
+	RETURNING rule_id`
+
+	var ruleID uuid.UUID
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		rule.BudgetID,
+		rule.BudgetTransactionCategoryID,
+		rule.Priority,
+		rule.NameContains,
+		rule.NamePrefix,
+		rule.NameRegex,
+		rule.Merchant,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.ExternalAccountID,
+		rule.PlaidCategory,
+	).Scan(&ruleID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCategorizationRule(ctx, ruleID)
+}
+
+// UpdateCategorizationRule ...
+func (s *PostgresBudgetStore) UpdateCategorizationRule(ctx context.Context, rule models.CategorizationRule) error {
+	query := `UPDATE budget_categorization_rules SET budget_transaction_category_id = $2, priority = $3,
+	name_contains = NULLIF($4, ''), name_prefix = NULLIF($5, ''), name_regex = NULLIF($6, ''), merchant = NULLIF($7, ''),
+	min_amount = $8, max_amount = $9, external_account_id = $10, plaid_category = NULLIF($11, '')
+	WHERE rule_id = $1`
+
+	result, err := database.Conn(ctx).ExecContext(
+		ctx,
+		query,
+		rule.RuleID,
+		rule.BudgetTransactionCategoryID,
+		rule.Priority,
+		rule.NameContains,
+		rule.NamePrefix,
+		rule.NameRegex,
+		rule.Merchant,
+		rule.MinAmount,
+		rule.MaxAmount,
+		rule.ExternalAccountID,
+		rule.PlaidCategory,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteCategorizationRule ...
+func (s *PostgresBudgetStore) DeleteCategorizationRule(ctx context.Context, ruleID uuid.UUID) error {
+	query := "DELETE FROM budget_categorization_rules WHERE rule_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, ruleID)
+
+	return err
+}
+
+// mappingColumns are selected by every Plaid category mapping read in scan order
+const mappingColumns = `m.mapping_id, m.budget_id, m.budget_transaction_category_id, btc.category_name,
+	COALESCE(m.plaid_category_id, ''), COALESCE(m.plaid_category, '{}')
+	FROM budget_plaid_category_mappings m JOIN budget_transaction_categories btc
+	ON btc.budget_transaction_category_id = m.budget_transaction_category_id`
+
+// DeleteAccountCategorizationRules ...
+func (s *PostgresBudgetStore) DeleteAccountCategorizationRules(ctx context.Context, externalAccountID uuid.UUID) ([]models.CategorizationRule, error) {
+	query := `WITH deleted AS (
+		DELETE FROM budget_categorization_rules WHERE external_account_id = $1 RETURNING *
+	)
+	SELECT r.rule_id, r.budget_id, r.budget_transaction_category_id, btc.category_name, r.priority,
+	COALESCE(r.name_contains, ''), COALESCE(r.name_prefix, ''), COALESCE(r.name_regex, ''), COALESCE(r.merchant, ''),
+	r.min_amount, r.max_amount, r.external_account_id, COALESCE(r.plaid_category, '')
+	FROM deleted r JOIN budget_transaction_categories btc
+	ON btc.budget_transaction_category_id = r.budget_transaction_category_id
+	ORDER BY r.budget_id, r.priority, r.created_at`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, externalAccountID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rules := make([]models.CategorizationRule, 0)
+
+	for rows.Next() {
+		rule, scanErr := scanRule(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		rules = append(rules, *rule)
+	}
+
+	return rules, nil
+}
+
+// GetPlaidCategoryMappings ...
+func (s *PostgresBudgetStore) GetPlaidCategoryMappings(ctx context.Context, budgetID uuid.UUID) ([]models.PlaidCategoryMapping, error) {
+	query := "SELECT " + mappingColumns + " WHERE m.budget_id = $1 ORDER BY m.created_at"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	mappings := make([]models.PlaidCategoryMapping, 0)
+
+	for rows.Next() {
+		mapping, scanErr := scanMapping(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		mappings = append(mappings, *mapping)
+	}
+
+	return mappings, nil
+}
+
+// GetPlaidCategoryMapping ...
+func (s *PostgresBudgetStore) GetPlaidCategoryMapping(ctx context.Context, mappingID uuid.UUID) (*models.PlaidCategoryMapping, error) {
+	query := "SELECT " + mappingColumns + " WHERE m.mapping_id = $1"
+
+	return scanMapping(database.Conn(ctx).QueryRowContext(ctx, query, mappingID))
+}
+
+// CreatePlaidCategoryMapping ...
+func (s *PostgresBudgetStore) CreatePlaidCategoryMapping(ctx context.Context, mapping models.PlaidCategoryMapping) (*models.PlaidCategoryMapping, error) {
+	query := `INSERT INTO budget_plaid_category_mappings (budget_id, budget_transaction_category_id, plaid_category_id, plaid_category)
+	VALUES ($1, $2, NULLIF($3, ''), $4)
+	RETURNING mapping_id`
+
+	// An empty path is stored as NULL so budgets
+	// can have more than one id mapping
+	var plaidCategory interface{}
+
+	if len(mapping.PlaidCategory) > 0 {
+		plaidCategory = pq.Array(mapping.PlaidCategory)
+	}
+
+	var mappingID uuid.UUID
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		mapping.BudgetID,
+		mapping.BudgetTransactionCategoryID,
+		mapping.PlaidCategoryID,
+		plaidCategory,
+	).Scan(&mappingID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPlaidCategoryMapping(ctx, mappingID)
+}
+
+// DeletePlaidCategoryMapping ...
+func (s *PostgresBudgetStore) DeletePlaidCategoryMapping(ctx context.Context, mappingID uuid.UUID) error {
+	query := "DELETE FROM budget_plaid_category_mappings WHERE mapping_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, mappingID)
+
+	return err
+}
+
+// GetMerchantAliases ...
+func (s *PostgresBudgetStore) GetMerchantAliases(ctx context.Context, budgetID uuid.UUID) ([]models.MerchantAlias, error) {
+	query := "SELECT alias_id, budget_id, merchant, alias FROM budget_merchant_aliases WHERE budget_id = $1 ORDER BY created_at"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	aliases := make([]models.MerchantAlias, 0)
+
+	for rows.Next() {
+		var alias models.MerchantAlias
+
+		if scanErr := rows.Scan(&alias.AliasID, &alias.BudgetID, &alias.Merchant, &alias.Alias); scanErr != nil {
+			return nil, scanErr
+		}
+
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, nil
+}
+
+// GetMerchantAlias ...
+func (s *PostgresBudgetStore) GetMerchantAlias(ctx context.Context, aliasID uuid.UUID) (*models.MerchantAlias, error) {
+	query := "SELECT alias_id, budget_id, merchant, alias FROM budget_merchant_aliases WHERE alias_id = $1"
+
+	var alias models.MerchantAlias
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, aliasID).Scan(&alias.AliasID, &alias.BudgetID, &alias.Merchant, &alias.Alias)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &alias, nil
+}
+
+// CreateMerchantAlias ...
+func (s *PostgresBudgetStore) CreateMerchantAlias(ctx context.Context, alias models.MerchantAlias) (*models.MerchantAlias, error) {
+	query := "INSERT INTO budget_merchant_aliases (budget_id, merchant, alias) VALUES ($1, $2, $3) RETURNING alias_id"
+
+	if err := database.Conn(ctx).QueryRowContext(ctx, query, alias.BudgetID, alias.Merchant, alias.Alias).Scan(&alias.AliasID); err != nil {
+		return nil, err
+	}
+
+	return &alias, nil
+}
+
+// DeleteMerchantAlias ...
+func (s *PostgresBudgetStore) DeleteMerchantAlias(ctx context.Context, aliasID uuid.UUID) error {
+	query := "DELETE FROM budget_merchant_aliases WHERE alias_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, aliasID)
+
+	return err
+}
+
+// GetAlertRules ...
+func (s *PostgresBudgetStore) GetAlertRules(ctx context.Context, budgetID uuid.UUID) ([]models.AlertRule, error) {
+	query := "SELECT alert_rule_id, budget_id, expense_id, user_id, kind, thresholds, amount, channel, webhook_url, created_at FROM budget_alert_rules WHERE budget_id = $1 ORDER BY created_at"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rules := make([]models.AlertRule, 0)
+
+	for rows.Next() {
+		rule, scanErr := scanAlertRule(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		rules = append(rules, *rule)
+	}
+
+	return rules, nil
+}
+
+// GetAlertRule ...
+func (s *PostgresBudgetStore) GetAlertRule(ctx context.Context, alertRuleID uuid.UUID) (*models.AlertRule, error) {
+	query := "SELECT alert_rule_id, budget_id, expense_id, user_id, kind, thresholds, amount, channel, webhook_url, created_at FROM budget_alert_rules WHERE alert_rule_id = $1"
+
+	return scanAlertRule(database.Conn(ctx).QueryRowContext(ctx, query, alertRuleID))
+}
+
+// CreateAlertRule ...
+func (s *PostgresBudgetStore) CreateAlertRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error) {
+	query := `INSERT INTO budget_alert_rules (budget_id, expense_id, user_id, kind, thresholds, amount, channel, webhook_url)
+	VALUES ($1, $2, $3, $4, $5, NULLIF($6::NUMERIC, 0), $7, NULLIF($8, '')) RETURNING alert_rule_id, created_at`
+
+	thresholds := make(pq.Int64Array, 0, len(rule.Thresholds))
+
+	for _, threshold := range rule.Thresholds {
+		thresholds = append(thresholds, int64(threshold))
+	}
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		rule.BudgetID,
+		rule.ExpenseID,
+		rule.UserID,
+		rule.Kind,
+		thresholds,
+		rule.Amount,
+		rule.Channel,
+		rule.WebhookURL,
+	).Scan(&rule.AlertRuleID, &rule.CreatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// DeleteAlertRule ...
+func (s *PostgresBudgetStore) DeleteAlertRule(ctx context.Context, alertRuleID uuid.UUID) error {
+	query := "DELETE FROM budget_alert_rules WHERE alert_rule_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, alertRuleID)
+
+	return err
+}
+
+// RecordAlertFired ...
+func (s *PostgresBudgetStore) RecordAlertFired(ctx context.Context, alertRuleID uuid.UUID, alertKey string) (bool, error) {
+	query := "INSERT INTO budget_alerts_fired (alert_rule_id, alert_key) VALUES ($1, $2) ON CONFLICT DO NOTHING"
+
+	res, err := database.Conn(ctx).ExecContext(ctx, query, alertRuleID, alertKey)
+
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := res.RowsAffected()
+
+	return inserted > 0, err
+}
+
+// ForgetAlertFired ...
+func (s *PostgresBudgetStore) ForgetAlertFired(ctx context.Context, alertRuleID uuid.UUID, alertKey string) error {
+	query := "DELETE FROM budget_alerts_fired WHERE alert_rule_id = $1 AND alert_key = $2"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, alertRuleID, alertKey)
+
+	return err
+}
+
+// GetIncomeSources ...
+func (s *PostgresBudgetStore) GetIncomeSources(ctx context.Context, budgetID uuid.UUID) ([]models.IncomeSource, error) {
+	query := `SELECT isr.income_source_id, isr.budget_id, isr.income_source_name, isr.expected_amount, ecc.expense_charge_cycle_id,
+	ecc.unit, ecc.days, COALESCE(to_char(isr.charge_cycle_anchor, 'YYYY-MM-DD'), ''), COALESCE(isr.charge_cycle_interval, 0),
+	COALESCE(isr.charge_cycle_day, 0), isr.payer, isr.external_account_id
+	FROM income_sources isr JOIN expense_charge_cycles ecc ON ecc.expense_charge_cycle_id = isr.expense_charge_cycle_id
+	WHERE isr.budget_id = $1 ORDER BY isr.created_at`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sources := make([]models.IncomeSource, 0)
+
+	for rows.Next() {
+		source, scanErr := scanIncomeSource(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		sources = append(sources, *source)
+	}
+
+	return sources, nil
+}
+
+// GetIncomeSource ...
+func (s *PostgresBudgetStore) GetIncomeSource(ctx context.Context, incomeSourceID uuid.UUID) (*models.IncomeSource, error) {
+	query := `SELECT isr.income_source_id, isr.budget_id, isr.income_source_name, isr.expected_amount, ecc.expense_charge_cycle_id,
+	ecc.unit, ecc.days, COALESCE(to_char(isr.charge_cycle_anchor, 'YYYY-MM-DD'), ''), COALESCE(isr.charge_cycle_interval, 0),
+	COALESCE(isr.charge_cycle_day, 0), isr.payer, isr.external_account_id
+	FROM income_sources isr JOIN expense_charge_cycles ecc ON ecc.expense_charge_cycle_id = isr.expense_charge_cycle_id
+	WHERE isr.income_source_id = $1`
+
+	return scanIncomeSource(database.Conn(ctx).QueryRowContext(ctx, query, incomeSourceID))
+}
+
+// CreateIncomeSource ...
+func (s *PostgresBudgetStore) CreateIncomeSource(ctx context.Context, source models.IncomeSource) (*models.IncomeSource, error) {
+	query := `INSERT INTO income_sources (budget_id, income_source_name, expected_amount, expense_charge_cycle_id,
+	charge_cycle_anchor, charge_cycle_interval, charge_cycle_day, payer, external_account_id
+	) VALUES ($1, $2, $3, $4, NULLIF($5, '')::DATE, NULLIF($6, 0), NULLIF($7, 0), $8, $9) RETURNING income_source_id`
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		source.BudgetID,
+		source.IncomeSourceName,
+		source.ExpectedAmount,
+		source.ChargeCycle.ExpenseChargeCycleID,
+		source.ChargeCycle.AnchorDate,
+		source.ChargeCycle.IntervalWeeks,
+		source.ChargeCycle.DayOfMonth,
+		source.Payer,
+		source.ExternalAccountID,
+	).Scan(&source.IncomeSourceID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &source, nil
+}
+
+// DeleteIncomeSource ...
+func (s *PostgresBudgetStore) DeleteIncomeSource(ctx context.Context, incomeSourceID uuid.UUID) error {
+	query := "DELETE FROM income_sources WHERE income_source_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, incomeSourceID)
+
+	return err
+}
+
+// UnlinkAccountIncomeSources ...
+func (s *PostgresBudgetStore) UnlinkAccountIncomeSources(ctx context.Context, externalAccountID uuid.UUID) ([]models.IncomeSource, error) {
+	query := `WITH unlinked AS (
+		UPDATE income_sources SET external_account_id = NULL WHERE external_account_id = $1 RETURNING *
+	)
+	SELECT isr.income_source_id, isr.budget_id, isr.income_source_name, isr.expected_amount, ecc.expense_charge_cycle_id,
+	ecc.unit, ecc.days, COALESCE(to_char(isr.charge_cycle_anchor, 'YYYY-MM-DD'), ''), COALESCE(isr.charge_cycle_interval, 0),
+	COALESCE(isr.charge_cycle_day, 0), isr.payer, isr.external_account_id
+	FROM unlinked isr JOIN expense_charge_cycles ecc ON ecc.expense_charge_cycle_id = isr.expense_charge_cycle_id
+	ORDER BY isr.created_at`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, externalAccountID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sources := make([]models.IncomeSource, 0)
+
+	for rows.Next() {
+		source, scanErr := scanIncomeSource(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		sources = append(sources, *source)
+	}
+
+	return sources, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRule(row scanner) (*models.CategorizationRule, error) {
+	var rule models.CategorizationRule
+
+	err := row.Scan(
+		&rule.RuleID,
+		&rule.BudgetID,
+		&rule.BudgetTransactionCategoryID,
+		&rule.CategoryName,
+		&rule.Priority,
+		&rule.NameContains,
+		&rule.NamePrefix,
+		&rule.NameRegex,
+		&rule.Merchant,
+		&rule.MinAmount,
+		&rule.MaxAmount,
+		&rule.ExternalAccountID,
+		&rule.PlaidCategory,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+func scanMapping(row scanner) (*models.PlaidCategoryMapping, error) {
+	var mapping models.PlaidCategoryMapping
+
+	err := row.Scan(
+		&mapping.MappingID,
+		&mapping.BudgetID,
+		&mapping.BudgetTransactionCategoryID,
+		&mapping.CategoryName,
+		&mapping.PlaidCategoryID,
+		pq.Array(&mapping.PlaidCategory),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &mapping, nil
+}
+
+func scanAlertRule(row scanner) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	var thresholds pq.Int64Array
+	var amount sql.NullFloat64
+	var webhookURL sql.NullString
+
+	err := row.Scan(
+		&rule.AlertRuleID,
+		&rule.BudgetID,
+		&rule.ExpenseID,
+		&rule.UserID,
+		&rule.Kind,
+		&thresholds,
+		&amount,
+		&rule.Channel,
+		&webhookURL,
+		&rule.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, threshold := range thresholds {
+		rule.Thresholds = append(rule.Thresholds, int(threshold))
+	}
+
+	rule.Amount = amount.Float64
+	rule.WebhookURL = webhookURL.String
+
+	return &rule, nil
+}
+
+func scanIncomeSource(row scanner) (*models.IncomeSource, error) {
+	var source models.IncomeSource
+
+	err := row.Scan(
+		&source.IncomeSourceID,
+		&source.BudgetID,
+		&source.IncomeSourceName,
+		&source.ExpectedAmount,
+		&source.ChargeCycle.ExpenseChargeCycleID,
+		&source.ChargeCycle.Unit,
+		&source.ChargeCycle.Days,
+		&source.ChargeCycle.AnchorDate,
+		&source.ChargeCycle.IntervalWeeks,
+		&source.ChargeCycle.DayOfMonth,
+		&source.Payer,
+		&source.ExternalAccountID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &source, nil
+}
@@ -0,0 +1,496 @@
+package budget
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+const (
+	// recurringHistoryDays is how far back recurring series are looked
+	// for, long enough to see a yearly charge twice
+	recurringHistoryDays = 400
+
+	// recurringRegularShare is the share of the gaps between a series'
+	// charges that have to match its cadence
+	recurringRegularShare = 0.75
+
+	// recurringPriceTolerance is how far a charge can stray from the
+	// price of the charges before it without being a price change
+	recurringPriceTolerance = 0.05
+
+	// recurringVariableTolerance is how far charges of a series whose
+	// amount varies, like a utility bill, can stray from their median
+	recurringVariableTolerance = 0.25
+
+	// recurringMaxPriceChanges is the most price changes a series
+	// can have before its amounts count as varying instead
+	recurringMaxPriceChanges = 2
+)
+
+// recurringCadence ...
+// A charge cycle unit and the gaps in days between
+// charges that match it. Cadences are tried in order
+type recurringCadence struct {
+	unit           string
+	minDays        int
+	maxDays        int
+	minOccurrences int
+}
+
+var recurringCadences = []recurringCadence{
+	{unit: "weekly", minDays: 6, maxDays: 8, minOccurrences: 3},
+	{unit: "bi-weekly", minDays: 13, maxDays: 15, minOccurrences: 3},
+	{unit: "semi-monthly", minDays: 12, maxDays: 18, minOccurrences: 3},
+	{unit: "monthly", minDays: 27, maxDays: 33, minOccurrences: 3},
+	{unit: "semi-annually", minDays: 173, maxDays: 193, minOccurrences: 2},
+	{unit: "annually", minDays: 355, maxDays: 375, minOccurrences: 2},
+}
+
+// GetRecurringSeries ...
+// Detects the recurring charges, like subscriptions, in the
+// transactions of a budget's sources. Series that stopped
+// charging when they were due are left out
+func GetRecurringSeries(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.RecurringSeries, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	return budgetRecurringSeries(ctx, budgetID, time.Now().Local())
+}
+
+// ConvertRecurringSeries ...
+// Creates an expense tracking a recurring series of a budget, valued
+// at what the series charges now with the charge cycle matching its
+// cadence and counted from its last charge. Without a category the
+// expense gets a category named after it, under the category the
+// series lands in now, and a rule sending the series' charges to it
+func ConvertRecurringSeries(ctx context.Context, payload models.SubscriptionConversionPayload, userID uuid.UUID) (*models.Expense, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, payload.BudgetID, userID) && !roleService.IsUserOwner(ctx, payload.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to add expenses to the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	detected, detectErr := budgetRecurringSeries(ctx, payload.BudgetID, time.Now().Local())
+
+	if detectErr != nil {
+		return nil, detectErr
+	}
+
+	var series *models.RecurringSeries
+
+	for i := range detected {
+		if detected[i].SeriesID == payload.SeriesID {
+			series = &detected[i]
+		}
+	}
+
+	if series == nil {
+		return nil, &errors.Error{
+			Message:    "Recurring series not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	expenseName := strings.TrimSpace(payload.ExpenseName)
+
+	if expenseName == "" {
+		expenseName = series.Merchant
+	}
+
+	var expense *models.Expense
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		categoryName, categoryErr := seriesCategory(ctx, payload, *series, expenseName, userID)
+
+		if categoryErr != nil {
+			return categoryErr
+		}
+
+		created, expenseErr := expenseService.AddExpenseToBudget(ctx, &models.AddExpensePayload{
+			BudgetID:                     payload.BudgetID,
+			ExpenseName:                  expenseName,
+			ExpenseValue:                 float32(series.TypicalAmount),
+			ExpenseDescription:           "Recurring " + series.Frequency + " charge by " + series.Merchant,
+			ExpenseChargeCycle:           seriesChargeCycle(*series),
+			ExpenseTransactionCategories: []string{categoryName},
+		}, userID)
+
+		if expenseErr != nil {
+			return expenseErr
+		}
+
+		expense = created
+
+		return nil
+	})
+
+	if txErr != nil {
+		return nil, toServiceError(txErr)
+	}
+
+	return expense, nil
+}
+
+// seriesCategory ...
+// The name of the category an expense converted from a series
+// tracks, creating it and the rule feeding it when none was given
+func seriesCategory(ctx context.Context, payload models.SubscriptionConversionPayload, series models.RecurringSeries, expenseName string, userID uuid.UUID) (string, *errors.Error) {
+	if payload.BudgetTransactionCategoryID != nil {
+		category, err := store.GetTransactionCategory(ctx, *payload.BudgetTransactionCategoryID)
+
+		if err != nil || category.BudgetID != payload.BudgetID {
+			return "", &errors.Error{
+				Message:    "Provided category not found",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+
+		return category.CategoryName, nil
+	}
+
+	categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, payload.BudgetID)
+
+	if categoriesErr != nil {
+		return "", categoriesErr
+	}
+
+	var parentID *uuid.UUID
+
+	for _, category := range categories {
+		if category.CategoryName == series.CategoryName {
+			id := category.BudgetTransactionCategoryID
+			parentID = &id
+		}
+	}
+
+	category, createErr := CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{
+		BudgetID:         payload.BudgetID,
+		CategoryName:     expenseName,
+		ParentCategoryID: parentID,
+	}, userID)
+
+	if createErr != nil {
+		return "", createErr
+	}
+
+	rules, rulesErr := store.GetCategorizationRules(ctx, payload.BudgetID)
+
+	if rulesErr != nil {
+		return "", &errors.Error{
+			Message:    rulesErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	// The series' rule goes before every other rule
+	priority := 0
+
+	for _, rule := range rules {
+		if rule.Priority <= priority {
+			priority = rule.Priority - 1
+		}
+	}
+
+	externalAccountID := series.ExternalAccountID
+
+	_, ruleErr := CreateCategorizationRule(ctx, models.CategorizationRule{
+		BudgetID:                    payload.BudgetID,
+		BudgetTransactionCategoryID: category.BudgetTransactionCategoryID,
+		Priority:                    priority,
+		Merchant:                    series.Merchant,
+		ExternalAccountID:           &externalAccountID,
+	}, userID)
+
+	if ruleErr != nil {
+		return "", ruleErr
+	}
+
+	return category.CategoryName, nil
+}
+
+// seriesChargeCycle ...
+// The charge cycle of an expense tracking a series, lined
+// up so every period starts on the day it charges
+func seriesChargeCycle(series models.RecurringSeries) models.ExpenseChargeCycle {
+	cycle := models.ExpenseChargeCycle{Unit: series.Frequency}
+	lastDate, err := time.Parse("2006-01-02", series.LastDate)
+
+	if err != nil {
+		return cycle
+	}
+
+	switch series.Frequency {
+	case "monthly":
+		cycle.Unit = "day-of-month"
+		cycle.DayOfMonth = lastDate.Day()
+	case "weekly", "bi-weekly", "semi-annually", "annually":
+		cycle.AnchorDate = series.LastDate
+	}
+
+	return cycle
+}
+
+// budgetRecurringSeries ...
+// Detects the recurring series in the transactions of a budget's
+// sources, with merchants named the way the budget names them
+func budgetRecurringSeries(ctx context.Context, budgetID uuid.UUID, now time.Time) ([]models.RecurringSeries, *errors.Error) {
+	sources, sourcesErr := GetBudgetTransactionSources(ctx, budgetID)
+
+	if sourcesErr != nil {
+		return nil, sourcesErr
+	}
+
+	categorizer, categorizerErr := newCategorizer(ctx, budgetID)
+
+	if categorizerErr != nil {
+		return nil, categorizerErr
+	}
+
+	transactions, _ := budgetTransactions(
+		ctx,
+		sources,
+		now.AddDate(0, 0, -recurringHistoryDays).Format("2006-01-02"),
+		now.Format("2006-01-02"),
+	)
+
+	for i := range transactions {
+		transactions[i].Merchant = categorizer.merchant(transactions[i])
+	}
+
+	return detectRecurringSeries(transactions, now, func(tx models.Transaction) string {
+		return categorizer.categorize(tx, tx.Merchant).CategoryName
+	}), nil
+}
+
+// detectRecurringSeries ...
+// Finds the charges by the same merchant to the same account whose
+// gaps mostly match a cadence and whose amounts either hold steady
+// between a few price changes or stay close to their median.
+// categorize names the category a series' latest charge is in
+func detectRecurringSeries(transactions []models.Transaction, now time.Time, categorize func(models.Transaction) string) []models.RecurringSeries {
+	groups := make(map[string][]models.Transaction)
+
+	for _, tx := range transactions {
+		if !isSpending(tx.Transaction) || tx.Merchant == "" {
+			continue
+		}
+
+		key := tx.ExternalAccountID.String() + ":" + merchantKey(tx.Merchant)
+		groups[key] = append(groups[key], tx)
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	detected := make([]models.RecurringSeries, 0)
+
+	for key, charges := range groups {
+		sort.SliceStable(charges, func(i, j int) bool {
+			return charges[i].Date < charges[j].Date
+		})
+
+		series, ok := recurringSeriesOf(charges, today)
+
+		if !ok {
+			continue
+		}
+
+		series.SeriesID = uuid.NewSHA1(uuid.NameSpaceOID, []byte(key))
+		series.CategoryName = categorize(charges[len(charges)-1])
+		detected = append(detected, series)
+	}
+
+	sort.Slice(detected, func(i, j int) bool {
+		return detected[i].Merchant < detected[j].Merchant
+	})
+
+	return detected
+}
+
+// recurringSeriesOf ...
+// The series a merchant's charges to an account make, oldest
+// first, if they recur and were still charging when last due
+func recurringSeriesOf(charges []models.Transaction, today time.Time) (models.RecurringSeries, bool) {
+	dates := make([]time.Time, 0, len(charges))
+
+	for _, tx := range charges {
+		date, err := time.Parse("2006-01-02", tx.Date)
+
+		if err != nil {
+			return models.RecurringSeries{}, false
+		}
+
+		dates = append(dates, date)
+	}
+
+	gaps := make([]float64, 0, len(dates))
+
+	for i := 1; i < len(dates); i++ {
+		gaps = append(gaps, float64(daysBetween(dates[i-1], dates[i])))
+	}
+
+	if len(gaps) == 0 {
+		return models.RecurringSeries{}, false
+	}
+
+	cadence, ok := matchCadence(gaps, len(charges))
+
+	if !ok {
+		return models.RecurringSeries{}, false
+	}
+
+	typical, priceChanges, ok := recurringAmounts(charges)
+
+	if !ok {
+		return models.RecurringSeries{}, false
+	}
+
+	last := charges[len(charges)-1]
+	lastDate := dates[len(dates)-1]
+	next := nextCharge(cadence.unit, lastDate, median(gaps))
+
+	// Series that missed their last charge have stopped
+	grace := int(math.Max(3, median(gaps)/2))
+
+	if today.After(next.AddDate(0, 0, grace)) {
+		return models.RecurringSeries{}, false
+	}
+
+	return models.RecurringSeries{
+		ExternalAccountID: last.ExternalAccountID,
+		Merchant:          last.Merchant,
+		Frequency:         cadence.unit,
+		TypicalAmount:     roundCents(typical),
+		LastAmount:        last.Amount,
+		LastDate:          last.Date,
+		NextExpectedDate:  next.Format("2006-01-02"),
+		Occurrences:       len(charges),
+		PriceChanges:      priceChanges,
+	}, true
+}
+
+// matchCadence ...
+// The first cadence the median gap falls in
+// that enough of the gaps match
+func matchCadence(gaps []float64, occurrences int) (recurringCadence, bool) {
+	typical := median(gaps)
+
+	for _, cadence := range recurringCadences {
+		if occurrences < cadence.minOccurrences || typical < float64(cadence.minDays) || typical > float64(cadence.maxDays) {
+			continue
+		}
+
+		regular := 0
+
+		for _, gap := range gaps {
+			if gap >= float64(cadence.minDays) && gap <= float64(cadence.maxDays) {
+				regular++
+			}
+		}
+
+		if float64(regular) >= recurringRegularShare*float64(len(gaps)) {
+			return cadence, true
+		}
+	}
+
+	return recurringCadence{}, false
+}
+
+// recurringAmounts ...
+// What a series charges now and when its price changed. Charges
+// split into runs at about the same price; series with more runs
+// than price changes allow are fine as long as every charge is
+// close to the median, but then have no price changes to report
+func recurringAmounts(charges []models.Transaction) (float64, []models.RecurringPriceChange, bool) {
+	priceChanges := make([]models.RecurringPriceChange, 0)
+	runStart := 0
+
+	for i := 1; i < len(charges); i++ {
+		price := charges[runStart].Amount
+
+		if math.Abs(charges[i].Amount-price) <= recurringPriceTolerance*price {
+			continue
+		}
+
+		priceChanges = append(priceChanges, models.RecurringPriceChange{
+			Date:      charges[i].Date,
+			OldAmount: price,
+			NewAmount: charges[i].Amount,
+		})
+
+		runStart = i
+	}
+
+	if len(priceChanges) <= recurringMaxPriceChanges {
+		amounts := make([]float64, 0, len(charges)-runStart)
+
+		for _, tx := range charges[runStart:] {
+			amounts = append(amounts, tx.Amount)
+		}
+
+		return median(amounts), priceChanges, true
+	}
+
+	amounts := make([]float64, 0, len(charges))
+
+	for _, tx := range charges {
+		amounts = append(amounts, tx.Amount)
+	}
+
+	typical := median(amounts)
+
+	for _, amount := range amounts {
+		if math.Abs(amount-typical) > recurringVariableTolerance*typical {
+			return 0, nil, false
+		}
+	}
+
+	return typical, make([]models.RecurringPriceChange, 0), true
+}
+
+// nextCharge ...
+// When a series charging on lastDate at a cadence charges next.
+// Month based cadences keep the day of the month, or the
+// last day of shorter months
+func nextCharge(unit string, lastDate time.Time, typicalGap float64) time.Time {
+	months := 0
+
+	switch unit {
+	case "weekly":
+		return lastDate.AddDate(0, 0, 7)
+	case "bi-weekly":
+		return lastDate.AddDate(0, 0, 14)
+	case "monthly":
+		months = 1
+	case "semi-annually":
+		months = 6
+	case "annually":
+		months = 12
+	default:
+		return lastDate.AddDate(0, 0, int(math.Round(typicalGap)))
+	}
+
+	first := time.Date(lastDate.Year(), lastDate.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := first.AddDate(0, 1, -1).Day()
+	day := lastDate.Day()
+
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,181 @@
+package budget
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// charges ...
+// A charge by merchant on each date, of the amount at the
+// same index or of the only amount given
+func charges(merchant string, dates []string, amounts ...float64) []models.Transaction {
+	transactions := make([]models.Transaction, 0, len(dates))
+
+	for i, date := range dates {
+		amount := amounts[0]
+
+		if len(amounts) > 1 {
+			amount = amounts[i]
+		}
+
+		transactions = append(transactions, merchantTransaction(merchant, date, amount))
+	}
+
+	return transactions
+}
+
+func TestDetectRecurringSeries(t *testing.T) {
+	now := day("2021-06-20")
+	monthly := []string{"2021-02-15", "2021-03-15", "2021-04-15", "2021-05-15", "2021-06-15"}
+
+	tests := []struct {
+		name             string
+		transactions     []models.Transaction
+		wantFrequency    string
+		wantTypical      float64
+		wantNext         string
+		wantPriceChanges int
+	}{
+		{
+			name:          "monthly subscription",
+			transactions:  charges("StreamFlix", monthly, 15.99),
+			wantFrequency: "monthly",
+			wantTypical:   15.99,
+			wantNext:      "2021-07-15",
+		},
+		{
+			name:          "days jitter around the cadence",
+			transactions:  charges("Gym", []string{"2021-02-14", "2021-03-16", "2021-04-13", "2021-05-17", "2021-06-15"}, 40),
+			wantFrequency: "monthly",
+			wantTypical:   40,
+			wantNext:      "2021-07-15",
+		},
+		{
+			name:          "one skipped month",
+			transactions:  charges("Gym", []string{"2021-01-15", "2021-02-15", "2021-03-15", "2021-05-15", "2021-06-15"}, 40),
+			wantFrequency: "monthly",
+			wantTypical:   40,
+			wantNext:      "2021-07-15",
+		},
+		{
+			name:         "irregular intervals",
+			transactions: charges("Hardware Store", []string{"2021-01-03", "2021-01-09", "2021-02-20", "2021-03-01", "2021-05-30"}, 25),
+		},
+		{
+			name:             "price change",
+			transactions:     charges("StreamFlix", monthly, 9.99, 9.99, 9.99, 12.99, 12.99),
+			wantFrequency:    "monthly",
+			wantTypical:      12.99,
+			wantNext:         "2021-07-15",
+			wantPriceChanges: 1,
+		},
+		{
+			name:             "slow drift is a few price changes",
+			transactions:     charges("Power Co", []string{"2021-01-15", "2021-02-15", "2021-03-15", "2021-04-15", "2021-05-15", "2021-06-15"}, 10, 10.4, 10.8, 11.2, 11.6, 12),
+			wantFrequency:    "monthly",
+			wantTypical:      11.8,
+			wantNext:         "2021-07-15",
+			wantPriceChanges: 2,
+		},
+		{
+			name:          "varying bill stays near its median",
+			transactions:  charges("Power Co", []string{"2021-01-10", "2021-02-10", "2021-03-10", "2021-04-10", "2021-05-10", "2021-06-10"}, 80, 95, 70, 88, 76, 92),
+			wantFrequency: "monthly",
+			wantTypical:   84,
+			wantNext:      "2021-07-10",
+		},
+		{
+			name:         "amounts all over the place",
+			transactions: charges("Power Co", monthly, 50, 80, 120, 60, 130),
+		},
+		{
+			name:         "single charge",
+			transactions: charges("StreamFlix", []string{"2021-06-01"}, 15.99),
+		},
+		{
+			name:         "too few monthly charges",
+			transactions: charges("StreamFlix", []string{"2021-05-15", "2021-06-15"}, 15.99),
+		},
+		{
+			name:          "two yearly charges",
+			transactions:  charges("Cloud Storage", []string{"2020-06-01", "2021-06-01"}, 99),
+			wantFrequency: "annually",
+			wantTypical:   99,
+			wantNext:      "2022-06-01",
+		},
+		{
+			name:         "stopped charging",
+			transactions: charges("StreamFlix", []string{"2021-01-15", "2021-02-15", "2021-03-15"}, 15.99),
+		},
+		{
+			name:         "refunds aren't charges",
+			transactions: charges("StreamFlix", monthly, -15.99),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			detected := detectRecurringSeries(test.transactions, now, func(tx models.Transaction) string {
+				return "Subscriptions"
+			})
+
+			if test.wantFrequency == "" {
+				if len(detected) != 0 {
+					t.Fatalf("detected %+v, want nothing", detected)
+				}
+
+				return
+			}
+
+			if len(detected) != 1 {
+				t.Fatalf("detected %d series, want 1", len(detected))
+			}
+
+			series := detected[0]
+
+			if series.Frequency != test.wantFrequency {
+				t.Errorf("frequency %s, want %s", series.Frequency, test.wantFrequency)
+			}
+
+			if series.TypicalAmount != test.wantTypical {
+				t.Errorf("typical amount %.2f, want %.2f", series.TypicalAmount, test.wantTypical)
+			}
+
+			if series.NextExpectedDate != test.wantNext {
+				t.Errorf("next expected %s, want %s", series.NextExpectedDate, test.wantNext)
+			}
+
+			if len(series.PriceChanges) != test.wantPriceChanges {
+				t.Errorf("price changes %+v, want %d", series.PriceChanges, test.wantPriceChanges)
+			}
+
+			if series.Occurrences != len(test.transactions) || series.CategoryName != "Subscriptions" {
+				t.Errorf("series = %+v, want every charge counted and categorized", series)
+			}
+		})
+	}
+}
+
+func TestDetectRecurringSeriesSplitsAccounts(t *testing.T) {
+	now := day("2021-06-20")
+	dates := []string{"2021-04-15", "2021-05-15", "2021-06-15"}
+
+	personal := charges("StreamFlix", dates, 15.99)
+	joint := charges("StreamFlix", dates[1:], 15.99)
+
+	jointAccountID := uuid.New()
+
+	for i := range joint {
+		joint[i].ExternalAccountID = jointAccountID
+	}
+
+	detected := detectRecurringSeries(append(personal, joint...), now, func(tx models.Transaction) string {
+		return ""
+	})
+
+	if len(detected) != 1 || detected[0].Occurrences != 3 {
+		t.Fatalf("detected %+v, want only the series charged three times to one account", detected)
+	}
+}
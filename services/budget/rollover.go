@@ -0,0 +1,213 @@
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	"github.com/lakshay35/finlit-backend/services/account"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// settlementGraceDays is how long after a period ends its snapshot
+// waits to be saved. Transactions post days after they're made, and
+// until then the period's carry-over is worked out on every request
+const settlementGraceDays = 7
+
+// rolloverPosition ...
+// The first period of an expense that rolls over without a
+// snapshot yet, and what is carried into it. Snapshots from before
+// rollover was last turned on are ignored, and rollover starts
+// with the period it was turned on in
+func rolloverPosition(expense models.Expense, snapshots []models.ExpensePeriodSnapshot, now time.Time) (time.Time, float64) {
+	var last *models.ExpensePeriodSnapshot
+
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+
+		if snapshot.ExpenseID != expense.ExpenseID || snapshot.PeriodEnd < expense.RolloverStart {
+			continue
+		}
+
+		if last == nil || snapshot.PeriodStart > last.PeriodStart {
+			last = snapshot
+		}
+	}
+
+	if last != nil {
+		lastDay, err := time.Parse("2006-01-02", last.PeriodEnd)
+
+		if err == nil {
+			return lastDay.AddDate(0, 0, 1), last.CarriedOut
+		}
+	}
+
+	start, err := time.Parse("2006-01-02", expense.RolloverStart)
+
+	if err != nil {
+		start = now
+	}
+
+	periodStart, _ := expenseService.ChargeCyclePeriod(expense.ExpenseChargeCycle, start)
+
+	return periodStart, 0
+}
+
+// unsettledStartDate ...
+// The first day of the oldest finished period of an expense that
+// rolls over without a snapshot yet. Empty when there is none
+func unsettledStartDate(expenses []models.Expense, snapshots []models.ExpensePeriodSnapshot, now time.Time) string {
+	startDate := ""
+
+	for _, expense := range expenses {
+		if !expense.Rollover {
+			continue
+		}
+
+		next, _ := rolloverPosition(expense, snapshots, now)
+		currentStart, _ := expenseService.ChargeCyclePeriod(expense.ExpenseChargeCycle, now)
+
+		if !next.Before(currentStart) {
+			continue
+		}
+
+		if date := next.Format("2006-01-02"); startDate == "" || date < startDate {
+			startDate = date
+		}
+	}
+
+	return startDate
+}
+
+// blocksSettlement ...
+// Whether a source's Plaid item stopped syncing, so spending
+// misses its transactions. Items pending expiration still
+// sync and sources that are gone for good never come back,
+// so neither holds settlement up
+func blocksSettlement(sourceIssues []models.BudgetSourceIssue) bool {
+	for _, issue := range sourceIssues {
+		if account.PlaidItemIsBroken(issue.ItemStatus) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// settleRollovers ...
+// Works out the finished periods of the expenses that roll over that
+// don't have a snapshot yet, oldest first, and returns what every one
+// of them carries into its current period along with all snapshots.
+// Only periods past the grace window are saved, and none while
+// blocked because spending misses a broken source, so later
+// requests settle them once everything spent in them is synced
+func settleRollovers(
+	ctx context.Context,
+	expenses []models.Expense,
+	snapshots []models.ExpensePeriodSnapshot,
+	spending map[string]models.ExpenseCategorySummary,
+	expenseCategories map[uuid.UUID][]string,
+	blocked bool,
+	now time.Time,
+) (map[uuid.UUID]float64, []models.ExpensePeriodSnapshot, *errors.Error) {
+	carried := make(map[uuid.UUID]float64)
+	settled := make([]models.ExpensePeriodSnapshot, 0)
+	saved := make([]models.ExpensePeriodSnapshot, 0)
+
+	for _, expense := range expenses {
+		if !expense.Rollover {
+			continue
+		}
+
+		next, carriedIn := rolloverPosition(expense, snapshots, now)
+		currentStart, _ := expenseService.ChargeCyclePeriod(expense.ExpenseChargeCycle, now)
+
+		for next.Before(currentStart) {
+			start, end := expenseService.ChargeCyclePeriod(expense.ExpenseChargeCycle, next)
+
+			period := expensePeriod{
+				start: start,
+				end:   end,
+				limit: float64(expense.ExpenseValue),
+			}
+
+			actual := periodSpending(spending, expenseCategories[expense.ExpenseID], period)
+			carriedOut := expenseService.CarryOver(expense, carriedIn, period.limit, actual)
+
+			snapshot := models.ExpensePeriodSnapshot{
+				ExpenseID:   expense.ExpenseID,
+				PeriodStart: period.start.Format("2006-01-02"),
+				PeriodEnd:   period.lastDay().Format("2006-01-02"),
+				Limit:       period.limit,
+				CarriedIn:   carriedIn,
+				Actual:      actual,
+				CarriedOut:  carriedOut,
+			}
+
+			settled = append(settled, snapshot)
+
+			if !blocked && !now.Before(end.AddDate(0, 0, settlementGraceDays)) {
+				saved = append(saved, snapshot)
+			}
+
+			carriedIn = carriedOut
+			next = end
+		}
+
+		carried[expense.ExpenseID] = carriedIn
+	}
+
+	if len(saved) == 0 {
+		return carried, append(snapshots, settled...), nil
+	}
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		for _, snapshot := range saved {
+			if err := expenseService.CreatePeriodSnapshot(ctx, snapshot); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if txErr != nil {
+		return nil, nil, toServiceError(txErr)
+	}
+
+	return carried, append(snapshots, settled...), nil
+}
+
+// carriedInto ...
+// What was carried into a finished period of an expense
+// that rolls over, according to its snapshot
+func carriedInto(expense models.Expense, snapshots []models.ExpensePeriodSnapshot, period expensePeriod) float64 {
+	start := period.start.Format("2006-01-02")
+
+	for _, snapshot := range snapshots {
+		if snapshot.ExpenseID == expense.ExpenseID && snapshot.PeriodStart == start && snapshot.PeriodEnd >= expense.RolloverStart {
+			return snapshot.CarriedIn
+		}
+	}
+
+	return 0
+}
+
+// periodSpending ...
+// What was spent in the given categories during a period
+func periodSpending(spending map[string]models.ExpenseCategorySummary, categories []string, period expensePeriod) float64 {
+	total := 0.0
+
+	for _, category := range categories {
+		for _, tx := range spending[category].Transactions {
+			if period.contains(tx.Date) {
+				total += tx.Amount
+			}
+		}
+	}
+
+	return total
+}
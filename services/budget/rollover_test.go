@@ -0,0 +1,157 @@
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	expenseService "github.com/lakshay35/finlit-backend/services/expense"
+)
+
+// rolloverGroceries ...
+// A monthly grocery expense of 300 in a new budget
+// that has rolled over since February
+func rolloverGroceries(t *testing.T, ctx context.Context) (models.Expense, map[uuid.UUID][]string) {
+	t.Helper()
+
+	roles := useMemoryStores()
+	ownerID := uuid.New()
+	budgetID := uuid.New()
+	roles.SetBudgetOwner(budgetID, ownerID)
+
+	created, addErr := expenseService.AddExpenseToBudget(ctx, &models.AddExpensePayload{
+		BudgetID:           budgetID,
+		ExpenseName:        "Groceries",
+		ExpenseValue:       300,
+		ExpenseChargeCycle: models.ExpenseChargeCycle{Unit: "monthly"},
+		Rollover:           true,
+	}, ownerID)
+
+	if addErr != nil {
+		t.Fatal(addErr.Message)
+	}
+
+	groceries := *created
+	groceries.RolloverStart = "2021-02-01"
+
+	return groceries, map[uuid.UUID][]string{groceries.ExpenseID: {"Groceries"}}
+}
+
+func TestSettleRollovers(t *testing.T) {
+	ctx := context.Background()
+
+	spending := map[string]models.ExpenseCategorySummary{
+		"Groceries": {CategoryName: "Groceries", Transactions: []models.Transaction{
+			transaction("Market", "2021-02-10", 250),
+			transaction("Market", "2021-03-10", 320),
+		}},
+	}
+
+	tests := []struct {
+		name      string
+		now       string
+		blocked   bool
+		wantSaved int
+	}{
+		{name: "past the grace window", now: "2021-04-15", wantSaved: 2},
+		{name: "last period in the grace window", now: "2021-04-03", wantSaved: 1},
+		{name: "a source's item is broken", now: "2021-04-15", blocked: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			groceries, expenseCategories := rolloverGroceries(t, ctx)
+
+			carried, snapshots, settleErr := settleRollovers(ctx, []models.Expense{groceries}, nil, spending, expenseCategories, test.blocked, day(test.now))
+
+			if settleErr != nil {
+				t.Fatal(settleErr.Message)
+			}
+
+			// February leaves 50 over, March overspends by 20
+			if carried[groceries.ExpenseID] != 30 {
+				t.Errorf("carried into April = %v, want 30", carried[groceries.ExpenseID])
+			}
+
+			if len(snapshots) != 2 {
+				t.Fatalf("got %d snapshots, want February and March", len(snapshots))
+			}
+
+			saved, err := expenseService.GetBudgetPeriodSnapshots(ctx, groceries.BudgetID)
+
+			if err != nil {
+				t.Fatal(err.Message)
+			}
+
+			if len(saved) != test.wantSaved {
+				t.Errorf("saved %d snapshots, want %d", len(saved), test.wantSaved)
+			}
+		})
+	}
+}
+
+func TestSettleRolloversCountsLatePostings(t *testing.T) {
+	ctx := context.Background()
+	groceries, expenseCategories := rolloverGroceries(t, ctx)
+
+	spending := map[string]models.ExpenseCategorySummary{
+		"Groceries": {CategoryName: "Groceries", Transactions: []models.Transaction{
+			transaction("Market", "2021-02-10", 250),
+			transaction("Market", "2021-03-10", 320),
+		}},
+	}
+
+	if _, _, settleErr := settleRollovers(ctx, []models.Expense{groceries}, nil, spending, expenseCategories, false, day("2021-04-02")); settleErr != nil {
+		t.Fatal(settleErr.Message)
+	}
+
+	// A purchase from the end of March posts in April
+	summary := spending["Groceries"]
+	summary.Transactions = append(summary.Transactions, transaction("Market", "2021-03-31", 15))
+	spending["Groceries"] = summary
+
+	saved, err := expenseService.GetBudgetPeriodSnapshots(ctx, groceries.BudgetID)
+
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	carried, snapshots, settleErr := settleRollovers(ctx, []models.Expense{groceries}, saved, spending, expenseCategories, false, day("2021-04-20"))
+
+	if settleErr != nil {
+		t.Fatal(settleErr.Message)
+	}
+
+	if carried[groceries.ExpenseID] != 15 {
+		t.Errorf("carried into April = %v, want 15", carried[groceries.ExpenseID])
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.PeriodStart == "2021-03-01" && snapshot.Actual != 335 {
+			t.Errorf("March spending = %v, want 335 with the late posting", snapshot.Actual)
+		}
+	}
+}
+
+func TestBlocksSettlement(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{status: models.PlaidItemStatusHealthy},
+		{status: models.PlaidItemStatusPendingExpiration},
+		{status: models.PlaidItemStatusLoginRequired, want: true},
+		{status: models.PlaidItemStatusRevoked, want: true},
+		// Sources whose account is gone have no item
+		{status: ""},
+	}
+
+	for _, test := range tests {
+		issues := []models.BudgetSourceIssue{{ItemStatus: test.status, Skipped: test.status != models.PlaidItemStatusPendingExpiration}}
+
+		if got := blocksSettlement(issues); got != test.want {
+			t.Errorf("blocksSettlement(%q) = %v, want %v", test.status, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,492 @@
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	"github.com/lakshay35/finlit-backend/services/account"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// GetCategorizationRules ...
+// Gets the categorization rules of a budget in the order they are tried
+func GetCategorizationRules(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.CategorizationRule, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	rules, err := store.GetCategorizationRules(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return rules, nil
+}
+
+// CreateCategorizationRule ...
+// Creates a categorization rule for a budget
+func CreateCategorizationRule(ctx context.Context, rule models.CategorizationRule, userID uuid.UUID) (*models.CategorizationRule, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, rule.BudgetID, userID) && !roleService.IsUserOwner(ctx, rule.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to create categorization rules for the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if validationErr := validateRule(ctx, rule); validationErr != nil {
+		return nil, validationErr
+	}
+
+	created, err := store.CreateCategorizationRule(ctx, rule)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return created, nil
+}
+
+// UpdateCategorizationRule ...
+// Replaces the category, priority and conditions of a rule.
+// A rule stays with the budget it was created for
+func UpdateCategorizationRule(ctx context.Context, rule models.CategorizationRule, userID uuid.UUID) (*models.CategorizationRule, *errors.Error) {
+	existing, getErr := getUserRule(ctx, rule.RuleID, userID)
+
+	if getErr != nil {
+		return nil, getErr
+	}
+
+	rule.BudgetID = existing.BudgetID
+
+	if validationErr := validateRule(ctx, rule); validationErr != nil {
+		return nil, validationErr
+	}
+
+	if err := store.UpdateCategorizationRule(ctx, rule); err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	updated, err := store.GetCategorizationRule(ctx, rule.RuleID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return updated, nil
+}
+
+// DeleteCategorizationRule ...
+// Deletes a categorization rule
+func DeleteCategorizationRule(ctx context.Context, ruleID uuid.UUID, userID uuid.UUID) *errors.Error {
+	if _, getErr := getUserRule(ctx, ruleID, userID); getErr != nil {
+		return getErr
+	}
+
+	if err := store.DeleteCategorizationRule(ctx, ruleID); err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// TestCategorizationRules ...
+// Runs a transaction through a budget's categorization and reports
+// the category it lands in along with every rule it matches.
+// Synced transactions have to come from one of the budget's sources
+func TestCategorizationRules(ctx context.Context, payload models.CategorizationRuleTestPayload, userID uuid.UUID) (*models.TransactionCategorization, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, payload.BudgetID, userID) && !roleService.IsUserOwner(ctx, payload.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	transaction := models.Transaction{
+		Transaction: plaid.Transaction{
+			Name:     payload.Name,
+			Amount:   payload.Amount,
+			Category: payload.Category,
+		},
+	}
+
+	if payload.ExternalAccountID != nil {
+		transaction.ExternalAccountID = *payload.ExternalAccountID
+	}
+
+	if payload.TransactionID != "" {
+		stored, storedErr := account.GetStoredTransaction(ctx, payload.TransactionID)
+
+		if storedErr != nil {
+			return nil, storedErr
+		}
+
+		if !isBudgetSource(ctx, payload.BudgetID, stored.ExternalAccountID) {
+			return nil, &errors.Error{
+				Message:    "Transaction does not belong to any of the budget's transaction sources",
+				StatusCode: http.StatusNotFound,
+			}
+		}
+
+		transaction = *stored
+	}
+
+	if transaction.Name == "" {
+		return nil, &errors.Error{
+			Message:    "Either transaction_id or name must be provided",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	categorizer, categorizerErr := newCategorizer(ctx, payload.BudgetID)
+
+	if categorizerErr != nil {
+		return nil, categorizerErr
+	}
+
+	merchant := payload.Merchant
+
+	if merchant == "" {
+		merchant = categorizer.merchant(transaction)
+	}
+
+	result := categorizer.categorize(transaction, merchant)
+	result.MatchingRules = make([]models.CategorizationRule, 0)
+
+	for _, rule := range categorizer.rules {
+		if rule.matches(transaction, merchant) {
+			result.MatchingRules = append(result.MatchingRules, rule.CategorizationRule)
+		}
+	}
+
+	return &result, nil
+}
+
+// categorizer ...
+// Puts transactions of a budget in its categories. Merchants
+// of transactions users assigned a category by hand win, then
+// the first matching rule in priority order and last the budget's
+// mapping of the transaction's Plaid category. Merchants
+// are matched as the budget's aliases name them. Deposits
+// are matched to the budget's income sources
+type categorizer struct {
+	assignments   map[string]string
+	rules         []compiledRule
+	mappings      []models.PlaidCategoryMapping
+	aliases       merchantAliases
+	incomeSources []models.IncomeSource
+}
+
+type compiledRule struct {
+	models.CategorizationRule
+	nameRegex *regexp.Regexp
+}
+
+// newCategorizer ...
+// Loads the category assignments, rules, Plaid category
+// mappings, merchant aliases and income sources of a budget
+func newCategorizer(ctx context.Context, budgetID uuid.UUID) (*categorizer, *errors.Error) {
+	assignments, assignmentsErr := GetBudgetTransactionCategoryTransactions(ctx, budgetID)
+
+	if assignmentsErr != nil {
+		return nil, assignmentsErr
+	}
+
+	rules, err := store.GetCategorizationRules(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	mappings, err := store.GetPlaidCategoryMappings(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	aliases, aliasesErr := loadMerchantAliases(ctx, budgetID)
+
+	if aliasesErr != nil {
+		return nil, aliasesErr
+	}
+
+	incomeSources, err := store.GetIncomeSources(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	c := &categorizer{
+		assignments:   make(map[string]string, len(assignments)),
+		rules:         make([]compiledRule, 0, len(rules)),
+		mappings:      mappings,
+		aliases:       aliases,
+		incomeSources: incomeSources,
+	}
+
+	for _, assignment := range assignments {
+		c.assignments[assignmentKey(assignment.TransactionName)] = assignment.CategoryName
+	}
+
+	for _, rule := range rules {
+		compiled := compiledRule{CategorizationRule: rule}
+
+		if rule.NameRegex != "" {
+			nameRegex, compileErr := regexp.Compile(rule.NameRegex)
+
+			// Patterns are validated when saved, so this only
+			// skips rules stored before the pattern went bad
+			if compileErr != nil {
+				logging.WarningLogger.Printf("skipping categorization rule %s: %s", rule.RuleID, compileErr)
+				continue
+			}
+
+			compiled.nameRegex = nameRegex
+		}
+
+		c.rules = append(c.rules, compiled)
+	}
+
+	return c, nil
+}
+
+// merchant ...
+// The merchant of a transaction as the budget calls it
+func (c *categorizer) merchant(tx models.Transaction) string {
+	return c.aliases.merchant(tx)
+}
+
+// categorize ...
+// Finds the category of a transaction, leaving the
+// category name empty when nothing matches
+func (c *categorizer) categorize(tx models.Transaction, merchant string) models.TransactionCategorization {
+	result := models.TransactionCategorization{
+		TransactionName: tx.Name,
+		Merchant:        merchant,
+	}
+
+	if categoryName, ok := c.assignments[assignmentKey(tx.Name)]; ok {
+		result.CategoryName = categoryName
+		result.Source = models.CategorizationSourceAssignment
+
+		return result
+	}
+
+	for i := range c.rules {
+		if c.rules[i].matches(tx, merchant) {
+			matched := c.rules[i].CategorizationRule
+			result.CategoryName = matched.CategoryName
+			result.Source = models.CategorizationSourceRule
+			result.MatchedRule = &matched
+
+			return result
+		}
+	}
+
+	if mapping := matchPlaidCategoryMapping(c.mappings, tx); mapping != nil {
+		matched := *mapping
+		result.CategoryName = matched.CategoryName
+		result.Source = models.CategorizationSourcePlaid
+		result.MatchedMapping = &matched
+	}
+
+	return result
+}
+
+// matches ...
+// Whether a transaction meets every condition of a rule.
+// Text conditions other than the regex ignore case
+func (r compiledRule) matches(tx models.Transaction, merchant string) bool {
+	name := strings.ToLower(tx.Name)
+
+	if r.NameContains != "" && !strings.Contains(name, strings.ToLower(r.NameContains)) {
+		return false
+	}
+
+	if r.NamePrefix != "" && !strings.HasPrefix(name, strings.ToLower(r.NamePrefix)) {
+		return false
+	}
+
+	if r.nameRegex != nil && !r.nameRegex.MatchString(tx.Name) {
+		return false
+	}
+
+	if r.Merchant != "" && !strings.EqualFold(strings.TrimSpace(r.Merchant), merchant) {
+		return false
+	}
+
+	if r.MinAmount != nil && tx.Amount < *r.MinAmount {
+		return false
+	}
+
+	if r.MaxAmount != nil && tx.Amount > *r.MaxAmount {
+		return false
+	}
+
+	if r.ExternalAccountID != nil && *r.ExternalAccountID != tx.ExternalAccountID {
+		return false
+	}
+
+	if r.PlaidCategory != "" && !inPlaidCategory(tx.Category, r.PlaidCategory) {
+		return false
+	}
+
+	return true
+}
+
+// inPlaidCategory ...
+// Whether a Plaid category hierarchy contains the given
+// category at any level, e.g. "Food and Drink" or "Coffee Shop"
+func inPlaidCategory(hierarchy []string, category string) bool {
+	for _, level := range hierarchy {
+		if strings.EqualFold(level, category) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateRule ...
+// Checks a rule has at least one sound condition and points
+// at a category and account belonging to its budget
+func validateRule(ctx context.Context, rule models.CategorizationRule) *errors.Error {
+	if rule.NameContains == "" && rule.NamePrefix == "" && rule.NameRegex == "" && rule.Merchant == "" &&
+		rule.MinAmount == nil && rule.MaxAmount == nil && rule.ExternalAccountID == nil && rule.PlaidCategory == "" {
+		return &errors.Error{
+			Message:    "A categorization rule needs at least one condition",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if rule.NameRegex != "" {
+		if _, compileErr := regexp.Compile(rule.NameRegex); compileErr != nil {
+			return &errors.Error{
+				Message:    "name_regex is not a valid regular expression: " + compileErr.Error(),
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return &errors.Error{
+			Message:    "min_amount can't be greater than max_amount",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	categories, err := store.GetTransactionCategories(ctx, rule.BudgetID)
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	categoryValid := false
+
+	for _, cat := range categories {
+		if cat.BudgetTransactionCategoryID == rule.BudgetTransactionCategoryID {
+			categoryValid = true
+		}
+	}
+
+	if !categoryValid {
+		return &errors.Error{
+			Message:    "Provided category not found",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if rule.ExternalAccountID != nil && !isBudgetSource(ctx, rule.BudgetID, *rule.ExternalAccountID) {
+		return &errors.Error{
+			Message:    "Provided external account is not a transaction source of the budget",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return nil
+}
+
+// isBudgetSource ...
+// Whether an external account feeds a budget
+func isBudgetSource(ctx context.Context, budgetID uuid.UUID, externalAccountID uuid.UUID) bool {
+	sources, err := store.GetTransactionSources(ctx, budgetID)
+
+	if err != nil {
+		return false
+	}
+
+	for _, source := range sources {
+		if source.ExternalAccountID == externalAccountID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getUserRule ...
+// Gets a rule of a budget the user administers
+func getUserRule(ctx context.Context, ruleID uuid.UUID, userID uuid.UUID) (*models.CategorizationRule, *errors.Error) {
+	rule, err := store.GetCategorizationRule(ctx, ruleID)
+
+	if err == sql.ErrNoRows {
+		return nil, &errors.Error{
+			Message:    "Categorization rule not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if !roleService.IsUserAdmin(ctx, rule.BudgetID, userID) && !roleService.IsUserOwner(ctx, rule.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to change categorization rules of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	return rule, nil
+}
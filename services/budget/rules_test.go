@@ -0,0 +1,137 @@
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+func TestCategorizerAssignmentsMatchMerchants(t *testing.T) {
+	ctx := context.Background()
+	useMemoryStores()
+
+	budget, createErr := CreateBudget(ctx, uuid.New(), "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	for name, categoryName := range map[string]string{
+		"UBER *EATS 8832":      "Dining",
+		"UBER *TRIP":           "Transport",
+		"SQ *BLUE BOTTLE #123": "Coffee",
+	} {
+		category, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: categoryName})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.CreateCategoryTransaction(ctx, models.BudgetTransactionCategoryTransaction{
+			BudgetTransactionCategoryID: category.BudgetTransactionCategoryID,
+			TransactionName:             name,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	categorizer, categorizerErr := newCategorizer(ctx, budget.BudgetID)
+
+	if categorizerErr != nil {
+		t.Fatal(categorizerErr.Message)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "UBER *EATS 8832", want: "Dining"},
+		{name: "UBER EATS 8005928996", want: "Dining"},
+		{name: "UBER *TRIP HELP.UBER.COM", want: "Transport"},
+		{name: "BLUE BOTTLE 4411", want: "Coffee"},
+		{name: "LYFT *RIDE", want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx := transaction(test.name, "2021-04-01", 12)
+			result := categorizer.categorize(tx, categorizer.merchant(tx))
+
+			if result.CategoryName != test.want {
+				t.Errorf("categorized as %q, want %q", result.CategoryName, test.want)
+			}
+
+			if test.want != "" && result.Source != models.CategorizationSourceAssignment {
+				t.Errorf("categorized by %s, want an assignment", result.Source)
+			}
+		})
+	}
+}
+
+func TestDisconnectExternalAccountDeletesItsRules(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	roles := useMemoryStores()
+
+	accounts := linkFakeBank(t, ctx, ownerID)
+	linked, other := accounts[0], accounts[1]
+
+	budget, createErr := CreateBudget(ctx, ownerID, "Household")
+
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+
+	roles.SetBudgetOwner(budget.BudgetID, ownerID)
+
+	category, err := store.CreateTransactionCategory(ctx, models.BudgetTransactionCategoryCreationPayload{BudgetID: budget.BudgetID, CategoryName: "Coffee"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rules := make(map[uuid.UUID]*models.CategorizationRule)
+
+	for _, act := range accounts {
+		if _, err := store.CreateTransactionSource(ctx, models.BudgetTransactionSourceCreationPayload{
+			BudgetID:          budget.BudgetID,
+			ExternalAccountID: act.ExternalAccountID,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		externalAccountID := act.ExternalAccountID
+		rule, ruleErr := CreateCategorizationRule(ctx, models.CategorizationRule{
+			BudgetID:                    budget.BudgetID,
+			BudgetTransactionCategoryID: category.BudgetTransactionCategoryID,
+			NameContains:                "Coffee",
+			ExternalAccountID:           &externalAccountID,
+		}, ownerID)
+
+		if ruleErr != nil {
+			t.Fatal(ruleErr.Message)
+		}
+
+		rules[externalAccountID] = rule
+	}
+
+	disconnection, disconnectErr := DisconnectExternalAccount(ctx, ownerID, linked.ExternalAccountID)
+
+	if disconnectErr != nil {
+		t.Fatal(disconnectErr.Message)
+	}
+
+	deleted := disconnection.DeletedRules
+
+	if len(deleted) != 1 || deleted[0].RuleID != rules[linked.ExternalAccountID].RuleID || deleted[0].CategoryName != "Coffee" {
+		t.Fatalf("deleted rules = %+v, want the rule limited to the account", deleted)
+	}
+
+	remaining, _ := GetCategorizationRules(ctx, budget.BudgetID, ownerID)
+
+	if len(remaining) != 1 || remaining[0].RuleID != rules[other.ExternalAccountID].RuleID {
+		t.Errorf("remaining rules = %+v, want the other account's rule", remaining)
+	}
+}
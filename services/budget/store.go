@@ -0,0 +1,154 @@
+package budget
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// BudgetStore ...
+// Persistence operations needed by the budget service
+type BudgetStore interface {
+	// FindBudget returns the budget a user owns with the
+	// given name or sql.ErrNoRows if there is none
+	FindBudget(ctx context.Context, ownerID uuid.UUID, budgetName string) (*models.Budget, error)
+	// GetBudget returns a budget or sql.ErrNoRows if there is none
+	GetBudget(ctx context.Context, budgetID uuid.UUID) (*models.Budget, error)
+	// GetBudgets returns every budget a user owns
+	GetBudgets(ctx context.Context, ownerID uuid.UUID) ([]models.Budget, error)
+	// CreateBudget inserts a budget
+	CreateBudget(ctx context.Context, ownerID uuid.UUID, budgetName string) (*models.Budget, error)
+	// SetBudgetZeroBased turns zero-based budgeting on or off for a budget
+	SetBudgetZeroBased(ctx context.Context, budgetID uuid.UUID, zeroBased bool) error
+	// DeleteBudget deletes a budget
+	DeleteBudget(ctx context.Context, budgetID uuid.UUID) error
+
+	// GetTransactionSources returns the accounts feeding a budget
+	GetTransactionSources(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionSourcePayload, error)
+	// GetTransactionSource returns a budget transaction source
+	// or sql.ErrNoRows if there is none
+	GetTransactionSource(ctx context.Context, budgetTransactionSourceID uuid.UUID) (*models.BudgetTransactionSource, error)
+	// CreateTransactionSource links an external account to a budget
+	CreateTransactionSource(ctx context.Context, source models.BudgetTransactionSourceCreationPayload) (*models.BudgetTransactionSource, error)
+	// DeleteTransactionSource unlinks a transaction source
+	DeleteTransactionSource(ctx context.Context, budgetTransactionSourceID uuid.UUID) error
+	// DeleteAllTransactionSources unlinks every transaction source of a budget
+	DeleteAllTransactionSources(ctx context.Context, budgetID uuid.UUID) error
+	// GetAccountSourceBudgets returns every budget using an
+	// external account as a transaction source
+	GetAccountSourceBudgets(ctx context.Context, externalAccountID uuid.UUID) ([]models.Budget, error)
+	// DeleteAccountTransactionSources unlinks an external
+	// account from every budget using it
+	DeleteAccountTransactionSources(ctx context.Context, externalAccountID uuid.UUID) error
+
+	// GetTransactionCategories returns the transaction categories of a budget
+	GetTransactionCategories(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategory, error)
+	// GetTransactionCategory returns a transaction category
+	// or sql.ErrNoRows if there is none
+	GetTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID) (*models.BudgetTransactionCategory, error)
+	// CreateTransactionCategory inserts a transaction category
+	CreateTransactionCategory(ctx context.Context, category models.BudgetTransactionCategoryCreationPayload) (*models.BudgetTransactionCategory, error)
+	// SetTransactionCategoryParent moves a transaction category under
+	// another, or to the top when parentCategoryID is nil
+	SetTransactionCategoryParent(ctx context.Context, budgetTransactionCategoryID uuid.UUID, parentCategoryID *uuid.UUID) error
+	// RenameTransactionCategory renames a transaction category
+	// or returns sql.ErrNoRows if there is none
+	RenameTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID, categoryName string) error
+	// ReassignTransactionCategory moves the tagged transactions, rules
+	// and Plaid category mappings of a category to another one
+	ReassignTransactionCategory(ctx context.Context, fromCategoryID uuid.UUID, toCategoryID uuid.UUID) error
+	// DeleteTransactionCategory deletes a transaction category
+	DeleteTransactionCategory(ctx context.Context, budgetTransactionCategoryID uuid.UUID) error
+	// DeleteAllTransactionCategories deletes every transaction category
+	// of a budget along with the transactions tagged with them
+	DeleteAllTransactionCategories(ctx context.Context, budgetID uuid.UUID) error
+
+	// GetCategoryTransactions returns the transaction names users
+	// have tagged with one of a budget's categories
+	GetCategoryTransactions(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategoryTransaction, error)
+	// CreateCategoryTransaction tags a transaction name with a category
+	CreateCategoryTransaction(ctx context.Context, categoryTransaction models.BudgetTransactionCategoryTransaction) error
+	// DeleteBudgetCategoryTransaction removes the categories a
+	// transaction name was tagged with in a budget
+	DeleteBudgetCategoryTransaction(ctx context.Context, budgetID uuid.UUID, transactionName string) error
+	// DeleteCategoryTransactions removes every transaction tagged with a category
+	DeleteCategoryTransactions(ctx context.Context, budgetTransactionCategoryID uuid.UUID) error
+
+	// GetCategorizationRules returns the categorization rules
+	// of a budget in the order they are tried
+	GetCategorizationRules(ctx context.Context, budgetID uuid.UUID) ([]models.CategorizationRule, error)
+	// GetCategorizationRule returns a categorization rule
+	// or sql.ErrNoRows if there is none
+	GetCategorizationRule(ctx context.Context, ruleID uuid.UUID) (*models.CategorizationRule, error)
+	// CreateCategorizationRule inserts a categorization rule
+	CreateCategorizationRule(ctx context.Context, rule models.CategorizationRule) (*models.CategorizationRule, error)
+	// UpdateCategorizationRule replaces the category, priority and
+	// conditions of a rule or returns sql.ErrNoRows if there is none
+	UpdateCategorizationRule(ctx context.Context, rule models.CategorizationRule) error
+	// DeleteCategorizationRule deletes a categorization rule
+	DeleteCategorizationRule(ctx context.Context, ruleID uuid.UUID) error
+	// DeleteAccountCategorizationRules deletes the categorization
+	// rules limited to an external account and returns them
+	DeleteAccountCategorizationRules(ctx context.Context, externalAccountID uuid.UUID) ([]models.CategorizationRule, error)
+
+	// GetPlaidCategoryMappings returns the Plaid category mappings of a budget
+	GetPlaidCategoryMappings(ctx context.Context, budgetID uuid.UUID) ([]models.PlaidCategoryMapping, error)
+	// GetPlaidCategoryMapping returns a Plaid category mapping
+	// or sql.ErrNoRows if there is none
+	GetPlaidCategoryMapping(ctx context.Context, mappingID uuid.UUID) (*models.PlaidCategoryMapping, error)
+	// CreatePlaidCategoryMapping inserts a Plaid category mapping
+	CreatePlaidCategoryMapping(ctx context.Context, mapping models.PlaidCategoryMapping) (*models.PlaidCategoryMapping, error)
+	// DeletePlaidCategoryMapping deletes a Plaid category mapping
+	DeletePlaidCategoryMapping(ctx context.Context, mappingID uuid.UUID) error
+
+	// GetMerchantAliases returns the merchant aliases of a budget
+	GetMerchantAliases(ctx context.Context, budgetID uuid.UUID) ([]models.MerchantAlias, error)
+	// GetMerchantAlias returns a merchant alias
+	// or sql.ErrNoRows if there is none
+	GetMerchantAlias(ctx context.Context, aliasID uuid.UUID) (*models.MerchantAlias, error)
+	// CreateMerchantAlias inserts a merchant alias
+	CreateMerchantAlias(ctx context.Context, alias models.MerchantAlias) (*models.MerchantAlias, error)
+	// DeleteMerchantAlias deletes a merchant alias
+	DeleteMerchantAlias(ctx context.Context, aliasID uuid.UUID) error
+
+	// GetAlertRules returns the alert rules of a budget
+	GetAlertRules(ctx context.Context, budgetID uuid.UUID) ([]models.AlertRule, error)
+	// GetAlertRule returns an alert rule
+	// or sql.ErrNoRows if there is none
+	GetAlertRule(ctx context.Context, alertRuleID uuid.UUID) (*models.AlertRule, error)
+	// CreateAlertRule inserts an alert rule
+	CreateAlertRule(ctx context.Context, rule models.AlertRule) (*models.AlertRule, error)
+	// DeleteAlertRule deletes an alert rule and
+	// the record of the alerts it fired
+	DeleteAlertRule(ctx context.Context, alertRuleID uuid.UUID) error
+	// RecordAlertFired records that a rule fired the alert with the
+	// given key and reports false if it already had
+	RecordAlertFired(ctx context.Context, alertRuleID uuid.UUID, alertKey string) (bool, error)
+	// ForgetAlertFired removes the record of an alert having
+	// fired so it fires again
+	ForgetAlertFired(ctx context.Context, alertRuleID uuid.UUID, alertKey string) error
+
+	// GetIncomeSources returns the income sources of a
+	// budget in the order they were created
+	GetIncomeSources(ctx context.Context, budgetID uuid.UUID) ([]models.IncomeSource, error)
+	// GetIncomeSource returns an income source
+	// or sql.ErrNoRows if there is none
+	GetIncomeSource(ctx context.Context, incomeSourceID uuid.UUID) (*models.IncomeSource, error)
+	// CreateIncomeSource inserts an income source
+	CreateIncomeSource(ctx context.Context, source models.IncomeSource) (*models.IncomeSource, error)
+	// DeleteIncomeSource deletes an income source
+	DeleteIncomeSource(ctx context.Context, incomeSourceID uuid.UUID) error
+	// UnlinkAccountIncomeSources clears the external account of the
+	// income sources limited to it and returns them as they are now
+	UnlinkAccountIncomeSources(ctx context.Context, externalAccountID uuid.UUID) ([]models.IncomeSource, error)
+}
+
+var store BudgetStore
+
+// SetStore ...
+// Sets the store used by the budget service.
+// Called once at startup
+func SetStore(s BudgetStore) {
+	store = s
+}
@@ -0,0 +1,173 @@
+package budget
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	merchantService "github.com/lakshay35/finlit-backend/services/merchant"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// GetCategorySuggestions ...
+// Suggests categories for the budget's uncategorized spending of
+// the past 30 days, learned from the transactions users tagged by
+// hand. Tagging a transaction through /transaction/categorize
+// accepts or corrects a suggestion and retrains the classifier
+func GetCategorySuggestions(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.TransactionCategorySuggestions, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, budgetID, userID) && !roleService.IsUserOwner(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	suggestions, _, err := suggestCategories(ctx, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stripSuggestions(suggestions), nil
+}
+
+// ApplyCategorySuggestions ...
+// Tags every uncategorized transaction whose top suggestion is at
+// least threshold confident with the suggested category. A classifier
+// that has only learned one category is sure of it for everything,
+// so nothing is applied until it has learned at least two
+func ApplyCategorySuggestions(ctx context.Context, payload models.CategorySuggestionApplyPayload, userID uuid.UUID) ([]models.TransactionCategorySuggestions, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, payload.BudgetID, userID) && !roleService.IsUserOwner(ctx, payload.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to categorize transactions of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if payload.Threshold <= 0 || payload.Threshold > 1 {
+		return nil, &errors.Error{
+			Message:    "threshold must be greater than 0 and at most 1",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	suggestions, trained, err := suggestCategories(ctx, payload.BudgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if trained.classes() < 2 {
+		return stripSuggestions(suggestions), nil
+	}
+
+	applied := make(map[string]*models.CategorySuggestion)
+
+	txErr := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		for i := range suggestions {
+			name := suggestions[i].TransactionName
+
+			if suggestion, ok := applied[name]; ok {
+				suggestions[i].Applied = suggestion
+				continue
+			}
+
+			if len(suggestions[i].Suggestions) == 0 || suggestions[i].Suggestions[0].Confidence < payload.Threshold {
+				continue
+			}
+
+			top := suggestions[i].Suggestions[0]
+
+			tagErr := CreateBudgetTransactionCategoryTransaction(ctx, payload.BudgetID, models.BudgetTransactionCategoryTransaction{
+				BudgetTransactionCategoryID: top.BudgetTransactionCategoryID,
+				TransactionName:             name,
+				CategoryName:                top.CategoryName,
+				PlaidCategory:               suggestions[i].plaidCategory,
+			})
+
+			if tagErr != nil {
+				return tagErr
+			}
+
+			applied[name] = &top
+			suggestions[i].Applied = &top
+		}
+
+		return nil
+	})
+
+	if txErr != nil {
+		return nil, toServiceError(txErr)
+	}
+
+	return stripSuggestions(suggestions), nil
+}
+
+// transactionSuggestions ...
+// Suggestions for a transaction along with
+// the Plaid category they'd be tagged with
+type transactionSuggestions struct {
+	models.TransactionCategorySuggestions
+	plaidCategory []string
+}
+
+// suggestCategories ...
+// Runs the budget's uncategorized spending through its classifier
+func suggestCategories(ctx context.Context, budgetID uuid.UUID) ([]transactionSuggestions, *classifier, *errors.Error) {
+	categories, categoriesErr := GetAllBudgetTransactionCategories(ctx, budgetID)
+
+	if categoriesErr != nil {
+		return nil, nil, categoriesErr
+	}
+
+	trained, err := budgetClassifier(ctx, budgetID)
+
+	if err != nil {
+		return nil, nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	// Suggestions only cover the sources that could be read,
+	// the inbox reports the others
+	transactions, _, uncategorizedErr := uncategorizedSpending(ctx, budgetID, defaultInboxDays)
+
+	if uncategorizedErr != nil {
+		return nil, nil, uncategorizedErr
+	}
+
+	suggestions := make([]transactionSuggestions, 0, len(transactions))
+
+	for _, tx := range transactions {
+		suggestions = append(suggestions, transactionSuggestions{
+			TransactionCategorySuggestions: models.TransactionCategorySuggestions{
+				TransactionID:     tx.ID,
+				TransactionName:   tx.Name,
+				Merchant:          tx.Merchant,
+				Amount:            tx.Amount,
+				Date:              tx.Date,
+				ExternalAccountID: tx.ExternalAccountID,
+				Suggestions:       trained.suggest(tx, merchantService.Normalize(tx.Name), categories),
+			},
+			plaidCategory: tx.Category,
+		})
+	}
+
+	return suggestions, trained, nil
+}
+
+// stripSuggestions ...
+// Drops what only applying suggestions needs
+func stripSuggestions(suggestions []transactionSuggestions) []models.TransactionCategorySuggestions {
+	stripped := make([]models.TransactionCategorySuggestions, 0, len(suggestions))
+
+	for _, suggestion := range suggestions {
+		stripped = append(stripped, suggestion.TransactionCategorySuggestions)
+	}
+
+	return stripped
+}
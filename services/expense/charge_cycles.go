@@ -0,0 +1,280 @@
+package expense
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+)
+
+// Charge cycle units
+const (
+	chargeCycleDaily        = "daily"
+	chargeCycleWeekly       = "weekly"
+	chargeCycleBiWeekly     = "bi-weekly"
+	chargeCycleEveryNWeeks  = "every-n-weeks"
+	chargeCycleSemiMonthly  = "semi-monthly"
+	chargeCycleMonthly      = "monthly"
+	chargeCycleDayOfMonth   = "day-of-month"
+	chargeCycleSemiAnnually = "semi-annually"
+	chargeCycleAnnually     = "annually"
+)
+
+// defaultChargeCycleAnchor is what cycles without an anchor date
+// count from. It is a Monday, so weekly cycles run Monday to
+// Sunday and yearly ones start on the 1st of January
+var defaultChargeCycleAnchor = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// ChargeCyclePeriod ...
+// The period of a charge cycle the given time falls in. Start is
+// the first day of the period and end the day after its last one
+func ChargeCyclePeriod(cycle models.ExpenseChargeCycle, now time.Time) (time.Time, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	anchor := chargeCycleAnchor(cycle)
+
+	switch cycle.Unit {
+	case chargeCycleDaily:
+		return today, today.AddDate(0, 0, 1)
+	case chargeCycleWeekly:
+		return fixedPeriod(today, anchor, 7)
+	case chargeCycleBiWeekly:
+		return fixedPeriod(today, anchor, 14)
+	case chargeCycleEveryNWeeks:
+		return fixedPeriod(today, anchor, cycle.IntervalWeeks*7)
+	case chargeCycleSemiMonthly:
+		if today.Day() < 15 {
+			return monthDate(today.Year(), today.Month(), 1), monthDate(today.Year(), today.Month(), 15)
+		}
+
+		return monthDate(today.Year(), today.Month(), 15), monthDate(today.Year(), today.Month()+1, 1)
+	case chargeCycleMonthly:
+		return monthlyPeriod(today, today, 1, 1)
+	case chargeCycleDayOfMonth:
+		day := cycle.DayOfMonth
+
+		if day == 0 {
+			day = anchor.Day()
+		}
+
+		return monthlyPeriod(today, anchor, 1, day)
+	case chargeCycleSemiAnnually:
+		return monthlyPeriod(today, anchor, 6, anchor.Day())
+	case chargeCycleAnnually:
+		return monthlyPeriod(today, anchor, 12, anchor.Day())
+	}
+
+	// Units added to the table without a calendar rule
+	// fall back to their length in days
+	return fixedPeriod(today, anchor, cycle.Days)
+}
+
+// PreviousChargeCyclePeriod ...
+// The period of a charge cycle the given number of periods
+// before the one the given time falls in
+func PreviousChargeCyclePeriod(cycle models.ExpenseChargeCycle, now time.Time, periodsAgo int) (time.Time, time.Time) {
+	start, end := ChargeCyclePeriod(cycle, now)
+
+	for i := 0; i < periodsAgo; i++ {
+		start, end = ChargeCyclePeriod(cycle, start.AddDate(0, 0, -1))
+	}
+
+	return start, end
+}
+
+// ChargeCycleLimit ...
+// What an expense charged value per period of its cycle may
+// spend between start and the day before end. Periods only
+// partly in the window count for the share of their days in it
+func ChargeCycleLimit(cycle models.ExpenseChargeCycle, value float64, start time.Time, end time.Time) float64 {
+	limit := 0.0
+	periodStart, periodEnd := ChargeCyclePeriod(cycle, start)
+
+	for periodStart.Before(end) {
+		overlapStart := periodStart
+		overlapEnd := periodEnd
+
+		if overlapStart.Before(start) {
+			overlapStart = start
+		}
+
+		if overlapEnd.After(end) {
+			overlapEnd = end
+		}
+
+		limit += value * overlapEnd.Sub(overlapStart).Hours() / periodEnd.Sub(periodStart).Hours()
+		periodStart, periodEnd = ChargeCyclePeriod(cycle, periodEnd)
+	}
+
+	return limit
+}
+
+// ResolveChargeCycle ...
+// Checks a charge cycle of something other than an expense the
+// way expense cycles are checked, filling in its id and length
+func ResolveChargeCycle(ctx context.Context, cycle models.ExpenseChargeCycle) (models.ExpenseChargeCycle, *errors.Error) {
+	cycles, err := store.GetChargeCycles(ctx)
+
+	if err != nil {
+		return cycle, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if cycleErr := validateChargeCycle(cycle); cycleErr != nil {
+		return cycle, cycleErr
+	}
+
+	for _, known := range cycles {
+		if known.Unit == cycle.Unit {
+			cycle.ExpenseChargeCycleID = known.ExpenseChargeCycleID
+			cycle.Days = known.Days
+
+			return cycle, nil
+		}
+	}
+
+	return cycle, &errors.Error{
+		Message:    "charge_cycle " + cycle.Unit + " is not valid",
+		StatusCode: http.StatusBadRequest,
+	}
+}
+
+// validateChargeCycle ...
+// Checks the anchor date, interval and day of month of a
+// charge cycle make sense for its unit
+func validateChargeCycle(cycle models.ExpenseChargeCycle) *errors.Error {
+	if cycle.AnchorDate != "" {
+		if _, err := time.Parse("2006-01-02", cycle.AnchorDate); err != nil {
+			return &errors.Error{
+				Message:    "anchor_date must be a date formatted as YYYY-MM-DD",
+				StatusCode: http.StatusBadRequest,
+			}
+		}
+	}
+
+	if cycle.Unit == chargeCycleEveryNWeeks && cycle.IntervalWeeks < 1 {
+		return &errors.Error{
+			Message:    "interval_weeks must be at least 1 for " + chargeCycleEveryNWeeks + " charge cycles",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if cycle.Unit != chargeCycleEveryNWeeks && cycle.IntervalWeeks != 0 {
+		return &errors.Error{
+			Message:    "interval_weeks can only be set for " + chargeCycleEveryNWeeks + " charge cycles",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if cycle.DayOfMonth < 0 || cycle.DayOfMonth > 31 {
+		return &errors.Error{
+			Message:    "day_of_month " + strconv.Itoa(cycle.DayOfMonth) + " is not a day of the month",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if cycle.Unit != chargeCycleDayOfMonth && cycle.DayOfMonth != 0 {
+		return &errors.Error{
+			Message:    "day_of_month can only be set for " + chargeCycleDayOfMonth + " charge cycles",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return nil
+}
+
+// chargeCycleAnchor ...
+// The date a charge cycle counts from
+func chargeCycleAnchor(cycle models.ExpenseChargeCycle) time.Time {
+	anchor, err := time.Parse("2006-01-02", cycle.AnchorDate)
+
+	if err != nil {
+		return defaultChargeCycleAnchor
+	}
+
+	return anchor
+}
+
+// fixedPeriod ...
+// The period of a cycle of the given number of
+// days, counted from anchor, that today is in
+func fixedPeriod(today time.Time, anchor time.Time, length int) (time.Time, time.Time) {
+	if length < 1 {
+		length = 1
+	}
+
+	// Durations top out at about 292 years, so the days
+	// are counted from the dates' Unix days instead
+	elapsed := unixDay(today) - unixDay(anchor)
+	periods := floorDiv(elapsed, length)
+
+	start := anchor.AddDate(0, 0, periods*length)
+
+	return start, start.AddDate(0, 0, length)
+}
+
+// unixDay ...
+// The number of days from 1970-01-01 to a date
+func unixDay(date time.Time) int {
+	return floorDiv(int(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Unix()), 86400)
+}
+
+// monthlyPeriod ...
+// The period of a cycle of the given number of months, counted
+// from the month of anchor, that today is in. Periods start on
+// day, or the last day of months that are shorter
+func monthlyPeriod(today time.Time, anchor time.Time, months int, day int) (time.Time, time.Time) {
+	anchorMonth := anchor.Year()*12 + int(anchor.Month()) - 1
+	currentMonth := today.Year()*12 + int(today.Month()) - 1
+
+	startMonth := anchorMonth + floorDiv(currentMonth-anchorMonth, months)*months
+	start := monthIndexDate(startMonth, day)
+
+	if start.After(today) {
+		startMonth -= months
+		start = monthIndexDate(startMonth, day)
+	}
+
+	return start, monthIndexDate(startMonth+months, day)
+}
+
+// monthIndexDate ...
+// The given day of a month counted from year 0
+func monthIndexDate(month int, day int) time.Time {
+	return monthDate(month/12, time.Month(month%12+1), day)
+}
+
+// monthDate ...
+// The given day of a month, or its last
+// day when the month is shorter
+func monthDate(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+
+	if day > last {
+		day = last
+	}
+
+	if day < 1 {
+		day = 1
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
+
+// floorDiv ...
+// Integer division rounding towards negative infinity,
+// so anchors in the future count backwards
+func floorDiv(a int, b int) int {
+	q := a / b
+
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+
+	return q
+}
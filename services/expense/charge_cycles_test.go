@@ -0,0 +1,124 @@
+package expense
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+func day(date string) time.Time {
+	t, err := time.Parse("2006-01-02", date)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestChargeCyclePeriod(t *testing.T) {
+	tests := []struct {
+		name      string
+		cycle     models.ExpenseChargeCycle
+		now       string
+		wantStart string
+		wantEnd   string
+	}{
+		{name: "31st clamps to the end of February", cycle: models.ExpenseChargeCycle{Unit: chargeCycleDayOfMonth, DayOfMonth: 31}, now: "2021-02-15", wantStart: "2021-01-31", wantEnd: "2021-02-28"},
+		{name: "clamped day starts the next period", cycle: models.ExpenseChargeCycle{Unit: chargeCycleDayOfMonth, DayOfMonth: 31}, now: "2021-02-28", wantStart: "2021-02-28", wantEnd: "2021-03-31"},
+		{name: "31st clamps to the 29th in leap years", cycle: models.ExpenseChargeCycle{Unit: chargeCycleDayOfMonth, DayOfMonth: 31}, now: "2024-02-20", wantStart: "2024-01-31", wantEnd: "2024-02-29"},
+		{name: "leap day is its own start", cycle: models.ExpenseChargeCycle{Unit: chargeCycleDayOfMonth, DayOfMonth: 31}, now: "2024-02-29", wantStart: "2024-02-29", wantEnd: "2024-03-31"},
+		{name: "day of month defaults to the anchor's", cycle: models.ExpenseChargeCycle{Unit: chargeCycleDayOfMonth, AnchorDate: "2021-01-31"}, now: "2021-04-30", wantStart: "2021-04-30", wantEnd: "2021-05-31"},
+		{name: "leap day anchor in a common year", cycle: models.ExpenseChargeCycle{Unit: chargeCycleAnnually, AnchorDate: "2020-02-29"}, now: "2021-03-01", wantStart: "2021-02-28", wantEnd: "2022-02-28"},
+		{name: "leap day anchor the day before a leap day", cycle: models.ExpenseChargeCycle{Unit: chargeCycleAnnually, AnchorDate: "2020-02-29"}, now: "2024-02-28", wantStart: "2023-02-28", wantEnd: "2024-02-29"},
+		{name: "leap day anchor on a leap day", cycle: models.ExpenseChargeCycle{Unit: chargeCycleAnnually, AnchorDate: "2020-02-29"}, now: "2024-02-29", wantStart: "2024-02-29", wantEnd: "2025-02-28"},
+		{name: "semi-annual 31st clamps", cycle: models.ExpenseChargeCycle{Unit: chargeCycleSemiAnnually, AnchorDate: "2020-08-31"}, now: "2021-02-27", wantStart: "2020-08-31", wantEnd: "2021-02-28"},
+		{name: "monthly in January", cycle: models.ExpenseChargeCycle{Unit: chargeCycleMonthly}, now: "2021-01-05", wantStart: "2021-01-01", wantEnd: "2021-02-01"},
+		{name: "semi-monthly across new year", cycle: models.ExpenseChargeCycle{Unit: chargeCycleSemiMonthly}, now: "2020-12-20", wantStart: "2020-12-15", wantEnd: "2021-01-01"},
+		{name: "semi-monthly first half", cycle: models.ExpenseChargeCycle{Unit: chargeCycleSemiMonthly}, now: "2021-01-14", wantStart: "2021-01-01", wantEnd: "2021-01-15"},
+		{name: "week spanning new year", cycle: models.ExpenseChargeCycle{Unit: chargeCycleWeekly}, now: "2021-01-01", wantStart: "2020-12-28", wantEnd: "2021-01-04"},
+		{name: "anchored fortnight spanning new year", cycle: models.ExpenseChargeCycle{Unit: chargeCycleBiWeekly, AnchorDate: "2020-12-25"}, now: "2021-01-07", wantStart: "2020-12-25", wantEnd: "2021-01-08"},
+		{name: "day of month spanning new year", cycle: models.ExpenseChargeCycle{Unit: chargeCycleDayOfMonth, DayOfMonth: 15}, now: "2021-01-10", wantStart: "2020-12-15", wantEnd: "2021-01-15"},
+		{name: "annual on new year's eve", cycle: models.ExpenseChargeCycle{Unit: chargeCycleAnnually}, now: "2020-12-31", wantStart: "2020-01-01", wantEnd: "2021-01-01"},
+		{name: "week centuries after the anchor", cycle: models.ExpenseChargeCycle{Unit: chargeCycleWeekly}, now: "2350-01-01", wantStart: "2349-12-26", wantEnd: "2350-01-02"},
+		{name: "week centuries before the anchor", cycle: models.ExpenseChargeCycle{Unit: chargeCycleWeekly}, now: "1700-03-10", wantStart: "1700-03-08", wantEnd: "1700-03-15"},
+		{name: "anchored fortnight centuries later", cycle: models.ExpenseChargeCycle{Unit: chargeCycleBiWeekly, AnchorDate: "2020-12-25"}, now: "2400-06-01", wantStart: "2400-05-19", wantEnd: "2400-06-02"},
+		{name: "every three weeks before the anchor", cycle: models.ExpenseChargeCycle{Unit: chargeCycleEveryNWeeks, IntervalWeeks: 3, AnchorDate: "2021-01-04"}, now: "2021-01-01", wantStart: "2020-12-14", wantEnd: "2021-01-04"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end := ChargeCyclePeriod(test.cycle, day(test.now))
+
+			if start != day(test.wantStart) || end != day(test.wantEnd) {
+				t.Errorf("ChargeCyclePeriod() = %s to %s, want %s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"), test.wantStart, test.wantEnd)
+			}
+		})
+	}
+}
+
+func TestPreviousChargeCyclePeriod(t *testing.T) {
+	endOfMonth := models.ExpenseChargeCycle{Unit: chargeCycleDayOfMonth, DayOfMonth: 31}
+	leapDay := models.ExpenseChargeCycle{Unit: chargeCycleAnnually, AnchorDate: "2020-02-29"}
+
+	tests := []struct {
+		name       string
+		cycle      models.ExpenseChargeCycle
+		now        string
+		periodsAgo int
+		wantStart  string
+		wantEnd    string
+	}{
+		{name: "current period", cycle: endOfMonth, now: "2021-03-15", periodsAgo: 0, wantStart: "2021-02-28", wantEnd: "2021-03-31"},
+		{name: "back through February", cycle: endOfMonth, now: "2021-03-15", periodsAgo: 1, wantStart: "2021-01-31", wantEnd: "2021-02-28"},
+		{name: "back across new year", cycle: endOfMonth, now: "2021-03-15", periodsAgo: 2, wantStart: "2020-12-31", wantEnd: "2021-01-31"},
+		{name: "back to a 30 day month", cycle: endOfMonth, now: "2021-03-15", periodsAgo: 3, wantStart: "2020-11-30", wantEnd: "2020-12-31"},
+		{name: "a year of months", cycle: models.ExpenseChargeCycle{Unit: chargeCycleMonthly}, now: "2021-01-15", periodsAgo: 12, wantStart: "2020-01-01", wantEnd: "2020-02-01"},
+		{name: "weeks across new year", cycle: models.ExpenseChargeCycle{Unit: chargeCycleWeekly}, now: "2021-01-01", periodsAgo: 2, wantStart: "2020-12-14", wantEnd: "2020-12-21"},
+		{name: "year back to a leap day", cycle: leapDay, now: "2025-03-01", periodsAgo: 1, wantStart: "2024-02-29", wantEnd: "2025-02-28"},
+		{name: "years back past a leap day", cycle: leapDay, now: "2025-03-01", periodsAgo: 2, wantStart: "2023-02-28", wantEnd: "2024-02-29"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end := PreviousChargeCyclePeriod(test.cycle, day(test.now), test.periodsAgo)
+
+			if start != day(test.wantStart) || end != day(test.wantEnd) {
+				t.Errorf("PreviousChargeCyclePeriod() = %s to %s, want %s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"), test.wantStart, test.wantEnd)
+			}
+		})
+	}
+}
+
+func TestChargeCycleLimit(t *testing.T) {
+	monthly := models.ExpenseChargeCycle{Unit: chargeCycleMonthly}
+
+	tests := []struct {
+		name  string
+		start string
+		end   string
+		want  float64
+	}{
+		{name: "whole periods", start: "2021-01-01", end: "2021-03-01", want: 620},
+		{name: "half of January", start: "2021-01-17", end: "2021-02-01", want: 150},
+		{name: "across new year", start: "2020-12-17", end: "2021-01-16", want: 300},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := ChargeCycleLimit(monthly, 310, day(test.start), day(test.end))
+
+			if got < test.want-0.01 || got > test.want+0.01 {
+				t.Errorf("ChargeCycleLimit() = %.2f, want %.2f", got, test.want)
+			}
+		})
+	}
+
+	// Weeks more than 292 years from the anchor still advance
+	weekly := models.ExpenseChargeCycle{Unit: chargeCycleWeekly}
+
+	if got := ChargeCycleLimit(weekly, 70, day("2350-01-01"), day("2350-01-15")); got < 139.99 || got > 140.01 {
+		t.Errorf("ChargeCycleLimit() in 2350 = %.2f, want 140", got)
+	}
+}
@@ -0,0 +1,449 @@
+package expense
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// CategoryLookup ...
+// Gets the transaction categories of a budget, which
+// belong to the budget service rather than expenses
+type CategoryLookup func(ctx context.Context, budgetID uuid.UUID) ([]models.BudgetTransactionCategory, error)
+
+// MemoryExpenseStore ...
+// In-memory ExpenseStore for tests and local development.
+// Like the tables, expenses link categories by id and get
+// their names from the budget's categories when read
+type MemoryExpenseStore struct {
+	mutex      sync.RWMutex
+	categories CategoryLookup
+	cycles     []models.ExpenseChargeCycle
+	expenses   map[uuid.UUID]models.Expense
+	links      map[uuid.UUID][]uuid.UUID
+	snapshots  []models.ExpensePeriodSnapshot
+}
+
+// NewMemoryExpenseStore ...
+// Creates an in-memory ExpenseStore seeded with the same
+// charge cycles as the migrations, looking up the names
+// of the categories expenses track with categories
+func NewMemoryExpenseStore(categories CategoryLookup) *MemoryExpenseStore {
+	return &MemoryExpenseStore{
+		categories: categories,
+		cycles: []models.ExpenseChargeCycle{
+			{ExpenseChargeCycleID: 1, Unit: "annually", Days: 365},
+			{ExpenseChargeCycleID: 2, Unit: "semi-annually", Days: 182},
+			{ExpenseChargeCycleID: 3, Unit: "monthly", Days: 30},
+			{ExpenseChargeCycleID: 4, Unit: "semi-monthly", Days: 15},
+			{ExpenseChargeCycleID: 5, Unit: "bi-weekly", Days: 14},
+			{ExpenseChargeCycleID: 6, Unit: "weekly", Days: 7},
+			{ExpenseChargeCycleID: 7, Unit: "daily", Days: 1},
+			{ExpenseChargeCycleID: 8, Unit: "every-n-weeks", Days: 7},
+			{ExpenseChargeCycleID: 9, Unit: "day-of-month", Days: 30},
+		},
+		expenses: make(map[uuid.UUID]models.Expense),
+		links:    make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+func (s *MemoryExpenseStore) findCycle(match func(models.ExpenseChargeCycle) bool) (models.ExpenseChargeCycle, bool) {
+	for _, cycle := range s.cycles {
+		if match(cycle) {
+			return cycle, true
+		}
+	}
+
+	return models.ExpenseChargeCycle{}, false
+}
+
+// GetExpense ...
+func (s *MemoryExpenseStore) GetExpense(ctx context.Context, expenseID uuid.UUID) (*models.Expense, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	expense, ok := s.expenses[expenseID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	names, err := s.categoryNames(ctx, expense.BudgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	expense.ExpenseTransactionCategories = s.linkedNames(expenseID, names)
+
+	return &expense, nil
+}
+
+// GetChargeCycleID ...
+func (s *MemoryExpenseStore) GetChargeCycleID(ctx context.Context, unit string) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	cycle, ok := s.findCycle(func(c models.ExpenseChargeCycle) bool { return c.Unit == unit })
+
+	if !ok {
+		return -1, sql.ErrNoRows
+	}
+
+	return cycle.ExpenseChargeCycleID, nil
+}
+
+// GetChargeCycleName ...
+func (s *MemoryExpenseStore) GetChargeCycleName(ctx context.Context, expenseChargeCycleID int) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	cycle, ok := s.findCycle(func(c models.ExpenseChargeCycle) bool { return c.ExpenseChargeCycleID == expenseChargeCycleID })
+
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+
+	return cycle.Unit, nil
+}
+
+// GetChargeCycles ...
+func (s *MemoryExpenseStore) GetChargeCycles(ctx context.Context) ([]models.ExpenseChargeCycle, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return append(make([]models.ExpenseChargeCycle, 0, len(s.cycles)), s.cycles...), nil
+}
+
+// GetBudgetExpenses ...
+func (s *MemoryExpenseStore) GetBudgetExpenses(ctx context.Context, budgetID uuid.UUID) ([]models.Expense, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	names, err := s.categoryNames(ctx, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	expenses := make([]models.Expense, 0)
+
+	for _, expense := range s.expenses {
+		if expense.BudgetID == budgetID {
+			expense.ExpenseTransactionCategories = s.linkedNames(expense.ExpenseID, names)
+			expenses = append(expenses, expense)
+		}
+	}
+
+	return expenses, nil
+}
+
+// GetBudgetExpenseCategoryMappings ...
+func (s *MemoryExpenseStore) GetBudgetExpenseCategoryMappings(ctx context.Context, budgetID uuid.UUID) ([]models.ExpenseBudgetTransactionCategory, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	names, err := s.categoryNames(ctx, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := make([]models.ExpenseBudgetTransactionCategory, 0)
+
+	for _, expense := range s.expenses {
+		if expense.BudgetID != budgetID {
+			continue
+		}
+
+		for _, categoryID := range s.links[expense.ExpenseID] {
+			if name, ok := names[categoryID]; ok {
+				mappings = append(mappings, models.ExpenseBudgetTransactionCategory{
+					ExpenseID:                  expense.ExpenseID,
+					BudgeTransactionCategoryID: categoryID,
+					CategoryName:               name,
+				})
+			}
+		}
+	}
+
+	return mappings, nil
+}
+
+// CreateExpense ...
+func (s *MemoryExpenseStore) CreateExpense(ctx context.Context, expense *models.AddExpensePayload, expenseChargeCycleID int) (*models.Expense, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cycle, ok := s.findCycle(func(c models.ExpenseChargeCycle) bool { return c.ExpenseChargeCycleID == expenseChargeCycleID })
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	cycle.AnchorDate = expense.ExpenseChargeCycle.AnchorDate
+	cycle.IntervalWeeks = expense.ExpenseChargeCycle.IntervalWeeks
+	cycle.DayOfMonth = expense.ExpenseChargeCycle.DayOfMonth
+
+	categories, err := s.categories(ctx, expense.BudgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := models.Expense{
+		ExpenseID:                    uuid.New(),
+		BudgetID:                     expense.BudgetID,
+		ExpenseName:                  expense.ExpenseName,
+		ExpenseValue:                 expense.ExpenseValue,
+		ExpenseDescription:           expense.ExpenseDescription,
+		ExpenseChargeCycle:           cycle,
+		ExpenseTransactionCategories: append([]string{}, expense.ExpenseTransactionCategories...),
+		Rollover:                     expense.Rollover,
+		RolloverCap:                  expense.RolloverCap,
+		RolloverStart:                expense.RolloverStart,
+	}
+
+	stored := result
+	stored.ExpenseTransactionCategories = nil
+
+	s.expenses[result.ExpenseID] = stored
+	s.links[result.ExpenseID] = linkedCategoryIDs(expense.ExpenseTransactionCategories, categories)
+
+	return &result, nil
+}
+
+// UpdateExpense ...
+func (s *MemoryExpenseStore) UpdateExpense(ctx context.Context, expense *models.Expense) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, ok := s.expenses[expense.ExpenseID]
+
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	cycle, ok := s.findCycle(func(c models.ExpenseChargeCycle) bool { return c.Unit == expense.ExpenseChargeCycle.Unit })
+
+	if !ok {
+		return sql.ErrNoRows
+	}
+
+	cycle.AnchorDate = expense.ExpenseChargeCycle.AnchorDate
+	cycle.IntervalWeeks = expense.ExpenseChargeCycle.IntervalWeeks
+	cycle.DayOfMonth = expense.ExpenseChargeCycle.DayOfMonth
+
+	existing.BudgetID = expense.BudgetID
+	existing.ExpenseName = expense.ExpenseName
+	existing.ExpenseValue = expense.ExpenseValue
+	existing.ExpenseDescription = expense.ExpenseDescription
+	existing.ExpenseChargeCycle = cycle
+	existing.Rollover = expense.Rollover
+	existing.RolloverCap = expense.RolloverCap
+	existing.RolloverStart = expense.RolloverStart
+
+	s.expenses[expense.ExpenseID] = existing
+
+	return nil
+}
+
+// DeleteExpense ...
+func (s *MemoryExpenseStore) DeleteExpense(ctx context.Context, expenseID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.expenses, expenseID)
+	delete(s.links, expenseID)
+	s.deleteSnapshots(func(snapshot models.ExpensePeriodSnapshot) bool {
+		return snapshot.ExpenseID == expenseID
+	})
+
+	return nil
+}
+
+// DeleteBudgetExpenses ...
+func (s *MemoryExpenseStore) DeleteBudgetExpenses(ctx context.Context, budgetID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for id, expense := range s.expenses {
+		if expense.BudgetID == budgetID {
+			delete(s.expenses, id)
+			delete(s.links, id)
+		}
+	}
+
+	s.deleteSnapshots(func(snapshot models.ExpensePeriodSnapshot) bool {
+		_, ok := s.expenses[snapshot.ExpenseID]
+		return !ok
+	})
+
+	return nil
+}
+
+// ReassignTransactionCategory ...
+// Expenses already tracking both categories keep a single link
+func (s *MemoryExpenseStore) ReassignTransactionCategory(ctx context.Context, from models.BudgetTransactionCategory, to models.BudgetTransactionCategory) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.replaceCategory(from.BudgetTransactionCategoryID, func(categoryIDs []uuid.UUID) []uuid.UUID {
+		for _, categoryID := range categoryIDs {
+			if categoryID == to.BudgetTransactionCategoryID {
+				return categoryIDs
+			}
+		}
+
+		return append(categoryIDs, to.BudgetTransactionCategoryID)
+	})
+
+	return nil
+}
+
+// UnlinkTransactionCategory ...
+func (s *MemoryExpenseStore) UnlinkTransactionCategory(ctx context.Context, category models.BudgetTransactionCategory) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.replaceCategory(category.BudgetTransactionCategoryID, func(categoryIDs []uuid.UUID) []uuid.UUID {
+		return categoryIDs
+	})
+
+	return nil
+}
+
+// replaceCategory ...
+// Takes a category out of the expenses tracking it and
+// lets replace add what tracks it instead.
+// Callers hold the mutex
+func (s *MemoryExpenseStore) replaceCategory(categoryID uuid.UUID, replace func([]uuid.UUID) []uuid.UUID) {
+	for expenseID, categoryIDs := range s.links {
+		kept := make([]uuid.UUID, 0, len(categoryIDs))
+		tracked := false
+
+		for _, linked := range categoryIDs {
+			if linked == categoryID {
+				tracked = true
+				continue
+			}
+
+			kept = append(kept, linked)
+		}
+
+		if tracked {
+			s.links[expenseID] = replace(kept)
+		}
+	}
+}
+
+// categoryNames ...
+// Gets the names of a budget's categories by id
+func (s *MemoryExpenseStore) categoryNames(ctx context.Context, budgetID uuid.UUID) (map[uuid.UUID]string, error) {
+	categories, err := s.categories(ctx, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uuid.UUID]string, len(categories))
+
+	for _, category := range categories {
+		names[category.BudgetTransactionCategoryID] = category.CategoryName
+	}
+
+	return names, nil
+}
+
+// linkedNames ...
+// The names of the categories an expense tracks.
+// Callers hold the mutex
+func (s *MemoryExpenseStore) linkedNames(expenseID uuid.UUID, names map[uuid.UUID]string) []string {
+	linked := make([]string, 0, len(s.links[expenseID]))
+
+	for _, categoryID := range s.links[expenseID] {
+		if name, ok := names[categoryID]; ok {
+			linked = append(linked, name)
+		}
+	}
+
+	return linked
+}
+
+// linkedCategoryIDs ...
+// Looks up the ids of the named categories. Like the insert
+// into the tables, names of no category link nothing
+func linkedCategoryIDs(categoryNames []string, categories []models.BudgetTransactionCategory) []uuid.UUID {
+	categoryIDs := make([]uuid.UUID, 0, len(categoryNames))
+
+	for _, name := range categoryNames {
+		for _, category := range categories {
+			if category.CategoryName == name {
+				categoryIDs = append(categoryIDs, category.BudgetTransactionCategoryID)
+				break
+			}
+		}
+	}
+
+	return categoryIDs
+}
+
+// GetBudgetPeriodSnapshots ...
+func (s *MemoryExpenseStore) GetBudgetPeriodSnapshots(ctx context.Context, budgetID uuid.UUID) ([]models.ExpensePeriodSnapshot, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	snapshots := make([]models.ExpensePeriodSnapshot, 0)
+
+	for _, snapshot := range s.snapshots {
+		if s.expenses[snapshot.ExpenseID].BudgetID == budgetID {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].PeriodStart < snapshots[j].PeriodStart
+	})
+
+	return snapshots, nil
+}
+
+// CreatePeriodSnapshot ...
+func (s *MemoryExpenseStore) CreatePeriodSnapshot(ctx context.Context, snapshot models.ExpensePeriodSnapshot) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.expenses[snapshot.ExpenseID]; !ok {
+		return sql.ErrNoRows
+	}
+
+	for _, existing := range s.snapshots {
+		if existing.ExpenseID == snapshot.ExpenseID && existing.PeriodStart == snapshot.PeriodStart {
+			return nil
+		}
+	}
+
+	snapshot.SnapshotID = uuid.New()
+	s.snapshots = append(s.snapshots, snapshot)
+
+	return nil
+}
+
+// deleteSnapshots ...
+// Removes the snapshots matching remove.
+// Callers hold the mutex
+func (s *MemoryExpenseStore) deleteSnapshots(remove func(models.ExpensePeriodSnapshot) bool) {
+	kept := s.snapshots[:0]
+
+	for _, snapshot := range s.snapshots {
+		if !remove(snapshot) {
+			kept = append(kept, snapshot)
+		}
+	}
+
+	s.snapshots = kept
+}
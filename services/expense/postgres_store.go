@@ -0,0 +1,414 @@
+package expense
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/database"
+	"github.com/shopspring/decimal"
+)
+
+// PostgresExpenseStore ...
+// ExpenseStore backed by the postgres database
+type PostgresExpenseStore struct{}
+
+// NewPostgresExpenseStore ...
+// Creates a postgres backed ExpenseStore
+func NewPostgresExpenseStore() *PostgresExpenseStore {
+	return &PostgresExpenseStore{}
+}
+
+// GetExpense ...
+func (s *PostgresExpenseStore) GetExpense(ctx context.Context, expenseID uuid.UUID) (*models.Expense, error) {
+	query := `SELECT expense_id, budget_id, expense_name, expense_value, expense_description, unit, ecc.expense_charge_cycle_id,
+	ecc.days, COALESCE(to_char(ep.charge_cycle_anchor, 'YYYY-MM-DD'), ''), COALESCE(ep.charge_cycle_interval, 0),
+	COALESCE(ep.charge_cycle_day, 0), ep.rollover, COALESCE(ep.rollover_cap, 0), COALESCE(to_char(ep.rollover_start, 'YYYY-MM-DD'), '')
+	FROM expenses ep JOIN expense_charge_cycles ecc ON ecc.expense_charge_cycle_id = ep.expense_charge_cycle_id
+	WHERE ep.expense_id = $1`
+
+	var expense models.Expense
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, expenseID).Scan(
+		&expense.ExpenseID,
+		&expense.BudgetID,
+		&expense.ExpenseName,
+		&expense.ExpenseValue,
+		&expense.ExpenseDescription,
+		&expense.ExpenseChargeCycle.Unit,
+		&expense.ExpenseChargeCycle.ExpenseChargeCycleID,
+		&expense.ExpenseChargeCycle.Days,
+		&expense.ExpenseChargeCycle.AnchorDate,
+		&expense.ExpenseChargeCycle.IntervalWeeks,
+		&expense.ExpenseChargeCycle.DayOfMonth,
+		&expense.Rollover,
+		&expense.RolloverCap,
+		&expense.RolloverStart,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &expense, nil
+}
+
+// GetChargeCycleID ...
+func (s *PostgresExpenseStore) GetChargeCycleID(ctx context.Context, unit string) (int, error) {
+	query := "SELECT expense_charge_cycle_id from expense_charge_cycles WHERE unit = $1"
+
+	var expenseChargeCycleID int
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, unit).Scan(&expenseChargeCycleID)
+
+	if err != nil {
+		return -1, err
+	}
+
+	return expenseChargeCycleID, nil
+}
+
+// GetChargeCycleName ...
+func (s *PostgresExpenseStore) GetChargeCycleName(ctx context.Context, expenseChargeCycleID int) (string, error) {
+	query := "SELECT unit from expense_charge_cycles WHERE expense_charge_cycle_id = $1"
+
+	var unit string
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, expenseChargeCycleID).Scan(&unit)
+
+	if err != nil {
+		return "", err
+	}
+
+	return unit, nil
+}
+
+// GetChargeCycles ...
+func (s *PostgresExpenseStore) GetChargeCycles(ctx context.Context) ([]models.ExpenseChargeCycle, error) {
+	query := "SELECT expense_charge_cycle_id, unit, days FROM expense_charge_cycles"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	cycles := make([]models.ExpenseChargeCycle, 0)
+
+	for rows.Next() {
+		var cycle models.ExpenseChargeCycle
+
+		err = rows.Scan(&cycle.ExpenseChargeCycleID, &cycle.Unit, &cycle.Days)
+
+		if err != nil {
+			return nil, err
+		}
+
+		cycles = append(cycles, cycle)
+	}
+
+	return cycles, nil
+}
+
+// GetBudgetExpenses ...
+func (s *PostgresExpenseStore) GetBudgetExpenses(ctx context.Context, budgetID uuid.UUID) ([]models.Expense, error) {
+	query := `SELECT expense_id, budget_id, expense_name, expense_value, expense_description, unit, ecc.expense_charge_cycle_id,
+	ecc.days, COALESCE(to_char(ep.charge_cycle_anchor, 'YYYY-MM-DD'), ''), COALESCE(ep.charge_cycle_interval, 0),
+	COALESCE(ep.charge_cycle_day, 0), ep.rollover, COALESCE(ep.rollover_cap, 0), COALESCE(to_char(ep.rollover_start, 'YYYY-MM-DD'), '')
+	FROM expenses ep JOIN expense_charge_cycles ecc ON ecc.expense_charge_cycle_id = ep.expense_charge_cycle_id
+	WHERE ep.budget_id = $1`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	expenses := make([]models.Expense, 0)
+
+	for rows.Next() {
+		var expense models.Expense
+
+		err = rows.Scan(
+			&expense.ExpenseID,
+			&expense.BudgetID,
+			&expense.ExpenseName,
+			&expense.ExpenseValue,
+			&expense.ExpenseDescription,
+			&expense.ExpenseChargeCycle.Unit,
+			&expense.ExpenseChargeCycle.ExpenseChargeCycleID,
+			&expense.ExpenseChargeCycle.Days,
+			&expense.ExpenseChargeCycle.AnchorDate,
+			&expense.ExpenseChargeCycle.IntervalWeeks,
+			&expense.ExpenseChargeCycle.DayOfMonth,
+			&expense.Rollover,
+			&expense.RolloverCap,
+			&expense.RolloverStart,
+		)
+
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		expenses = append(expenses, expense)
+	}
+
+	rows.Close()
+
+	query = "SELECT category_name FROM budget_expense_transaction_categories betc join budget_transaction_categories btc ON betc.budget_transaction_category_id = btc.budget_transaction_category_id WHERE expense_id = $1"
+
+	for i := range expenses {
+		categories, catErr := database.Conn(ctx).QueryContext(ctx, query, expenses[i].ExpenseID)
+
+		if catErr != nil {
+			return nil, catErr
+		}
+
+		for categories.Next() {
+			var categoryName string
+
+			if scanErr := categories.Scan(&categoryName); scanErr != nil {
+				categories.Close()
+				return nil, scanErr
+			}
+
+			expenses[i].ExpenseTransactionCategories = append(expenses[i].ExpenseTransactionCategories, categoryName)
+		}
+
+		categories.Close()
+	}
+
+	return expenses, nil
+}
+
+// GetBudgetExpenseCategoryMappings ...
+func (s *PostgresExpenseStore) GetBudgetExpenseCategoryMappings(ctx context.Context, budgetID uuid.UUID) ([]models.ExpenseBudgetTransactionCategory, error) {
+	query := "select expense_id, betc.budget_transaction_category_id, category_name from budget_expense_transaction_categories betc join budget_transaction_categories btci on btci.budget_transaction_category_id = betc.budget_transaction_category_id WHERE btci.budget_id = $1"
+
+	res, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Close()
+
+	expenseMappings := make([]models.ExpenseBudgetTransactionCategory, 0)
+
+	for res.Next() {
+		var temp models.ExpenseBudgetTransactionCategory
+
+		if scanErr := res.Scan(&temp.ExpenseID, &temp.BudgeTransactionCategoryID, &temp.CategoryName); scanErr != nil {
+			return nil, scanErr
+		}
+
+		expenseMappings = append(expenseMappings, temp)
+	}
+
+	return expenseMappings, nil
+}
+
+// CreateExpense ...
+func (s *PostgresExpenseStore) CreateExpense(ctx context.Context, expense *models.AddExpensePayload, expenseChargeCycleID int) (*models.Expense, error) {
+	query := `INSERT INTO expenses (budget_id, expense_name, expense_value, expense_description, expense_charge_cycle_id,
+	charge_cycle_anchor, charge_cycle_interval, charge_cycle_day, rollover, rollover_cap, rollover_start
+	) VALUES ($1, $2, $3, $4, $5, NULLIF($6, '')::DATE, NULLIF($7, 0), NULLIF($8, 0), $9, NULLIF($10, 0), NULLIF($11, '')::DATE) RETURNING expense_id, (SELECT days from expense_charge_cycles where expense_charge_cycle_id = $5)`
+
+	var expenseResult = models.Expense{
+		BudgetID:           expense.BudgetID,
+		ExpenseName:        expense.ExpenseName,
+		ExpenseValue:       expense.ExpenseValue,
+		ExpenseDescription: expense.ExpenseDescription,
+		ExpenseChargeCycle: expense.ExpenseChargeCycle,
+		Rollover:           expense.Rollover,
+		RolloverCap:        expense.RolloverCap,
+		RolloverStart:      expense.RolloverStart,
+	}
+
+	expenseResult.ExpenseChargeCycle.ExpenseChargeCycleID = expenseChargeCycleID
+
+	// The expense and its category mappings are written together
+	err := database.RunInTransaction(ctx, func(ctx context.Context) error {
+		err := database.Conn(ctx).QueryRowContext(
+			ctx,
+			query,
+			expense.BudgetID,
+			expense.ExpenseName,
+			expense.ExpenseValue,
+			expense.ExpenseDescription,
+			expenseChargeCycleID,
+			expense.ExpenseChargeCycle.AnchorDate,
+			expense.ExpenseChargeCycle.IntervalWeeks,
+			expense.ExpenseChargeCycle.DayOfMonth,
+			expense.Rollover,
+			expense.RolloverCap,
+			expense.RolloverStart,
+		).Scan(&expenseResult.ExpenseID, &expenseResult.ExpenseChargeCycle.Days)
+
+		if err != nil {
+			return err
+		}
+
+		categoryQuery := "INSERT INTO budget_expense_transaction_categories (expense_id, budget_transaction_category_id) VALUES ($1, (Select budget_transaction_category_id from budget_transaction_categories WHERE category_name = $2 AND budget_id = $3))"
+
+		for _, cat := range expense.ExpenseTransactionCategories {
+			if _, err = database.Conn(ctx).ExecContext(ctx, categoryQuery, expenseResult.ExpenseID, cat, expense.BudgetID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	expenseResult.ExpenseTransactionCategories = expense.ExpenseTransactionCategories
+
+	return &expenseResult, nil
+}
+
+// UpdateExpense ...
+func (s *PostgresExpenseStore) UpdateExpense(ctx context.Context, expense *models.Expense) error {
+	query := `UPDATE expenses SET budget_id = $1, expense_name = $2, expense_value = $3, expense_description = $4, expense_charge_cycle_id = (SELECT expense_charge_cycle_id FROM expense_charge_cycles where unit = $5),
+	charge_cycle_anchor = NULLIF($7, '')::DATE, charge_cycle_interval = NULLIF($8, 0), charge_cycle_day = NULLIF($9, 0),
+	rollover = $10, rollover_cap = NULLIF($11, 0), rollover_start = NULLIF($12, '')::DATE WHERE expense_id = $6`
+
+	_, err := database.Conn(ctx).ExecContext(
+		ctx,
+		query,
+		expense.BudgetID,
+		expense.ExpenseName,
+		decimal.NewFromFloat32(expense.ExpenseValue).DivRound(decimal.NewFromInt(1), 2),
+		expense.ExpenseDescription,
+		expense.ExpenseChargeCycle.Unit,
+		expense.ExpenseID,
+		expense.ExpenseChargeCycle.AnchorDate,
+		expense.ExpenseChargeCycle.IntervalWeeks,
+		expense.ExpenseChargeCycle.DayOfMonth,
+		expense.Rollover,
+		expense.RolloverCap,
+		expense.RolloverStart,
+	)
+
+	return err
+}
+
+// DeleteExpense ...
+func (s *PostgresExpenseStore) DeleteExpense(ctx context.Context, expenseID uuid.UUID) error {
+	query := `DELETE FROM expenses WHERE expense_id = $1`
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, expenseID)
+
+	return err
+}
+
+// DeleteBudgetExpenses ...
+// The categories expenses track are unlinked first
+// since those links don't cascade
+func (s *PostgresExpenseStore) DeleteBudgetExpenses(ctx context.Context, budgetID uuid.UUID) error {
+	queries := []string{
+		`DELETE FROM budget_expense_transaction_categories betc USING expenses e
+	WHERE e.expense_id = betc.expense_id AND e.budget_id = $1`,
+		`DELETE FROM expenses WHERE budget_id = $1`,
+	}
+
+	for _, query := range queries {
+		if _, err := database.Conn(ctx).ExecContext(ctx, query, budgetID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ReassignTransactionCategory ...
+// Expenses already tracking both categories keep a single link
+func (s *PostgresExpenseStore) ReassignTransactionCategory(ctx context.Context, from models.BudgetTransactionCategory, to models.BudgetTransactionCategory) error {
+	duplicateQuery := `DELETE FROM budget_expense_transaction_categories betc WHERE betc.budget_transaction_category_id = $1
+	AND EXISTS (SELECT 1 FROM budget_expense_transaction_categories other
+	WHERE other.expense_id = betc.expense_id AND other.budget_transaction_category_id = $2)`
+
+	if _, err := database.Conn(ctx).ExecContext(ctx, duplicateQuery, from.BudgetTransactionCategoryID, to.BudgetTransactionCategoryID); err != nil {
+		return err
+	}
+
+	query := "UPDATE budget_expense_transaction_categories SET budget_transaction_category_id = $2 WHERE budget_transaction_category_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, from.BudgetTransactionCategoryID, to.BudgetTransactionCategoryID)
+
+	return err
+}
+
+// UnlinkTransactionCategory ...
+func (s *PostgresExpenseStore) UnlinkTransactionCategory(ctx context.Context, category models.BudgetTransactionCategory) error {
+	query := "DELETE FROM budget_expense_transaction_categories WHERE budget_transaction_category_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, category.BudgetTransactionCategoryID)
+
+	return err
+}
+
+// GetBudgetPeriodSnapshots ...
+func (s *PostgresExpenseStore) GetBudgetPeriodSnapshots(ctx context.Context, budgetID uuid.UUID) ([]models.ExpensePeriodSnapshot, error) {
+	query := `SELECT eps.snapshot_id, eps.expense_id, to_char(eps.period_start, 'YYYY-MM-DD'), to_char(eps.period_end, 'YYYY-MM-DD'),
+	eps.limit_amount, eps.carried_in, eps.actual, eps.carried_out FROM expense_period_snapshots eps
+	JOIN expenses ep ON ep.expense_id = eps.expense_id WHERE ep.budget_id = $1 ORDER BY eps.period_start`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	snapshots := make([]models.ExpensePeriodSnapshot, 0)
+
+	for rows.Next() {
+		var snapshot models.ExpensePeriodSnapshot
+
+		err = rows.Scan(
+			&snapshot.SnapshotID,
+			&snapshot.ExpenseID,
+			&snapshot.PeriodStart,
+			&snapshot.PeriodEnd,
+			&snapshot.Limit,
+			&snapshot.CarriedIn,
+			&snapshot.Actual,
+			&snapshot.CarriedOut,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, rows.Err()
+}
+
+// CreatePeriodSnapshot ...
+func (s *PostgresExpenseStore) CreatePeriodSnapshot(ctx context.Context, snapshot models.ExpensePeriodSnapshot) error {
+	query := `INSERT INTO expense_period_snapshots (expense_id, period_start, period_end, limit_amount, carried_in, actual, carried_out)
+	VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (expense_id, period_start) DO NOTHING`
+
+	_, err := database.Conn(ctx).ExecContext(
+		ctx,
+		query,
+		snapshot.ExpenseID,
+		snapshot.PeriodStart,
+		snapshot.PeriodEnd,
+		snapshot.Limit,
+		snapshot.CarriedIn,
+		snapshot.Actual,
+		snapshot.CarriedOut,
+	)
+
+	return err
+}
@@ -0,0 +1,93 @@
+package expense
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+)
+
+// GetBudgetPeriodSnapshots ...
+// Gets the finished periods of the expenses of a budget that roll over
+func GetBudgetPeriodSnapshots(ctx context.Context, budgetID uuid.UUID) ([]models.ExpensePeriodSnapshot, *errors.Error) {
+	snapshots, err := store.GetBudgetPeriodSnapshots(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return snapshots, nil
+}
+
+// CreatePeriodSnapshot ...
+// Records a finished period of an expense that rolls over
+func CreatePeriodSnapshot(ctx context.Context, snapshot models.ExpensePeriodSnapshot) *errors.Error {
+	if err := store.CreatePeriodSnapshot(ctx, snapshot); err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// CarryOver ...
+// What an expense that rolls over carries out of a period it
+// spent actual in, given its limit and what was carried in.
+// Negative when more was spent than there was available
+func CarryOver(expense models.Expense, carriedIn float64, limit float64, actual float64) float64 {
+	carried := carriedIn + limit - actual
+	maxCarried := float64(expense.RolloverCap)
+
+	if maxCarried > 0 && carried > maxCarried {
+		return maxCarried
+	}
+
+	if maxCarried > 0 && carried < -maxCarried {
+		return -maxCarried
+	}
+
+	return carried
+}
+
+// validateRollover ...
+// Checks the rollover cap is only set for expenses that roll over
+func validateRollover(rollover bool, rolloverCap float32) *errors.Error {
+	if rolloverCap < 0 {
+		return &errors.Error{
+			Message:    "rollover_cap can't be negative",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if !rollover && rolloverCap != 0 {
+		return &errors.Error{
+			Message:    "rollover_cap can only be set for expenses that roll over",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return nil
+}
+
+// rolloverStart ...
+// The day an expense started rolling over. Expenses that already
+// did keep their start, and ones that don't have none
+func rolloverStart(rollover bool, existing *models.Expense) string {
+	if !rollover {
+		return ""
+	}
+
+	if existing != nil && existing.Rollover && existing.RolloverStart != "" {
+		return existing.RolloverStart
+	}
+
+	return time.Now().Local().Format("2006-01-02")
+}
@@ -0,0 +1,58 @@
+package expense
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// ExpenseStore ...
+// Persistence operations needed by the expense service
+type ExpenseStore interface {
+	// GetExpense returns the expense with the given id
+	// or sql.ErrNoRows if there is none
+	GetExpense(ctx context.Context, expenseID uuid.UUID) (*models.Expense, error)
+	// GetChargeCycleID returns the id of the charge cycle with the given unit
+	GetChargeCycleID(ctx context.Context, unit string) (int, error)
+	// GetChargeCycleName returns the unit of the charge cycle with the given id
+	GetChargeCycleName(ctx context.Context, expenseChargeCycleID int) (string, error)
+	// GetChargeCycles returns every available charge cycle
+	GetChargeCycles(ctx context.Context) ([]models.ExpenseChargeCycle, error)
+	// GetBudgetExpenses returns every expense of a budget
+	// along with the names of its transaction categories
+	GetBudgetExpenses(ctx context.Context, budgetID uuid.UUID) ([]models.Expense, error)
+	// GetBudgetExpenseCategoryMappings returns the expense to
+	// transaction category mappings of a budget
+	GetBudgetExpenseCategoryMappings(ctx context.Context, budgetID uuid.UUID) ([]models.ExpenseBudgetTransactionCategory, error)
+	// CreateExpense inserts an expense and its category mappings
+	CreateExpense(ctx context.Context, expense *models.AddExpensePayload, expenseChargeCycleID int) (*models.Expense, error)
+	// UpdateExpense overwrites an existing expense
+	UpdateExpense(ctx context.Context, expense *models.Expense) error
+	// DeleteExpense deletes an expense
+	DeleteExpense(ctx context.Context, expenseID uuid.UUID) error
+	// DeleteBudgetExpenses deletes every expense of a budget
+	DeleteBudgetExpenses(ctx context.Context, budgetID uuid.UUID) error
+
+	// ReassignTransactionCategory makes the expenses tracking a
+	// transaction category track another one instead
+	ReassignTransactionCategory(ctx context.Context, from models.BudgetTransactionCategory, to models.BudgetTransactionCategory) error
+	// UnlinkTransactionCategory stops expenses from tracking a transaction category
+	UnlinkTransactionCategory(ctx context.Context, category models.BudgetTransactionCategory) error
+
+	// GetBudgetPeriodSnapshots returns the period snapshots of
+	// every expense of a budget, oldest period first
+	GetBudgetPeriodSnapshots(ctx context.Context, budgetID uuid.UUID) ([]models.ExpensePeriodSnapshot, error)
+	// CreatePeriodSnapshot records a finished period of an expense.
+	// Periods that already have a snapshot are left as they are
+	CreatePeriodSnapshot(ctx context.Context, snapshot models.ExpensePeriodSnapshot) error
+}
+
+var store ExpenseStore
+
+// SetStore ...
+// Sets the store used by the expense service.
+// Called once at startup
+func SetStore(s ExpenseStore) {
+	store = s
+}
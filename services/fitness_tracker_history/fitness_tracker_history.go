@@ -1,19 +1,18 @@
 package fitness_tracker_history
 
 import (
-	"fmt"
+	"context"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jinzhu/now"
 	"github.com/lakshay35/finlit-backend/models"
-	"github.com/lakshay35/finlit-backend/utils/database"
 )
 
 // GetUserFitnessHistory...
 // Retrieves user fitness history
-func GetUserFitnessHistory(userId uuid.UUID, pageIndex int) (*models.FitnessHistory, *models.Error) {
-	totalRecords, totalPages := TotalPagesAndRecords(userId)
+func GetUserFitnessHistory(ctx context.Context, userId uuid.UUID, pageIndex int) (*models.FitnessHistory, *models.Error) {
+	totalRecords, totalPages := TotalPagesAndRecords(ctx, userId)
 
 	if pageIndex >= totalPages || pageIndex < 0 {
 		return nil, &models.Error{
@@ -21,33 +20,13 @@ func GetUserFitnessHistory(userId uuid.UUID, pageIndex int) (*models.FitnessHist
 			Reason: "Page index is out of bounds",
 		}
 	}
-	conn := database.GetConnection()
 
-	defer database.CloseConnection(conn)
-
-	query := "Select active_today, date, note from fitness_tracker_history WHERE user_id = $1 ORDER BY date desc LIMIT 10 OFFSET $2"
-
-	stmt := database.PrepareStatement(conn, query)
-
-	rows, queryError := stmt.Query(userId, pageIndex*10)
+	result, queryError := store.GetRecords(ctx, userId, 10, pageIndex*10)
 
 	if queryError != nil {
 		panic(queryError)
 	}
 
-	result := make([]models.FitnessHistoryRecord, 0)
-
-	for rows.Next() {
-		var record models.FitnessHistoryRecord
-		scanErr := rows.Scan(&record.ActiveToday, &record.Date, &record.Note)
-
-		if scanErr != nil {
-			panic(scanErr)
-		}
-
-		result = append(result, record)
-	}
-
 	return &models.FitnessHistory{
 		TotalRecords: totalRecords,
 		Records:      result,
@@ -58,7 +37,7 @@ func GetUserFitnessHistory(userId uuid.UUID, pageIndex int) (*models.FitnessHist
 
 // GetUserCalendarFitnessHistory...
 // Retrieves user fitness history
-func GetUserCalendarFitnessHistory(userId uuid.UUID, monthIndex int) (*models.FitnessHistory, *models.Error) {
+func GetUserCalendarFitnessHistory(ctx context.Context, userId uuid.UUID, monthIndex int) (*models.FitnessHistory, *models.Error) {
 
 	if monthIndex > 12 || monthIndex < 1 {
 		return nil, &models.Error{
@@ -66,13 +45,6 @@ func GetUserCalendarFitnessHistory(userId uuid.UUID, monthIndex int) (*models.Fi
 			Reason: "Month index is out of bounds",
 		}
 	}
-	conn := database.GetConnection()
-
-	defer database.CloseConnection(conn)
-
-	query := "Select active_today, date, note from fitness_tracker_history WHERE user_id = $1 AND date >= $2 AND date <= $3 order by date"
-
-	stmt := database.PrepareStatement(conn, query)
 
 	est, estErr := time.LoadLocation("EST")
 
@@ -87,7 +59,7 @@ func GetUserCalendarFitnessHistory(userId uuid.UUID, monthIndex int) (*models.Fi
 	endDate := now.With(date).EndOfMonth()
 	today := time.Now().In(est)
 
-	rows, queryError := stmt.Query(userId, startDate.Format("01-02-2006"), endDate.Format("01-02-2006"))
+	records, queryError := store.GetRecordsBetween(ctx, userId, startDate, endDate)
 
 	if queryError != nil {
 		panic(queryError)
@@ -96,14 +68,7 @@ func GetUserCalendarFitnessHistory(userId uuid.UUID, monthIndex int) (*models.Fi
 	cache := make(map[string]models.FitnessHistoryRecord)
 
 	// Populates map with existing records
-	for rows.Next() {
-		var record models.FitnessHistoryRecord
-		scanErr := rows.Scan(&record.ActiveToday, &record.Date, &record.Note)
-
-		if scanErr != nil {
-			panic(scanErr)
-		}
-
+	for _, record := range records {
 		cache[record.Date.Format("01-02-2006")] = record
 	}
 
@@ -151,30 +116,21 @@ func GetUserCalendarFitnessHistory(userId uuid.UUID, monthIndex int) (*models.Fi
 
 // CheckIn...
 // Records user fitness checkin
-func CheckIn(userId uuid.UUID, activeToday bool, note string, date *time.Time) (*models.FitnessHistoryRecord, *models.Error) {
-	fmt.Println(date)
+func CheckIn(ctx context.Context, userId uuid.UUID, activeToday bool, note string, date *time.Time) (*models.FitnessHistoryRecord, *models.Error) {
 	if date != nil {
-		if HasUserCheckedIn(userId, date) {
+		if HasUserCheckedIn(ctx, userId, date) {
 			return nil, &models.Error{
 				Error:  true,
 				Reason: "You have already checked in for " + date.Format("01-02-2006"),
 			}
 		}
-	} else if HasUserCheckedIn(userId, nil) {
+	} else if HasUserCheckedIn(ctx, userId, nil) {
 		return nil, &models.Error{
 			Error:  true,
 			Reason: "You have already checked in for today",
 		}
 	}
 
-	conn := database.GetConnection()
-
-	defer database.CloseConnection(conn)
-
-	query := "INSERT INTO fitness_tracker_history (active_today, note, user_id, date) VALUES ($1, $2, $3, $4)"
-
-	stmt := database.PrepareStatement(conn, query)
-
 	est, estErr := time.LoadLocation("EST")
 
 	if estErr != nil {
@@ -187,36 +143,28 @@ func CheckIn(userId uuid.UUID, activeToday bool, note string, date *time.Time) (
 		selectedDate = *date
 	}
 
-	currentTime := selectedDate.Format("01-02-2006")
+	record := models.FitnessHistoryRecord{
+		ActiveToday: activeToday,
+		Date:        selectedDate,
+		Note:        note,
+	}
 
-	_, execError := stmt.Exec(activeToday, note, userId, currentTime)
+	execError := store.CreateRecord(ctx, userId, record)
 
 	if execError != nil {
 		panic(execError)
 	}
 
-	return &models.FitnessHistoryRecord{
-		ActiveToday: activeToday,
-		Date:        selectedDate,
-		Note:        note,
-	}, nil
+	return &record, nil
 }
 
 // HasUserCheckedIn...
 // Determines if user has checked in today
-func HasUserCheckedIn(userId uuid.UUID, date *time.Time) bool {
-	conn := database.GetConnection()
-
-	defer database.CloseConnection(conn)
-
-	query := "SELECT COUNT(*) as count FROM fitness_tracker_history WHERE user_id = $1 AND date = $2"
-
-	stmt := database.PrepareStatement(conn, query)
-
+func HasUserCheckedIn(ctx context.Context, userId uuid.UUID, date *time.Time) bool {
 	var count int
 
 	if date != nil {
-		_ = stmt.QueryRow(userId, date.Format("01-02-2006")).Scan(&count)
+		count, _ = store.CountRecordsOn(ctx, userId, *date)
 	} else {
 		est, estErr := time.LoadLocation("EST")
 
@@ -224,38 +172,24 @@ func HasUserCheckedIn(userId uuid.UUID, date *time.Time) bool {
 			panic(estErr)
 		}
 
-		currentTime := time.Now().In(est).Format("01-02-2006")
-		fmt.Println("checking if user has che3cked in", currentTime)
-		_ = stmt.QueryRow(userId, currentTime).Scan(&count)
+		count, _ = store.CountRecordsOn(ctx, userId, time.Now().In(est))
 	}
 
 	return count > 0
 }
 
-// TODO: Index the date field in fitness_tracker_history and define it in db.sql
-
 // TotalCheckinRecords...
 // Returns total number of check in records for a given user id
-func TotalCheckinRecords(userId uuid.UUID) int {
-	conn := database.GetConnection()
-
-	defer database.CloseConnection(conn)
-
-	query := "SELECT COUNT(*) as count FROM fitness_tracker_history WHERE user_id = $1"
-
-	stmt := database.PrepareStatement(conn, query)
-
-	var count int
-
-	_ = stmt.QueryRow(userId).Scan(&count)
+func TotalCheckinRecords(ctx context.Context, userId uuid.UUID) int {
+	count, _ := store.CountRecords(ctx, userId)
 
 	return count
 }
 
 // TotalPages...
 // Returns total number of pages a user's fitness history has
-func TotalPagesAndRecords(userId uuid.UUID) (int, int) {
-	totalRecords := TotalCheckinRecords(userId)
+func TotalPagesAndRecords(ctx context.Context, userId uuid.UUID) (int, int) {
+	totalRecords := TotalCheckinRecords(ctx, userId)
 
 	remainder := totalRecords % 10
 
@@ -266,102 +200,50 @@ func TotalPagesAndRecords(userId uuid.UUID) (int, int) {
 	return totalRecords, (totalRecords / 10)
 }
 
-func RecentCheckinHistory(userId uuid.UUID) []models.FitnessHistoryRecord {
-
-	conn := database.GetConnection()
-
-	defer database.CloseConnection(conn)
-
-	query := "Select date, active_today, note from fitness_tracker_history WHERE user_id = $1 ORDER BY date desc LIMIT 5"
-
-	stmt := database.PrepareStatement(conn, query)
-
-	rows, queryErr := stmt.Query(userId)
+func RecentCheckinHistory(ctx context.Context, userId uuid.UUID) []models.FitnessHistoryRecord {
+	recentHistory, queryErr := store.GetRecords(ctx, userId, 5, 0)
 
 	if queryErr != nil {
 		panic(queryErr)
 	}
 
-	recentHistory := make([]models.FitnessHistoryRecord, 0)
-
-	for rows.Next() {
-		var record models.FitnessHistoryRecord
-		rows.Scan(&record.Date, &record.ActiveToday, &record.Note)
-		recentHistory = append(recentHistory, record)
-	}
-
 	return recentHistory
 }
 
 // GetUserFitnessRate
-func GetUserFitnessRate(userId uuid.UUID) models.FitnessCheckinHistory {
-	conn := database.GetConnection()
-
-	defer database.CloseConnection(conn)
-
-	query := "Select active_today from fitness_tracker_history WHERE user_id = $1"
-
-	stmt := database.PrepareStatement(conn, query)
-
-	rows, queryErr := stmt.Query(userId)
+func GetUserFitnessRate(ctx context.Context, userId uuid.UUID) models.FitnessCheckinHistory {
+	records, queryErr := store.GetRecords(ctx, userId, 0, 0)
 
 	if queryErr != nil {
 		panic(queryErr)
 	}
 
-	activeCount := 0
-	inactiveCount := 0
-
-	for rows.Next() {
-		var active_today bool
-
-		rows.Scan(&active_today)
-
-		if active_today {
-			activeCount++
-		} else {
-			inactiveCount++
-		}
-
-	}
-
-	return models.FitnessCheckinHistory{
-		ActiveCount:   activeCount,
-		InactiveCount: inactiveCount,
-		TotalCheckins: activeCount + inactiveCount,
-	}
+	return fitnessRate(records)
 }
 
 // GetUserWeeklyFitnessRate
-func GetUserWeeklyFitnessRate(userId uuid.UUID) models.FitnessCheckinHistory {
-	conn := database.GetConnection()
-
-	defer database.CloseConnection(conn)
-
-	query := "Select active_today from fitness_tracker_history WHERE user_id = $1 ORDER BY date desc LIMIT 7"
-
-	stmt := database.PrepareStatement(conn, query)
-
-	rows, queryErr := stmt.Query(userId)
+func GetUserWeeklyFitnessRate(ctx context.Context, userId uuid.UUID) models.FitnessCheckinHistory {
+	records, queryErr := store.GetRecords(ctx, userId, 7, 0)
 
 	if queryErr != nil {
 		panic(queryErr)
 	}
 
+	return fitnessRate(records)
+}
+
+// fitnessRate ...
+// Tallies active and inactive check-ins
+func fitnessRate(records []models.FitnessHistoryRecord) models.FitnessCheckinHistory {
 	activeCount := 0
 	inactiveCount := 0
 
-	for rows.Next() {
-		var active_today bool
-
-		rows.Scan(&active_today)
-
-		if active_today {
+	for _, record := range records {
+		if record.ActiveToday {
 			activeCount++
 		} else {
 			inactiveCount++
 		}
-
 	}
 
 	return models.FitnessCheckinHistory{
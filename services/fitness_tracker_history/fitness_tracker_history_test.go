@@ -0,0 +1,80 @@
+package fitness_tracker_history
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jinzhu/now"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+func TestGetUserCalendarFitnessHistoryBounds(t *testing.T) {
+	SetStore(NewMemoryFitnessStore())
+
+	for _, monthIndex := range []int{-1, 0, 13} {
+		if _, err := GetUserCalendarFitnessHistory(context.Background(), uuid.New(), monthIndex); err == nil {
+			t.Errorf("month %d was accepted", monthIndex)
+		}
+	}
+}
+
+func TestGetUserCalendarFitnessHistory(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	est, err := time.LoadLocation("EST")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Now().In(est)
+	firstOfMonth := time.Date(today.Year(), today.Month(), 1, 12, 0, 0, 0, est)
+	daysInMonth := now.With(firstOfMonth).EndOfMonth().Day()
+
+	SetStore(NewMemoryFitnessStore())
+
+	if _, checkInErr := CheckIn(ctx, userID, true, "Leg day", &firstOfMonth); checkInErr != nil {
+		t.Fatal(checkInErr.Reason)
+	}
+
+	history, historyErr := GetUserCalendarFitnessHistory(ctx, userID, int(today.Month()))
+
+	if historyErr != nil {
+		t.Fatal(historyErr.Reason)
+	}
+
+	if history.Month != int(today.Month()) {
+		t.Errorf("month = %d, want %d", history.Month, today.Month())
+	}
+
+	if len(history.Records) != daysInMonth {
+		t.Fatalf("got %d records, want one for each of the %d days", len(history.Records), daysInMonth)
+	}
+
+	for i, record := range history.Records {
+		dayOfMonth := i + 1
+
+		if record.Date.Day() != dayOfMonth {
+			t.Fatalf("record %d is dated %s, want day %d", i, record.Date.Format("2006-01-02"), dayOfMonth)
+		}
+
+		var want models.FitnessHistoryRecord
+
+		switch {
+		case dayOfMonth == 1:
+			want = models.FitnessHistoryRecord{ActiveToday: true, Note: "Leg day"}
+		case dayOfMonth > today.Day():
+			want = models.FitnessHistoryRecord{Note: "Date in Future", FutureDate: true}
+		default:
+			want = models.FitnessHistoryRecord{Note: "No Check-in Recorded", NoCheckin: true}
+		}
+
+		if record.ActiveToday != want.ActiveToday || record.Note != want.Note ||
+			record.FutureDate != want.FutureDate || record.NoCheckin != want.NoCheckin {
+			t.Errorf("day %d = %+v, want %+v", dayOfMonth, record, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package fitness_tracker_history
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// MemoryFitnessStore ...
+// In-memory FitnessStore for tests and local development
+type MemoryFitnessStore struct {
+	mutex   sync.RWMutex
+	records map[uuid.UUID][]models.FitnessHistoryRecord
+}
+
+// NewMemoryFitnessStore ...
+// Creates an empty in-memory FitnessStore
+func NewMemoryFitnessStore() *MemoryFitnessStore {
+	return &MemoryFitnessStore{
+		records: make(map[uuid.UUID][]models.FitnessHistoryRecord),
+	}
+}
+
+// sameDay ...
+// Compares dates the way the DATE column does, ignoring time of day
+func sameDay(a time.Time, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// CountRecords ...
+func (s *MemoryFitnessStore) CountRecords(ctx context.Context, userID uuid.UUID) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return len(s.records[userID]), nil
+}
+
+// CountRecordsOn ...
+func (s *MemoryFitnessStore) CountRecordsOn(ctx context.Context, userID uuid.UUID, date time.Time) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+
+	for _, record := range s.records[userID] {
+		if sameDay(record.Date, date) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// GetRecords ...
+func (s *MemoryFitnessStore) GetRecords(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.FitnessHistoryRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	records := append([]models.FitnessHistoryRecord{}, s.records[userID]...)
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Date.After(records[j].Date)
+	})
+
+	if offset >= len(records) {
+		return make([]models.FitnessHistoryRecord, 0), nil
+	}
+
+	records = records[offset:]
+
+	if limit > 0 && limit < len(records) {
+		records = records[:limit]
+	}
+
+	return records, nil
+}
+
+// GetRecordsBetween ...
+func (s *MemoryFitnessStore) GetRecordsBetween(ctx context.Context, userID uuid.UUID, startDate time.Time, endDate time.Time) ([]models.FitnessHistoryRecord, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	start := startDate.Format("2006-01-02")
+	end := endDate.Format("2006-01-02")
+
+	records := make([]models.FitnessHistoryRecord, 0)
+
+	for _, record := range s.records[userID] {
+		day := record.Date.Format("2006-01-02")
+
+		if day >= start && day <= end {
+			records = append(records, record)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Date.Before(records[j].Date)
+	})
+
+	return records, nil
+}
+
+// CreateRecord ...
+func (s *MemoryFitnessStore) CreateRecord(ctx context.Context, userID uuid.UUID, record models.FitnessHistoryRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.records[userID] = append(s.records[userID], record)
+
+	return nil
+}
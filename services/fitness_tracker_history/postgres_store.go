@@ -0,0 +1,103 @@
+package fitness_tracker_history
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// PostgresFitnessStore ...
+// FitnessStore backed by the postgres database
+type PostgresFitnessStore struct{}
+
+// NewPostgresFitnessStore ...
+// Creates a postgres backed FitnessStore
+func NewPostgresFitnessStore() *PostgresFitnessStore {
+	return &PostgresFitnessStore{}
+}
+
+// CountRecords ...
+func (s *PostgresFitnessStore) CountRecords(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := "SELECT COUNT(*) as count FROM fitness_tracker_history WHERE user_id = $1"
+
+	var count int
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, userID).Scan(&count)
+
+	return count, err
+}
+
+// CountRecordsOn ...
+func (s *PostgresFitnessStore) CountRecordsOn(ctx context.Context, userID uuid.UUID, date time.Time) (int, error) {
+	query := "SELECT COUNT(*) as count FROM fitness_tracker_history WHERE user_id = $1 AND date = $2"
+
+	var count int
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, userID, date.Format("01-02-2006")).Scan(&count)
+
+	return count, err
+}
+
+// GetRecords ...
+func (s *PostgresFitnessStore) GetRecords(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.FitnessHistoryRecord, error) {
+	query := "Select active_today, date, note from fitness_tracker_history WHERE user_id = $1 ORDER BY date desc LIMIT $2 OFFSET $3"
+
+	// A NULL limit is the same as omitting LIMIT
+	var queryLimit interface{}
+
+	if limit > 0 {
+		queryLimit = limit
+	}
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, userID, queryLimit, offset)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRecords(rows)
+}
+
+// GetRecordsBetween ...
+func (s *PostgresFitnessStore) GetRecordsBetween(ctx context.Context, userID uuid.UUID, startDate time.Time, endDate time.Time) ([]models.FitnessHistoryRecord, error) {
+	query := "Select active_today, date, note from fitness_tracker_history WHERE user_id = $1 AND date >= $2 AND date <= $3 order by date"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, userID, startDate.Format("01-02-2006"), endDate.Format("01-02-2006"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanRecords(rows)
+}
+
+// CreateRecord ...
+func (s *PostgresFitnessStore) CreateRecord(ctx context.Context, userID uuid.UUID, record models.FitnessHistoryRecord) error {
+	query := "INSERT INTO fitness_tracker_history (active_today, note, user_id, date) VALUES ($1, $2, $3, $4)"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, record.ActiveToday, record.Note, userID, record.Date.Format("01-02-2006"))
+
+	return err
+}
+
+func scanRecords(rows *sql.Rows) ([]models.FitnessHistoryRecord, error) {
+	defer rows.Close()
+
+	result := make([]models.FitnessHistoryRecord, 0)
+
+	for rows.Next() {
+		var record models.FitnessHistoryRecord
+
+		if scanErr := rows.Scan(&record.ActiveToday, &record.Date, &record.Note); scanErr != nil {
+			return nil, scanErr
+		}
+
+		result = append(result, record)
+	}
+
+	return result, nil
+}
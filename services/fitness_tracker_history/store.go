@@ -0,0 +1,35 @@
+package fitness_tracker_history
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// FitnessStore ...
+// Persistence operations needed by the fitness tracker service
+type FitnessStore interface {
+	// CountRecords returns the number of check-ins of a user
+	CountRecords(ctx context.Context, userID uuid.UUID) (int, error)
+	// CountRecordsOn returns the number of check-ins of a user on date
+	CountRecordsOn(ctx context.Context, userID uuid.UUID, date time.Time) (int, error)
+	// GetRecords returns check-ins newest first. A limit
+	// of zero or less returns every remaining record
+	GetRecords(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]models.FitnessHistoryRecord, error)
+	// GetRecordsBetween returns check-ins dated within
+	// [startDate, endDate] oldest first
+	GetRecordsBetween(ctx context.Context, userID uuid.UUID, startDate time.Time, endDate time.Time) ([]models.FitnessHistoryRecord, error)
+	// CreateRecord inserts a check-in
+	CreateRecord(ctx context.Context, userID uuid.UUID, record models.FitnessHistoryRecord) error
+}
+
+var store FitnessStore
+
+// SetStore ...
+// Sets the store used by the fitness tracker service.
+// Called once at startup
+func SetStore(s FitnessStore) {
+	store = s
+}
@@ -0,0 +1,379 @@
+package goal
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	accountService "github.com/lakshay35/finlit-backend/services/account"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+)
+
+// GetBudgetGoals ...
+// Gets the savings goals of a budget with their progress.
+// Every member of the budget can see them, viewers included
+func GetBudgetGoals(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) ([]models.SavingsGoalProgress, *errors.Error) {
+	if !canViewGoals(ctx, budgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	goals, err := store.GetBudgetGoals(ctx, budgetID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	today := time.Now().Local()
+	progress := make([]models.SavingsGoalProgress, 0, len(goals))
+
+	for _, goal := range goals {
+		saved, savedErr := goalSaved(ctx, goal)
+
+		if savedErr != nil {
+			progress = append(progress, models.SavingsGoalProgress{
+				Goal:    goal,
+				Status:  models.GoalStatusUnknown,
+				Message: savedErr.Message,
+			})
+
+			continue
+		}
+
+		progress = append(progress, goalProgress(goal, saved, today))
+	}
+
+	return progress, nil
+}
+
+// CreateGoal ...
+// Creates a savings goal for a budget. Only accounts of
+// the user creating the goal can be linked to it
+func CreateGoal(ctx context.Context, goal models.SavingsGoal, userID uuid.UUID) (*models.SavingsGoal, *errors.Error) {
+	if !roleService.IsUserAdmin(ctx, goal.BudgetID, userID) && !roleService.IsUserOwner(ctx, goal.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to create goals for the given budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	goal.GoalName = strings.TrimSpace(goal.GoalName)
+
+	if goal.GoalName == "" {
+		return nil, &errors.Error{
+			Message:    "Goals need a goal_name",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if goal.TargetAmount <= 0 {
+		return nil, &errors.Error{
+			Message:    "target_amount must be greater than 0",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	targetDate, dateErr := time.Parse("2006-01-02", goal.TargetDate)
+
+	if dateErr != nil {
+		return nil, &errors.Error{
+			Message:    "target_date must be a date formatted as YYYY-MM-DD",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if !targetDate.After(startOfDay(time.Now().Local())) {
+		return nil, &errors.Error{
+			Message:    "target_date must be in the future",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if goal.ExternalAccountID != nil {
+		if _, accountErr := accountService.GetUserExternalAccount(ctx, userID, *goal.ExternalAccountID); accountErr != nil {
+			return nil, accountErr
+		}
+	}
+
+	created, err := store.CreateGoal(ctx, goal)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return created, nil
+}
+
+// DeleteGoal ...
+// Deletes a savings goal and its contributions
+func DeleteGoal(ctx context.Context, goalID uuid.UUID, userID uuid.UUID) *errors.Error {
+	goal, goalErr := getGoal(ctx, goalID)
+
+	if goalErr != nil {
+		return goalErr
+	}
+
+	if !roleService.IsUserAdmin(ctx, goal.BudgetID, userID) && !roleService.IsUserOwner(ctx, goal.BudgetID, userID) {
+		return &errors.Error{
+			Message:    "You are not authorized to change goals of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if err := store.DeleteGoal(ctx, goalID); err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// GetGoalContributions ...
+// Gets the contributions to a savings goal
+func GetGoalContributions(ctx context.Context, goalID uuid.UUID, userID uuid.UUID) ([]models.GoalContribution, *errors.Error) {
+	goal, goalErr := getGoal(ctx, goalID)
+
+	if goalErr != nil {
+		return nil, goalErr
+	}
+
+	if !canViewGoals(ctx, goal.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not entitled to this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	contributions, err := store.GetContributions(ctx, goalID)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return contributions, nil
+}
+
+// AddGoalContribution ...
+// Records a contribution to a goal that isn't tracking
+// an account's balance, dated today unless a date is given
+func AddGoalContribution(ctx context.Context, contribution models.GoalContribution, userID uuid.UUID) (*models.GoalContribution, *errors.Error) {
+	goal, goalErr := getGoal(ctx, contribution.GoalID)
+
+	if goalErr != nil {
+		return nil, goalErr
+	}
+
+	if !roleService.IsUserAdmin(ctx, goal.BudgetID, userID) && !roleService.IsUserOwner(ctx, goal.BudgetID, userID) {
+		return nil, &errors.Error{
+			Message:    "You are not authorized to contribute to goals of this budget",
+			StatusCode: http.StatusForbidden,
+		}
+	}
+
+	if goal.ExternalAccountID != nil {
+		return nil, &errors.Error{
+			Message:    "Goals tracking an account's balance don't take contributions",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if contribution.Amount == 0 {
+		return nil, &errors.Error{
+			Message:    "amount can't be 0",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	today := startOfDay(time.Now().Local())
+
+	if contribution.ContributionDate == "" {
+		contribution.ContributionDate = today.Format("2006-01-02")
+	}
+
+	date, dateErr := time.Parse("2006-01-02", contribution.ContributionDate)
+
+	if dateErr != nil {
+		return nil, &errors.Error{
+			Message:    "contribution_date must be a date formatted as YYYY-MM-DD",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	if date.After(today) {
+		return nil, &errors.Error{
+			Message:    "contribution_date can't be in the future",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	contribution.UserID = &userID
+	contribution.Note = strings.TrimSpace(contribution.Note)
+
+	created, err := store.CreateContribution(ctx, contribution)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	return created, nil
+}
+
+// canViewGoals ...
+// Whether a user has any role in a budget
+func canViewGoals(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) bool {
+	return roleService.IsUserViewer(ctx, budgetID, userID) ||
+		roleService.IsUserAdmin(ctx, budgetID, userID) ||
+		roleService.IsUserOwner(ctx, budgetID, userID)
+}
+
+// getGoal ...
+// Gets a goal, not found when there is none
+func getGoal(ctx context.Context, goalID uuid.UUID) (*models.SavingsGoal, *errors.Error) {
+	goal, err := store.GetGoal(ctx, goalID)
+
+	if err == sql.ErrNoRows {
+		return nil, &errors.Error{
+			Message:    "Goal not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return goal, nil
+}
+
+// goalSaved ...
+// What a goal has saved so far, the current balance of
+// its account or else the sum of its contributions
+func goalSaved(ctx context.Context, goal models.SavingsGoal) (float64, *errors.Error) {
+	if goal.ExternalAccountID != nil {
+		balance, balanceErr := accountService.GetAccountBalance(ctx, *goal.ExternalAccountID)
+
+		if balanceErr != nil {
+			return 0, balanceErr
+		}
+
+		return balance.Current, nil
+	}
+
+	contributions, err := store.GetContributions(ctx, goal.GoalID)
+
+	if err != nil {
+		return 0, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	saved := 0.0
+
+	for _, contribution := range contributions {
+		saved += contribution.Amount
+	}
+
+	return saved, nil
+}
+
+// goalProgress ...
+// How far along a goal that saved the given amount is today
+func goalProgress(goal models.SavingsGoal, saved float64, now time.Time) models.SavingsGoalProgress {
+	today := startOfDay(now)
+	targetDate, _ := time.Parse("2006-01-02", goal.TargetDate)
+	startDate, startErr := time.Parse("2006-01-02", goal.StartDate)
+
+	if startErr != nil {
+		startDate = today
+	}
+
+	remaining := math.Max(goal.TargetAmount-saved, 0)
+	monthsLeft := monthsUntil(today, targetDate)
+	required := remaining
+
+	if monthsLeft > 0 {
+		required = remaining / float64(monthsLeft)
+	}
+
+	progress := models.SavingsGoalProgress{
+		Goal:            goal,
+		Saved:           roundCents(saved),
+		Remaining:       roundCents(remaining),
+		PercentComplete: math.Round(math.Min(math.Max(saved/goal.TargetAmount, 0), 1)*10000) / 100,
+		MonthsLeft:      monthsLeft,
+		RequiredMonthly: roundCents(required),
+	}
+
+	switch {
+	case remaining == 0:
+		progress.Status = models.GoalStatusAchieved
+	case !today.Before(targetDate):
+		progress.Status = models.GoalStatusBehind
+	case saved >= goal.TargetAmount*elapsedShare(startDate, targetDate, today):
+		progress.Status = models.GoalStatusOnTrack
+	default:
+		progress.Status = models.GoalStatusBehind
+	}
+
+	return progress
+}
+
+// elapsedShare ...
+// The share of the time from start to end that
+// passed by today, between 0 and 1
+func elapsedShare(start time.Time, end time.Time, today time.Time) float64 {
+	total := end.Sub(start).Hours()
+
+	if total <= 0 {
+		return 1
+	}
+
+	return math.Min(math.Max(today.Sub(start).Hours()/total, 0), 1)
+}
+
+// monthsUntil ...
+// The number of months from today until a date,
+// counting a month that's only partly left as one
+func monthsUntil(today time.Time, date time.Time) int {
+	months := 0
+
+	for today.AddDate(0, months, 0).Before(date) {
+		months++
+	}
+
+	return months
+}
+
+func startOfDay(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,166 @@
+package goal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	accountService "github.com/lakshay35/finlit-backend/services/account"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	roleService "github.com/lakshay35/finlit-backend/services/role"
+)
+
+func day(date string) time.Time {
+	parsed, err := time.Parse("2006-01-02", date)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return parsed
+}
+
+func TestGoalProgress(t *testing.T) {
+	now := day("2021-06-15")
+
+	tests := []struct {
+		name          string
+		targetDate    string
+		saved         float64
+		wantStatus    string
+		wantPercent   float64
+		wantMonths    int
+		wantRequired  float64
+		wantRemaining float64
+	}{
+		{name: "ahead of schedule", targetDate: "2021-12-31", saved: 700, wantStatus: models.GoalStatusOnTrack, wantPercent: 58.33, wantMonths: 7, wantRequired: 71.43, wantRemaining: 500},
+		{name: "behind schedule", targetDate: "2021-12-31", saved: 300, wantStatus: models.GoalStatusBehind, wantPercent: 25, wantMonths: 7, wantRequired: 128.57, wantRemaining: 900},
+		{name: "completed", targetDate: "2021-12-31", saved: 1250, wantStatus: models.GoalStatusAchieved, wantPercent: 100, wantMonths: 7},
+		{name: "completed after the deadline", targetDate: "2021-03-01", saved: 1200, wantStatus: models.GoalStatusAchieved, wantPercent: 100},
+		{name: "past the deadline", targetDate: "2021-03-01", saved: 1000, wantStatus: models.GoalStatusBehind, wantPercent: 83.33, wantRequired: 200, wantRemaining: 200},
+		{name: "deadline today", targetDate: "2021-06-15", saved: 1100, wantStatus: models.GoalStatusBehind, wantPercent: 91.67, wantRequired: 100, wantRemaining: 100},
+		{name: "more taken out than put in", targetDate: "2021-12-31", saved: -50, wantStatus: models.GoalStatusBehind, wantPercent: 0, wantMonths: 7, wantRequired: 178.57, wantRemaining: 1250},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			goal := models.SavingsGoal{TargetAmount: 1200, StartDate: "2021-01-01", TargetDate: test.targetDate}
+			progress := goalProgress(goal, test.saved, now)
+
+			if progress.Status != test.wantStatus {
+				t.Errorf("status %s, want %s", progress.Status, test.wantStatus)
+			}
+
+			if progress.PercentComplete != test.wantPercent {
+				t.Errorf("percent complete %.2f, want %.2f", progress.PercentComplete, test.wantPercent)
+			}
+
+			if progress.MonthsLeft != test.wantMonths {
+				t.Errorf("months left %d, want %d", progress.MonthsLeft, test.wantMonths)
+			}
+
+			if progress.RequiredMonthly != test.wantRequired {
+				t.Errorf("required monthly %.2f, want %.2f", progress.RequiredMonthly, test.wantRequired)
+			}
+
+			if progress.Remaining != test.wantRemaining {
+				t.Errorf("remaining %.2f, want %.2f", progress.Remaining, test.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestGetBudgetGoals(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	budgetID := uuid.New()
+	roles := roleService.NewMemoryRoleStore()
+
+	SetStore(NewMemoryGoalStore())
+	roleService.SetStore(roles)
+	accountService.SetStore(accountService.NewMemoryAccountStore())
+	plaidService.SetClient(plaidService.NewSeededFakeClient())
+	roles.SetBudgetOwner(budgetID, ownerID)
+
+	if err := accountService.RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), ownerID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	accounts, _ := accountService.GetAllExternalAccounts(ctx, ownerID)
+	var checking models.Account
+
+	for _, act := range accounts {
+		if act.AccountName == "Checking" {
+			checking = act
+		}
+	}
+
+	goals := map[string]models.SavingsGoal{
+		"linked":   {GoalName: "Emergency fund", TargetAmount: 2000, ExternalAccountID: &checking.ExternalAccountID},
+		"removed":  {GoalName: "Vacation", TargetAmount: 1000},
+		"missing":  {GoalName: "Car", TargetAmount: 5000, ExternalAccountID: &uuid.UUID{}},
+		"finished": {GoalName: "Laptop", TargetAmount: 200},
+	}
+
+	goalIDs := make(map[uuid.UUID]string)
+
+	for key, goal := range goals {
+		goal.BudgetID = budgetID
+		goal.TargetDate = time.Now().AddDate(1, 0, 0).Format("2006-01-02")
+
+		created, err := store.CreateGoal(ctx, goal)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		goalIDs[created.GoalID] = key
+	}
+
+	// The vacation goal's account was disconnected, which clears
+	// its external_account_id, so it counts contributions now
+	for goalID, key := range goalIDs {
+		amount := map[string]float64{"removed": 300, "finished": 250}[key]
+
+		if amount == 0 {
+			continue
+		}
+
+		if _, err := AddGoalContribution(ctx, models.GoalContribution{GoalID: goalID, Amount: amount}, ownerID); err != nil {
+			t.Fatal(err.Message)
+		}
+	}
+
+	progress, err := GetBudgetGoals(ctx, budgetID, ownerID)
+
+	if err != nil {
+		t.Fatal(err.Message)
+	}
+
+	if len(progress) != len(goals) {
+		t.Fatalf("got %d goals, want %d", len(progress), len(goals))
+	}
+
+	for _, goalProgress := range progress {
+		switch goalIDs[goalProgress.Goal.GoalID] {
+		case "linked":
+			if goalProgress.Saved != 2500.12 || goalProgress.Status != models.GoalStatusAchieved {
+				t.Errorf("linked goal = %+v, want the checking balance and achieved", goalProgress)
+			}
+		case "removed":
+			if goalProgress.Saved != 300 || goalProgress.Status == models.GoalStatusUnknown {
+				t.Errorf("goal without an account = %+v, want its contributions", goalProgress)
+			}
+		case "missing":
+			if goalProgress.Status != models.GoalStatusUnknown || goalProgress.Message == "" {
+				t.Errorf("goal of a missing account = %+v, want unknown with a message", goalProgress)
+			}
+		case "finished":
+			if goalProgress.Status != models.GoalStatusAchieved || goalProgress.PercentComplete != 100 || goalProgress.Remaining != 0 {
+				t.Errorf("finished goal = %+v, want achieved", goalProgress)
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package goal
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// MemoryGoalStore ...
+// In-memory GoalStore for tests and local development
+type MemoryGoalStore struct {
+	mutex         sync.RWMutex
+	goals         map[uuid.UUID]models.SavingsGoal
+	contributions []models.GoalContribution
+}
+
+// NewMemoryGoalStore ...
+// Creates an empty in-memory GoalStore
+func NewMemoryGoalStore() *MemoryGoalStore {
+	return &MemoryGoalStore{
+		goals: make(map[uuid.UUID]models.SavingsGoal),
+	}
+}
+
+// GetBudgetGoals ...
+func (s *MemoryGoalStore) GetBudgetGoals(ctx context.Context, budgetID uuid.UUID) ([]models.SavingsGoal, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	goals := make([]models.SavingsGoal, 0)
+
+	for _, goal := range s.goals {
+		if goal.BudgetID == budgetID {
+			goals = append(goals, goal)
+		}
+	}
+
+	sort.Slice(goals, func(i, j int) bool {
+		return goals[i].TargetDate < goals[j].TargetDate
+	})
+
+	return goals, nil
+}
+
+// GetGoal ...
+func (s *MemoryGoalStore) GetGoal(ctx context.Context, goalID uuid.UUID) (*models.SavingsGoal, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	goal, ok := s.goals[goalID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &goal, nil
+}
+
+// CreateGoal ...
+func (s *MemoryGoalStore) CreateGoal(ctx context.Context, goal models.SavingsGoal) (*models.SavingsGoal, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	goal.GoalID = uuid.New()
+	goal.StartDate = time.Now().Format("2006-01-02")
+	s.goals[goal.GoalID] = goal
+
+	return &goal, nil
+}
+
+// DeleteGoal ...
+func (s *MemoryGoalStore) DeleteGoal(ctx context.Context, goalID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.goals, goalID)
+
+	contributions := make([]models.GoalContribution, 0, len(s.contributions))
+
+	for _, contribution := range s.contributions {
+		if contribution.GoalID != goalID {
+			contributions = append(contributions, contribution)
+		}
+	}
+
+	s.contributions = contributions
+
+	return nil
+}
+
+// GetContributions ...
+func (s *MemoryGoalStore) GetContributions(ctx context.Context, goalID uuid.UUID) ([]models.GoalContribution, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	contributions := make([]models.GoalContribution, 0)
+
+	for _, contribution := range s.contributions {
+		if contribution.GoalID == goalID {
+			contributions = append(contributions, contribution)
+		}
+	}
+
+	sort.SliceStable(contributions, func(i, j int) bool {
+		return contributions[i].ContributionDate < contributions[j].ContributionDate
+	})
+
+	return contributions, nil
+}
+
+// CreateContribution ...
+func (s *MemoryGoalStore) CreateContribution(ctx context.Context, contribution models.GoalContribution) (*models.GoalContribution, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	contribution.ContributionID = uuid.New()
+	s.contributions = append(s.contributions, contribution)
+
+	return &contribution, nil
+}
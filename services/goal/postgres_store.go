@@ -0,0 +1,169 @@
+package goal
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// PostgresGoalStore ...
+// GoalStore backed by the postgres database
+type PostgresGoalStore struct{}
+
+// NewPostgresGoalStore ...
+// Creates a postgres backed GoalStore
+func NewPostgresGoalStore() *PostgresGoalStore {
+	return &PostgresGoalStore{}
+}
+
+// GetBudgetGoals ...
+func (s *PostgresGoalStore) GetBudgetGoals(ctx context.Context, budgetID uuid.UUID) ([]models.SavingsGoal, error) {
+	query := `SELECT goal_id, budget_id, goal_name, target_amount, to_char(target_date, 'YYYY-MM-DD'), external_account_id,
+	to_char(created_at, 'YYYY-MM-DD') FROM savings_goals WHERE budget_id = $1 ORDER BY target_date, created_at`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	goals := make([]models.SavingsGoal, 0)
+
+	for rows.Next() {
+		goal, scanErr := scanGoal(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		goals = append(goals, *goal)
+	}
+
+	return goals, nil
+}
+
+// GetGoal ...
+func (s *PostgresGoalStore) GetGoal(ctx context.Context, goalID uuid.UUID) (*models.SavingsGoal, error) {
+	query := `SELECT goal_id, budget_id, goal_name, target_amount, to_char(target_date, 'YYYY-MM-DD'), external_account_id,
+	to_char(created_at, 'YYYY-MM-DD') FROM savings_goals WHERE goal_id = $1`
+
+	return scanGoal(database.Conn(ctx).QueryRowContext(ctx, query, goalID))
+}
+
+// CreateGoal ...
+func (s *PostgresGoalStore) CreateGoal(ctx context.Context, goal models.SavingsGoal) (*models.SavingsGoal, error) {
+	query := `INSERT INTO savings_goals (budget_id, goal_name, target_amount, target_date, external_account_id)
+	VALUES ($1, $2, $3, $4, $5) RETURNING goal_id, to_char(created_at, 'YYYY-MM-DD')`
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		goal.BudgetID,
+		goal.GoalName,
+		goal.TargetAmount,
+		goal.TargetDate,
+		goal.ExternalAccountID,
+	).Scan(&goal.GoalID, &goal.StartDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &goal, nil
+}
+
+// DeleteGoal ...
+func (s *PostgresGoalStore) DeleteGoal(ctx context.Context, goalID uuid.UUID) error {
+	query := "DELETE FROM savings_goals WHERE goal_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, goalID)
+
+	return err
+}
+
+// GetContributions ...
+func (s *PostgresGoalStore) GetContributions(ctx context.Context, goalID uuid.UUID) ([]models.GoalContribution, error) {
+	query := `SELECT contribution_id, goal_id, user_id, amount, to_char(contribution_date, 'YYYY-MM-DD'), COALESCE(note, '')
+	FROM savings_goal_contributions WHERE goal_id = $1 ORDER BY contribution_date, created_at`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, goalID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	contributions := make([]models.GoalContribution, 0)
+
+	for rows.Next() {
+		var contribution models.GoalContribution
+
+		scanErr := rows.Scan(
+			&contribution.ContributionID,
+			&contribution.GoalID,
+			&contribution.UserID,
+			&contribution.Amount,
+			&contribution.ContributionDate,
+			&contribution.Note,
+		)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		contributions = append(contributions, contribution)
+	}
+
+	return contributions, nil
+}
+
+// CreateContribution ...
+func (s *PostgresGoalStore) CreateContribution(ctx context.Context, contribution models.GoalContribution) (*models.GoalContribution, error) {
+	query := `INSERT INTO savings_goal_contributions (goal_id, user_id, amount, contribution_date, note)
+	VALUES ($1, $2, $3, $4, NULLIF($5, '')) RETURNING contribution_id`
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		contribution.GoalID,
+		contribution.UserID,
+		contribution.Amount,
+		contribution.ContributionDate,
+		contribution.Note,
+	).Scan(&contribution.ContributionID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &contribution, nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanGoal(row scanner) (*models.SavingsGoal, error) {
+	var goal models.SavingsGoal
+
+	err := row.Scan(
+		&goal.GoalID,
+		&goal.BudgetID,
+		&goal.GoalName,
+		&goal.TargetAmount,
+		&goal.TargetDate,
+		&goal.ExternalAccountID,
+		&goal.StartDate,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &goal, nil
+}
@@ -0,0 +1,38 @@
+package goal
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// GoalStore ...
+// Persistence operations needed by the goal service
+type GoalStore interface {
+	// GetBudgetGoals returns the savings goals of a
+	// budget, the earliest target date first
+	GetBudgetGoals(ctx context.Context, budgetID uuid.UUID) ([]models.SavingsGoal, error)
+	// GetGoal returns a savings goal
+	// or sql.ErrNoRows if there is none
+	GetGoal(ctx context.Context, goalID uuid.UUID) (*models.SavingsGoal, error)
+	// CreateGoal inserts a savings goal
+	CreateGoal(ctx context.Context, goal models.SavingsGoal) (*models.SavingsGoal, error)
+	// DeleteGoal deletes a savings goal and its contributions
+	DeleteGoal(ctx context.Context, goalID uuid.UUID) error
+
+	// GetContributions returns the contributions
+	// to a goal, the earliest first
+	GetContributions(ctx context.Context, goalID uuid.UUID) ([]models.GoalContribution, error)
+	// CreateContribution inserts a contribution to a goal
+	CreateContribution(ctx context.Context, contribution models.GoalContribution) (*models.GoalContribution, error)
+}
+
+var store GoalStore
+
+// SetStore ...
+// Sets the store used by the goal service.
+// Called once at startup
+func SetStore(s GoalStore) {
+	store = s
+}
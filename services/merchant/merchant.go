@@ -0,0 +1,133 @@
+package merchant
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// cleanupRule ...
+// Rewrites the parts of a transaction name matching pattern
+type cleanupRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// prefixRules strip what card processors and banks put in
+// front of the merchant
+var prefixRules = []cleanupRule{
+	// Card processors and payment apps prefix the merchant
+	// with their own code, e.g. "SQ *BLUE BOTTLE" or "TST* JOE'S"
+	{regexp.MustCompile(`(?i)^\s*(sq|tst|sp|pp|paypal|py|wpy|dd|ic|google|apl)\s*\*+\s*`), ""},
+	// Point of sale and card wording in front of the merchant
+	{regexp.MustCompile(`(?i)^\s*((pos|debit|credit|card|checkcard|check card|purchase|recurring|preauthorized|ach|visa|mc|withdrawal)\s+)+`), ""},
+}
+
+// idRules strip the codes and numbers after the merchant. They
+// run in order, so later rules see what earlier ones left behind
+var idRules = []cleanupRule{
+	// A one word merchant followed by its product after an
+	// asterisk, e.g. "UBER *EATS" or "LYFT *RIDE SUN 4PM",
+	// keeps the product so they aren't one merchant
+	{regexp.MustCompile(`^\s*([^\s*]+)\s*\*+\s*([A-Za-z]+)\b.*$`), "$1 $2"},
+	// Order and reference codes after an asterisk, e.g. "NETFLIX.COM*1A2B3C"
+	{regexp.MustCompile(`\*.*$`), ""},
+	// Masked card numbers, e.g. "XXXX1234" or "...1234"
+	{regexp.MustCompile(`(?i)(x{2,}|\.{2,})\d+`), " "},
+	// Store numbers, e.g. "#1234" or "NO. 12"
+	{regexp.MustCompile(`(?i)(#|\bno\.\s*)\s*\d+`), " "},
+	// Support sites after the merchant, e.g. "UBER TRIP HELP.UBER.COM"
+	{regexp.MustCompile(`(?i)\s+\S+\.(com|net|org|co|io)\b\S*`), " "},
+	// Web domains, e.g. "SPOTIFY.COM"
+	{regexp.MustCompile(`(?i)\.(com|net|org|co|io)\b`), ""},
+}
+
+// tidyRules clean up what the other rules left behind
+var tidyRules = []cleanupRule{
+	// Punctuation left dangling at either end
+	{regexp.MustCompile(`^[\s\-_*#.,:/]+|[\s\-_*#.,:/]+$`), ""},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// Normalize ...
+// Cleans a raw transaction name up into the merchant behind it,
+// so "SQ *BLUE BOTTLE #123" and "BLUE BOTTLE 4411" are both
+// "Blue Bottle". Names in all upper case are title cased unless
+// they are a short acronym. Names that are nothing but card
+// wording and numbers, like "POS 12345", keep the wording, and
+// names nothing is left of are returned trimmed
+func Normalize(name string) string {
+	merchant := clean(name, prefixRules, idRules)
+
+	if merchant == "" {
+		merchant = clean(name, idRules)
+	}
+
+	if merchant == "" {
+		return strings.TrimSpace(name)
+	}
+
+	// Short single words like "KFC" or "CVS" are acronyms
+	if strings.ToUpper(merchant) == merchant && (strings.Contains(merchant, " ") || len(merchant) > 4) {
+		merchant = titleCase(merchant)
+	}
+
+	return merchant
+}
+
+// clean ...
+// Runs the given rules over a name, drops the words that
+// are ids and tidies up what is left
+func clean(name string, ruleSets ...[]cleanupRule) string {
+	merchant := name
+
+	for _, rules := range ruleSets {
+		merchant = applyRules(merchant, rules)
+	}
+
+	return applyRules(dropIDWords(merchant), tidyRules)
+}
+
+func applyRules(s string, rules []cleanupRule) string {
+	for _, rule := range rules {
+		s = rule.pattern.ReplaceAllString(s, rule.replacement)
+	}
+
+	return s
+}
+
+// dropIDWords ...
+// Words with digits in them are store ids, dates, phone
+// numbers and card suffixes. The first word is the merchant
+// itself though when it has letters too, e.g. "7-ELEVEN"
+func dropIDWords(s string) string {
+	kept := make([]string, 0)
+
+	for _, word := range strings.Fields(s) {
+		if strings.IndexFunc(word, unicode.IsDigit) >= 0 &&
+			(len(kept) > 0 || strings.IndexFunc(word, unicode.IsLetter) < 0) {
+			continue
+		}
+
+		kept = append(kept, word)
+	}
+
+	return strings.Join(kept, " ")
+}
+
+// titleCase ...
+// Upper cases the first letter of every word and lower cases the rest
+func titleCase(s string) string {
+	runes := []rune(strings.ToLower(s))
+	wordStart := true
+
+	for i, r := range runes {
+		if wordStart && unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+		}
+
+		wordStart = unicode.IsSpace(r) || r == '-' || r == '/'
+	}
+
+	return string(runes)
+}
@@ -0,0 +1,49 @@
+package merchant
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "SQ *BLUE BOTTLE #123", want: "Blue Bottle"},
+		{name: "BLUE BOTTLE 4411", want: "Blue Bottle"},
+		{name: "TST* JOE'S PIZZA", want: "Joe's Pizza"},
+		{name: "POS DEBIT STARBUCKS 0412 SEATTLE WA", want: "Starbucks Seattle Wa"},
+		{name: "NETFLIX.COM*1A2B3C", want: "Netflix"},
+		{name: "SPOTIFY.COM", want: "Spotify"},
+		{name: "AMZN Mktp US*2K4J91", want: "AMZN Mktp US"},
+		{name: "SHELL OIL XXXX1234", want: "Shell Oil"},
+		{name: "Whole Foods Market NO. 12", want: "Whole Foods Market"},
+		{name: "KFC", want: "KFC"},
+		{name: "CVS 04211", want: "CVS"},
+
+		// Merchants with digits in their name keep them
+		{name: "7-ELEVEN 33421", want: "7-Eleven"},
+		{name: "7-ELEVEN #1102 DALLAS", want: "7-Eleven Dallas"},
+		{name: "DEBIT 0412 STARBUCKS", want: "Starbucks"},
+
+		// Rides and food delivery stay apart
+		{name: "UBER *TRIP HELP.UBER.COM", want: "Uber Trip"},
+		{name: "UBER TRIP", want: "Uber Trip"},
+		{name: "UBER *EATS", want: "Uber Eats"},
+		{name: "UBER   EATS 8005928996", want: "Uber Eats"},
+		{name: "LYFT *RIDE SUN 4PM", want: "Lyft Ride"},
+
+		// Names that are only wording and numbers keep the wording
+		{name: "POS 12345", want: "POS"},
+		{name: "CHECK #1042", want: "Check"},
+		{name: "ACH 20210405 88812", want: "ACH"},
+		{name: "  12345  ", want: "12345"},
+		{name: "", want: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Normalize(test.name); got != test.want {
+				t.Errorf("Normalize(%q) = %q, want %q", test.name, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package notifier
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+)
+
+// InAppNotifier ...
+// Keeps notifications for users to read in the app. Backed
+// by the notification store, so the memory store makes it
+// its own stand in
+type InAppNotifier struct{}
+
+// NewInAppNotifier ...
+// Creates a notifier keeping notifications in the store
+func NewInAppNotifier() *InAppNotifier {
+	return &InAppNotifier{}
+}
+
+// Notify ...
+func (n *InAppNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	_, err := store.CreateNotification(ctx, notification)
+
+	return err
+}
+
+// GetNotifications ...
+// Gets the in-app notifications of a user, newest first
+func GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool) ([]models.Notification, *errors.Error) {
+	notifications, err := store.GetUserNotifications(ctx, userID, unreadOnly)
+
+	if err != nil {
+		return nil, &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return notifications, nil
+}
+
+// MarkNotificationRead ...
+// Marks one of a user's in-app notifications as read
+func MarkNotificationRead(ctx context.Context, notificationID uuid.UUID, userID uuid.UUID) *errors.Error {
+	notification, err := store.GetNotification(ctx, notificationID)
+
+	if err == sql.ErrNoRows || (err == nil && notification.UserID != userID) {
+		return &errors.Error{
+			Message:    "Notification not found",
+			StatusCode: http.StatusNotFound,
+		}
+	}
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	if markErr := store.MarkNotificationRead(ctx, notificationID); markErr != nil {
+		return &errors.Error{
+			Message:    markErr.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
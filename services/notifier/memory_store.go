@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// MemoryNotificationStore ...
+// In-memory NotificationStore for tests and local development
+type MemoryNotificationStore struct {
+	mutex         sync.RWMutex
+	notifications []models.Notification
+}
+
+// NewMemoryNotificationStore ...
+// Creates an empty in-memory NotificationStore
+func NewMemoryNotificationStore() *MemoryNotificationStore {
+	return &MemoryNotificationStore{}
+}
+
+// CreateNotification ...
+func (s *MemoryNotificationStore) CreateNotification(ctx context.Context, notification models.Notification) (*models.Notification, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	notification.NotificationID = uuid.New()
+	notification.CreatedAt = time.Now().Format(time.RFC3339)
+	notification.ReadAt = nil
+	notification.Email = ""
+	notification.WebhookURL = ""
+
+	s.notifications = append(s.notifications, notification)
+
+	return &notification, nil
+}
+
+// GetUserNotifications ...
+func (s *MemoryNotificationStore) GetUserNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool) ([]models.Notification, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	notifications := make([]models.Notification, 0)
+
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		notification := s.notifications[i]
+
+		if notification.UserID != userID || (unreadOnly && notification.ReadAt != nil) {
+			continue
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+// GetNotification ...
+func (s *MemoryNotificationStore) GetNotification(ctx context.Context, notificationID uuid.UUID) (*models.Notification, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, notification := range s.notifications {
+		if notification.NotificationID == notificationID {
+			return &notification, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// MarkNotificationRead ...
+func (s *MemoryNotificationStore) MarkNotificationRead(ctx context.Context, notificationID uuid.UUID) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.notifications {
+		if s.notifications[i].NotificationID == notificationID && s.notifications[i].ReadAt == nil {
+			readAt := time.Now().Format(time.RFC3339)
+			s.notifications[i].ReadAt = &readAt
+		}
+	}
+
+	return nil
+}
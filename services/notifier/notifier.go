@@ -0,0 +1,112 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lakshay35/finlit-backend/models"
+	environment "github.com/lakshay35/finlit-backend/services/environment"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+)
+
+// NotifierEnv set to "local" delivers every channel
+// through RecordingNotifiers instead of for real
+var NotifierEnv = ""
+
+func init() {
+	NotifierEnv = environment.GetEnvVariable("NOTIFIER_ENV")
+}
+
+// Notifier ...
+// Delivers notifications through one channel
+type Notifier interface {
+	Notify(ctx context.Context, notification models.Notification) error
+}
+
+var (
+	_ Notifier = (*SMTPNotifier)(nil)
+	_ Notifier = (*WebhookNotifier)(nil)
+	_ Notifier = (*InAppNotifier)(nil)
+	_ Notifier = (*RecordingNotifier)(nil)
+)
+
+var (
+	notifiersMutex sync.Mutex
+	notifiers      = make(map[string]Notifier)
+)
+
+// Send ...
+// Delivers a notification through a channel
+func Send(ctx context.Context, channel string, notification models.Notification) error {
+	n, err := channelNotifier(channel)
+
+	if err != nil {
+		return err
+	}
+
+	return n.Notify(ctx, notification)
+}
+
+// SetNotifier ...
+// Replaces the notifier of a channel
+func SetNotifier(channel string, n Notifier) {
+	notifiersMutex.Lock()
+	defer notifiersMutex.Unlock()
+
+	notifiers[channel] = n
+}
+
+// IsChannel ...
+// Whether notifications can be sent through a channel
+func IsChannel(channel string) bool {
+	switch channel {
+	case models.NotificationChannelEmail, models.NotificationChannelWebhook, models.NotificationChannelInApp:
+		return true
+	}
+
+	return false
+}
+
+// channelNotifier ...
+// The notifier of a channel. Built once from the environment
+func channelNotifier(channel string) (Notifier, error) {
+	notifiersMutex.Lock()
+	defer notifiersMutex.Unlock()
+
+	if n, ok := notifiers[channel]; ok {
+		return n, nil
+	}
+
+	if !IsChannel(channel) {
+		return nil, fmt.Errorf("no notifier for channel %q", channel)
+	}
+
+	n := newNotifierFromEnvironment(channel)
+	notifiers[channel] = n
+
+	return n, nil
+}
+
+func newNotifierFromEnvironment(channel string) Notifier {
+	if NotifierEnv == "local" {
+		return NewRecordingNotifier()
+	}
+
+	switch channel {
+	case models.NotificationChannelEmail:
+		config := smtpConfigFromEnvironment()
+
+		if config.Host == "" {
+			logging.WarningLogger.Println("SMTP_HOST is not set, emails are only recorded")
+
+			return NewRecordingNotifier()
+		}
+
+		return NewSMTPNotifier(config)
+	case models.NotificationChannelWebhook:
+		return NewWebhookNotifier(nil)
+	}
+
+	return NewInAppNotifier()
+}
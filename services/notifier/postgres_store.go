@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// PostgresNotificationStore ...
+// NotificationStore backed by the postgres database
+type PostgresNotificationStore struct{}
+
+// NewPostgresNotificationStore ...
+// Creates a postgres backed NotificationStore
+func NewPostgresNotificationStore() *PostgresNotificationStore {
+	return &PostgresNotificationStore{}
+}
+
+// CreateNotification ...
+func (s *PostgresNotificationStore) CreateNotification(ctx context.Context, notification models.Notification) (*models.Notification, error) {
+	query := "INSERT INTO notifications (user_id, budget_id, title, message) VALUES ($1, $2, $3, $4) RETURNING notification_id, created_at"
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		notification.UserID,
+		notification.BudgetID,
+		notification.Title,
+		notification.Message,
+	).Scan(&notification.NotificationID, &notification.CreatedAt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// GetUserNotifications ...
+func (s *PostgresNotificationStore) GetUserNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool) ([]models.Notification, error) {
+	query := "SELECT notification_id, user_id, budget_id, title, message, created_at, read_at FROM notifications WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL) ORDER BY created_at DESC"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, userID, unreadOnly)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	notifications := make([]models.Notification, 0)
+
+	for rows.Next() {
+		notification, scanErr := scanNotification(rows)
+
+		if scanErr != nil {
+			return nil, scanErr
+		}
+
+		notifications = append(notifications, *notification)
+	}
+
+	return notifications, nil
+}
+
+// GetNotification ...
+func (s *PostgresNotificationStore) GetNotification(ctx context.Context, notificationID uuid.UUID) (*models.Notification, error) {
+	query := "SELECT notification_id, user_id, budget_id, title, message, created_at, read_at FROM notifications WHERE notification_id = $1"
+
+	return scanNotification(database.Conn(ctx).QueryRowContext(ctx, query, notificationID))
+}
+
+// MarkNotificationRead ...
+func (s *PostgresNotificationStore) MarkNotificationRead(ctx context.Context, notificationID uuid.UUID) error {
+	query := "UPDATE notifications SET read_at = current_timestamp WHERE notification_id = $1 AND read_at IS NULL"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, notificationID)
+
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNotification(row scanner) (*models.Notification, error) {
+	var notification models.Notification
+
+	err := row.Scan(
+		&notification.NotificationID,
+		&notification.UserID,
+		&notification.BudgetID,
+		&notification.Title,
+		&notification.Message,
+		&notification.CreatedAt,
+		&notification.ReadAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
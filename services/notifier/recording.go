@@ -0,0 +1,47 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+)
+
+// RecordingNotifier ...
+// Stand in for any channel that keeps and logs what
+// it is asked to deliver instead of delivering it
+type RecordingNotifier struct {
+	mutex         sync.Mutex
+	notifications []models.Notification
+}
+
+// NewRecordingNotifier ...
+// Creates a RecordingNotifier that has recorded nothing
+func NewRecordingNotifier() *RecordingNotifier {
+	return &RecordingNotifier{}
+}
+
+// Notify ...
+func (n *RecordingNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	logging.InfoLogger.Printf("notification for user %s: %s", notification.UserID, notification.Title)
+
+	n.notifications = append(n.notifications, notification)
+
+	return nil
+}
+
+// Notifications ...
+// Every notification recorded so far, oldest first
+func (n *RecordingNotifier) Notifications() []models.Notification {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	notifications := make([]models.Notification, len(n.notifications))
+	copy(notifications, n.notifications)
+
+	return notifications
+}
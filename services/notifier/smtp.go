@@ -0,0 +1,93 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"github.com/lakshay35/finlit-backend/models"
+	environment "github.com/lakshay35/finlit-backend/services/environment"
+)
+
+// SMTPConfig ...
+// Where and as whom emails are sent
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPNotifier ...
+// Emails notifications to the address they carry
+type SMTPNotifier struct {
+	config SMTPConfig
+}
+
+// NewSMTPNotifier ...
+// Creates a notifier sending through an SMTP server
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	if config.Port == "" {
+		config.Port = "587"
+	}
+
+	return &SMTPNotifier{config: config}
+}
+
+// Notify ...
+func (n *SMTPNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	if notification.Email == "" {
+		return fmt.Errorf("notification for user %s has no email address", notification.UserID)
+	}
+
+	var auth smtp.Auth
+
+	if n.config.Username != "" {
+		auth = smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+	}
+
+	return smtp.SendMail(
+		net.JoinHostPort(n.config.Host, n.config.Port),
+		auth,
+		n.config.From,
+		[]string{notification.Email},
+		emailMessage(n.config.From, notification),
+	)
+}
+
+// emailMessage ...
+// A plain text email of a notification
+func emailMessage(from string, notification models.Notification) []byte {
+	headers := []string{
+		"From: " + from,
+		"To: " + notification.Email,
+		"Subject: " + headerText(notification.Title),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+	}
+
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + notification.Message + "\r\n")
+}
+
+// headerText ...
+// Makes text safe for a header. Line breaks would start headers
+// of their own, and anything outside ASCII is Q-encoded
+func headerText(text string) string {
+	text = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(text)
+
+	return mime.QEncoding.Encode("utf-8", text)
+}
+
+func smtpConfigFromEnvironment() SMTPConfig {
+	return SMTPConfig{
+		Host:     environment.GetEnvVariable("SMTP_HOST"),
+		Port:     environment.GetEnvVariable("SMTP_PORT"),
+		Username: environment.GetEnvVariable("SMTP_USERNAME"),
+		Password: environment.GetEnvVariable("SMTP_PASSWORD"),
+		From:     environment.GetEnvVariable("SMTP_FROM"),
+	}
+}
@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+func TestEmailMessageKeepsTitleInSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		subject string
+	}{
+		{name: "plain", title: "Groceries reached 80% of its limit", subject: "Subject: Groceries reached 80% of its limit"},
+		{name: "line feed", title: "Rent\nBcc: victim@example.com", subject: "Subject: Rent Bcc: victim@example.com"},
+		{name: "carriage return", title: "Rent\rBcc: victim@example.com", subject: "Subject: Rent Bcc: victim@example.com"},
+		{name: "crlf", title: "Rent\r\nBcc: victim@example.com", subject: "Subject: Rent Bcc: victim@example.com"},
+		{name: "non ascii", title: "Café reached 100%", subject: "Subject: =?utf-8?q?Caf=C3=A9_reached_100%?="},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			message := string(emailMessage("alerts@finlit.test", models.Notification{
+				UserID:  uuid.New(),
+				Email:   "user@example.com",
+				Title:   test.title,
+				Message: "body",
+			}))
+
+			headers := strings.Split(message[:strings.Index(message, "\r\n\r\n")], "\r\n")
+
+			if len(headers) != 5 {
+				t.Fatalf("got %d header lines, want 5: %q", len(headers), headers)
+			}
+
+			if headers[2] != test.subject {
+				t.Errorf("subject header = %q, want %q", headers[2], test.subject)
+			}
+
+			for _, header := range headers {
+				if strings.ContainsAny(header, "\r\n") {
+					t.Errorf("header %q has a line break", header)
+				}
+			}
+		})
+	}
+}
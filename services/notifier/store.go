@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// NotificationStore ...
+// Persistence operations needed by in-app notifications
+type NotificationStore interface {
+	// CreateNotification stores a notification for its user
+	CreateNotification(ctx context.Context, notification models.Notification) (*models.Notification, error)
+	// GetUserNotifications returns the notifications of a
+	// user, newest first, only unread ones if unreadOnly
+	GetUserNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool) ([]models.Notification, error)
+	// GetNotification returns a notification
+	// or sql.ErrNoRows if there is none
+	GetNotification(ctx context.Context, notificationID uuid.UUID) (*models.Notification, error)
+	// MarkNotificationRead records that a notification was read
+	MarkNotificationRead(ctx context.Context, notificationID uuid.UUID) error
+}
+
+var store NotificationStore
+
+// SetStore ...
+// Sets the store used by in-app notifications.
+// Called once at startup
+func SetStore(s NotificationStore) {
+	store = s
+}
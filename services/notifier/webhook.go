@@ -0,0 +1,182 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// webhookTimeout bounds how long a webhook receiver can take
+const webhookTimeout = 10 * time.Second
+
+// privateNetworks are the address ranges webhooks may not reach:
+// this host, private and shared networks, link-local addresses
+// such as cloud metadata services, and multicast
+var privateNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+)
+
+// WebhookNotifier ...
+// Posts notifications as JSON to the URL they carry
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier ...
+// Creates a notifier posting with client, or with a client
+// that times out, doesn't follow redirects and refuses to
+// connect to anything but public addresses when it is nil
+func NewWebhookNotifier(client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = publicHTTPClient()
+	}
+
+	return &WebhookNotifier{client: client}
+}
+
+// ValidateWebhookURL ...
+// Checks a webhook url is https and that its host only
+// resolves to public addresses. Addresses are checked
+// again when connecting, since DNS can change in between
+func ValidateWebhookURL(rawURL string) error {
+	webhookURL, err := url.Parse(rawURL)
+
+	if err != nil || webhookURL.Scheme != "https" || webhookURL.Hostname() == "" {
+		return fmt.Errorf("webhook url must be an https url")
+	}
+
+	ips, err := net.LookupIP(webhookURL.Hostname())
+
+	if err != nil {
+		return fmt.Errorf("webhook host %s can't be resolved", webhookURL.Hostname())
+	}
+
+	for _, ip := range ips {
+		if !isPublicIP(ip) {
+			return fmt.Errorf("webhook host %s resolves to non-public address %s", webhookURL.Hostname(), ip)
+		}
+	}
+
+	return nil
+}
+
+// Notify ...
+func (n *WebhookNotifier) Notify(ctx context.Context, notification models.Notification) error {
+	if notification.WebhookURL == "" {
+		return fmt.Errorf("notification for user %s has no webhook url", notification.UserID)
+	}
+
+	body, err := json.Marshal(notification)
+
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, notification.WebhookURL, bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := n.client.Do(request.WithContext(ctx))
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with %s", notification.WebhookURL, response.Status)
+	}
+
+	return nil
+}
+
+// publicHTTPClient ...
+// A client whose connections are checked after DNS resolution,
+// so a host that starts resolving to an internal address once
+// its url was validated still can't be reached. Redirects are
+// returned as they are instead of followed
+func publicHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookTimeout,
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("webhook connection to non-public address %s refused", host)
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookTimeout,
+		},
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublicIP ...
+// Whether an address is outside every private network
+func isPublicIP(ip net.IP) bool {
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+
+		if err != nil {
+			panic(err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks
+}
@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "public https", url: "https://93.184.216.34/hooks/alerts"},
+		{name: "http", url: "http://93.184.216.34/hooks/alerts", wantErr: true},
+		{name: "no host", url: "https:///hooks/alerts", wantErr: true},
+		{name: "loopback", url: "https://127.0.0.1:8080/hooks", wantErr: true},
+		{name: "localhost", url: "https://localhost/hooks", wantErr: true},
+		{name: "private", url: "https://10.1.2.3/hooks", wantErr: true},
+		{name: "metadata service", url: "https://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "ipv6 loopback", url: "https://[::1]/hooks", wantErr: true},
+		{name: "ipv4 mapped loopback", url: "https://[::ffff:127.0.0.1]/hooks", wantErr: true},
+		{name: "unique local ipv6", url: "https://[fd00::1]/hooks", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateWebhookURL(test.url)
+
+			if (err != nil) != test.wantErr {
+				t.Errorf("ValidateWebhookURL(%q) = %v, want error %v", test.url, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"93.184.216.34":    true,
+		"172.15.255.255":   true,
+		"100.128.0.1":      true,
+		"::ffff:8.8.8.8":   true,
+		"2606:4700::1111":  true,
+		"10.0.0.1":         false,
+		"172.31.255.255":   false,
+		"192.168.1.1":      false,
+		"100.64.0.1":       false,
+		"169.254.169.254":  false,
+		"127.0.0.1":        false,
+		"0.0.0.0":          false,
+		"224.0.0.251":      false,
+		"::ffff:127.0.0.1": false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd12:3456::1":     false,
+	} {
+		if got := isPublicIP(net.ParseIP(ip)); got != want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestWebhookNotifierPostsJSON(t *testing.T) {
+	var received models.Notification
+	var contentType string
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+
+	defer server.Close()
+
+	// The test server's client trusts its certificate but,
+	// unlike the public client, connects to loopback
+	notifier := NewWebhookNotifier(server.Client())
+	notification := models.Notification{
+		UserID:     uuid.New(),
+		Title:      "Rent reached 100% of its limit",
+		WebhookURL: server.URL + "/hooks/alerts",
+	}
+
+	if err := notifier.Notify(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+
+	if contentType != "application/json" || received.UserID != notification.UserID || received.Title != notification.Title {
+		t.Errorf("received %+v as %s, want the notification as JSON", received, contentType)
+	}
+}
+
+func TestWebhookNotifierReportsFailedDeliveries(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.Client())
+
+	err := notifier.Notify(context.Background(), models.Notification{
+		UserID:     uuid.New(),
+		Title:      "Rent reached 100% of its limit",
+		WebhookURL: server.URL,
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Notify() = %v, want the 503 reported", err)
+	}
+
+	if err := notifier.Notify(context.Background(), models.Notification{UserID: uuid.New()}); err == nil {
+		t.Error("Notify() without a webhook url succeeded")
+	}
+}
+
+func TestWebhookNotifierRefusesPrivateTLSAddresses(t *testing.T) {
+	called := false
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	defer server.Close()
+
+	// Trusting the server's certificate leaves the address
+	// check as the only thing refusing the connection
+	client := publicHTTPClient()
+	client.Transport.(*http.Transport).TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	err := NewWebhookNotifier(client).Notify(context.Background(), models.Notification{
+		UserID:     uuid.New(),
+		Title:      "Rent reached 100% of its limit",
+		WebhookURL: server.URL,
+	})
+
+	if err == nil || called || !strings.Contains(err.Error(), "non-public address") {
+		t.Fatalf("Notify() = %v, receiver called %v, want the connection refused", err, called)
+	}
+}
+
+func TestWebhookNotifierRefusesPrivateAddresses(t *testing.T) {
+	called := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	defer server.Close()
+
+	err := NewWebhookNotifier(nil).Notify(context.Background(), models.Notification{
+		UserID:     uuid.New(),
+		Title:      "Rent reached 100% of its limit",
+		WebhookURL: server.URL,
+	})
+
+	if err == nil || called {
+		t.Fatalf("Notify() = %v, receiver called %v, want the connection refused", err, called)
+	}
+}
+
+func TestWebhookNotifierDoesNotFollowRedirects(t *testing.T) {
+	redirected := false
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+
+	defer target.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+
+	defer server.Close()
+
+	// The test servers are on loopback, so only the redirect
+	// policy of the public client is used here
+	client := server.Client()
+	client.CheckRedirect = publicHTTPClient().CheckRedirect
+
+	err := NewWebhookNotifier(client).Notify(context.Background(), models.Notification{
+		UserID:     uuid.New(),
+		Title:      "Rent reached 100% of its limit",
+		WebhookURL: server.URL,
+	})
+
+	if err == nil || redirected {
+		t.Fatalf("Notify() = %v, redirect followed %v, want the redirect reported", err, redirected)
+	}
+}
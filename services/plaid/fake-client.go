@@ -0,0 +1,559 @@
+package plaid
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// FakePublicTokenPrefix ...
+// Public tokens the fake exchanges are this prefix followed
+// by an institution id, e.g. public-fake-ins_fake_bank
+const FakePublicTokenPrefix = "public-fake-"
+
+// FakeInstitution ...
+// An institution known to the FakeClient along with the
+// accounts and transactions every item linked to it gets
+type FakeInstitution struct {
+	InstitutionID string
+	Name          string
+	Accounts      []plaid.Account
+	Transactions  []plaid.Transaction
+}
+
+// fakeChange ...
+// An entry in an institution's change log, which
+// SyncTransactions cursors are positions in
+type fakeChange struct {
+	transaction plaid.Transaction
+	removed     bool
+}
+
+type fakeItem struct {
+	itemID        string
+	institutionID string
+	webhook       string
+}
+
+// FakeClient ...
+// Deterministic in-memory stand in for Plaid. Seed it with
+// institutions, then exchange FakePublicToken(institutionID)
+// to link an item. Ids are derived from a counter so the
+// same sequence of calls always yields the same data
+type FakeClient struct {
+	mutex        sync.Mutex
+	institutions map[string]FakeInstitution
+	changes      map[string][]fakeChange
+	items        map[string]fakeItem
+	removed      map[string]bool
+	itemErrors   map[string]plaid.Error
+	sequence     int
+	webhook      string
+}
+
+// NewFakeClient ...
+// Creates a FakeClient with no institutions
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		institutions: make(map[string]FakeInstitution),
+		changes:      make(map[string][]fakeChange),
+		items:        make(map[string]fakeItem),
+		removed:      make(map[string]bool),
+		itemErrors:   make(map[string]plaid.Error),
+	}
+}
+
+// NewSeededFakeClient ...
+// Creates a FakeClient with a checking and credit card
+// account at ins_fake_bank and a month of transactions
+// ending today
+func NewSeededFakeClient() *FakeClient {
+	fake := NewFakeClient()
+
+	today := time.Now().Local()
+	day := func(daysAgo int) string {
+		return today.AddDate(0, 0, -daysAgo).Format("2006-01-02")
+	}
+
+	fake.AddInstitution(FakeInstitution{
+		InstitutionID: "ins_fake_bank",
+		Name:          "Fake Bank",
+		Accounts: []plaid.Account{
+			{
+				AccountID:    "checking",
+				Mask:         "0000",
+				Name:         "Checking",
+				OfficialName: "Fake Bank Everyday Checking",
+				Type:         "depository",
+				Subtype:      "checking",
+				Balances:     plaid.AccountBalances{Available: 2450.12, Current: 2500.12, ISOCurrencyCode: "USD"},
+			},
+			{
+				AccountID:    "credit",
+				Mask:         "1111",
+				Name:         "Credit Card",
+				OfficialName: "Fake Bank Rewards Card",
+				Type:         "credit",
+				Subtype:      "credit card",
+				Balances:     plaid.AccountBalances{Current: 410.55, Limit: 5000, ISOCurrencyCode: "USD"},
+			},
+		},
+		Transactions: []plaid.Transaction{
+			{ID: "paycheck-1", AccountID: "checking", Name: "ACME Payroll", Amount: -2100, Date: day(28), Category: []string{"Transfer", "Payroll"}, CategoryID: "21009000"},
+			{ID: "rent", AccountID: "checking", Name: "Maple Apartments", Amount: 1450, Date: day(27), Category: []string{"Payment", "Rent"}, CategoryID: "16002000"},
+			{ID: "groceries-1", AccountID: "credit", Name: "Green Grocer", Amount: 84.23, Date: day(21), Category: []string{"Shops", "Supermarkets and Groceries"}, CategoryID: "19047000"},
+			{ID: "coffee-1", AccountID: "credit", Name: "Corner Coffee", Amount: 4.75, Date: day(18), Category: []string{"Food and Drink", "Restaurants", "Coffee Shop"}, CategoryID: "13005043"},
+			{ID: "streaming", AccountID: "credit", Name: "StreamFlix", Amount: 15.99, Date: day(15), Category: []string{"Service", "Subscription"}, CategoryID: "18061000"},
+			{ID: "paycheck-2", AccountID: "checking", Name: "ACME Payroll", Amount: -2100, Date: day(14), Category: []string{"Transfer", "Payroll"}, CategoryID: "21009000"},
+			{ID: "fuel", AccountID: "credit", Name: "Quick Fuel", Amount: 42.10, Date: day(10), Category: []string{"Travel", "Gas Stations"}, CategoryID: "22009000"},
+			{ID: "groceries-2", AccountID: "credit", Name: "Green Grocer", Amount: 96.40, Date: day(7), Category: []string{"Shops", "Supermarkets and Groceries"}, CategoryID: "19047000"},
+			{ID: "coffee-2", AccountID: "credit", Name: "Corner Coffee", Amount: 5.25, Date: day(3), Category: []string{"Food and Drink", "Restaurants", "Coffee Shop"}, CategoryID: "13005043"},
+			{ID: "card-payment", AccountID: "checking", Name: "Fake Bank Card Payment", Amount: 300, Date: day(2), Category: []string{"Payment", "Credit Card"}, CategoryID: "16001000"},
+			{ID: "groceries-3", AccountID: "credit", Name: "Green Grocer", Amount: 23.80, Date: day(0), Category: []string{"Shops", "Supermarkets and Groceries"}, CategoryID: "19047000", Pending: true},
+		},
+	})
+
+	return fake
+}
+
+// FakePublicToken ...
+// Returns the public token that links an item
+// at institutionID when exchanged
+func FakePublicToken(institutionID string) string {
+	return FakePublicTokenPrefix + institutionID
+}
+
+// AddInstitution ...
+// Adds or replaces an institution. Items already linked
+// to it see the new accounts and transactions
+func (f *FakeClient) AddInstitution(institution FakeInstitution) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.institutions[institution.InstitutionID] = institution
+	f.changes[institution.InstitutionID] = nil
+
+	for _, tx := range institution.Transactions {
+		f.changes[institution.InstitutionID] = append(f.changes[institution.InstitutionID], fakeChange{transaction: tx})
+	}
+}
+
+// AddTransactions ...
+// Appends transaction fixtures to an institution
+func (f *FakeClient) AddTransactions(institutionID string, transactions ...plaid.Transaction) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	institution := f.institutions[institutionID]
+	institution.Transactions = append(institution.Transactions, transactions...)
+	f.institutions[institutionID] = institution
+
+	for _, tx := range transactions {
+		f.changes[institutionID] = append(f.changes[institutionID], fakeChange{transaction: tx})
+	}
+}
+
+// ModifyTransaction ...
+// Replaces the transaction fixture with the same id, as
+// when a pending transaction posts with a new amount
+func (f *FakeClient) ModifyTransaction(institutionID string, transaction plaid.Transaction) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	institution := f.institutions[institutionID]
+
+	for i, tx := range institution.Transactions {
+		if tx.ID == transaction.ID {
+			institution.Transactions[i] = transaction
+		}
+	}
+
+	f.institutions[institutionID] = institution
+	f.changes[institutionID] = append(f.changes[institutionID], fakeChange{transaction: transaction})
+}
+
+// RemoveTransaction ...
+// Removes a transaction fixture, as when the
+// institution drops a pending transaction
+func (f *FakeClient) RemoveTransaction(institutionID string, transactionID string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	institution := f.institutions[institutionID]
+	kept := make([]plaid.Transaction, 0, len(institution.Transactions))
+
+	for _, tx := range institution.Transactions {
+		if tx.ID != transactionID {
+			kept = append(kept, tx)
+		}
+	}
+
+	institution.Transactions = kept
+	f.institutions[institutionID] = institution
+	f.changes[institutionID] = append(f.changes[institutionID], fakeChange{
+		transaction: plaid.Transaction{ID: transactionID},
+		removed:     true,
+	})
+}
+
+// SetItemError ...
+// Makes every call for an item fail with the given Plaid
+// error code, as when its login expires. An empty code
+// clears the error, as when the user logs in again
+func (f *FakeClient) SetItemError(itemID string, errorCode string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if errorCode == "" {
+		delete(f.itemErrors, itemID)
+		return
+	}
+
+	f.itemErrors[itemID] = fakeError("ITEM_ERROR", errorCode, "the fake item is in error state "+errorCode)
+}
+
+// CreateLinkToken ...
+func (f *FakeClient) CreateLinkToken(configs plaid.LinkTokenConfigs) (plaid.CreateLinkTokenResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.sequence++
+	f.webhook = configs.Webhook
+
+	return plaid.CreateLinkTokenResponse{
+		LinkToken:  fmt.Sprintf("link-fake-%d", f.sequence),
+		Expiration: time.Now().Add(4 * time.Hour),
+	}, nil
+}
+
+// ExchangePublicToken ...
+func (f *FakeClient) ExchangePublicToken(publicToken string) (plaid.ExchangePublicTokenResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	institutionID := strings.TrimPrefix(publicToken, FakePublicTokenPrefix)
+
+	if _, ok := f.institutions[institutionID]; !ok || institutionID == publicToken {
+		return plaid.ExchangePublicTokenResponse{}, fakeError("INVALID_INPUT", "INVALID_PUBLIC_TOKEN", "provided public token is in an invalid format")
+	}
+
+	f.sequence++
+	itemID := fmt.Sprintf("item-fake-%d", f.sequence)
+	accessToken := fmt.Sprintf("access-fake-%d", f.sequence)
+
+	f.items[accessToken] = fakeItem{
+		itemID:        itemID,
+		institutionID: institutionID,
+		webhook:       f.webhook,
+	}
+
+	return plaid.ExchangePublicTokenResponse{
+		AccessToken: accessToken,
+		ItemID:      itemID,
+	}, nil
+}
+
+// GetAccounts ...
+func (f *FakeClient) GetAccounts(accessToken string) (plaid.GetAccountsResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	item, institution, err := f.lookup(accessToken)
+
+	if err != nil {
+		return plaid.GetAccountsResponse{}, err
+	}
+
+	return plaid.GetAccountsResponse{
+		Accounts: itemAccounts(item, institution),
+		Item:     plaidItem(item),
+	}, nil
+}
+
+// GetBalances ...
+func (f *FakeClient) GetBalances(accessToken string) (plaid.GetBalancesResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	item, institution, err := f.lookup(accessToken)
+
+	if err != nil {
+		return plaid.GetBalancesResponse{}, err
+	}
+
+	return plaid.GetBalancesResponse{
+		Accounts: itemAccounts(item, institution),
+	}, nil
+}
+
+// GetTransactions ...
+func (f *FakeClient) GetTransactions(accessToken string, startDate string, endDate string) (plaid.GetTransactionsResponse, error) {
+	return f.GetTransactionsWithOptions(accessToken, plaid.GetTransactionsOptions{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Count:     100,
+	})
+}
+
+// GetTransactionsWithOptions ...
+// Returns the item's transactions within the date range,
+// newest first, paged by Count and Offset like Plaid
+func (f *FakeClient) GetTransactionsWithOptions(accessToken string, options plaid.GetTransactionsOptions) (plaid.GetTransactionsResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	item, institution, err := f.lookup(accessToken)
+
+	if err != nil {
+		return plaid.GetTransactionsResponse{}, err
+	}
+
+	if options.StartDate == "" || options.EndDate == "" {
+		return plaid.GetTransactionsResponse{}, fakeError("INVALID_REQUEST", "MISSING_FIELDS", "start_date and end_date are required")
+	}
+
+	accountFilter := make(map[string]bool, len(options.AccountIDs))
+
+	for _, id := range options.AccountIDs {
+		accountFilter[id] = true
+	}
+
+	matching := make([]plaid.Transaction, 0)
+
+	// Dates are YYYY-MM-DD so they compare as strings
+	for _, tx := range institution.Transactions {
+		if tx.Date < options.StartDate || tx.Date > options.EndDate {
+			continue
+		}
+
+		tx.ID = item.itemID + "-" + tx.ID
+		tx.AccountID = item.itemID + "-" + tx.AccountID
+
+		if len(accountFilter) > 0 && !accountFilter[tx.AccountID] {
+			continue
+		}
+
+		matching = append(matching, tx)
+	}
+
+	sort.SliceStable(matching, func(i, j int) bool {
+		return matching[i].Date > matching[j].Date
+	})
+
+	count := options.Count
+
+	if count <= 0 {
+		count = 100
+	}
+
+	start := options.Offset
+
+	if start > len(matching) {
+		start = len(matching)
+	}
+
+	end := start + count
+
+	if end > len(matching) {
+		end = len(matching)
+	}
+
+	return plaid.GetTransactionsResponse{
+		Accounts:          itemAccounts(item, institution),
+		Item:              plaidItem(item),
+		Transactions:      matching[start:end],
+		TotalTransactions: len(matching),
+	}, nil
+}
+
+// SyncTransactions ...
+// Pages through the institution's change log from the
+// position the cursor holds. Transactions the cursor
+// already covers come back as modified, not added
+func (f *FakeClient) SyncTransactions(accessToken string, cursor string, count int) (TransactionsSyncResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	item, _, err := f.lookup(accessToken)
+
+	if err != nil {
+		return TransactionsSyncResponse{}, err
+	}
+
+	position := 0
+
+	if cursor != "" {
+		if _, scanErr := fmt.Sscanf(cursor, "cursor-fake-%d", &position); scanErr != nil {
+			return TransactionsSyncResponse{}, fakeError("INVALID_INPUT", "INVALID_FIELD", "cursor is not valid")
+		}
+	}
+
+	changes := f.changes[item.institutionID]
+
+	if position > len(changes) {
+		return TransactionsSyncResponse{}, fakeError("INVALID_INPUT", "INVALID_FIELD", "cursor is not valid")
+	}
+
+	if count <= 0 {
+		count = 100
+	}
+
+	end := position + count
+
+	if end > len(changes) {
+		end = len(changes)
+	}
+
+	synced := make(map[string]bool)
+
+	for _, change := range changes[:position] {
+		synced[change.transaction.ID] = !change.removed
+	}
+
+	response := TransactionsSyncResponse{
+		Added:      make([]plaid.Transaction, 0),
+		Modified:   make([]plaid.Transaction, 0),
+		Removed:    make([]RemovedTransaction, 0),
+		NextCursor: fmt.Sprintf("cursor-fake-%d", end),
+		HasMore:    end < len(changes),
+	}
+
+	for _, change := range changes[position:end] {
+		tx := change.transaction
+		tx.ID = item.itemID + "-" + tx.ID
+		tx.AccountID = item.itemID + "-" + tx.AccountID
+
+		switch {
+		case change.removed:
+			response.Removed = append(response.Removed, RemovedTransaction{TransactionID: tx.ID})
+		case synced[change.transaction.ID]:
+			response.Modified = append(response.Modified, tx)
+		default:
+			response.Added = append(response.Added, tx)
+		}
+	}
+
+	return response, nil
+}
+
+// GetItem ...
+// Like Plaid, items in an error state are still
+// returned, with the error set on the item
+func (f *FakeClient) GetItem(accessToken string) (plaid.GetItemResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	item, ok := f.items[accessToken]
+
+	if !ok {
+		_, _, err := f.lookup(accessToken)
+		return plaid.GetItemResponse{}, err
+	}
+
+	response := plaid.GetItemResponse{Item: plaidItem(item)}
+	response.Item.Error = f.itemErrors[item.itemID]
+
+	return response, nil
+}
+
+// RemoveItem ...
+func (f *FakeClient) RemoveItem(accessToken string) (plaid.RemoveItemResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// Broken items can still be removed
+	if _, ok := f.items[accessToken]; !ok {
+		_, _, err := f.lookup(accessToken)
+		return plaid.RemoveItemResponse{}, err
+	}
+
+	delete(f.items, accessToken)
+	f.removed[accessToken] = true
+
+	return plaid.RemoveItemResponse{Removed: true}, nil
+}
+
+// GetInstitutionByID ...
+func (f *FakeClient) GetInstitutionByID(id string) (plaid.GetInstitutionByIDResponse, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	institution, ok := f.institutions[id]
+
+	if !ok {
+		return plaid.GetInstitutionByIDResponse{}, fakeError("INVALID_INPUT", "INVALID_INSTITUTION", "invalid institution_id provided")
+	}
+
+	return plaid.GetInstitutionByIDResponse{
+		Institution: plaid.Institution{
+			ID:           institution.InstitutionID,
+			Name:         institution.Name,
+			Products:     []string{"transactions"},
+			CountryCodes: []string{"US"},
+		},
+	}, nil
+}
+
+// GetWebhookVerificationKey ...
+// The fake never sends webhooks so it has no keys
+func (f *FakeClient) GetWebhookVerificationKey(keyID string) (plaid.GetWebhookVerificationKeyResponse, error) {
+	return plaid.GetWebhookVerificationKeyResponse{}, fakeError("INVALID_INPUT", "INVALID_WEBHOOK_VERIFICATION_KEY_ID", "the fake client does not sign webhooks")
+}
+
+// lookup ...
+// Tokens of removed items fail the way
+// Plaid fails them, unknown ones as malformed
+func (f *FakeClient) lookup(accessToken string) (fakeItem, FakeInstitution, error) {
+	item, ok := f.items[accessToken]
+
+	if f.removed[accessToken] {
+		return fakeItem{}, FakeInstitution{}, fakeError("INVALID_INPUT", "ITEM_NOT_FOUND", "the requested item was not found")
+	}
+
+	if !ok {
+		return fakeItem{}, FakeInstitution{}, fakeError("INVALID_INPUT", "INVALID_ACCESS_TOKEN", "provided access token is in an invalid format")
+	}
+
+	if itemErr, broken := f.itemErrors[item.itemID]; broken {
+		return fakeItem{}, FakeInstitution{}, itemErr
+	}
+
+	return item, f.institutions[item.institutionID], nil
+}
+
+// itemAccounts ...
+// Account ids are prefixed with the item id so
+// linking the same institution twice doesn't clash
+func itemAccounts(item fakeItem, institution FakeInstitution) []plaid.Account {
+	accounts := make([]plaid.Account, 0, len(institution.Accounts))
+
+	for _, act := range institution.Accounts {
+		act.AccountID = item.itemID + "-" + act.AccountID
+		accounts = append(accounts, act)
+	}
+
+	return accounts
+}
+
+func plaidItem(item fakeItem) plaid.Item {
+	return plaid.Item{
+		ItemID:         item.itemID,
+		InstitutionID:  item.institutionID,
+		Webhook:        item.webhook,
+		BilledProducts: []string{"transactions"},
+	}
+}
+
+func fakeError(errorType string, errorCode string, message string) plaid.Error {
+	return plaid.Error{
+		ErrorType:    errorType,
+		ErrorCode:    errorCode,
+		ErrorMessage: message,
+		StatusCode:   http.StatusBadRequest,
+	}
+}
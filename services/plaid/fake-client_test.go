@@ -0,0 +1,295 @@
+package plaid
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// contractPageSize ...
+// Sync page size the contract pages with,
+// small enough to take more than one page
+const contractPageSize = 2
+
+// contractTransactions ...
+// Transactions every client under contract has for the item
+const contractTransactions = 3
+
+// clientContract ...
+// Checks the behavior of a Client that linking and syncing an
+// item rely on. breakItem puts the linked item in the
+// ITEM_LOGIN_REQUIRED state
+func clientContract(t *testing.T, client Client, publicToken string, breakItem func(itemID string)) {
+	exchanged, err := client.ExchangePublicToken(publicToken)
+
+	if err != nil || exchanged.AccessToken == "" || exchanged.ItemID == "" {
+		t.Fatalf("ExchangePublicToken() = %+v, %v", exchanged, err)
+	}
+
+	accessToken := exchanged.AccessToken
+	accounts, err := client.GetAccounts(accessToken)
+
+	if err != nil {
+		t.Fatalf("GetAccounts() = %v", err)
+	}
+
+	if accounts.Item.ItemID != exchanged.ItemID || accounts.Item.InstitutionID == "" {
+		t.Errorf("GetAccounts() item = %+v, want item %s at an institution", accounts.Item, exchanged.ItemID)
+	}
+
+	accountIDs := make(map[string]bool)
+
+	for _, act := range accounts.Accounts {
+		accountIDs[act.AccountID] = true
+	}
+
+	if len(accountIDs) == 0 || len(accountIDs) != len(accounts.Accounts) {
+		t.Fatalf("GetAccounts() accounts = %+v, want distinct ids", accounts.Accounts)
+	}
+
+	// Paging from no cursor yields everything once
+	added := make(map[string]bool)
+	cursor := ""
+	pages := 0
+
+	for {
+		page, err := client.SyncTransactions(accessToken, cursor, contractPageSize)
+
+		if err != nil {
+			t.Fatalf("SyncTransactions(%q) = %v", cursor, err)
+		}
+
+		pages++
+
+		if page.NextCursor == "" || len(page.Added) > contractPageSize {
+			t.Fatalf("SyncTransactions(%q) = %d added, next cursor %q", cursor, len(page.Added), page.NextCursor)
+		}
+
+		for _, tx := range page.Added {
+			if added[tx.ID] || !accountIDs[tx.AccountID] {
+				t.Errorf("transaction %s of account %s added twice or for an unknown account", tx.ID, tx.AccountID)
+			}
+
+			added[tx.ID] = true
+		}
+
+		cursor = page.NextCursor
+
+		if !page.HasMore || pages > contractTransactions {
+			break
+		}
+	}
+
+	if len(added) != contractTransactions || pages != 2 {
+		t.Errorf("synced %d transactions over %d pages, want %d over 2", len(added), pages, contractTransactions)
+	}
+
+	caughtUp, err := client.SyncTransactions(accessToken, cursor, contractPageSize)
+
+	if err != nil || caughtUp.HasMore || caughtUp.NextCursor == "" ||
+		len(caughtUp.Added)+len(caughtUp.Modified)+len(caughtUp.Removed) != 0 {
+		t.Errorf("SyncTransactions() from the last cursor = %+v, %v, want no changes", caughtUp, err)
+	}
+
+	// Items in error fail every data call with the item error
+	// but can still be looked up and removed
+	breakItem(exchanged.ItemID)
+
+	calls := map[string]func() error{
+		"GetAccounts": func() error {
+			_, err := client.GetAccounts(accessToken)
+			return err
+		},
+		"GetBalances": func() error {
+			_, err := client.GetBalances(accessToken)
+			return err
+		},
+		"SyncTransactions": func() error {
+			_, err := client.SyncTransactions(accessToken, cursor, contractPageSize)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		plaidErr, ok := call().(plaid.Error)
+
+		if !ok || plaidErr.ErrorType != "ITEM_ERROR" || plaidErr.ErrorCode != "ITEM_LOGIN_REQUIRED" || plaidErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s() on a broken item = %+v, want a 400 ITEM_LOGIN_REQUIRED", name, plaidErr)
+		}
+	}
+
+	item, err := client.GetItem(accessToken)
+
+	if err != nil || item.Item.ItemID != exchanged.ItemID || item.Item.Error.ErrorCode != "ITEM_LOGIN_REQUIRED" {
+		t.Errorf("GetItem() on a broken item = %+v, %v, want the item with its error", item.Item, err)
+	}
+
+	removed, err := client.RemoveItem(accessToken)
+
+	if err != nil || !removed.Removed {
+		t.Errorf("RemoveItem() = %+v, %v", removed, err)
+	}
+
+	// Removing again is how callers learn an item was already gone
+	_, err = client.RemoveItem(accessToken)
+
+	if plaidErr, ok := err.(plaid.Error); !ok || plaidErr.ErrorCode != "ITEM_NOT_FOUND" {
+		t.Errorf("RemoveItem() on a removed item = %v, want ITEM_NOT_FOUND", err)
+	}
+}
+
+func TestFakeClientContract(t *testing.T) {
+	fake := NewFakeClient()
+
+	fake.AddInstitution(FakeInstitution{
+		InstitutionID: "ins_contract",
+		Name:          "Contract Bank",
+		Accounts: []plaid.Account{
+			{AccountID: "checking", Name: "Checking", Type: "depository", Subtype: "checking"},
+			{AccountID: "credit", Name: "Credit Card", Type: "credit", Subtype: "credit card"},
+		},
+		Transactions: []plaid.Transaction{
+			{ID: "rent", AccountID: "checking", Name: "Maple Apartments", Amount: 1450, Date: "2021-04-01"},
+			{ID: "coffee", AccountID: "credit", Name: "Corner Coffee", Amount: 4.75, Date: "2021-04-02"},
+			{ID: "fuel", AccountID: "credit", Name: "Quick Fuel", Amount: 42.10, Date: "2021-04-03"},
+		},
+	})
+
+	clientContract(t, fake, FakePublicToken("ins_contract"), func(itemID string) {
+		fake.SetItemError(itemID, "ITEM_LOGIN_REQUIRED")
+	})
+}
+
+// plaidStub ...
+// Answers the endpoints the contract calls with the
+// responses Plaid documents for them
+type plaidStub struct {
+	t       *testing.T
+	mutex   sync.Mutex
+	broken  bool
+	removed bool
+}
+
+// syncPages are the /transactions/sync responses
+// by cursor for pages of contractPageSize
+var syncPages = map[string]string{
+	"": `{"added":[
+		{"transaction_id":"tx-rent","account_id":"acc-checking","name":"Maple Apartments","amount":1450,"date":"2021-04-01","category":["Payment","Rent"]},
+		{"transaction_id":"tx-coffee","account_id":"acc-credit","name":"Corner Coffee","amount":4.75,"date":"2021-04-02","category":["Food and Drink","Restaurants","Coffee Shop"]}
+	],"modified":[],"removed":[],"next_cursor":"cursor-2","has_more":true,"request_id":"req-sync-1"}`,
+	"cursor-2": `{"added":[
+		{"transaction_id":"tx-fuel","account_id":"acc-credit","name":"Quick Fuel","amount":42.1,"date":"2021-04-03","category":["Travel","Gas Stations"]}
+	],"modified":[],"removed":[],"next_cursor":"cursor-3","has_more":false,"request_id":"req-sync-2"}`,
+	"cursor-3": `{"added":[],"modified":[],"removed":[],"next_cursor":"cursor-3","has_more":false,"request_id":"req-sync-3"}`,
+}
+
+const stubAccounts = `"accounts":[
+	{"account_id":"acc-checking","name":"Checking","type":"depository","subtype":"checking","balances":{"current":2500.12,"iso_currency_code":"USD"}},
+	{"account_id":"acc-credit","name":"Credit Card","type":"credit","subtype":"credit card","balances":{"current":410.55,"iso_currency_code":"USD"}}
+]`
+
+const stubItem = `"item":{"item_id":"item-sandbox-1","institution_id":"ins_109508","webhook":"","billed_products":["transactions"],"available_products":[]%s}`
+
+const stubLoginRequired = `{"error_type":"ITEM_ERROR","error_code":"ITEM_LOGIN_REQUIRED","error_message":"the login details of this item have changed","display_message":null,"request_id":"req-error"}`
+
+func (s *plaidStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var request struct {
+		Cursor string `json:"cursor"`
+		Count  int    `json:"count"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.t.Errorf("%s: %v", r.URL.Path, err)
+	}
+
+	respond := func(status int, body string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+
+	if r.URL.Path == "/item/public_token/exchange" {
+		respond(http.StatusOK, `{"access_token":"access-sandbox-1","item_id":"item-sandbox-1","request_id":"req-exchange"}`)
+		return
+	}
+
+	if s.removed {
+		respond(http.StatusBadRequest, `{"error_type":"INVALID_INPUT","error_code":"ITEM_NOT_FOUND","error_message":"the requested item was not found","request_id":"req-gone"}`)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/item/get":
+		itemError := ""
+
+		if s.broken {
+			itemError = `,"error":` + stubLoginRequired
+		}
+
+		respond(http.StatusOK, `{`+fmt.Sprintf(stubItem, itemError)+`,"status":null,"request_id":"req-item"}`)
+	case "/item/remove":
+		s.removed = true
+		respond(http.StatusOK, `{"removed":true,"request_id":"req-remove"}`)
+	case "/accounts/get", "/accounts/balance/get", "/transactions/sync":
+		if s.broken {
+			respond(http.StatusBadRequest, stubLoginRequired)
+			return
+		}
+
+		if r.URL.Path != "/transactions/sync" {
+			respond(http.StatusOK, `{`+stubAccounts+`,`+fmt.Sprintf(stubItem, "")+`,"request_id":"req-accounts"}`)
+			return
+		}
+
+		page, ok := syncPages[request.Cursor]
+
+		if !ok || request.Count != contractPageSize {
+			respond(http.StatusBadRequest, `{"error_type":"INVALID_INPUT","error_code":"INVALID_FIELD","error_message":"cursor is not valid","request_id":"req-cursor"}`)
+			return
+		}
+
+		respond(http.StatusOK, page)
+	default:
+		s.t.Errorf("unexpected call to %s", r.URL.Path)
+		respond(http.StatusNotFound, `{}`)
+	}
+}
+
+func TestAPIClientContract(t *testing.T) {
+	stub := &plaidStub{t: t}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	environment := plaid.Environment(server.URL)
+	plaidClient, err := plaid.NewClient(plaid.ClientOptions{
+		ClientID:    "client-id",
+		Secret:      "secret",
+		Environment: environment,
+		HTTPClient:  server.Client(),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &apiClient{
+		Client:      plaidClient,
+		environment: environment,
+		httpClient:  server.Client(),
+	}
+
+	clientContract(t, client, "public-sandbox-1", func(itemID string) {
+		stub.mutex.Lock()
+		defer stub.mutex.Unlock()
+
+		stub.broken = true
+	})
+}
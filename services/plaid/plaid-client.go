@@ -3,6 +3,7 @@ package plaid
 import (
 	"fmt"
 	"net/http"
+	"sync"
 
 	environment "github.com/lakshay35/finlit-backend/services/environment"
 	"github.com/plaid/plaid-go/plaid"
@@ -16,6 +17,7 @@ var (
 	PlaidProducts     = ""
 	PlaidCountryCodes = ""
 	PlaidRedirectURI  = ""
+	PlaidWebhookURL   = ""
 	environments      = map[string]plaid.Environment{
 		"sandbox":     plaid.Sandbox,
 		"development": plaid.Development,
@@ -30,20 +32,81 @@ func init() {
 	PlaidProducts = environment.GetEnvVariable("PLAID_PRODUCTS")
 	PlaidCountryCodes = environment.GetEnvVariable("PLAID_COUNTRY_CODES")
 	PlaidRedirectURI = ""
+	PlaidWebhookURL = environment.GetEnvVariable("PLAID_WEBHOOK_URL")
 }
 
+// Client ...
+// The Plaid calls the API makes. Satisfied by *plaid.Client
+// extended with the calls it lacks and by FakeClient for
+// working offline
+type Client interface {
+	CreateLinkToken(configs plaid.LinkTokenConfigs) (plaid.CreateLinkTokenResponse, error)
+	ExchangePublicToken(publicToken string) (plaid.ExchangePublicTokenResponse, error)
+	GetAccounts(accessToken string) (plaid.GetAccountsResponse, error)
+	GetTransactions(accessToken string, startDate string, endDate string) (plaid.GetTransactionsResponse, error)
+	GetTransactionsWithOptions(accessToken string, options plaid.GetTransactionsOptions) (plaid.GetTransactionsResponse, error)
+	SyncTransactions(accessToken string, cursor string, count int) (TransactionsSyncResponse, error)
+	GetBalances(accessToken string) (plaid.GetBalancesResponse, error)
+	GetItem(accessToken string) (plaid.GetItemResponse, error)
+	RemoveItem(accessToken string) (plaid.RemoveItemResponse, error)
+	GetInstitutionByID(id string) (plaid.GetInstitutionByIDResponse, error)
+	GetWebhookVerificationKey(keyID string) (plaid.GetWebhookVerificationKeyResponse, error)
+}
+
+var (
+	_ Client = (*apiClient)(nil)
+	_ Client = (*FakeClient)(nil)
+)
+
+var (
+	clientMutex sync.Mutex
+	client      Client
+)
+
 // PlaidClient ...
-// Client to communicate with plaid api
-var PlaidClient = func() *plaid.Client {
-	client, err := plaid.NewClient(
+// Client to communicate with plaid api. Built once from
+// the environment; PLAID_ENV=fake selects a seeded FakeClient
+// so the API works without network access or credentials
+func PlaidClient() Client {
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+
+	if client == nil {
+		client = newClientFromEnvironment()
+	}
+
+	return client
+}
+
+// SetClient ...
+// Replaces the client returned by PlaidClient
+func SetClient(c Client) {
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+
+	client = c
+}
+
+func newClientFromEnvironment() Client {
+	if PlaidEnv == "fake" {
+		return NewSeededFakeClient()
+	}
+
+	httpClient := &http.Client{}
+
+	plaidClient, err := plaid.NewClient(
 		plaid.ClientOptions{
 			ClientID:    PlaidClientID,
 			Secret:      PlaidSecret,
 			Environment: environments[PlaidEnv],
-			HTTPClient:  &http.Client{},
+			HTTPClient:  httpClient,
 		})
 	if err != nil {
 		panic(fmt.Errorf("unexpected error while initializing plaid client %w", err))
 	}
-	return client
+	return &apiClient{
+		Client:      plaidClient,
+		environment: environments[PlaidEnv],
+		httpClient:  httpClient,
+	}
 }
@@ -0,0 +1,99 @@
+package plaid
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/plaid/plaid-go/plaid"
+)
+
+// transactionsSyncAPIVersion ...
+// /transactions/sync only exists from this API version on,
+// newer than the one plaid-go pins for its own calls
+const transactionsSyncAPIVersion = "2020-09-14"
+
+// RemovedTransaction ...
+// A transaction Plaid no longer has
+type RemovedTransaction struct {
+	TransactionID string `json:"transaction_id"`
+}
+
+// TransactionsSyncResponse ...
+// One page of changes to an item's transactions
+// since the cursor it was requested with
+type TransactionsSyncResponse struct {
+	plaid.APIResponse
+	Added      []plaid.Transaction  `json:"added"`
+	Modified   []plaid.Transaction  `json:"modified"`
+	Removed    []RemovedTransaction `json:"removed"`
+	NextCursor string               `json:"next_cursor"`
+	HasMore    bool                 `json:"has_more"`
+}
+
+type transactionsSyncRequest struct {
+	ClientID    string `json:"client_id"`
+	Secret      string `json:"secret"`
+	AccessToken string `json:"access_token"`
+	Cursor      string `json:"cursor,omitempty"`
+	Count       int    `json:"count,omitempty"`
+}
+
+// apiClient ...
+// *plaid.Client along with the calls the
+// version of plaid-go in use doesn't have
+type apiClient struct {
+	*plaid.Client
+	environment plaid.Environment
+	httpClient  *http.Client
+}
+
+// SyncTransactions ...
+// Gets a page of the changes to an item's transactions since
+// cursor, everything Plaid has when cursor is empty
+func (c *apiClient) SyncTransactions(accessToken string, cursor string, count int) (TransactionsSyncResponse, error) {
+	var response TransactionsSyncResponse
+
+	body, err := json.Marshal(transactionsSyncRequest{
+		ClientID:    PlaidClientID,
+		Secret:      PlaidSecret,
+		AccessToken: accessToken,
+		Cursor:      cursor,
+		Count:       count,
+	})
+
+	if err != nil {
+		return response, err
+	}
+
+	req, err := http.NewRequest("POST", string(c.environment)+"/transactions/sync", bytes.NewReader(body))
+
+	if err != nil {
+		return response, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Plaid-Version", transactionsSyncAPIVersion)
+
+	res, err := c.httpClient.Do(req)
+
+	if err != nil {
+		return response, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		plaidErr := plaid.Error{StatusCode: res.StatusCode}
+
+		if decodeErr := json.NewDecoder(res.Body).Decode(&plaidErr); decodeErr != nil {
+			return response, decodeErr
+		}
+
+		return response, plaidErr
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&response)
+
+	return response, err
+}
@@ -0,0 +1,78 @@
+package role
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type memoryUserRole struct {
+	budgetID uuid.UUID
+	userID   uuid.UUID
+	roleName string
+}
+
+// MemoryRoleStore ...
+// In-memory RoleStore for tests and local development
+type MemoryRoleStore struct {
+	mutex  sync.RWMutex
+	owners map[uuid.UUID]uuid.UUID
+	roles  []memoryUserRole
+}
+
+// NewMemoryRoleStore ...
+// Creates an empty in-memory RoleStore
+func NewMemoryRoleStore() *MemoryRoleStore {
+	return &MemoryRoleStore{
+		owners: make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+// SetBudgetOwner ...
+// Records ownerID as the owner of budgetID. Budget ownership
+// lives with budgets in postgres, so in-memory tests seed it here
+func (s *MemoryRoleStore) SetBudgetOwner(budgetID uuid.UUID, ownerID uuid.UUID) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.owners[budgetID] = ownerID
+}
+
+// IsOwner ...
+func (s *MemoryRoleStore) IsOwner(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	ownerID, ok := s.owners[budgetID]
+
+	return ok && ownerID == userID, nil
+}
+
+// HasRole ...
+func (s *MemoryRoleStore) HasRole(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID, roleName string) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, role := range s.roles {
+		if role.budgetID == budgetID && role.userID == userID && role.roleName == roleName {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// AddUserRole ...
+func (s *MemoryRoleStore) AddUserRole(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID, roleName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.roles = append(s.roles, memoryUserRole{
+		budgetID: budgetID,
+		userID:   userID,
+		roleName: roleName,
+	})
+
+	return nil
+}
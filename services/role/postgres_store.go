@@ -0,0 +1,58 @@
+package role
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// PostgresRoleStore ...
+// RoleStore backed by the postgres database
+type PostgresRoleStore struct{}
+
+// NewPostgresRoleStore ...
+// Creates a postgres backed RoleStore
+func NewPostgresRoleStore() *PostgresRoleStore {
+	return &PostgresRoleStore{}
+}
+
+// IsOwner ...
+func (s *PostgresRoleStore) IsOwner(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) (bool, error) {
+	query := "SELECT owner_id FROM budgets WHERE budget_id = $1 AND owner_id = $2"
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, budgetID, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer rows.Close()
+
+	return rows.Next(), nil
+}
+
+// HasRole ...
+func (s *PostgresRoleStore) HasRole(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID, roleName string) (bool, error) {
+	query := `SELECT * FROM user_roles ur WHERE ur.user_id = $1 AND ur.role_id = (SELECT role_id FROM roles WHERE role_name = $2)
+	AND ur.budget_id = $3`
+
+	rows, err := database.Conn(ctx).QueryContext(ctx, query, userID, roleName, budgetID)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer rows.Close()
+
+	return rows.Next(), nil
+}
+
+// AddUserRole ...
+func (s *PostgresRoleStore) AddUserRole(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID, roleName string) error {
+	query := "INSERT INTO user_roles (user_id, role_id, budget_id) VALUES ($1, (SELECT role_id FROM roles WHERE role_name = $2), $3)"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, userID, roleName, budgetID)
+
+	return err
+}
@@ -0,0 +1,27 @@
+package role
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RoleStore ...
+// Persistence operations needed by the role service
+type RoleStore interface {
+	// IsOwner reports whether userID owns budgetID
+	IsOwner(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID) (bool, error)
+	// HasRole reports whether userID holds roleName on budgetID
+	HasRole(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID, roleName string) (bool, error)
+	// AddUserRole grants roleName on budgetID to userID
+	AddUserRole(ctx context.Context, budgetID uuid.UUID, userID uuid.UUID, roleName string) error
+}
+
+var store RoleStore
+
+// SetStore ...
+// Sets the store used by the role service.
+// Called once at startup
+func SetStore(s RoleStore) {
+	store = s
+}
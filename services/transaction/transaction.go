@@ -1,30 +1,46 @@
 package transaction
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
-	"github.com/google/uuid"
 	"github.com/lakshay35/finlit-backend/models"
 	"github.com/lakshay35/finlit-backend/models/errors"
+	accountService "github.com/lakshay35/finlit-backend/services/account"
 	budgetService "github.com/lakshay35/finlit-backend/services/budget"
-	"github.com/lakshay35/finlit-backend/utils/database"
 )
 
 // CategorizeTransaction ...
-func CategorizeTransaction(payload models.BudgetTransactionCategoryTransactionCreationPayload) *errors.Error {
-	transactionCategories, transactionCategoriesErr := budgetService.GetTransactionCategories(payload.BudgetID)
+// Transactions can be identified by a synced transaction_id
+// or by name directly. Synced transactions also pass their
+// Plaid category on to the budget's category suggestions
+func CategorizeTransaction(ctx context.Context, payload models.BudgetTransactionCategoryTransactionCreationPayload) *errors.Error {
+	var plaidCategory []string
+
+	if payload.TransactionID != "" {
+		transaction, transactionErr := accountService.GetStoredTransaction(ctx, payload.TransactionID)
+
+		if transactionErr != nil {
+			return transactionErr
+		}
+
+		payload.TransactionName = transaction.Name
+		plaidCategory = transaction.Category
+	}
+
+	transactionCategories, transactionCategoriesErr := budgetService.GetTransactionCategories(ctx, payload.BudgetID)
 
 	if transactionCategoriesErr != nil {
 		return transactionCategoriesErr
 	}
 
-	var categoryID uuid.UUID
+	var category models.BudgetTransactionCategory
 	categoryValid := false
 
 	for _, cat := range transactionCategories {
 		if strings.EqualFold(cat.CategoryName, payload.CategoryName) {
-			categoryID = cat.BudgetTransactionCategoryID
+			category = cat
 			categoryValid = true
 		}
 	}
@@ -36,22 +52,10 @@ func CategorizeTransaction(payload models.BudgetTransactionCategoryTransactionCr
 		}
 	}
 
-	connection := database.GetConnection()
-
-	defer database.CloseConnection(connection)
-
-	query := "INSERT INTO budget_transaction_category_transactions (budget_transaction_category_id, transaction_name) VALUES ($1, $2)"
-
-	stmt := database.PrepareStatement(connection, query)
-
-	_, insertErr := stmt.Exec(categoryID, payload.TransactionName)
-
-	if insertErr != nil {
-		return &errors.Error{
-			Message:    insertErr.Error(),
-			StatusCode: http.StatusBadRequest,
-		}
-	}
-
-	return nil
+	return budgetService.CreateBudgetTransactionCategoryTransaction(ctx, payload.BudgetID, models.BudgetTransactionCategoryTransaction{
+		BudgetTransactionCategoryID: category.BudgetTransactionCategoryID,
+		TransactionName:             payload.TransactionName,
+		CategoryName:                category.CategoryName,
+		PlaidCategory:               plaidCategory,
+	})
 }
@@ -0,0 +1,79 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// MemoryUserStore ...
+// In-memory UserStore for tests and local development
+type MemoryUserStore struct {
+	mutex sync.RWMutex
+	users map[string]models.User
+}
+
+// NewMemoryUserStore ...
+// Creates an empty in-memory UserStore
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{
+		users: make(map[string]models.User),
+	}
+}
+
+// GetByGoogleID ...
+func (s *MemoryUserStore) GetByGoogleID(ctx context.Context, googleID string) (*models.User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	user, ok := s.users[googleID]
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &user, nil
+}
+
+// GetByID ...
+func (s *MemoryUserStore) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, user := range s.users {
+		if user.UserID == userID {
+			return &user, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+// Create ...
+func (s *MemoryUserStore) Create(ctx context.Context, user models.UserRegistrationPayload) (*models.User, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.users[user.GoogleID]; exists {
+		return nil, fmt.Errorf("user with google_id %s already exists", user.GoogleID)
+	}
+
+	result := models.User{
+		UserID:           uuid.New(),
+		RegistrationDate: time.Now().Format(time.RFC3339),
+		FirstName:        user.FirstName,
+		LastName:         user.LastName,
+		Email:            user.Email,
+		Phone:            user.Phone,
+		GoogleID:         user.GoogleID,
+	}
+
+	s.users[user.GoogleID] = result
+
+	return &result, nil
+}
@@ -0,0 +1,95 @@
+package user
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// PostgresUserStore ...
+// UserStore backed by the postgres database
+type PostgresUserStore struct{}
+
+// NewPostgresUserStore ...
+// Creates a postgres backed UserStore
+func NewPostgresUserStore() *PostgresUserStore {
+	return &PostgresUserStore{}
+}
+
+// GetByGoogleID ...
+func (s *PostgresUserStore) GetByGoogleID(ctx context.Context, googleID string) (*models.User, error) {
+	query := "SELECT user_id, first_name, last_name, email, phone, google_id, registration_date FROM users where google_id = $1"
+
+	var userResult models.User
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, googleID).Scan(
+		&userResult.UserID,
+		&userResult.FirstName,
+		&userResult.LastName,
+		&userResult.Email,
+		&userResult.Phone,
+		&userResult.GoogleID,
+		&userResult.RegistrationDate,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &userResult, nil
+}
+
+// GetByID ...
+func (s *PostgresUserStore) GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	query := "SELECT user_id, first_name, last_name, email, phone, google_id, registration_date FROM users where user_id = $1"
+
+	var userResult models.User
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, userID).Scan(
+		&userResult.UserID,
+		&userResult.FirstName,
+		&userResult.LastName,
+		&userResult.Email,
+		&userResult.Phone,
+		&userResult.GoogleID,
+		&userResult.RegistrationDate,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &userResult, nil
+}
+
+// Create ...
+func (s *PostgresUserStore) Create(ctx context.Context, user models.UserRegistrationPayload) (*models.User, error) {
+	query := "INSERT INTO users (first_name, last_name, email, phone, google_id) VALUES ($1, $2, $3, $4, $5) RETURNING user_id, registration_date"
+
+	result := models.User{
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Phone:     user.Phone,
+		GoogleID:  user.GoogleID,
+	}
+
+	err := database.Conn(ctx).QueryRowContext(
+		ctx,
+		query,
+		result.FirstName,
+		result.LastName,
+		result.Email,
+		result.Phone,
+		result.GoogleID,
+	).Scan(&result.UserID, &result.RegistrationDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
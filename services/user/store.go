@@ -0,0 +1,31 @@
+package user
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// UserStore ...
+// Persistence operations needed by the user service
+type UserStore interface {
+	// GetByGoogleID returns the user registered with googleID
+	// or sql.ErrNoRows if there is none
+	GetByGoogleID(ctx context.Context, googleID string) (*models.User, error)
+	// GetByID returns the user with the given id
+	// or sql.ErrNoRows if there is none
+	GetByID(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	// Create registers a new user
+	Create(ctx context.Context, user models.UserRegistrationPayload) (*models.User, error)
+}
+
+var store UserStore
+
+// SetStore ...
+// Sets the store used by the user service.
+// Called once at startup
+func SetStore(s UserStore) {
+	store = s
+}
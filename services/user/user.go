@@ -1,71 +1,48 @@
 package user
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/google/uuid"
 	"github.com/lakshay35/finlit-backend/models"
 	"github.com/lakshay35/finlit-backend/models/errors"
-	"github.com/lakshay35/finlit-backend/utils/database"
 )
 
 //GetUser ...
 // Gets user from database
-func GetUser(googleID string) (*models.User, *errors.Error) {
-	tx := database.GetConnection()
-	defer database.CloseConnection(tx)
+func GetUser(ctx context.Context, googleID string) (*models.User, *errors.Error) {
+	user, err := store.GetByGoogleID(ctx, googleID)
 
-	stmt := database.PrepareStatement(tx, "SELECT * FROM users where google_id = $1")
-
-	res, err := stmt.Query(googleID)
-
-	if err != nil || !res.Next() {
+	if err != nil {
 		return nil, &errors.Error{
 			Message:    "User does not exist",
 			StatusCode: http.StatusNotFound,
 		}
 	}
 
-	var userResult models.User
+	return user, nil
+}
 
-	err = res.Scan(
-		&userResult.UserID,
-		&userResult.FirstName,
-		&userResult.LastName,
-		&userResult.Email,
-		&userResult.Phone,
-		&userResult.GoogleID,
-		&userResult.RegistrationDate,
-	)
+// GetUserByID ...
+// Gets a user by their id
+func GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, *errors.Error) {
+	user, err := store.GetByID(ctx, userID)
 
 	if err != nil {
-		panic(err)
+		return nil, &errors.Error{
+			Message:    "User does not exist",
+			StatusCode: http.StatusNotFound,
+		}
 	}
 
-	res.Close()
-
-	return &userResult, nil
+	return user, nil
 }
 
 // RegisterUser ...
 // Registers user in the db
-func RegisterUser(user models.UserRegistrationPayload) (*models.User, *errors.Error) {
-	connection := database.GetConnection()
-	defer database.CloseConnection(connection)
-
-	stmt := database.PrepareStatement(
-		connection,
-		"INSERT INTO users (first_name, last_name, email, phone, google_id) VALUES ($1, $2, $3, $4, $5) RETURNING user_id, registration_date",
-	)
-
-	var result models.User
-
-	err := stmt.QueryRow(
-		result.FirstName,
-		result.LastName,
-		result.Email,
-		result.Phone,
-		result.GoogleID,
-	).Scan(&result.UserID, &result.RegistrationDate)
+func RegisterUser(ctx context.Context, user models.UserRegistrationPayload) (*models.User, *errors.Error) {
+	result, err := store.Create(ctx, user)
 
 	if err != nil {
 		return nil, &errors.Error{
@@ -74,5 +51,5 @@ func RegisterUser(user models.UserRegistrationPayload) (*models.User, *errors.Er
 		}
 	}
 
-	return &result, nil
+	return result, nil
 }
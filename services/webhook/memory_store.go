@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// MemoryWebhookEvent ...
+// A webhook event held by the MemoryWebhookStore
+type MemoryWebhookEvent struct {
+	models.PlaidWebhookEvent
+	Processed bool
+	Error     string
+}
+
+// MemoryWebhookStore ...
+// In-memory WebhookStore for tests and local development
+type MemoryWebhookStore struct {
+	mutex  sync.RWMutex
+	events []MemoryWebhookEvent
+}
+
+// NewMemoryWebhookStore ...
+// Creates an empty in-memory WebhookStore
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{}
+}
+
+// RecordEvent ...
+func (s *MemoryWebhookStore) RecordEvent(ctx context.Context, webhook models.PlaidWebhook, payload []byte) (uuid.UUID, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	event := MemoryWebhookEvent{
+		PlaidWebhookEvent: models.PlaidWebhookEvent{
+			WebhookEventID: uuid.New(),
+			Webhook:        webhook,
+			Payload:        append([]byte{}, payload...),
+			ReceivedAt:     time.Now(),
+		},
+	}
+
+	s.events = append(s.events, event)
+
+	return event.WebhookEventID, nil
+}
+
+// MarkProcessed ...
+func (s *MemoryWebhookStore) MarkProcessed(ctx context.Context, webhookEventID uuid.UUID, handlerErr string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i := range s.events {
+		if s.events[i].WebhookEventID == webhookEventID {
+			s.events[i].Processed = true
+			s.events[i].Error = handlerErr
+			return nil
+		}
+	}
+
+	return sql.ErrNoRows
+}
+
+// Events ...
+// Returns the recorded events in the order received
+func (s *MemoryWebhookStore) Events() []MemoryWebhookEvent {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return append([]MemoryWebhookEvent{}, s.events...)
+}
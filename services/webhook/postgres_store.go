@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/utils/database"
+)
+
+// PostgresWebhookStore ...
+// WebhookStore backed by the postgres database
+type PostgresWebhookStore struct{}
+
+// NewPostgresWebhookStore ...
+// Creates a postgres backed WebhookStore
+func NewPostgresWebhookStore() *PostgresWebhookStore {
+	return &PostgresWebhookStore{}
+}
+
+// RecordEvent ...
+func (s *PostgresWebhookStore) RecordEvent(ctx context.Context, webhook models.PlaidWebhook, payload []byte) (uuid.UUID, error) {
+	query := "INSERT INTO plaid_webhook_events (webhook_type, webhook_code, item_id, payload) VALUES ($1, $2, NULLIF($3, ''), $4) RETURNING webhook_event_id"
+
+	var webhookEventID uuid.UUID
+
+	err := database.Conn(ctx).QueryRowContext(ctx, query, webhook.WebhookType, webhook.WebhookCode, webhook.ItemID, string(payload)).Scan(&webhookEventID)
+
+	return webhookEventID, err
+}
+
+// MarkProcessed ...
+func (s *PostgresWebhookStore) MarkProcessed(ctx context.Context, webhookEventID uuid.UUID, handlerErr string) error {
+	query := "UPDATE plaid_webhook_events SET processed_at = current_timestamp, error = NULLIF($2, '') WHERE webhook_event_id = $1"
+
+	_, err := database.Conn(ctx).ExecContext(ctx, query, webhookEventID, handlerErr)
+
+	return err
+}
@@ -0,0 +1,27 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+)
+
+// WebhookStore ...
+// Persistence operations needed by the webhook service
+type WebhookStore interface {
+	// RecordEvent stores a received webhook and returns its id
+	RecordEvent(ctx context.Context, webhook models.PlaidWebhook, payload []byte) (uuid.UUID, error)
+	// MarkProcessed records that an event was handled,
+	// with the handler's error message if it failed
+	MarkProcessed(ctx context.Context, webhookEventID uuid.UUID, handlerErr string) error
+}
+
+var store WebhookStore
+
+// SetStore ...
+// Sets the store used by the webhook service.
+// Called once at startup
+func SetStore(s WebhookStore) {
+	store = s
+}
@@ -0,0 +1,299 @@
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+const (
+	// maxTokenAge is how old a Plaid-Verification token may
+	// be before it's treated as a replay
+	maxTokenAge = 5 * time.Minute
+
+	// maxClockSkew is how far in the future a token may be
+	// issued, allowing for Plaid's clock running ahead of ours
+	maxClockSkew = 1 * time.Minute
+
+	// keyCacheTTL is how long a fetched key is trusted
+	// before it's looked up again to catch revocations
+	keyCacheTTL = 24 * time.Hour
+
+	// unknownKeyTTL is how long a key id Plaid doesn't know is
+	// remembered, so forged tokens can't make us call Plaid
+	// for every request
+	unknownKeyTTL = 5 * time.Minute
+)
+
+// ErrInvalidVerification ...
+// Returned for any webhook whose Plaid-Verification
+// header doesn't check out
+var ErrInvalidVerification = errors.New("invalid plaid webhook verification")
+
+// FetchVerificationKey ...
+// Looks up a Plaid webhook verification key by key id.
+// Replaced in tests to verify against a local key pair
+var FetchVerificationKey = func(keyID string) (plaid.WebhookVerificationKey, error) {
+	response, err := plaidService.PlaidClient().GetWebhookVerificationKey(keyID)
+
+	if err != nil {
+		return plaid.WebhookVerificationKey{}, err
+	}
+
+	return response.Key, nil
+}
+
+// cachedKey ...
+// A fetched verification key, or a key id Plaid
+// doesn't know when key is nil
+type cachedKey struct {
+	key       *ecdsa.PublicKey
+	expiredAt int64
+	fetchedAt time.Time
+}
+
+// keyFetch ...
+// A key lookup in flight that requests for
+// the same key id wait on instead of repeating
+type keyFetch struct {
+	done   sync.WaitGroup
+	cached cachedKey
+	err    error
+}
+
+var (
+	keyCacheMutex sync.Mutex
+	keyCache      = make(map[string]cachedKey)
+	keyFetches    = make(map[string]*keyFetch)
+)
+
+type verificationHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type verificationClaims struct {
+	IssuedAt          int64  `json:"iat"`
+	RequestBodySHA256 string `json:"request_body_sha256"`
+}
+
+// VerifyPlaidWebhook ...
+// Verifies the Plaid-Verification JWT sent with a webhook:
+// an ES256 signature by a current Plaid key, issued within
+// the last five minutes and not in the future, over a hash
+// of this exact body
+func VerifyPlaidWebhook(token string, body []byte, now time.Time) error {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed token", ErrInvalidVerification)
+	}
+
+	var header verificationHeader
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return err
+	}
+
+	if header.Alg != "ES256" {
+		return fmt.Errorf("%w: unexpected alg %q", ErrInvalidVerification, header.Alg)
+	}
+
+	key, err := verificationKey(header.Kid, now)
+
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil || len(signature) != 64 {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidVerification)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return fmt.Errorf("%w: bad signature", ErrInvalidVerification)
+	}
+
+	var claims verificationClaims
+
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return err
+	}
+
+	age := now.Sub(time.Unix(claims.IssuedAt, 0))
+
+	if age > maxTokenAge {
+		return fmt.Errorf("%w: token expired", ErrInvalidVerification)
+	}
+
+	if age < -maxClockSkew {
+		return fmt.Errorf("%w: token issued in the future", ErrInvalidVerification)
+	}
+
+	bodyDigest := sha256.Sum256(body)
+
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(bodyDigest[:])), []byte(claims.RequestBodySHA256)) != 1 {
+		return fmt.Errorf("%w: body hash mismatch", ErrInvalidVerification)
+	}
+
+	return nil
+}
+
+// ClearVerificationKeyCache ...
+// Forgets every cached verification key
+func ClearVerificationKeyCache() {
+	keyCacheMutex.Lock()
+	defer keyCacheMutex.Unlock()
+
+	keyCache = make(map[string]cachedKey)
+}
+
+// verificationKey ...
+// Returns the cached key for kid, fetching it from Plaid when
+// unknown or stale. Concurrent requests for the same kid share
+// one fetch, made without holding the cache lock. Expired keys
+// and key ids Plaid doesn't know are rejected
+func verificationKey(kid string, now time.Time) (*ecdsa.PublicKey, error) {
+	if kid == "" {
+		return nil, fmt.Errorf("%w: missing kid", ErrInvalidVerification)
+	}
+
+	keyCacheMutex.Lock()
+
+	cached, ok := keyCache[kid]
+	ttl := keyCacheTTL
+
+	if ok && cached.key == nil {
+		ttl = unknownKeyTTL
+	}
+
+	if !ok || now.Sub(cached.fetchedAt) > ttl {
+		fetch, inFlight := keyFetches[kid]
+
+		if !inFlight {
+			fetch = &keyFetch{}
+			fetch.done.Add(1)
+			keyFetches[kid] = fetch
+		}
+
+		keyCacheMutex.Unlock()
+
+		if !inFlight {
+			fetch.cached, fetch.err = fetchVerificationKey(kid, now)
+
+			keyCacheMutex.Lock()
+
+			if fetch.err == nil {
+				keyCache[kid] = fetch.cached
+			}
+
+			delete(keyFetches, kid)
+			keyCacheMutex.Unlock()
+
+			fetch.done.Done()
+		}
+
+		fetch.done.Wait()
+
+		if fetch.err != nil {
+			return nil, fetch.err
+		}
+
+		cached = fetch.cached
+	} else {
+		keyCacheMutex.Unlock()
+	}
+
+	if cached.key == nil {
+		return nil, fmt.Errorf("%w: unknown key %s", ErrInvalidVerification, kid)
+	}
+
+	if cached.expiredAt != 0 && now.Unix() >= cached.expiredAt {
+		return nil, fmt.Errorf("%w: key %s has expired", ErrInvalidVerification, kid)
+	}
+
+	return cached.key, nil
+}
+
+// fetchVerificationKey ...
+// Fetches and parses a key. A key id Plaid rejects comes back
+// as a cached entry without a key so it isn't asked for again
+// right away; failures to reach Plaid aren't cached
+func fetchVerificationKey(kid string, now time.Time) (cachedKey, error) {
+	jwk, err := FetchVerificationKey(kid)
+
+	if plaidErr, ok := err.(plaid.Error); ok && plaidErr.StatusCode >= 400 && plaidErr.StatusCode < 500 {
+		return cachedKey{fetchedAt: now}, nil
+	}
+
+	if err != nil {
+		return cachedKey{}, fmt.Errorf("fetching plaid verification key %s: %w", kid, err)
+	}
+
+	key, err := parseVerificationKey(jwk)
+
+	if err != nil {
+		return cachedKey{}, err
+	}
+
+	return cachedKey{key: key, expiredAt: jwk.ExpiredAt, fetchedAt: now}, nil
+}
+
+// parseVerificationKey ...
+// Converts a P-256 JWK into a public key
+func parseVerificationKey(jwk plaid.WebhookVerificationKey) (*ecdsa.PublicKey, error) {
+	if jwk.Kty != "EC" || jwk.Crv != "P-256" {
+		return nil, fmt.Errorf("%w: unsupported key %s/%s", ErrInvalidVerification, jwk.Kty, jwk.Crv)
+	}
+
+	x, xErr := base64.RawURLEncoding.DecodeString(jwk.X)
+	y, yErr := base64.RawURLEncoding.DecodeString(jwk.Y)
+
+	if xErr != nil || yErr != nil {
+		return nil, fmt.Errorf("%w: malformed key coordinates", ErrInvalidVerification)
+	}
+
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, fmt.Errorf("%w: key is not on P-256", ErrInvalidVerification)
+	}
+
+	return key, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidVerification)
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidVerification)
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lakshay35/finlit-backend/utils/testhelpers"
+	"github.com/plaid/plaid-go/plaid"
+)
+
+var webhookBody = []byte(`{"webhook_type":"TRANSACTIONS","webhook_code":"DEFAULT_UPDATE","item_id":"item-1"}`)
+
+// useSigner routes key lookups to a local signer and
+// returns how many times Plaid would have been asked
+func useSigner(t *testing.T, signer *testhelpers.PlaidWebhookSigner) *int {
+	t.Helper()
+
+	fetch := FetchVerificationKey
+	fetches := 0
+	var mutex sync.Mutex
+
+	FetchVerificationKey = func(keyID string) (plaid.WebhookVerificationKey, error) {
+		mutex.Lock()
+		fetches++
+		mutex.Unlock()
+
+		if keyID != signer.KeyID {
+			return plaid.WebhookVerificationKey{}, plaid.Error{ErrorCode: "INVALID_KEY_ID", StatusCode: http.StatusBadRequest}
+		}
+
+		return signer.VerificationKey(), nil
+	}
+
+	ClearVerificationKeyCache()
+
+	t.Cleanup(func() {
+		FetchVerificationKey = fetch
+		ClearVerificationKeyCache()
+	})
+
+	return &fetches
+}
+
+func TestVerifyPlaidWebhook(t *testing.T) {
+	now := time.Now()
+	signer := testhelpers.NewPlaidWebhookSigner("key-1")
+	otherSigner := testhelpers.NewPlaidWebhookSigner("key-1")
+	unknownSigner := testhelpers.NewPlaidWebhookSigner("key-unknown")
+
+	valid := signer.Sign(webhookBody, now)
+	parts := strings.Split(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		body    []byte
+		wantErr string
+	}{
+		{name: "valid", token: valid, body: webhookBody},
+		{name: "small clock skew", token: signer.Sign(webhookBody, now.Add(30*time.Second)), body: webhookBody},
+		{name: "signed by another key", token: otherSigner.Sign(webhookBody, now), body: webhookBody, wantErr: "bad signature"},
+		{name: "tampered signature", token: parts[0] + "." + parts[1] + "." + strings.Repeat("A", 86), body: webhookBody, wantErr: "bad signature"},
+		{name: "body changed", token: valid, body: append(webhookBody, ' '), wantErr: "body hash mismatch"},
+		{name: "stale", token: signer.Sign(webhookBody, now.Add(-maxTokenAge-time.Second)), body: webhookBody, wantErr: "token expired"},
+		{name: "issued in the future", token: signer.Sign(webhookBody, now.Add(maxClockSkew+time.Second)), body: webhookBody, wantErr: "issued in the future"},
+		{name: "unknown kid", token: unknownSigner.Sign(webhookBody, now), body: webhookBody, wantErr: "unknown key"},
+		{name: "malformed", token: "not-a-token", body: webhookBody, wantErr: "malformed token"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useSigner(t, signer)
+
+			err := VerifyPlaidWebhook(test.token, test.body, now)
+
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("VerifyPlaidWebhook() = %v, want nil", err)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, ErrInvalidVerification) || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("VerifyPlaidWebhook() = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerificationKeyCache(t *testing.T) {
+	now := time.Now()
+	signer := testhelpers.NewPlaidWebhookSigner("key-1")
+	unknownSigner := testhelpers.NewPlaidWebhookSigner("key-unknown")
+	fetches := useSigner(t, signer)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := VerifyPlaidWebhook(signer.Sign(webhookBody, now), webhookBody, now); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if *fetches != 1 {
+		t.Fatalf("fetched the key %d times, want 1", *fetches)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := VerifyPlaidWebhook(unknownSigner.Sign(webhookBody, now), webhookBody, now); err == nil {
+			t.Fatal("accepted a token signed by an unknown key")
+		}
+	}
+
+	if *fetches != 2 {
+		t.Fatalf("fetched keys %d times, want unknown key ids cached after one lookup", *fetches)
+	}
+
+	later := now.Add(keyCacheTTL + time.Minute)
+
+	if err := VerifyPlaidWebhook(signer.Sign(webhookBody, later), webhookBody, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if *fetches != 3 {
+		t.Fatalf("fetched keys %d times, want the stale key looked up again", *fetches)
+	}
+}
+
+func TestVerifyPlaidWebhookExpiredKey(t *testing.T) {
+	now := time.Now()
+	signer := testhelpers.NewPlaidWebhookSigner("key-1")
+	useSigner(t, signer)
+
+	FetchVerificationKey = func(keyID string) (plaid.WebhookVerificationKey, error) {
+		key := signer.VerificationKey()
+		key.ExpiredAt = now.Add(-time.Hour).Unix()
+
+		return key, nil
+	}
+
+	err := VerifyPlaidWebhook(signer.Sign(webhookBody, now), webhookBody, now)
+
+	if !errors.Is(err, ErrInvalidVerification) || !strings.Contains(err.Error(), "has expired") {
+		t.Fatalf("VerifyPlaidWebhook() = %v, want expired key", err)
+	}
+}
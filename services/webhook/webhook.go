@@ -0,0 +1,169 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lakshay35/finlit-backend/models"
+	"github.com/lakshay35/finlit-backend/models/errors"
+	accountService "github.com/lakshay35/finlit-backend/services/account"
+	"github.com/lakshay35/finlit-backend/utils/logging"
+)
+
+// Handler ...
+// Reacts to a verified Plaid webhook
+type Handler func(ctx context.Context, webhook models.PlaidWebhook) error
+
+var (
+	handlersMutex sync.RWMutex
+	handlers      = make(map[string]Handler)
+)
+
+func init() {
+	RegisterHandler("TRANSACTIONS", handleTransactionsWebhook)
+	RegisterHandler("ITEM", handleItemWebhook)
+	RegisterHandler("AUTH", handleAuthWebhook)
+}
+
+// RegisterHandler ...
+// Sets the handler for a webhook type,
+// replacing any previous one
+func RegisterHandler(webhookType string, handler Handler) {
+	handlersMutex.Lock()
+	defer handlersMutex.Unlock()
+
+	handlers[webhookType] = handler
+}
+
+// HandlePlaidWebhook ...
+// Records a verified webhook and sends it to the handler
+// for its type. Webhook types without a handler are
+// recorded and acknowledged
+func HandlePlaidWebhook(ctx context.Context, payload []byte) *errors.Error {
+	var webhook models.PlaidWebhook
+
+	if err := json.Unmarshal(payload, &webhook); err != nil || webhook.WebhookType == "" {
+		return &errors.Error{
+			Message:    "Webhook body is not a Plaid webhook",
+			StatusCode: http.StatusBadRequest,
+		}
+	}
+
+	webhookEventID, err := store.RecordEvent(ctx, webhook, payload)
+
+	if err != nil {
+		return &errors.Error{
+			Message:    err.Error(),
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	handlersMutex.RLock()
+	handler, ok := handlers[webhook.WebhookType]
+	handlersMutex.RUnlock()
+
+	var handlerErr error
+
+	if ok {
+		handlerErr = handler(ctx, webhook)
+	}
+
+	handlerMessage := ""
+
+	if handlerErr != nil {
+		handlerMessage = handlerErr.Error()
+	}
+
+	if err := store.MarkProcessed(ctx, webhookEventID, handlerMessage); err != nil {
+		logging.ErrorLogger.Println(err)
+	}
+
+	// A failing response makes Plaid retry the webhook
+	if handlerErr != nil {
+		return &errors.Error{
+			Message:    handlerMessage,
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	return nil
+}
+
+// handleTransactionsWebhook ...
+// Any transactions update means the item has changes
+// to pull, so every code triggers a sync of the item
+func handleTransactionsWebhook(ctx context.Context, webhook models.PlaidWebhook) error {
+	if webhook.ItemID == "" {
+		return nil
+	}
+
+	_, err := accountService.SyncItemTransactions(ctx, webhook.ItemID)
+
+	// Items whose accounts were all deleted have nothing to sync
+	if err != nil && err.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// handleItemWebhook ...
+// Records item problems that need the user to relink
+// on the item so clients can prompt for it
+func handleItemWebhook(ctx context.Context, webhook models.PlaidWebhook) error {
+	status := ""
+	errorCode := ""
+
+	var consentExpiresAt *time.Time
+
+	switch webhook.WebhookCode {
+	case "ERROR":
+		if webhook.Error != nil {
+			logging.WarningLogger.Printf("plaid item %s errored: %s %s", webhook.ItemID, webhook.Error.ErrorCode, webhook.Error.ErrorMessage)
+
+			errorCode = webhook.Error.ErrorCode
+			status = accountService.ItemStatusForErrorCode(errorCode)
+		}
+	case "PENDING_EXPIRATION":
+		logging.WarningLogger.Printf("plaid item %s consent expires at %s", webhook.ItemID, webhook.ConsentExpirationTime)
+
+		status = models.PlaidItemStatusPendingExpiration
+
+		if expiresAt, err := time.Parse(time.RFC3339, webhook.ConsentExpirationTime); err == nil {
+			consentExpiresAt = &expiresAt
+		}
+	case "USER_PERMISSION_REVOKED":
+		logging.WarningLogger.Printf("plaid item %s access was revoked by the user", webhook.ItemID)
+
+		status = models.PlaidItemStatusRevoked
+	case "LOGIN_REPAIRED":
+		status = models.PlaidItemStatusHealthy
+	default:
+		logging.InfoLogger.Printf("plaid item %s sent %s", webhook.ItemID, webhook.WebhookCode)
+	}
+
+	if status == "" || webhook.ItemID == "" {
+		return nil
+	}
+
+	err := accountService.SetItemStatus(ctx, webhook.ItemID, status, errorCode, consentExpiresAt)
+
+	// Items that were deleted have no status to keep
+	if err != nil && err.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// handleAuthWebhook ...
+// Auth isn't a product we request, so verification
+// updates are only logged
+func handleAuthWebhook(ctx context.Context, webhook models.PlaidWebhook) error {
+	logging.InfoLogger.Printf("plaid item %s sent auth %s for account %s", webhook.ItemID, webhook.WebhookCode, webhook.AccountID)
+
+	return nil
+}
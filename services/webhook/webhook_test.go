@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lakshay35/finlit-backend/models"
+	accountService "github.com/lakshay35/finlit-backend/services/account"
+	plaidService "github.com/lakshay35/finlit-backend/services/plaid"
+)
+
+// linkFakeItem ...
+// Links the fake bank for a new user and returns its item
+func linkFakeItem(t *testing.T, ctx context.Context) (uuid.UUID, models.PlaidItem) {
+	t.Helper()
+
+	SetStore(NewMemoryWebhookStore())
+	accountService.SetStore(accountService.NewMemoryAccountStore())
+	plaidService.SetClient(plaidService.NewSeededFakeClient())
+
+	userID := uuid.New()
+
+	if err := accountService.RegisterAccessToken(ctx, plaidService.FakePublicToken("ins_fake_bank"), userID); err != nil {
+		t.Fatal(err.Message)
+	}
+
+	items, itemsErr := accountService.GetUserItems(ctx, userID)
+
+	if itemsErr != nil || len(items) != 1 {
+		t.Fatalf("items = %+v (%v), want one", items, itemsErr)
+	}
+
+	return userID, items[0].PlaidItem
+}
+
+func TestItemWebhookSetsItemStatus(t *testing.T) {
+	ctx := context.Background()
+	userID, item := linkFakeItem(t, ctx)
+
+	// Each step starts from the status the previous one left
+	tests := []struct {
+		name          string
+		body          string
+		wantStatus    string
+		wantErrorCode string
+	}{
+		{
+			name:          "login required",
+			body:          `{"webhook_type":"ITEM","webhook_code":"ERROR","item_id":"%s","error":{"error_code":"ITEM_LOGIN_REQUIRED"}}`,
+			wantStatus:    models.PlaidItemStatusLoginRequired,
+			wantErrorCode: "ITEM_LOGIN_REQUIRED",
+		},
+		{
+			name:       "login repaired",
+			body:       `{"webhook_type":"ITEM","webhook_code":"LOGIN_REPAIRED","item_id":"%s"}`,
+			wantStatus: models.PlaidItemStatusHealthy,
+		},
+		{
+			name:       "pending expiration",
+			body:       `{"webhook_type":"ITEM","webhook_code":"PENDING_EXPIRATION","item_id":"%s","consent_expiration_time":"2030-01-02T15:04:05Z"}`,
+			wantStatus: models.PlaidItemStatusPendingExpiration,
+		},
+		{
+			name:       "error without a status",
+			body:       `{"webhook_type":"ITEM","webhook_code":"ERROR","item_id":"%s","error":{"error_code":"INSTITUTION_DOWN"}}`,
+			wantStatus: models.PlaidItemStatusPendingExpiration,
+		},
+		{
+			name:       "unknown code",
+			body:       `{"webhook_type":"ITEM","webhook_code":"WEBHOOK_UPDATE_ACKNOWLEDGED","item_id":"%s"}`,
+			wantStatus: models.PlaidItemStatusPendingExpiration,
+		},
+		{
+			name:          "revoking error",
+			body:          `{"webhook_type":"ITEM","webhook_code":"ERROR","item_id":"%s","error":{"error_code":"ITEM_NOT_FOUND"}}`,
+			wantStatus:    models.PlaidItemStatusRevoked,
+			wantErrorCode: "ITEM_NOT_FOUND",
+		},
+		{
+			name:       "login repaired again",
+			body:       `{"webhook_type":"ITEM","webhook_code":"LOGIN_REPAIRED","item_id":"%s"}`,
+			wantStatus: models.PlaidItemStatusHealthy,
+		},
+		{
+			name:       "permission revoked",
+			body:       `{"webhook_type":"ITEM","webhook_code":"USER_PERMISSION_REVOKED","item_id":"%s"}`,
+			wantStatus: models.PlaidItemStatusRevoked,
+		},
+	}
+
+	for _, test := range tests {
+		if err := HandlePlaidWebhook(ctx, []byte(fmt.Sprintf(test.body, item.ItemID))); err != nil {
+			t.Fatalf("%s: HandlePlaidWebhook() = %s", test.name, err.Message)
+		}
+
+		items, itemsErr := accountService.GetUserItems(ctx, userID)
+
+		if itemsErr != nil {
+			t.Fatal(itemsErr.Message)
+		}
+
+		got := items[0].PlaidItem
+
+		if got.Status != test.wantStatus || got.ErrorCode != test.wantErrorCode {
+			t.Errorf("%s: item status = %s (%q), want %s (%q)", test.name, got.Status, got.ErrorCode, test.wantStatus, test.wantErrorCode)
+		}
+
+		if got.NeedsAttention != (test.wantStatus != models.PlaidItemStatusHealthy) {
+			t.Errorf("%s: needs attention = %v in status %s", test.name, got.NeedsAttention, got.Status)
+		}
+
+		if test.name == "pending expiration" {
+			want := time.Date(2030, 1, 2, 15, 4, 5, 0, time.UTC)
+
+			if got.ConsentExpiresAt == nil || !got.ConsentExpiresAt.Equal(want) {
+				t.Errorf("consent expires at %v, want %v", got.ConsentExpiresAt, want)
+			}
+		}
+	}
+}
+
+func TestItemWebhookForUnknownItem(t *testing.T) {
+	ctx := context.Background()
+	linkFakeItem(t, ctx)
+
+	body := []byte(`{"webhook_type":"ITEM","webhook_code":"USER_PERMISSION_REVOKED","item_id":"deleted-item"}`)
+
+	// Plaid would keep retrying an item that's gone
+	if err := HandlePlaidWebhook(ctx, body); err != nil {
+		t.Fatalf("HandlePlaidWebhook() = %s, want deleted items acknowledged", err.Message)
+	}
+}
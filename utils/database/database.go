@@ -1,12 +1,23 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"os"
 )
 
 var database *sql.DB
 
+// Querier ...
+// Query methods shared by *sql.DB and *sql.Tx
+// so stores don't care which one they run on
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 // InitializeDatabase ...
 // Initializes database connection
 // for API
@@ -22,40 +33,23 @@ func InitializeDatabase() {
 	database = db
 }
 
-// GetConnection ...
-// Returns a transaction valid for
-// 1 db connection.
-// Once db interaction is complete
-// call tx.Commit() to return the
-// connection to the pool
-func GetConnection() *sql.Tx {
-	tx, err := database.Begin()
-
-	if err != nil {
-		panic(err)
+// Conn ...
+// Returns the unit of work carried by ctx, or
+// the connection pool when there is none.
+// Statements run on the pool are committed
+// individually
+func Conn(ctx context.Context) Querier {
+	if uow := unitOfWorkFromContext(ctx); uow != nil {
+		return uow
 	}
 
-	return tx
+	return database
 }
 
-// CloseConnection ...
-// Closes connection to db
-func CloseConnection(tx *sql.Tx) {
-	err := tx.Commit()
-
-	if err != nil {
-		panic(err)
-	}
-}
-
-// PrepareStatement ...
-// Prepares statement and
-// returns executable stmt object
-func PrepareStatement(tx *sql.Tx, query string) *sql.Stmt {
-	stmt, err := tx.Prepare(query)
-	if err != nil {
-		panic(err)
-	}
-
-	return stmt
+// GetDatabase ...
+// Returns the underlying connection pool.
+// Used by tooling such as migrations that
+// manage their own transactions
+func GetDatabase() *sql.DB {
+	return database
 }
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type unitOfWorkKey struct{}
+
+// UnitOfWork ...
+// A database transaction carried in a context so that
+// every store call made with that context commits or
+// rolls back together. The transaction is only begun
+// by the first statement run through the unit, so work
+// that never touches the database doesn't hold a
+// connection
+type UnitOfWork struct {
+	ctx         context.Context
+	tx          *sql.Tx
+	savepoints  int
+	done        bool
+	afterCommit []func()
+}
+
+// BeginUnitOfWork ...
+// Starts a unit of work and returns a context carrying it.
+// The caller must end it with Commit or Rollback
+func BeginUnitOfWork(ctx context.Context) (context.Context, *UnitOfWork) {
+	uow := &UnitOfWork{ctx: ctx}
+
+	return context.WithValue(ctx, unitOfWorkKey{}, uow), uow
+}
+
+// WithoutUnitOfWork ...
+// Returns a context whose store calls run outside any unit of
+// work ctx carries. For writes that must outlive a request that
+// fails, like recording that a Plaid item broke, and for work
+// that calls other services, which shouldn't hold a transaction
+// open while waiting on them
+func WithoutUnitOfWork(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unitOfWorkKey{}, (*UnitOfWork)(nil))
+}
+
+// AfterCommit ...
+// Runs fn once the unit of work ctx carries commits, or right
+// away when there is none. For keeping in-process state like
+// caches in step with writes that may still roll back
+func AfterCommit(ctx context.Context, fn func()) {
+	uow := unitOfWorkFromContext(ctx)
+
+	if uow == nil {
+		fn()
+		return
+	}
+
+	uow.afterCommit = append(uow.afterCommit, fn)
+}
+
+// Commit ...
+// Commits every write made in the unit of work
+// and then runs the functions waiting on it
+func (uow *UnitOfWork) Commit() error {
+	if uow.done {
+		return sql.ErrTxDone
+	}
+
+	uow.done = true
+
+	if uow.tx != nil {
+		if err := uow.tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	for _, fn := range uow.afterCommit {
+		fn()
+	}
+
+	uow.afterCommit = nil
+
+	return nil
+}
+
+// Rollback ...
+// Discards every write made in the unit of work.
+// Rolling back a finished unit of work is a no-op
+// so it is safe to defer
+func (uow *UnitOfWork) Rollback() error {
+	if uow.done {
+		return nil
+	}
+
+	uow.done = true
+	uow.afterCommit = nil
+
+	if uow.tx == nil {
+		return nil
+	}
+
+	return uow.tx.Rollback()
+}
+
+// ExecContext ...
+func (uow *UnitOfWork) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	tx, err := uow.begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.ExecContext(ctx, query, args...)
+}
+
+// PrepareContext ...
+func (uow *UnitOfWork) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	tx, err := uow.begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.PrepareContext(ctx, query)
+}
+
+// QueryContext ...
+func (uow *UnitOfWork) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	tx, err := uow.begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext ...
+// A *sql.Row can't carry an error of ours, so when the
+// transaction can't be begun the query runs on the pool
+// and reports its own failure. Nothing was written through
+// the unit yet, so the pool sees the same rows
+func (uow *UnitOfWork) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	tx, err := uow.begin()
+
+	if err != nil {
+		return database.QueryRowContext(ctx, query, args...)
+	}
+
+	return tx.QueryRowContext(ctx, query, args...)
+}
+
+// begin ...
+// Begins the transaction on the first statement
+func (uow *UnitOfWork) begin() (*sql.Tx, error) {
+	if uow.tx != nil {
+		return uow.tx, nil
+	}
+
+	if uow.done {
+		return nil, sql.ErrTxDone
+	}
+
+	tx, err := database.BeginTx(uow.ctx, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	uow.tx = tx
+
+	return tx, nil
+}
+
+// RunInTransaction ...
+// Runs fn so that the writes it makes through ctx commit
+// or roll back together. When ctx already carries a unit
+// of work fn runs under a savepoint of it, so an error only
+// undoes fn's own writes and the outer unit decides whether
+// to commit. When no database is configured, as with the
+// in-memory stores, fn simply runs
+func RunInTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if uow := unitOfWorkFromContext(ctx); uow != nil {
+		return uow.runInSavepoint(ctx, fn)
+	}
+
+	if database == nil {
+		return fn(ctx)
+	}
+
+	txCtx, uow := BeginUnitOfWork(ctx)
+
+	defer uow.Rollback()
+
+	if err := fn(txCtx); err != nil {
+		return err
+	}
+
+	return uow.Commit()
+}
+
+func (uow *UnitOfWork) runInSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	uow.savepoints++
+	savepoint := fmt.Sprintf("unit_of_work_%d", uow.savepoints)
+	pending := len(uow.afterCommit)
+
+	if _, err := uow.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+
+	if err := fn(ctx); err != nil {
+		uow.afterCommit = uow.afterCommit[:pending]
+
+		if _, rollbackErr := uow.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rollbackErr != nil {
+			return fmt.Errorf("%v (rollback failed: %w)", err, rollbackErr)
+		}
+
+		return err
+	}
+
+	_, err := uow.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+
+	return err
+}
+
+func unitOfWorkFromContext(ctx context.Context) *UnitOfWork {
+	if ctx == nil {
+		return nil
+	}
+
+	uow, _ := ctx.Value(unitOfWorkKey{}).(*UnitOfWork)
+
+	return uow
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func useMockDatabase(t *testing.T) sqlmock.Sqlmock {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := database
+	database = db
+
+	t.Cleanup(func() {
+		database = previous
+		db.Close()
+	})
+
+	return mock
+}
+
+func TestUnitOfWorkWithoutStatementsNeverBegins(t *testing.T) {
+	mock := useMockDatabase(t)
+	committed := false
+
+	ctx, uow := BeginUnitOfWork(context.Background())
+
+	AfterCommit(ctx, func() {
+		committed = true
+	})
+
+	if err := uow.Commit(); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+
+	if !committed {
+		t.Error("after commit functions didn't run")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnitOfWorkBeginsOnFirstStatement(t *testing.T) {
+	mock := useMockDatabase(t)
+
+	ctx, uow := BeginUnitOfWork(context.Background())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM budgets").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM expenses").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	if _, err := Conn(ctx).ExecContext(ctx, "DELETE FROM budgets"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Conn(ctx).ExecContext(ctx, "DELETE FROM expenses"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uow.Rollback(); err != nil {
+		t.Fatalf("Rollback() = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWithoutUnitOfWorkRunsOnThePool(t *testing.T) {
+	mock := useMockDatabase(t)
+
+	ctx, uow := BeginUnitOfWork(context.Background())
+
+	mock.ExpectExec("UPDATE plaid_items").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if _, err := Conn(WithoutUnitOfWork(ctx)).ExecContext(ctx, "UPDATE plaid_items"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := uow.Commit(); err != nil {
+		t.Fatalf("Commit() = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,12 +1,22 @@
+package migrations
+
+// Baseline schema previously applied by hand from db-script.sql.
+// Statements use IF NOT EXISTS so databases created from the old
+// script can adopt migrations without being rebuilt
+func init() {
+	register(Migration{
+		Version:     1,
+		Description: "initial schema",
+		Up: `
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
 CREATE TABLE IF NOT EXISTS users (
   user_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
   first_name VARCHAR (255) NOT NULL,
-	 last_name VARCHAR (255) NOT NULL,
-	 email VARCHAR (255) UNIQUE NOT NULL,
-	 phone VARCHAR (255) NOT NULL,
-   google_id VARCHAR UNIQUE NOT NULL,
+  last_name VARCHAR (255) NOT NULL,
+  email VARCHAR (255) UNIQUE NOT NULL,
+  phone VARCHAR (255) NOT NULL,
+  google_id VARCHAR UNIQUE NOT NULL,
   registration_date TIMESTAMP default current_timestamp
 );
 
@@ -15,19 +25,8 @@ CREATE TABLE IF NOT EXISTS roles (
   role_name VARCHAR (50) UNIQUE NOT NULL
 );
 
-insert into roles (role_name) VALUES ('Full Rights') on conflict do nothing;
-insert into roles (role_name) VALUES ('View Rights') on conflict do nothing;
-
--- CREATE TABLE IF NOT EXISTS audit_objects (
---   audit_object_id SERIAL PRIMARY KEY,
---   audit_object_name VARCHAR (255) NOT NULL,
---   foreign_id VARCHAR (255) NOT NULL
--- );
-
--- CREATE TABLE IF NOT EXISTS audit_types (
---   audit_type_id SERIAL PRIMARY KEY,
---   audit_type_name VARCHAR (255) NOT NULL
--- );
+INSERT INTO roles (role_name) VALUES ('Full Rights') ON CONFLICT DO NOTHING;
+INSERT INTO roles (role_name) VALUES ('View Rights') ON CONFLICT DO NOTHING;
 
 CREATE TABLE IF NOT EXISTS external_accounts (
   external_account_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -36,15 +35,15 @@ CREATE TABLE IF NOT EXISTS external_accounts (
   access_token VARCHAR (255) NOT NULL,
   account_name VARCHAR NOT NULL,
   FOREIGN KEY (user_id)
-      REFERENCES users (user_id)
+    REFERENCES users (user_id)
 );
 
 CREATE TABLE IF NOT EXISTS budgets (
   budget_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
   budget_name VARCHAR (255) NOT NULL,
   owner_id UUID NOT NULL,
-	FOREIGN KEY (owner_id)
-      REFERENCES users (user_id)
+  FOREIGN KEY (owner_id)
+    REFERENCES users (user_id)
 );
 
 CREATE TABLE IF NOT EXISTS expense_charge_cycles (
@@ -53,15 +52,15 @@ CREATE TABLE IF NOT EXISTS expense_charge_cycles (
   days INTEGER NOT NULL UNIQUE
 );
 
-insert into expense_charge_cycles (unit, days) VALUES ('annually', 365) on conflict do nothing;
-insert into expense_charge_cycles (unit, days) VALUES ('semi-annually', 182) on conflict do nothing;
-insert into expense_charge_cycles (unit, days) VALUES ('monthly', 30) on conflict do nothing;
-insert into expense_charge_cycles (unit, days) VALUES ('semi-monthly', 15) on conflict do nothing;
-insert into expense_charge_cycles (unit, days) VALUES ('bi-weekly', 14) on conflict do nothing;
-insert into expense_charge_cycles (unit, days) VALUES ('weekly', 7) on conflict do nothing;
-insert into expense_charge_cycles (unit, days) VALUES ('daily', 1) on conflict do nothing;
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('annually', 365) ON CONFLICT DO NOTHING;
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('semi-annually', 182) ON CONFLICT DO NOTHING;
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('monthly', 30) ON CONFLICT DO NOTHING;
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('semi-monthly', 15) ON CONFLICT DO NOTHING;
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('bi-weekly', 14) ON CONFLICT DO NOTHING;
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('weekly', 7) ON CONFLICT DO NOTHING;
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('daily', 1) ON CONFLICT DO NOTHING;
 
-CREATE TABLE IF NOT EXISTS	 expenses (
+CREATE TABLE IF NOT EXISTS expenses (
   expense_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
   budget_id UUID NOT NULL,
   expense_name VARCHAR (255),
@@ -69,9 +68,9 @@ CREATE TABLE IF NOT EXISTS	 expenses (
   expense_description VARCHAR,
   expense_charge_cycle_id INT NOT NULL,
   FOREIGN KEY (budget_id)
-      REFERENCES budgets (budget_id),
+    REFERENCES budgets (budget_id),
   FOREIGN KEY (expense_charge_cycle_id)
-      REFERENCES expense_charge_cycles (expense_charge_cycle_id)
+    REFERENCES expense_charge_cycles (expense_charge_cycle_id)
 );
 
 CREATE TABLE IF NOT EXISTS user_roles (
@@ -80,14 +79,13 @@ CREATE TABLE IF NOT EXISTS user_roles (
   role_id INT NOT NULL,
   budget_id UUID NOT NULL,
   FOREIGN KEY (budget_id)
-      REFERENCES budgets (budget_id),
+    REFERENCES budgets (budget_id),
   FOREIGN KEY (user_id)
-      REFERENCES users (user_id),
+    REFERENCES users (user_id),
   FOREIGN KEY (role_id)
-      REFERENCES roles (role_id)
+    REFERENCES roles (role_id)
 );
 
-
 CREATE TABLE IF NOT EXISTS budget_transaction_sources (
   budget_transaction_source_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
   external_account_id UUID,
@@ -114,7 +112,6 @@ CREATE TABLE IF NOT EXISTS budget_transaction_category_transactions (
     REFERENCES budget_transaction_categories (budget_transaction_category_id)
 );
 
-
 CREATE TABLE IF NOT EXISTS budget_expense_transaction_categories (
   budget_expense_transaction_category_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
   expense_id UUID,
@@ -123,8 +120,7 @@ CREATE TABLE IF NOT EXISTS budget_expense_transaction_categories (
     REFERENCES expenses (expense_id),
   FOREIGN KEY (budget_transaction_category_id)
     REFERENCES budget_transaction_categories (budget_transaction_category_id)
-
-)
+);
 
 CREATE TABLE IF NOT EXISTS fitness_tracker_history (
   id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -134,4 +130,21 @@ CREATE TABLE IF NOT EXISTS fitness_tracker_history (
   user_id UUID NOT NULL,
   FOREIGN KEY (user_id)
     REFERENCES users (user_id)
-)
\ No newline at end of file
+);
+`,
+		Down: `
+DROP TABLE IF EXISTS fitness_tracker_history;
+DROP TABLE IF EXISTS budget_expense_transaction_categories;
+DROP TABLE IF EXISTS budget_transaction_category_transactions;
+DROP TABLE IF EXISTS budget_transaction_categories;
+DROP TABLE IF EXISTS budget_transaction_sources;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS expenses;
+DROP TABLE IF EXISTS expense_charge_cycles;
+DROP TABLE IF EXISTS budgets;
+DROP TABLE IF EXISTS external_accounts;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS users;
+`,
+	})
+}
@@ -0,0 +1,16 @@
+package migrations
+
+// Check-in lookups always filter fitness history by user and date
+func init() {
+	register(Migration{
+		Version:     2,
+		Description: "index fitness_tracker_history by user and date",
+		Up: `
+CREATE INDEX IF NOT EXISTS fitness_tracker_history_user_id_date_idx
+  ON fitness_tracker_history (user_id, date);
+`,
+		Down: `
+DROP INDEX IF EXISTS fitness_tracker_history_user_id_date_idx;
+`,
+	})
+}
@@ -0,0 +1,54 @@
+package migrations
+
+// Local copy of Plaid transactions so reads don't depend on
+// Plaid being up. Each Plaid item keeps a sync cursor marking
+// how far its history has been pulled
+func init() {
+	register(Migration{
+		Version:     3,
+		Description: "transactions and per item sync cursors",
+		Up: `
+ALTER TABLE external_accounts ADD COLUMN IF NOT EXISTS item_id VARCHAR (255);
+
+CREATE TABLE IF NOT EXISTS transactions (
+  transaction_id VARCHAR (255) PRIMARY KEY,
+  external_account_id UUID NOT NULL,
+  item_id VARCHAR (255) NOT NULL,
+  institutional_id VARCHAR (255) NOT NULL,
+  name VARCHAR NOT NULL,
+  amount NUMERIC (14, 2) NOT NULL,
+  iso_currency_code VARCHAR (10),
+  unofficial_currency_code VARCHAR (10),
+  category VARCHAR[] NOT NULL DEFAULT '{}',
+  category_id VARCHAR (50),
+  date DATE NOT NULL,
+  authorized_date DATE,
+  pending BOOLEAN NOT NULL DEFAULT false,
+  pending_transaction_id VARCHAR (255),
+  payment_channel VARCHAR (50),
+  transaction_type VARCHAR (50),
+  updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (external_account_id)
+    REFERENCES external_accounts (external_account_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS transactions_external_account_id_date_idx
+  ON transactions (external_account_id, date);
+
+CREATE INDEX IF NOT EXISTS transactions_item_id_date_idx
+  ON transactions (item_id, date);
+
+CREATE TABLE IF NOT EXISTS transaction_sync_cursors (
+  item_id VARCHAR (255) PRIMARY KEY,
+  cursor VARCHAR (255) NOT NULL,
+  synced_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+`,
+		Down: `
+DROP TABLE IF EXISTS transaction_sync_cursors;
+DROP TABLE IF EXISTS transactions;
+ALTER TABLE external_accounts DROP COLUMN IF EXISTS item_id;
+`,
+	})
+}
@@ -0,0 +1,28 @@
+package migrations
+
+// Every verified Plaid webhook is recorded before it is
+// handled so failures can be inspected and replayed
+func init() {
+	register(Migration{
+		Version:     4,
+		Description: "plaid webhook events",
+		Up: `
+CREATE TABLE IF NOT EXISTS plaid_webhook_events (
+  webhook_event_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  webhook_type VARCHAR (50) NOT NULL,
+  webhook_code VARCHAR (100) NOT NULL,
+  item_id VARCHAR (255),
+  payload JSONB NOT NULL,
+  received_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  processed_at TIMESTAMP,
+  error VARCHAR
+);
+
+CREATE INDEX IF NOT EXISTS plaid_webhook_events_item_id_idx
+  ON plaid_webhook_events (item_id, received_at);
+`,
+		Down: `
+DROP TABLE IF EXISTS plaid_webhook_events;
+`,
+	})
+}
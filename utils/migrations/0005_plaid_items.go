@@ -0,0 +1,94 @@
+package migrations
+
+// Plaid items become their own table so the access token,
+// status and sync cursor live once per item instead of being
+// copied onto every external account. Existing accounts are
+// grouped into items by the access token they share
+func init() {
+	register(Migration{
+		Version:     5,
+		Description: "plaid items",
+		Up: `
+CREATE TABLE IF NOT EXISTS plaid_items (
+  plaid_item_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  item_id VARCHAR (255) UNIQUE,
+  user_id UUID NOT NULL,
+  institution_id VARCHAR (255),
+  institution_name VARCHAR (255),
+  access_token VARCHAR (255) NOT NULL,
+  status VARCHAR (50) NOT NULL DEFAULT 'active',
+  error_code VARCHAR (100),
+  consent_expires_at TIMESTAMP,
+  sync_cursor VARCHAR (255),
+  last_synced_at TIMESTAMP,
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (user_id)
+    REFERENCES users (user_id)
+);
+
+CREATE INDEX IF NOT EXISTS plaid_items_user_id_idx
+  ON plaid_items (user_id);
+
+INSERT INTO plaid_items (item_id, user_id, access_token)
+SELECT MAX(item_id), user_id, access_token
+FROM external_accounts
+GROUP BY user_id, access_token;
+
+UPDATE plaid_items p SET sync_cursor = c.cursor, last_synced_at = c.synced_at
+FROM transaction_sync_cursors c
+WHERE c.item_id = p.item_id;
+
+ALTER TABLE external_accounts
+  ADD COLUMN plaid_item_id UUID,
+  ADD COLUMN official_name VARCHAR,
+  ADD COLUMN mask VARCHAR (10);
+
+UPDATE external_accounts e SET plaid_item_id = p.plaid_item_id
+FROM plaid_items p
+WHERE p.user_id = e.user_id AND p.access_token = e.access_token;
+
+ALTER TABLE external_accounts
+  ALTER COLUMN plaid_item_id SET NOT NULL,
+  ADD CONSTRAINT external_accounts_plaid_item_id_fkey
+    FOREIGN KEY (plaid_item_id)
+    REFERENCES plaid_items (plaid_item_id)
+    ON DELETE CASCADE,
+  ADD CONSTRAINT external_accounts_plaid_item_id_institutional_id_key
+    UNIQUE (plaid_item_id, institutional_id),
+  DROP COLUMN access_token,
+  DROP COLUMN item_id;
+
+DROP TABLE IF EXISTS transaction_sync_cursors;
+`,
+		Down: `
+CREATE TABLE IF NOT EXISTS transaction_sync_cursors (
+  item_id VARCHAR (255) PRIMARY KEY,
+  cursor VARCHAR (255) NOT NULL,
+  synced_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+INSERT INTO transaction_sync_cursors (item_id, cursor, synced_at)
+SELECT item_id, sync_cursor, COALESCE(last_synced_at, current_timestamp)
+FROM plaid_items
+WHERE item_id IS NOT NULL AND sync_cursor IS NOT NULL;
+
+ALTER TABLE external_accounts
+  ADD COLUMN access_token VARCHAR (255),
+  ADD COLUMN item_id VARCHAR (255);
+
+UPDATE external_accounts e SET access_token = p.access_token, item_id = p.item_id
+FROM plaid_items p
+WHERE p.plaid_item_id = e.plaid_item_id;
+
+ALTER TABLE external_accounts
+  ALTER COLUMN access_token SET NOT NULL,
+  DROP CONSTRAINT IF EXISTS external_accounts_plaid_item_id_institutional_id_key,
+  DROP CONSTRAINT IF EXISTS external_accounts_plaid_item_id_fkey,
+  DROP COLUMN plaid_item_id,
+  DROP COLUMN official_name,
+  DROP COLUMN mask;
+
+DROP TABLE IF EXISTS plaid_items;
+`,
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+// Item statuses are named for their health so a working
+// item reads as healthy rather than merely active. Items
+// in error keep needing attention: revoked when their error
+// code says the access token is gone for good, otherwise
+// waiting on the user to log in again
+func init() {
+	register(Migration{
+		Version:     6,
+		Description: "plaid item health statuses",
+		Up: `
+ALTER TABLE plaid_items ALTER COLUMN status SET DEFAULT 'healthy';
+
+UPDATE plaid_items SET status = 'healthy' WHERE status = 'active';
+
+UPDATE plaid_items SET status = CASE
+  WHEN error_code IN ('ACCESS_NOT_GRANTED', 'INVALID_ACCESS_TOKEN', 'ITEM_NOT_FOUND', 'USER_PERMISSION_REVOKED')
+    THEN 'revoked'
+  ELSE 'login_required'
+END
+WHERE status = 'error';
+`,
+		Down: `
+ALTER TABLE plaid_items ALTER COLUMN status SET DEFAULT 'active';
+
+UPDATE plaid_items SET status = 'active' WHERE status IN ('healthy', 'pending_expiration');
+
+UPDATE plaid_items SET status = 'error' WHERE status IN ('login_required', 'revoked');
+`,
+	})
+}
@@ -0,0 +1,44 @@
+package migrations
+
+// Categorization rules let a budget put every transaction
+// matching a set of conditions in a category instead of
+// tagging transaction names one at a time. A rule's conditions
+// all have to match and rules are tried in priority order
+func init() {
+	register(Migration{
+		Version:     7,
+		Description: "budget categorization rules",
+		Up: `
+CREATE TABLE IF NOT EXISTS budget_categorization_rules (
+  rule_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  budget_id UUID NOT NULL,
+  budget_transaction_category_id UUID NOT NULL,
+  priority INT NOT NULL DEFAULT 0,
+  name_contains VARCHAR (255),
+  name_prefix VARCHAR (255),
+  name_regex VARCHAR (255),
+  merchant VARCHAR (255),
+  min_amount NUMERIC (14, 2),
+  max_amount NUMERIC (14, 2),
+  external_account_id UUID,
+  plaid_category VARCHAR (255),
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (budget_id)
+    REFERENCES budgets (budget_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (budget_transaction_category_id)
+    REFERENCES budget_transaction_categories (budget_transaction_category_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (external_account_id)
+    REFERENCES external_accounts (external_account_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS budget_categorization_rules_budget_id_priority_idx
+  ON budget_categorization_rules (budget_id, priority);
+`,
+		Down: `
+DROP TABLE IF EXISTS budget_categorization_rules;
+`,
+	})
+}
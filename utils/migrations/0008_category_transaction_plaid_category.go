@@ -0,0 +1,18 @@
+package migrations
+
+// Transactions users categorize by hand keep the Plaid category
+// they came with so the categorization suggestions can learn
+// from it along with the name
+func init() {
+	register(Migration{
+		Version:     8,
+		Description: "plaid category of hand categorized transactions",
+		Up: `
+ALTER TABLE budget_transaction_category_transactions
+  ADD COLUMN IF NOT EXISTS plaid_category VARCHAR[] NOT NULL DEFAULT '{}';
+`,
+		Down: `
+ALTER TABLE budget_transaction_category_transactions DROP COLUMN IF EXISTS plaid_category;
+`,
+	})
+}
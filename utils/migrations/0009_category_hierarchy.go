@@ -0,0 +1,25 @@
+package migrations
+
+// Transaction categories can sit under a parent category, to any
+// depth, so spending rolls up from "Groceries" into "Food"
+func init() {
+	register(Migration{
+		Version:     9,
+		Description: "transaction category hierarchy",
+		Up: `
+ALTER TABLE budget_transaction_categories
+  ADD COLUMN IF NOT EXISTS parent_category_id UUID,
+  ADD CONSTRAINT budget_transaction_categories_parent_category_id_fkey
+    FOREIGN KEY (parent_category_id)
+    REFERENCES budget_transaction_categories (budget_transaction_category_id);
+
+CREATE INDEX IF NOT EXISTS budget_transaction_categories_parent_category_id_idx
+  ON budget_transaction_categories (parent_category_id);
+`,
+		Down: `
+ALTER TABLE budget_transaction_categories
+  DROP CONSTRAINT IF EXISTS budget_transaction_categories_parent_category_id_fkey,
+  DROP COLUMN IF EXISTS parent_category_id;
+`,
+	})
+}
@@ -0,0 +1,33 @@
+package migrations
+
+// Plaid category mappings put transactions no categorization
+// rule matched in a budget category by the Plaid category they
+// came with. A mapping is for either a Plaid category id or a
+// Plaid category path, which matches every category below it
+func init() {
+	register(Migration{
+		Version:     10,
+		Description: "budget plaid category mappings",
+		Up: `
+CREATE TABLE IF NOT EXISTS budget_plaid_category_mappings (
+  mapping_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  budget_id UUID NOT NULL,
+  budget_transaction_category_id UUID NOT NULL,
+  plaid_category_id VARCHAR (255),
+  plaid_category VARCHAR[],
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (budget_id)
+    REFERENCES budgets (budget_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (budget_transaction_category_id)
+    REFERENCES budget_transaction_categories (budget_transaction_category_id)
+    ON DELETE CASCADE,
+  UNIQUE (budget_id, plaid_category_id),
+  UNIQUE (budget_id, plaid_category)
+);
+`,
+		Down: `
+DROP TABLE IF EXISTS budget_plaid_category_mappings;
+`,
+	})
+}
@@ -0,0 +1,32 @@
+package migrations
+
+// Transactions keep the merchant their name was cleaned up to
+// when synced, and budgets can rename merchants so that names
+// the cleanup leaves apart end up as the same merchant
+func init() {
+	register(Migration{
+		Version:     11,
+		Description: "transaction merchants and budget merchant aliases",
+		Up: `
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS merchant VARCHAR (255);
+
+CREATE TABLE IF NOT EXISTS budget_merchant_aliases (
+  alias_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  budget_id UUID NOT NULL,
+  merchant VARCHAR (255) NOT NULL,
+  alias VARCHAR (255) NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (budget_id)
+    REFERENCES budgets (budget_id)
+    ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS budget_merchant_aliases_budget_id_merchant_idx
+  ON budget_merchant_aliases (budget_id, lower(merchant));
+`,
+		Down: `
+DROP TABLE IF EXISTS budget_merchant_aliases;
+ALTER TABLE transactions DROP COLUMN IF EXISTS merchant;
+`,
+	})
+}
@@ -0,0 +1,38 @@
+package migrations
+
+// Charge cycles follow the calendar instead of a number of days.
+// Expenses keep the date their cycle is anchored to, the number
+// of weeks of "every-n-weeks" cycles and the day of the month of
+// "day-of-month" cycles. Days stays as an approximate length
+func init() {
+	register(Migration{
+		Version:     12,
+		Description: "calendar expense charge cycles",
+		Up: `
+ALTER TABLE expense_charge_cycles DROP CONSTRAINT IF EXISTS expense_charge_cycles_days_key;
+
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('every-n-weeks', 7) ON CONFLICT DO NOTHING;
+INSERT INTO expense_charge_cycles (unit, days) VALUES ('day-of-month', 30) ON CONFLICT DO NOTHING;
+
+ALTER TABLE expenses
+  ADD COLUMN IF NOT EXISTS charge_cycle_anchor DATE,
+  ADD COLUMN IF NOT EXISTS charge_cycle_interval INTEGER,
+  ADD COLUMN IF NOT EXISTS charge_cycle_day INTEGER;
+`,
+		Down: `
+ALTER TABLE expenses
+  DROP COLUMN IF EXISTS charge_cycle_anchor,
+  DROP COLUMN IF EXISTS charge_cycle_interval,
+  DROP COLUMN IF EXISTS charge_cycle_day;
+
+UPDATE expenses SET expense_charge_cycle_id = (SELECT expense_charge_cycle_id FROM expense_charge_cycles WHERE unit = 'weekly')
+  WHERE expense_charge_cycle_id IN (SELECT expense_charge_cycle_id FROM expense_charge_cycles WHERE unit = 'every-n-weeks');
+UPDATE expenses SET expense_charge_cycle_id = (SELECT expense_charge_cycle_id FROM expense_charge_cycles WHERE unit = 'monthly')
+  WHERE expense_charge_cycle_id IN (SELECT expense_charge_cycle_id FROM expense_charge_cycles WHERE unit = 'day-of-month');
+
+DELETE FROM expense_charge_cycles WHERE unit IN ('every-n-weeks', 'day-of-month');
+
+ALTER TABLE expense_charge_cycles ADD CONSTRAINT expense_charge_cycles_days_key UNIQUE (days);
+`,
+	})
+}
@@ -0,0 +1,42 @@
+package migrations
+
+// Expenses can roll what's left of a period, or what was spent
+// over it, into the next one. Finished periods are snapshotted so
+// the carried balance doesn't move when old transactions are
+// categorized differently later
+func init() {
+	register(Migration{
+		Version:     13,
+		Description: "expense rollover and period snapshots",
+		Up: `
+ALTER TABLE expenses
+  ADD COLUMN IF NOT EXISTS rollover BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN IF NOT EXISTS rollover_cap REAL,
+  ADD COLUMN IF NOT EXISTS rollover_start DATE;
+
+CREATE TABLE IF NOT EXISTS expense_period_snapshots (
+  snapshot_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  expense_id UUID NOT NULL,
+  period_start DATE NOT NULL,
+  period_end DATE NOT NULL,
+  limit_amount DOUBLE PRECISION NOT NULL,
+  carried_in DOUBLE PRECISION NOT NULL,
+  actual DOUBLE PRECISION NOT NULL,
+  carried_out DOUBLE PRECISION NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  UNIQUE (expense_id, period_start),
+  FOREIGN KEY (expense_id)
+    REFERENCES expenses (expense_id)
+    ON DELETE CASCADE
+);
+`,
+		Down: `
+DROP TABLE IF EXISTS expense_period_snapshots;
+
+ALTER TABLE expenses
+  DROP COLUMN IF EXISTS rollover,
+  DROP COLUMN IF EXISTS rollover_cap,
+  DROP COLUMN IF EXISTS rollover_start;
+`,
+	})
+}
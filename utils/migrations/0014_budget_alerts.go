@@ -0,0 +1,72 @@
+package migrations
+
+// Budgets can alert users when an expense passes a share of its
+// limit or a single transaction is over an amount. Every alert
+// that went out is recorded so each one only goes out once, and
+// alerts delivered in-app are kept until the user reads them
+func init() {
+	register(Migration{
+		Version:     14,
+		Description: "budget alert rules and notifications",
+		Up: `
+CREATE TABLE IF NOT EXISTS budget_alert_rules (
+  alert_rule_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  budget_id UUID NOT NULL,
+  expense_id UUID,
+  user_id UUID NOT NULL,
+  kind VARCHAR (50) NOT NULL,
+  thresholds INT[],
+  amount NUMERIC (14, 2),
+  channel VARCHAR (50) NOT NULL,
+  webhook_url VARCHAR (2048),
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (budget_id)
+    REFERENCES budgets (budget_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (expense_id)
+    REFERENCES expenses (expense_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (user_id)
+    REFERENCES users (user_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS budget_alert_rules_budget_id_idx
+  ON budget_alert_rules (budget_id);
+
+CREATE TABLE IF NOT EXISTS budget_alerts_fired (
+  alert_rule_id UUID NOT NULL,
+  alert_key VARCHAR (255) NOT NULL,
+  fired_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  PRIMARY KEY (alert_rule_id, alert_key),
+  FOREIGN KEY (alert_rule_id)
+    REFERENCES budget_alert_rules (alert_rule_id)
+    ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+  notification_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL,
+  budget_id UUID,
+  title VARCHAR (255) NOT NULL,
+  message TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  read_at TIMESTAMP,
+  FOREIGN KEY (user_id)
+    REFERENCES users (user_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (budget_id)
+    REFERENCES budgets (budget_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_created_at_idx
+  ON notifications (user_id, created_at);
+`,
+		Down: `
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS budget_alerts_fired;
+DROP TABLE IF EXISTS budget_alert_rules;
+`,
+	})
+}
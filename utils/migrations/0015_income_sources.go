@@ -0,0 +1,46 @@
+package migrations
+
+// Budgets track the income they expect alongside their expenses,
+// each source with an amount and a charge cycle like an expense,
+// and can plan every dollar of it in zero-based mode
+func init() {
+	register(Migration{
+		Version:     15,
+		Description: "income sources and zero-based budgets",
+		Up: `
+ALTER TABLE budgets
+  ADD COLUMN IF NOT EXISTS zero_based BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS income_sources (
+  income_source_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  budget_id UUID NOT NULL,
+  income_source_name VARCHAR (255) NOT NULL,
+  expected_amount NUMERIC (14, 2) NOT NULL,
+  expense_charge_cycle_id INT NOT NULL,
+  charge_cycle_anchor DATE,
+  charge_cycle_interval INTEGER,
+  charge_cycle_day INTEGER,
+  payer VARCHAR (255) NOT NULL,
+  external_account_id UUID,
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (budget_id)
+    REFERENCES budgets (budget_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (expense_charge_cycle_id)
+    REFERENCES expense_charge_cycles (expense_charge_cycle_id),
+  FOREIGN KEY (external_account_id)
+    REFERENCES external_accounts (external_account_id)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS income_sources_budget_id_idx
+  ON income_sources (budget_id);
+`,
+		Down: `
+DROP TABLE IF EXISTS income_sources;
+
+ALTER TABLE budgets
+  DROP COLUMN IF EXISTS zero_based;
+`,
+	})
+}
@@ -0,0 +1,55 @@
+package migrations
+
+// Budgets can save up for goals, either tracking the balance of
+// a linked account or adding up contributions recorded by hand.
+// Goals outlive the account they track and contributions the
+// user who recorded them
+func init() {
+	register(Migration{
+		Version:     16,
+		Description: "savings goals and contributions",
+		Up: `
+CREATE TABLE IF NOT EXISTS savings_goals (
+  goal_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  budget_id UUID NOT NULL,
+  goal_name VARCHAR (255) NOT NULL,
+  target_amount NUMERIC (14, 2) NOT NULL,
+  target_date DATE NOT NULL,
+  external_account_id UUID,
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (budget_id)
+    REFERENCES budgets (budget_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (external_account_id)
+    REFERENCES external_accounts (external_account_id)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS savings_goals_budget_id_idx
+  ON savings_goals (budget_id);
+
+CREATE TABLE IF NOT EXISTS savings_goal_contributions (
+  contribution_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  goal_id UUID NOT NULL,
+  user_id UUID,
+  amount NUMERIC (14, 2) NOT NULL,
+  contribution_date DATE NOT NULL,
+  note VARCHAR (255),
+  created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+  FOREIGN KEY (goal_id)
+    REFERENCES savings_goals (goal_id)
+    ON DELETE CASCADE,
+  FOREIGN KEY (user_id)
+    REFERENCES users (user_id)
+    ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS savings_goal_contributions_goal_id_idx
+  ON savings_goal_contributions (goal_id);
+`,
+		Down: `
+DROP TABLE IF EXISTS savings_goal_contributions;
+DROP TABLE IF EXISTS savings_goals;
+`,
+	})
+}
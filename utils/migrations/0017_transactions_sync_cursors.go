@@ -0,0 +1,22 @@
+package migrations
+
+// Items sync through /transactions/sync, whose cursors are
+// opaque and longer than the dates stored as cursors before.
+// Date cursors are cleared so those items sync from scratch
+func init() {
+	register(Migration{
+		Version:     17,
+		Description: "transactions sync cursors",
+		Up: `
+ALTER TABLE plaid_items ALTER COLUMN sync_cursor TYPE TEXT;
+
+UPDATE plaid_items SET sync_cursor = NULL
+WHERE sync_cursor ~ '^[0-9]{4}-[0-9]{2}-[0-9]{2}$';
+`,
+		Down: `
+UPDATE plaid_items SET sync_cursor = to_char(last_synced_at, 'YYYY-MM-DD');
+
+ALTER TABLE plaid_items ALTER COLUMN sync_cursor TYPE VARCHAR (255);
+`,
+	})
+}
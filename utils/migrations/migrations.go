@@ -0,0 +1,381 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// advisoryLockID ...
+// Arbitrary key used with pg_advisory_lock so that two migrate
+// processes never read and apply the pending steps concurrently
+const advisoryLockID = 7341926
+
+// querier ...
+// The statements migrations run, shared by the connection
+// pool and the single connection holding the migration lock
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Migration ...
+// A single numbered schema change with the SQL
+// needed to apply and revert it
+type Migration struct {
+	Version     int
+	Description string
+	Up          string
+	Down        string
+}
+
+// MigrationStatus ...
+// Applied state of a registered migration
+type MigrationStatus struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   *time.Time
+}
+
+var registry = make(map[int]Migration)
+
+// register ...
+// Adds a migration to the registry. Called from the
+// init function of each numbered migration file
+func register(migration Migration) {
+	if _, exists := registry[migration.Version]; exists {
+		panic(fmt.Sprintf("migration %d registered twice", migration.Version))
+	}
+
+	registry[migration.Version] = migration
+}
+
+// All ...
+// Returns every registered migration ordered by version
+func All() []Migration {
+	all := make([]Migration, 0, len(registry))
+
+	for _, migration := range registry {
+		all = append(all, migration)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Version < all[j].Version
+	})
+
+	return all
+}
+
+// LatestVersion ...
+// Returns the highest registered migration version
+func LatestVersion() int {
+	latest := 0
+
+	for version := range registry {
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest
+}
+
+// ensureMigrationsTable ...
+// Creates the bookkeeping table if it doesn't exist yet
+func ensureMigrationsTable(db querier) error {
+	_, err := db.ExecContext(context.Background(), `CREATE TABLE IF NOT EXISTS schema_migrations (
+  version INTEGER PRIMARY KEY,
+  description VARCHAR NOT NULL,
+  applied_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+)`)
+
+	return err
+}
+
+// appliedVersions ...
+// Returns applied migration versions mapped to when they were applied
+func appliedVersions(db querier) (map[int]time.Time, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(context.Background(), "SELECT version, applied_at FROM schema_migrations")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+
+		if scanErr := rows.Scan(&version, &appliedAt); scanErr != nil {
+			return nil, scanErr
+		}
+
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// CurrentVersion ...
+// Returns the highest applied migration version, 0 if none
+func CurrentVersion(db *sql.DB) (int, error) {
+	applied, err := appliedVersions(db)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return highestVersion(applied), nil
+}
+
+// highestVersion ...
+// The highest of the applied versions, 0 if none
+func highestVersion(applied map[int]time.Time) int {
+	current := 0
+
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+
+	return current
+}
+
+// Status ...
+// Returns the applied state of every registered migration
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	applied, err := appliedVersions(db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(registry))
+
+	for _, migration := range All() {
+		status := MigrationStatus{
+			Version:     migration.Version,
+			Description: migration.Description,
+		}
+
+		if appliedAt, ok := applied[migration.Version]; ok {
+			at := appliedAt
+			status.Applied = true
+			status.AppliedAt = &at
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Up ...
+// Applies every pending migration in order
+func Up(db *sql.DB) error {
+	return To(db, LatestVersion())
+}
+
+// Down ...
+// Reverts the most recently applied migration
+func Down(db *sql.DB) error {
+	return withMigrationLock(db, func(conn querier) error {
+		applied, err := appliedVersions(conn)
+
+		if err != nil {
+			return err
+		}
+
+		current := highestVersion(applied)
+
+		if current == 0 {
+			return nil
+		}
+
+		target := 0
+
+		for _, migration := range All() {
+			if migration.Version < current {
+				target = migration.Version
+			}
+		}
+
+		return migrate(conn, applied, target)
+	})
+}
+
+// To ...
+// Migrates the schema up or down until exactly the
+// migrations with version <= target are applied
+func To(db *sql.DB, target int) error {
+	if _, ok := registry[target]; !ok && target != 0 {
+		return fmt.Errorf("no migration registered with version %d", target)
+	}
+
+	return withMigrationLock(db, func(conn querier) error {
+		applied, err := appliedVersions(conn)
+
+		if err != nil {
+			return err
+		}
+
+		return migrate(conn, applied, target)
+	})
+}
+
+// migrate ...
+// Applies and reverts migrations until exactly the ones with
+// version <= target are applied. Callers hold the migration lock
+func migrate(conn querier, applied map[int]time.Time, target int) error {
+	all := All()
+
+	// Apply pending migrations in ascending order
+	for _, migration := range all {
+		if _, ok := applied[migration.Version]; ok || migration.Version > target {
+			continue
+		}
+
+		if err := apply(conn, migration); err != nil {
+			return err
+		}
+	}
+
+	// Revert migrations above the target in descending order
+	for i := len(all) - 1; i >= 0; i-- {
+		migration := all[i]
+
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= target {
+			continue
+		}
+
+		if err := revert(conn, migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withMigrationLock ...
+// Runs fn on a single connection holding the session level
+// migration lock, so what's pending is read and applied by
+// one process at a time even when several start together
+func withMigrationLock(db *sql.DB, fn func(conn querier) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		return err
+	}
+
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", advisoryLockID)
+
+	return fn(conn)
+}
+
+// EnsureCurrent ...
+// Returns an error if the database is not on the latest
+// schema version, behind it or ahead of this binary. Used to
+// refuse starting the API against a schema it doesn't match
+func EnsureCurrent(db *sql.DB) error {
+	applied, err := appliedVersions(db)
+
+	if err != nil {
+		return err
+	}
+
+	for version := range applied {
+		if _, ok := registry[version]; !ok {
+			return fmt.Errorf(
+				"database schema is ahead of this build, migration %d is applied but unknown (latest known is %d). Deploy a newer build or migrate down",
+				version,
+				LatestVersion(),
+			)
+		}
+	}
+
+	statuses, err := Status(db)
+
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if !status.Applied {
+			return fmt.Errorf(
+				"database schema is out of date, migration %d (%s) has not been applied. Run `make migrate` first",
+				status.Version,
+				status.Description,
+			)
+		}
+	}
+
+	return nil
+}
+
+// apply ...
+// Runs a migration's up step and records it in one transaction
+func apply(db querier, migration Migration) error {
+	return inTransaction(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(migration.Up); err != nil {
+			return fmt.Errorf("applying migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+
+		_, err := tx.Exec(
+			"INSERT INTO schema_migrations (version, description) VALUES ($1, $2)",
+			migration.Version,
+			migration.Description,
+		)
+
+		return err
+	})
+}
+
+// revert ...
+// Runs a migration's down step and removes its record in one transaction
+func revert(db querier, migration Migration) error {
+	return inTransaction(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(migration.Down); err != nil {
+			return fmt.Errorf("reverting migration %d (%s): %w", migration.Version, migration.Description, err)
+		}
+
+		_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+
+		return err
+	})
+}
+
+// inTransaction ...
+// Runs fn in a transaction, rolling back if fn fails
+func inTransaction(db querier, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(context.Background(), nil)
+
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
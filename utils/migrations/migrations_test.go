@@ -0,0 +1,134 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func appliedRows(versions ...int) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+
+	for _, version := range versions {
+		rows.AddRow(version, time.Now())
+	}
+
+	return rows
+}
+
+func registeredVersions() []int {
+	versions := make([]int, 0, len(registry))
+
+	for _, migration := range All() {
+		versions = append(versions, migration.Version)
+	}
+
+	return versions
+}
+
+func TestToReadsAppliedVersionsUnderSessionLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(registeredVersions()...))
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := To(db, LatestVersion()); err != nil {
+		t.Fatalf("To() returned %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestToAppliesPendingMigrationUnderSessionLock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	versions := registeredVersions()
+	latest := registry[LatestVersion()]
+
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(versions[:len(versions)-1]...))
+	mock.ExpectBegin()
+	mock.ExpectExec(".*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(latest.Version, latest.Description).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(advisoryLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err := To(db, latest.Version); err != nil {
+		t.Fatalf("To() returned %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnsureCurrent(t *testing.T) {
+	registered := registeredVersions()
+
+	tests := []struct {
+		name    string
+		applied []int
+		wantErr string
+	}{
+		{
+			name:    "current",
+			applied: registered,
+		},
+		{
+			name:    "behind",
+			applied: registered[:len(registered)-1],
+			wantErr: "out of date",
+		},
+		{
+			name:    "ahead of binary",
+			applied: append(append([]int{}, registered...), LatestVersion()+1),
+			wantErr: "ahead of this build",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer db.Close()
+
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(test.applied...))
+			mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(appliedRows(test.applied...))
+
+			err = EnsureCurrent(db)
+
+			if test.wantErr == "" && err != nil {
+				t.Fatalf("EnsureCurrent() returned %v", err)
+			}
+
+			if test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)) {
+				t.Fatalf("EnsureCurrent() = %v, want error containing %q", err, test.wantErr)
+			}
+		})
+	}
+}